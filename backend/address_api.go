@@ -0,0 +1,422 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/geo"
+)
+
+const maxSavedAddresses = 20
+
+// addressPinToleranceKm is how far a pin dropped by the customer may be from
+// where the address itself geocodes to
+var addressPinToleranceKm = envFloat("ADDRESS_PIN_TOLERANCE_KM", 0.3)
+
+var errPinTooFar = errors.New("The location pin is too far from the address")
+
+// savedAddress is an address book entry as the API reads and writes it
+type savedAddress struct {
+    AddressID int32      `json:"address_id"`
+    Label     string     `json:"label"`
+    Recipient string     `json:"recipient"`
+    Phone     string     `json:"phone"`
+    Line1     string     `json:"line1"`
+    Line2     string     `json:"line2"`
+    City      string     `json:"city"`
+    Postcode  string     `json:"postcode"`
+    Location  *geo.Point `json:"location"`
+    IsDefault bool       `json:"is_default"`
+    Formatted string     `json:"formatted"`
+}
+
+func savedAddressFromRow(row database.Address) savedAddress {
+    address := savedAddress{
+        AddressID: row.AddressID,
+        Label:     row.Label,
+        Recipient: row.Recipient,
+        Phone:     row.Phone,
+        Line1:     row.Line1,
+        Line2:     row.Line2,
+        City:      row.City,
+        Postcode:  row.Postcode,
+        IsDefault: row.IsDefault,
+        Formatted: formatAddress(row),
+    }
+    if row.Lat.Valid && row.Lng.Valid {
+        address.Location = &geo.Point{Lat: row.Lat.Float64, Lng: row.Lng.Float64}
+    }
+    return address
+}
+
+// formatAddress writes a saved address on one line, the way it is stored
+// on orders
+func formatAddress(row database.Address) string {
+    parts := []string{row.Line1}
+    if row.Line2 != "" {
+        parts = append(parts, row.Line2)
+    }
+    parts = append(parts, strings.TrimSpace(row.City+" "+row.Postcode))
+    return strings.Join(parts, ", ")
+}
+
+// placeAddress geocodes address and returns pin instead if the customer
+// dropped one close enough to it. The zone and fee always follow the address
+// itself, so a pin can't move it into a cheaper zone.
+func placeAddress(address string, pin *geo.Point) (geo.Point, error) {
+    point, err := geocodeAddress(address)
+    if err != nil || pin == nil {
+        return point, err
+    }
+    if geo.DistanceKm(point, *pin) > addressPinToleranceKm {
+        return point, errPinTooFar
+    }
+    return *pin, nil
+}
+
+// addressLocation returns the coordinates of a saved address. The address is
+// geocoded again every time; the stored pin is only used near the result.
+func addressLocation(row database.Address) (geo.Point, error) {
+    var pin *geo.Point
+    if row.Lat.Valid && row.Lng.Valid {
+        pin = &geo.Point{Lat: row.Lat.Float64, Lng: row.Lng.Float64}
+    }
+    point, err := placeAddress(formatAddress(row), pin)
+    if errors.Is(err, errPinTooFar) {
+        // Pins saved before they were checked; the address wins
+        return point, nil
+    }
+    return point, err
+}
+
+func validPhone(phone string) bool {
+    digits := 0
+    for _, r := range phone {
+        switch {
+        case r >= '0' && r <= '9':
+            digits++
+        case r == '+' || r == ' ' || r == '-' || r == '(' || r == ')':
+        default:
+            return false
+        }
+    }
+    return digits >= 5 && len(phone) <= 30
+}
+
+// validate tidies the address and checks its fields fit their columns
+func (a *savedAddress) validate() error {
+    for _, field := range []*string{&a.Label, &a.Recipient, &a.Phone, &a.Line1, &a.Line2, &a.City, &a.Postcode} {
+        *field = strings.TrimSpace(*field)
+    }
+    if a.Label == "" {
+        a.Label = "Home"
+    }
+    switch {
+    case len(a.Label) > 50:
+        return errors.New("Label must be at most 50 characters")
+    case a.Recipient == "" || len(a.Recipient) > 100:
+        return errors.New("Recipient is required and must be at most 100 characters")
+    case !validPhone(a.Phone):
+        return errors.New("Invalid phone number")
+    case a.Line1 == "" || len(a.Line1) > 255 || len(a.Line2) > 255:
+        return errors.New("Address line 1 is required and lines must be at most 255 characters")
+    case a.City == "" || len(a.City) > 100:
+        return errors.New("City is required and must be at most 100 characters")
+    case len(a.Postcode) > 20:
+        return errors.New("Postcode must be at most 20 characters")
+    case a.Location != nil && !a.Location.Valid():
+        return errors.New("Invalid coordinates")
+    }
+    return nil
+}
+
+// locate geocodes the address, keeping the customer's pin only if it lies
+// near the result. An address the geocoder can't find, or a pin far from it,
+// is rejected. If the geocoder is down the address is saved without
+// coordinates and geocoded again when ordering.
+func (a *savedAddress) locate() error {
+    point, err := placeAddress(formatAddress(a.row()), a.Location)
+    if errors.Is(err, errAddressNotFound) || errors.Is(err, errPinTooFar) {
+        return err
+    }
+    if err != nil {
+        log.Println("Error geocoding address:", err)
+        a.Location = nil
+        return nil
+    }
+    a.Location = &point
+    return nil
+}
+
+func (a savedAddress) row() database.Address {
+    row := database.Address{
+        AddressID: a.AddressID,
+        Label:     a.Label,
+        Recipient: a.Recipient,
+        Phone:     a.Phone,
+        Line1:     a.Line1,
+        Line2:     a.Line2,
+        City:      a.City,
+        Postcode:  a.Postcode,
+        IsDefault: a.IsDefault,
+    }
+    if a.Location != nil {
+        row.Lat = sql.NullFloat64{Float64: a.Location.Lat, Valid: true}
+        row.Lng = sql.NullFloat64{Float64: a.Location.Lng, Valid: true}
+    }
+    return row
+}
+
+// GET ADDRESSES
+func getAddressesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get addresses request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetAddressesByUser(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Failed to get addresses", http.StatusInternalServerError)
+        return
+    }
+    addresses := make([]savedAddress, len(rows))
+    for i, row := range rows {
+        addresses[i] = savedAddressFromRow(row)
+    }
+
+    resp := struct {
+        Success   bool           `json:"success"`
+        Addresses []savedAddress `json:"addresses"`
+        Message   string         `json:"message"`
+    }{
+        Success:   true,
+        Addresses: addresses,
+        Message:   "Addresses retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// SAVE ADDRESS
+func saveAddressHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Save address request received from user:", username)
+
+    type SaveAddressResponse struct {
+        Success bool         `json:"success"`
+        Address savedAddress `json:"address"`
+        Message string       `json:"message"`
+    }
+
+    // POST adds an address and PUT updates the address with address_id
+    var address savedAddress
+    if err := json.NewDecoder(req.Body).Decode(&address); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if err := address.validate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    count, err := queries.CountAddresses(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Failed to get addresses", http.StatusInternalServerError)
+        return
+    }
+    if req.Method == http.MethodPost {
+        if count >= maxSavedAddresses {
+            http.Error(writer, fmt.Sprintf("You can save at most %d addresses", maxSavedAddresses), http.StatusBadRequest)
+            return
+        }
+        // The first address is the default one
+        address.IsDefault = address.IsDefault || count == 0
+    } else {
+        current, err := queries.GetAddress(context.Background(), database.GetAddressParams{AddressID: address.AddressID, UserID: userID})
+        if err != nil {
+            http.Error(writer, "Invalid address ID", http.StatusBadRequest)
+            return
+        }
+        // Keep the stored coordinates unless the address itself changed
+        if address.Location == nil && formatAddress(current) == formatAddress(address.row()) {
+            address.Location = savedAddressFromRow(current).Location
+        }
+    }
+    if err := address.locate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+    row := address.row()
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    if address.IsDefault {
+        if err := qtx.ClearDefaultAddress(context.Background(), userID); err != nil {
+            http.Error(writer, "Failed to update default address", http.StatusInternalServerError)
+            return
+        }
+    }
+    if req.Method == http.MethodPost {
+        err = qtx.CreateAddress(context.Background(), database.CreateAddressParams{
+            UserID:    userID,
+            Label:     row.Label,
+            Recipient: row.Recipient,
+            Phone:     row.Phone,
+            Line1:     row.Line1,
+            Line2:     row.Line2,
+            City:      row.City,
+            Postcode:  row.Postcode,
+            Lat:       row.Lat,
+            Lng:       row.Lng,
+            IsDefault: row.IsDefault,
+        })
+        if err == nil {
+            row, err = qtx.GetLastInsertedAddress(context.Background())
+        }
+    } else {
+        err = qtx.UpdateAddress(context.Background(), database.UpdateAddressParams{
+            Label:     row.Label,
+            Recipient: row.Recipient,
+            Phone:     row.Phone,
+            Line1:     row.Line1,
+            Line2:     row.Line2,
+            City:      row.City,
+            Postcode:  row.Postcode,
+            Lat:       row.Lat,
+            Lng:       row.Lng,
+            AddressID: row.AddressID,
+            UserID:    userID,
+        })
+        if err == nil && address.IsDefault {
+            _, err = qtx.SetDefaultAddress(context.Background(), database.SetDefaultAddressParams{AddressID: row.AddressID, UserID: userID})
+        }
+        if err == nil {
+            row, err = qtx.GetAddress(context.Background(), database.GetAddressParams{AddressID: row.AddressID, UserID: userID})
+        }
+    }
+    if err != nil {
+        http.Error(writer, "Failed to save address", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to save address", http.StatusInternalServerError)
+        return
+    }
+
+    resp := SaveAddressResponse{Success: true, Address: savedAddressFromRow(row), Message: "Address saved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// DELETE ADDRESS
+func deleteAddressHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Delete address request received from user:", username)
+
+    type DeleteAddressRequest struct {
+        AddressID int32 `json:"address_id"`
+    }
+    type DeleteAddressResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var deleteReq DeleteAddressRequest
+    if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    address, err := qtx.GetAddress(context.Background(), database.GetAddressParams{AddressID: deleteReq.AddressID, UserID: userID})
+    if err != nil {
+        http.Error(writer, "Invalid address ID", http.StatusBadRequest)
+        return
+    }
+
+    // Orders keep their own copy of the address
+    _, err = qtx.DeleteAddress(context.Background(), database.DeleteAddressParams{AddressID: address.AddressID, UserID: userID})
+    if err == nil && address.IsDefault {
+        err = qtx.SetOldestAddressDefault(context.Background(), userID)
+    }
+    if err != nil {
+        http.Error(writer, "Failed to delete address", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to delete address", http.StatusInternalServerError)
+        return
+    }
+
+    resp := DeleteAddressResponse{Success: true, Message: "Address deleted successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -12,12 +12,12 @@ import(
 )
 
 const dbURL = "hahant:123456@tcp(localhost:3306)/fooddb?parseTime=true&tls=false"
-const authKey = "kp7Nbll8hKieGO2L1EQyOphkwJDVH0xD/tOp+DssAJ0Ll1t+jFnqdxK2BOmrAlMzQehX/2v4nt1xdDyXuU0CQQ=="
+const paymentCallbackURL = "http://localhost:8080/payments/webhook/mock_card"
 
 func enableCORS(w http.ResponseWriter) {
     w.Header().Set("Access-Control-Allow-Origin", "*")
     w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 }
 
 // SHOW MENU
@@ -0,0 +1,426 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "os"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/geo"
+)
+
+// restaurantLocation is where delivery distances are measured from
+var restaurantLocation = geo.Point{
+    Lat: envCoordinate("RESTAURANT_LAT", 31.2990, 90),
+    Lng: envCoordinate("RESTAURANT_LNG", 121.5010, 180),
+}
+
+var deliveryGeocoder = newGeocoder()
+
+var (
+    errAddressNotFound = errors.New("Could not find the delivery address")
+    errOutOfZone       = errors.New("Delivery address is outside our delivery area")
+)
+
+// newGeocoder picks the geocoder named by GEOCODER. The default stub works
+// offline and places unknown addresses within 8km of the restaurant.
+func newGeocoder() geo.Geocoder {
+    switch os.Getenv("GEOCODER") {
+    case "nominatim":
+        return geo.NominatimGeocoder{
+            BaseURL:   envString("NOMINATIM_URL", "https://nominatim.openstreetmap.org"),
+            UserAgent: envString("NOMINATIM_USER_AGENT", "ordersystem/1.0"),
+            Client:    &http.Client{Timeout: 10 * time.Second},
+        }
+    case "", "stub":
+        return geo.StubGeocoder{Origin: restaurantLocation, RadiusKm: 8}
+    default:
+        log.Printf("Unknown GEOCODER %q, using the stub geocoder", os.Getenv("GEOCODER"))
+        return geo.StubGeocoder{Origin: restaurantLocation, RadiusKm: 8}
+    }
+}
+
+// deliveryZone is a zone as the API reads and writes it
+type deliveryZone struct {
+    ZoneID   int32       `json:"zone_id"`
+    Name     string      `json:"name"`
+    Kind     string      `json:"kind"`
+    RadiusKm float64     `json:"radius_km,omitempty"`
+    Polygon  []geo.Point `json:"polygon,omitempty"`
+    BaseFee  float64     `json:"base_fee"`
+    FeePerKm float64     `json:"fee_per_km"`
+    MinOrder float64     `json:"min_order"`
+    IsActive bool        `json:"is_active"`
+}
+
+func (z deliveryZone) geoZone() geo.Zone {
+    return geo.Zone{
+        ID:       z.ZoneID,
+        Name:     z.Name,
+        Kind:     z.Kind,
+        RadiusKm: z.RadiusKm,
+        Polygon:  z.Polygon,
+        BaseFee:  z.BaseFee,
+        FeePerKm: z.FeePerKm,
+        MinOrder: z.MinOrder,
+    }
+}
+
+func zoneFromRow(row database.DeliveryZone) (deliveryZone, error) {
+    zone := deliveryZone{
+        ZoneID:   row.ZoneID,
+        Name:     row.Name,
+        Kind:     row.Kind,
+        RadiusKm: row.RadiusKm.Float64,
+        BaseFee:  row.BaseFee,
+        FeePerKm: row.FeePerKm,
+        MinOrder: row.MinOrder,
+        IsActive: row.IsActive,
+    }
+    if row.Polygon.Valid {
+        if err := json.Unmarshal([]byte(row.Polygon.String), &zone.Polygon); err != nil {
+            return zone, fmt.Errorf("zone %d has an invalid polygon: %w", row.ZoneID, err)
+        }
+    }
+    return zone, nil
+}
+
+// zoneColumns converts the shape of a zone to its database columns
+func zoneColumns(zone deliveryZone) (sql.NullFloat64, sql.NullString, error) {
+    if zone.Kind == geo.KindRadius {
+        return sql.NullFloat64{Float64: zone.RadiusKm, Valid: true}, sql.NullString{}, nil
+    }
+    polygon, err := json.Marshal(zone.Polygon)
+    if err != nil {
+        return sql.NullFloat64{}, sql.NullString{}, err
+    }
+    return sql.NullFloat64{}, sql.NullString{String: string(polygon), Valid: true}, nil
+}
+
+// geocodeAddress finds where an address is
+func geocodeAddress(address string) (geo.Point, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    point, err := deliveryGeocoder.Geocode(ctx, address)
+    if errors.Is(err, geo.ErrAddressNotFound) {
+        return point, errAddressNotFound
+    }
+    return point, err
+}
+
+// quoteDeliveryTo finds the zone that delivers to point
+func quoteDeliveryTo(queries *database.Queries, point geo.Point) (geo.Quote, error) {
+    rows, err := queries.GetActiveDeliveryZones(context.Background())
+    if err != nil {
+        return geo.Quote{}, fmt.Errorf("failed to get delivery zones: %w", err)
+    }
+    zones := make([]geo.Zone, 0, len(rows))
+    for _, row := range rows {
+        zone, err := zoneFromRow(row)
+        if err != nil {
+            log.Println("Skipping delivery zone:", err)
+            continue
+        }
+        zones = append(zones, zone.geoZone())
+    }
+
+    quote, ok := geo.Match(zones, restaurantLocation, point)
+    if !ok {
+        return geo.Quote{}, errOutOfZone
+    }
+    return quote, nil
+}
+
+// quoteDelivery geocodes an address and finds the zone that delivers to it
+func quoteDelivery(queries *database.Queries, address string) (geo.Point, geo.Quote, error) {
+    point, err := geocodeAddress(address)
+    if err != nil {
+        return point, geo.Quote{}, err
+    }
+    quote, err := quoteDeliveryTo(queries, point)
+    return point, quote, err
+}
+
+// DELIVERY QUOTE
+func deliveryQuoteHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Delivery quote request received from user:", username)
+
+    address := strings.TrimSpace(req.URL.Query().Get("address"))
+    var addressID int32
+    if value := req.URL.Query().Get("address_id"); value != "" {
+        if _, err := fmt.Sscanf(value, "%d", &addressID); err != nil {
+            http.Error(writer, "Invalid address_id", http.StatusBadRequest)
+            return
+        }
+    }
+    if address == "" && addressID == 0 {
+        http.Error(writer, "Missing address or address_id query parameter", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    var point geo.Point
+    var quote geo.Quote
+    if addressID != 0 {
+        saved, err := queries.GetAddress(context.Background(), database.GetAddressParams{AddressID: addressID, UserID: userID})
+        if err != nil {
+            http.Error(writer, "Invalid address ID", http.StatusBadRequest)
+            return
+        }
+        point, err = addressLocation(saved)
+        if err == nil {
+            quote, err = quoteDeliveryTo(queries, point)
+        }
+        if !writeDeliveryError(writer, err) {
+            return
+        }
+    } else {
+        point, quote, err = quoteDelivery(queries, address)
+        if !writeDeliveryError(writer, err) {
+            return
+        }
+    }
+
+    resp := struct {
+        Success    bool      `json:"success"`
+        Location   geo.Point `json:"location"`
+        ZoneID     int32     `json:"zone_id"`
+        ZoneName   string    `json:"zone_name"`
+        DistanceKm float64   `json:"distance_km"`
+        Fee        float64   `json:"delivery_fee"`
+        MinOrder   float64   `json:"min_order"`
+        Message    string    `json:"message"`
+    }{
+        Success:    true,
+        Location:   point,
+        ZoneID:     quote.Zone.ID,
+        ZoneName:   quote.Zone.Name,
+        DistanceKm: quote.DistanceKm,
+        Fee:        quote.Fee,
+        MinOrder:   quote.Zone.MinOrder,
+        Message:    "We deliver to this address",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// writeDeliveryError reports a failed delivery quote and returns false, or
+// returns true if there was no error
+func writeDeliveryError(writer http.ResponseWriter, err error) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, errAddressNotFound), errors.Is(err, errOutOfZone):
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+    default:
+        log.Println("Error quoting delivery:", err)
+        http.Error(writer, "Failed to look up the delivery address", http.StatusBadGateway)
+    }
+    return false
+}
+
+// GET DELIVERY ZONES
+func getDeliveryZonesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get delivery zones request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetDeliveryZones(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get delivery zones", http.StatusInternalServerError)
+        return
+    }
+    zones := make([]deliveryZone, 0, len(rows))
+    for _, row := range rows {
+        zone, err := zoneFromRow(row)
+        if err != nil {
+            log.Println("Error reading delivery zone:", err)
+        }
+        zones = append(zones, zone)
+    }
+
+    resp := struct {
+        Success    bool           `json:"success"`
+        Restaurant geo.Point      `json:"restaurant"`
+        Zones      []deliveryZone `json:"zones"`
+        Message    string         `json:"message"`
+    }{
+        Success:    true,
+        Restaurant: restaurantLocation,
+        Zones:      zones,
+        Message:    "Delivery zones retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// SAVE DELIVERY ZONE
+func saveDeliveryZoneHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Save delivery zone request received from user:", username)
+
+    type SaveZoneResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    // POST creates a zone and PUT updates the zone with zone_id
+    zone := deliveryZone{IsActive: true}
+    if err := json.NewDecoder(req.Body).Decode(&zone); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    zone.Name = strings.TrimSpace(zone.Name)
+    if zone.Name == "" {
+        http.Error(writer, "Zone name is required", http.StatusBadRequest)
+        return
+    }
+    if err := zone.geoZone().Validate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+    radius, polygon, err := zoneColumns(zone)
+    if err != nil {
+        http.Error(writer, "Invalid polygon", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if req.Method == http.MethodPost {
+        err = queries.CreateDeliveryZone(context.Background(), database.CreateDeliveryZoneParams{
+            Name:     zone.Name,
+            Kind:     zone.Kind,
+            RadiusKm: radius,
+            Polygon:  polygon,
+            BaseFee:  zone.BaseFee,
+            FeePerKm: zone.FeePerKm,
+            MinOrder: zone.MinOrder,
+        })
+    } else {
+        if _, err := queries.GetDeliveryZone(context.Background(), zone.ZoneID); err != nil {
+            http.Error(writer, "Invalid zone ID", http.StatusBadRequest)
+            return
+        }
+        err = queries.UpdateDeliveryZone(context.Background(), database.UpdateDeliveryZoneParams{
+            Name:     zone.Name,
+            Kind:     zone.Kind,
+            RadiusKm: radius,
+            Polygon:  polygon,
+            BaseFee:  zone.BaseFee,
+            FeePerKm: zone.FeePerKm,
+            MinOrder: zone.MinOrder,
+            IsActive: zone.IsActive,
+            ZoneID:   zone.ZoneID,
+        })
+    }
+    if err != nil {
+        http.Error(writer, "Failed to save delivery zone", http.StatusInternalServerError)
+        return
+    }
+
+    resp := SaveZoneResponse{Success: true, Message: "Delivery zone saved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// DELETE DELIVERY ZONE
+func deleteDeliveryZoneHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Delete delivery zone request received from user:", username)
+
+    type DeleteZoneRequest struct {
+        ZoneID int32 `json:"zone_id"`
+    }
+    type DeleteZoneResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var deleteReq DeleteZoneRequest
+    if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // Past orders keep their fee; only the link to the zone is cleared
+    err = queries.DeleteDeliveryZone(context.Background(), deleteReq.ZoneID)
+    if err != nil {
+        http.Error(writer, "Failed to delete delivery zone", http.StatusInternalServerError)
+        return
+    }
+
+    resp := DeleteZoneResponse{Success: true, Message: "Delivery zone deleted successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,1002 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/dispatch"
+    "github.com/Bryanthai/ordersystem/internal/geo"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+var (
+    riderLocationMaxAge = envDuration("RIDER_LOCATION_MAX_AGE", 10*time.Minute)
+    dispatchWindow      = envDuration("DISPATCH_WINDOW", 2*time.Hour)
+    proofPhotoDir       = envString("DELIVERY_PHOTO_DIR", "./static/images/delivery")
+)
+
+var (
+    errNotDeliverable     = errors.New("Order is not a finished delivery order")
+    errUnknownRider       = errors.New("Invalid rider ID")
+    errNoRiderAvailable   = errors.New("No rider is available right now")
+    errAlreadyAssigned    = errors.New("Order already has a rider")
+    errDeliveryInProgress = errors.New("Order has already been picked up")
+    errAlreadyDelivered   = errors.New("Order has already been delivered")
+    errInvalidPhoto       = errors.New("Photo must be a .jpg, .jpeg, .png or .webp file")
+)
+
+// dispatchOrder hands a finished delivery order to riderID, or to the idle
+// rider nearest the restaurant when riderID is 0. A manual assignment
+// replaces a rider who hasn't picked the order up yet.
+func dispatchOrder(db *sql.DB, queries *database.Queries, orderID, riderID int32, assignedBy sql.NullInt32) (database.Delivery, error) {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return database.Delivery{}, err
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    order, err := qtx.GetOrderByIdForUpdate(context.Background(), orderID)
+    if errors.Is(err, sql.ErrNoRows) || (err == nil && (!order.IsRanged || !order.IsDone || order.Deleted)) {
+        return database.Delivery{}, errNotDeliverable
+    }
+    if err != nil {
+        return database.Delivery{}, fmt.Errorf("failed to get order: %w", err)
+    }
+
+    latest, err := qtx.GetLatestDeliveryByOrder(context.Background(), orderID)
+    switch {
+    case errors.Is(err, sql.ErrNoRows):
+    case err != nil:
+        return database.Delivery{}, fmt.Errorf("failed to get delivery: %w", err)
+    case latest.Status == dispatch.StatusPickedUp:
+        return database.Delivery{}, errDeliveryInProgress
+    case latest.Status == dispatch.StatusDelivered:
+        return database.Delivery{}, errAlreadyDelivered
+    case latest.Status == dispatch.StatusAssigned:
+        if riderID == 0 || riderID == latest.RiderID {
+            return database.Delivery{}, errAlreadyAssigned
+        }
+        _, err = qtx.CancelDelivery(context.Background(), database.CancelDeliveryParams{
+            FailureReason: sql.NullString{String: "Reassigned", Valid: true},
+            DeliveryID:    latest.DeliveryID,
+        })
+        if err != nil {
+            return database.Delivery{}, fmt.Errorf("failed to cancel previous delivery: %w", err)
+        }
+    }
+
+    if riderID != 0 {
+        _, err := qtx.GetRiderForUpdate(context.Background(), riderID)
+        if errors.Is(err, sql.ErrNoRows) {
+            return database.Delivery{}, errUnknownRider
+        }
+        if err != nil {
+            return database.Delivery{}, fmt.Errorf("failed to get rider: %w", err)
+        }
+    } else {
+        riders, err := qtx.GetIdleRidersForUpdate(context.Background())
+        if err != nil {
+            return database.Delivery{}, fmt.Errorf("failed to get riders: %w", err)
+        }
+        candidates := make([]dispatch.Candidate, len(riders))
+        for i, rider := range riders {
+            candidates[i] = dispatch.Candidate{
+                RiderID:        rider.UserID,
+                Location:       geo.Point{Lat: rider.Lat.Float64, Lng: rider.Lng.Float64},
+                AvailableSince: rider.StatusAt.Time,
+            }
+            if rider.LocatedAt.Valid && rider.Lat.Valid && rider.Lng.Valid {
+                candidates[i].LocatedAt = rider.LocatedAt.Time
+            }
+        }
+        nearest, ok := dispatch.Nearest(candidates, restaurantLocation, time.Now().UTC(), riderLocationMaxAge)
+        if !ok {
+            return database.Delivery{}, errNoRiderAvailable
+        }
+        riderID = nearest.RiderID
+    }
+
+    err = qtx.CreateDelivery(context.Background(), database.CreateDeliveryParams{
+        OrderID:    orderID,
+        RiderID:    riderID,
+        AssignedBy: assignedBy,
+        AssignedAt: time.Now().UTC(),
+    })
+    if err != nil {
+        return database.Delivery{}, fmt.Errorf("failed to create delivery: %w", err)
+    }
+    delivery, err := qtx.GetLastInsertedDelivery(context.Background())
+    if err != nil {
+        return database.Delivery{}, fmt.Errorf("failed to retrieve delivery: %w", err)
+    }
+    if err := tx.Commit(); err != nil {
+        return database.Delivery{}, err
+    }
+    log.Printf("Order %d assigned to rider %d", orderID, riderID)
+    return delivery, nil
+}
+
+// writeDispatchError reports a failed assignment and returns false, or
+// returns true if there was no error
+func writeDispatchError(writer http.ResponseWriter, err error) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, errNotDeliverable), errors.Is(err, errUnknownRider):
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+    case errors.Is(err, errNoRiderAvailable), errors.Is(err, errAlreadyAssigned),
+        errors.Is(err, errDeliveryInProgress), errors.Is(err, errAlreadyDelivered):
+        http.Error(writer, err.Error(), http.StatusConflict)
+    default:
+        log.Println("Error assigning delivery:", err)
+        http.Error(writer, "Failed to assign delivery", http.StatusInternalServerError)
+    }
+    return false
+}
+
+// dispatchReadyDeliveries assigns riders to recently finished delivery
+// orders that never got one, e.g. because every rider was busy
+func dispatchReadyDeliveries() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Dispatch: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    since := sql.NullTime{Time: time.Now().UTC().Add(-dispatchWindow), Valid: true}
+    orders, err := queries.GetUndispatchedDeliveryOrders(context.Background(), since)
+    if err != nil {
+        log.Println("Dispatch: failed to get orders:", err)
+        return
+    }
+    for _, order := range orders {
+        _, err := dispatchOrder(db, queries, order.OrderID, 0, sql.NullInt32{})
+        if errors.Is(err, errNoRiderAvailable) {
+            return
+        }
+        if err != nil {
+            log.Printf("Dispatch: order %d: %v", order.OrderID, err)
+        }
+    }
+}
+
+// getRider returns the rider profile of the caller
+func getRider(queries *database.Queries, userID int32) (database.Rider, error) {
+    return queries.GetRider(context.Background(), userID)
+}
+
+// saveProofPhoto stores the optional "photo" upload of a delivery and
+// returns its file name
+func saveProofPhoto(req *http.Request, deliveryID int32) (sql.NullString, error) {
+    file, header, err := req.FormFile("photo")
+    if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+        return sql.NullString{}, nil
+    }
+    if err != nil {
+        return sql.NullString{}, err
+    }
+    defer file.Close()
+
+    ext := strings.ToLower(filepath.Ext(header.Filename))
+    switch ext {
+    case ".jpg", ".jpeg", ".png", ".webp":
+    default:
+        return sql.NullString{}, errInvalidPhoto
+    }
+
+    if err := os.MkdirAll(proofPhotoDir, 0o755); err != nil {
+        return sql.NullString{}, err
+    }
+    name := fmt.Sprintf("%d-%d%s", deliveryID, time.Now().UTC().Unix(), ext)
+    out, err := os.Create(filepath.Join(proofPhotoDir, name))
+    if err != nil {
+        return sql.NullString{}, err
+    }
+    defer out.Close()
+    if _, err := io.Copy(out, file); err != nil {
+        return sql.NullString{}, err
+    }
+    return sql.NullString{String: name, Valid: true}, nil
+}
+
+// ADD RIDER
+func addRiderHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Add rider request received from user:", username)
+
+    type AddRiderRequest struct {
+        UserID int32 `json:"user_id"`
+    }
+    type AddRiderResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var riderReq AddRiderRequest
+    if err := json.NewDecoder(req.Body).Decode(&riderReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if _, err := queries.GetAccountByID(context.Background(), riderReq.UserID); err != nil {
+        http.Error(writer, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    err = queries.UpsertRider(context.Background(), riderReq.UserID)
+    if err != nil {
+        http.Error(writer, "Failed to add rider", http.StatusInternalServerError)
+        return
+    }
+    err = queries.GrantRole(context.Background(), database.GrantRoleParams{
+        UserID:    riderReq.UserID,
+        Role:      rbac.RoleRider,
+        GrantedBy: sql.NullInt32{Int32: userID, Valid: true},
+        GrantedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to add rider", http.StatusInternalServerError)
+        return
+    }
+
+    resp := AddRiderResponse{Success: true, Message: "Rider added successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// REMOVE RIDER
+func removeRiderHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Remove rider request received from user:", username)
+
+    type RemoveRiderRequest struct {
+        UserID int32 `json:"user_id"`
+    }
+    type RemoveRiderResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var riderReq RemoveRiderRequest
+    if err := json.NewDecoder(req.Body).Decode(&riderReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    active, err := queries.GetActiveDeliveriesByRider(context.Background(), riderReq.UserID)
+    if err != nil {
+        http.Error(writer, "Failed to get rider deliveries", http.StatusInternalServerError)
+        return
+    }
+    if len(active) > 0 {
+        http.Error(writer, "Rider still has active deliveries", http.StatusConflict)
+        return
+    }
+
+    // Riders are deactivated rather than deleted to keep delivery history
+    rows, err := queries.DeactivateRider(context.Background(), riderReq.UserID)
+    if err != nil {
+        http.Error(writer, "Failed to remove rider", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Invalid rider ID", http.StatusBadRequest)
+        return
+    }
+    _, err = queries.RevokeRole(context.Background(), database.RevokeRoleParams{
+        UserID: riderReq.UserID,
+        Role:   rbac.RoleRider,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to remove rider", http.StatusInternalServerError)
+        return
+    }
+
+    resp := RemoveRiderResponse{Success: true, Message: "Rider removed successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET RIDERS
+func getRidersHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get riders request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    riders, err := queries.GetRiders(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get riders", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool                    `json:"success"`
+        Riders  []database.GetRidersRow `json:"riders"`
+        Message string                  `json:"message"`
+    }{
+        Success: true,
+        Riders:  riders,
+        Message: "Riders retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// ASSIGN DELIVERY
+func assignDeliveryHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Assign delivery request received from user:", username)
+
+    type AssignDeliveryRequest struct {
+        OrderID int32 `json:"order_id"`
+        RiderID int32 `json:"rider_id"`
+    }
+    type AssignDeliveryResponse struct {
+        Success  bool              `json:"success"`
+        Delivery database.Delivery `json:"delivery"`
+        Message  string            `json:"message"`
+    }
+
+    var assignReq AssignDeliveryRequest
+    if err := json.NewDecoder(req.Body).Decode(&assignReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    delivery, err := dispatchOrder(db, queries, assignReq.OrderID, assignReq.RiderID, sql.NullInt32{Int32: userID, Valid: true})
+    if !writeDispatchError(writer, err) {
+        return
+    }
+
+    resp := AssignDeliveryResponse{Success: true, Delivery: delivery, Message: "Delivery assigned successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET DELIVERIES
+func getDeliveriesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get deliveries request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    deliveries, err := queries.GetDeliveries(context.Background(), database.GetDeliveriesParams{
+        Status: req.URL.Query().Get("status"),
+        Limit:  100,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get deliveries", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success    bool                `json:"success"`
+        Deliveries []database.Delivery `json:"deliveries"`
+        Message    string              `json:"message"`
+    }{
+        Success:    true,
+        Deliveries: deliveries,
+        Message:    "Deliveries retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// RIDER STATUS
+func riderStatusHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Rider status request received from user:", username)
+
+    type RiderStatusRequest struct {
+        Status string `json:"status"`
+    }
+    type RiderStatusResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var statusReq RiderStatusRequest
+    if err := json.NewDecoder(req.Body).Decode(&statusReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if statusReq.Status != dispatch.RiderAvailable && statusReq.Status != dispatch.RiderOffline {
+        http.Error(writer, "Status must be available or offline", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rider, err := getRider(queries, userID)
+    if err != nil {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    err = queries.UpdateRiderStatus(context.Background(), database.UpdateRiderStatusParams{
+        Status:   statusReq.Status,
+        StatusAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID:   rider.UserID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update status", http.StatusInternalServerError)
+        return
+    }
+
+    resp := RiderStatusResponse{Success: true, Message: "Status updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// RIDER LOCATION
+func riderLocationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Rider location request received from user:", username)
+
+    type RiderLocationResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var point geo.Point
+    if err := json.NewDecoder(req.Body).Decode(&point); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if !point.Valid() {
+        http.Error(writer, "Invalid coordinates", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rider, err := getRider(queries, userID)
+    if err != nil {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    err = queries.UpdateRiderLocation(context.Background(), database.UpdateRiderLocationParams{
+        Lat:       sql.NullFloat64{Float64: point.Lat, Valid: true},
+        Lng:       sql.NullFloat64{Float64: point.Lng, Valid: true},
+        LocatedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID:    rider.UserID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update location", http.StatusInternalServerError)
+        return
+    }
+
+    // Keep a trail for every order the rider is carrying
+    active, err := queries.GetActiveDeliveriesByRider(context.Background(), rider.UserID)
+    if err != nil {
+        log.Println("Error getting rider deliveries:", err)
+    }
+    trail := []sql.NullInt32{}
+    for _, delivery := range active {
+        if delivery.Status == dispatch.StatusPickedUp {
+            trail = append(trail, sql.NullInt32{Int32: delivery.DeliveryID, Valid: true})
+        }
+    }
+    if len(trail) == 0 {
+        trail = append(trail, sql.NullInt32{})
+    }
+    for _, deliveryID := range trail {
+        err = queries.CreateRiderLocation(context.Background(), database.CreateRiderLocationParams{
+            RiderID:    rider.UserID,
+            DeliveryID: deliveryID,
+            Lat:        point.Lat,
+            Lng:        point.Lng,
+        })
+        if err != nil {
+            log.Println("Error recording rider location:", err)
+        }
+    }
+
+    resp := RiderLocationResponse{Success: true, Message: "Location updated"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET RIDER DELIVERIES
+func getRiderDeliveriesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get rider deliveries request received from user:", username)
+
+    type riderDelivery struct {
+        Delivery database.Delivery `json:"delivery"`
+        Order    database.Order    `json:"order"`
+        Items    []database.Item   `json:"items"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rider, err := getRider(queries, userID)
+    if err != nil {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    active, err := queries.GetActiveDeliveriesByRider(context.Background(), rider.UserID)
+    if err != nil {
+        http.Error(writer, "Failed to get deliveries", http.StatusInternalServerError)
+        return
+    }
+    deliveries := make([]riderDelivery, 0, len(active))
+    for _, delivery := range active {
+        order, err := queries.GetOrderById(context.Background(), delivery.OrderID)
+        if err != nil {
+            http.Error(writer, "Failed to get order", http.StatusInternalServerError)
+            return
+        }
+        items, err := queries.GetOrderedItems(context.Background(), delivery.OrderID)
+        if err != nil {
+            http.Error(writer, "Failed to get ordered items", http.StatusInternalServerError)
+            return
+        }
+        deliveries = append(deliveries, riderDelivery{Delivery: delivery, Order: order, Items: items})
+    }
+
+    resp := struct {
+        Success    bool            `json:"success"`
+        Status     string          `json:"status"`
+        Pickup     geo.Point       `json:"pickup"`
+        Deliveries []riderDelivery `json:"deliveries"`
+        Message    string          `json:"message"`
+    }{
+        Success:    true,
+        Status:     rider.Status,
+        Pickup:     restaurantLocation,
+        Deliveries: deliveries,
+        Message:    "Deliveries retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// UPDATE DELIVERY
+func updateDeliveryHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Update delivery request received from user:", username)
+
+    type UpdateDeliveryResponse struct {
+        Success  bool              `json:"success"`
+        Delivery database.Delivery `json:"delivery"`
+        Message  string            `json:"message"`
+    }
+
+    // A form rather than JSON so a proof photo can be attached
+    err := req.ParseMultipartForm(10 << 20)
+    if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+        http.Error(writer, "Invalid form data", http.StatusBadRequest)
+        return
+    }
+
+    var deliveryID int32
+    if _, err := fmt.Sscanf(req.FormValue("delivery_id"), "%d", &deliveryID); err != nil {
+        http.Error(writer, "Invalid delivery_id", http.StatusBadRequest)
+        return
+    }
+    status := req.FormValue("status")
+    note := strings.TrimSpace(req.FormValue("note"))
+    reason := strings.TrimSpace(req.FormValue("reason"))
+    if status != dispatch.StatusPickedUp && status != dispatch.StatusDelivered && status != dispatch.StatusFailed {
+        http.Error(writer, "Status must be picked_up, delivered or failed", http.StatusBadRequest)
+        return
+    }
+    if status == dispatch.StatusFailed && reason == "" {
+        http.Error(writer, "A reason is required for failed deliveries", http.StatusBadRequest)
+        return
+    }
+    if len(note) > 255 || len(reason) > 255 {
+        http.Error(writer, "Note and reason must be at most 255 characters", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rider, err := getRider(queries, userID)
+    if err != nil {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    delivery, err := queries.GetDelivery(context.Background(), deliveryID)
+    if err != nil || delivery.RiderID != rider.UserID {
+        http.Error(writer, "Invalid delivery ID", http.StatusBadRequest)
+        return
+    }
+    if err := dispatch.Transition(delivery.Status, status); err != nil {
+        http.Error(writer, err.Error(), http.StatusConflict)
+        return
+    }
+
+    var photo sql.NullString
+    if status != dispatch.StatusPickedUp {
+        photo, err = saveProofPhoto(req, delivery.DeliveryID)
+        if errors.Is(err, errInvalidPhoto) {
+            http.Error(writer, err.Error(), http.StatusBadRequest)
+            return
+        }
+        if err != nil {
+            log.Println("Error saving proof photo:", err)
+            http.Error(writer, "Failed to save photo", http.StatusInternalServerError)
+            return
+        }
+    }
+
+    now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+    proofNote := sql.NullString{String: note, Valid: note != ""}
+    var rows int64
+    switch status {
+    case dispatch.StatusPickedUp:
+        rows, err = queries.MarkDeliveryPickedUp(context.Background(), database.MarkDeliveryPickedUpParams{
+            PickedUpAt: now,
+            DeliveryID: delivery.DeliveryID,
+        })
+    case dispatch.StatusDelivered:
+        rows, err = queries.MarkDeliveryDelivered(context.Background(), database.MarkDeliveryDeliveredParams{
+            DeliveredAt: now,
+            ProofNote:   proofNote,
+            ProofPhoto:  photo,
+            DeliveryID:  delivery.DeliveryID,
+        })
+    case dispatch.StatusFailed:
+        rows, err = queries.MarkDeliveryFailed(context.Background(), database.MarkDeliveryFailedParams{
+            FailedAt:      now,
+            FailureReason: sql.NullString{String: reason, Valid: true},
+            ProofNote:     proofNote,
+            ProofPhoto:    photo,
+            DeliveryID:    delivery.DeliveryID,
+        })
+    }
+    if err != nil {
+        http.Error(writer, "Failed to update delivery", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Delivery has changed, please refresh", http.StatusConflict)
+        return
+    }
+
+    // Rider tips belong to whoever completed the delivery
+    if status == dispatch.StatusDelivered {
+        err = queries.SetRiderTipRecipient(context.Background(), database.SetRiderTipRecipientParams{
+            RecipientID: sql.NullInt32{Int32: rider.UserID, Valid: true},
+            OrderID:     delivery.OrderID,
+        })
+        if err != nil {
+            log.Println("Error assigning rider tips:", err)
+        }
+    }
+
+    delivery, err = queries.GetDelivery(context.Background(), delivery.DeliveryID)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve delivery", http.StatusInternalServerError)
+        return
+    }
+
+    resp := UpdateDeliveryResponse{Success: true, Delivery: delivery, Message: "Delivery updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// canViewDelivery reports whether the caller may follow the delivery of
+// order: its customer, dispatch staff or its rider
+func canViewDelivery(req *http.Request, queries *database.Queries, order database.Order, delivery *database.Delivery) bool {
+    _, userID := currentUser(req)
+    if order.UserID == userID || rbac.Can(currentRoles(req), rbac.PermDelivery) {
+        return true
+    }
+    if delivery != nil && delivery.RiderID == userID {
+        _, err := getRider(queries, userID)
+        return err == nil
+    }
+    return false
+}
+
+// GET ORDER DELIVERY
+func getOrderDeliveryHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get order delivery request received from user:", username)
+
+    type OrderDeliveryResponse struct {
+        Success       bool       `json:"success"`
+        OrderID       int32      `json:"order_id"`
+        Status        string     `json:"status"`
+        RiderName     string     `json:"rider_name,omitempty"`
+        AssignedAt    *time.Time `json:"assigned_at,omitempty"`
+        PickedUpAt    *time.Time `json:"picked_up_at,omitempty"`
+        DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+        FailedAt      *time.Time `json:"failed_at,omitempty"`
+        FailureReason string     `json:"failure_reason,omitempty"`
+        ProofNote     string     `json:"proof_note,omitempty"`
+        HasPhoto      bool       `json:"has_photo"`
+        RiderLocation *geo.Point `json:"rider_location,omitempty"`
+        LocatedAt     *time.Time `json:"located_at,omitempty"`
+        Message       string     `json:"message"`
+    }
+
+    var orderID int32
+    if _, err := fmt.Sscanf(req.URL.Query().Get("order_id"), "%d", &orderID); err != nil {
+        http.Error(writer, "Invalid order_id", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), orderID)
+    if err != nil || !order.IsRanged {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+
+    var current *database.Delivery
+    delivery, err := queries.GetLatestDeliveryByOrder(context.Background(), orderID)
+    if err == nil {
+        current = &delivery
+    } else if !errors.Is(err, sql.ErrNoRows) {
+        http.Error(writer, "Failed to get delivery", http.StatusInternalServerError)
+        return
+    }
+    if !canViewDelivery(req, queries, order, current) {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    resp := OrderDeliveryResponse{Success: true, OrderID: orderID, Message: "Delivery retrieved successfully"}
+    switch {
+    case !order.IsDone:
+        resp.Status = "preparing"
+    case current == nil || current.Status == dispatch.StatusCancelled:
+        resp.Status = "awaiting_rider"
+    default:
+        resp.Status = current.Status
+    }
+
+    if current != nil && current.Status != dispatch.StatusCancelled {
+        rider, err := queries.GetAccountByID(context.Background(), current.RiderID)
+        if err == nil {
+            resp.RiderName = rider.Username
+        }
+        resp.AssignedAt = &current.AssignedAt
+        resp.PickedUpAt = nullTimePtr(current.PickedUpAt)
+        resp.DeliveredAt = nullTimePtr(current.DeliveredAt)
+        resp.FailedAt = nullTimePtr(current.FailedAt)
+        resp.FailureReason = current.FailureReason.String
+        resp.ProofNote = current.ProofNote.String
+        resp.HasPhoto = current.ProofPhoto.Valid
+
+        // Only share where the rider is while they're carrying this order
+        if current.Status == dispatch.StatusPickedUp {
+            location, err := queries.GetRider(context.Background(), current.RiderID)
+            if err == nil && location.Lat.Valid && location.Lng.Valid {
+                resp.RiderLocation = &geo.Point{Lat: location.Lat.Float64, Lng: location.Lng.Float64}
+                resp.LocatedAt = nullTimePtr(location.LocatedAt)
+            }
+        }
+    }
+
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+    if !t.Valid {
+        return nil
+    }
+    return &t.Time
+}
+
+// GET DELIVERY PHOTO
+func getDeliveryPhotoHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get delivery photo request received from user:", username)
+
+    var orderID int32
+    if _, err := fmt.Sscanf(req.URL.Query().Get("order_id"), "%d", &orderID); err != nil {
+        http.Error(writer, "Invalid order_id", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), orderID)
+    if err != nil {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+    delivery, err := queries.GetLatestDeliveryByOrder(context.Background(), orderID)
+    if err != nil || !canViewDelivery(req, queries, order, &delivery) {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+    if !delivery.ProofPhoto.Valid {
+        http.Error(writer, "No photo for this delivery", http.StatusNotFound)
+        return
+    }
+
+    http.ServeFile(writer, req, filepath.Join(proofPhotoDir, filepath.Base(delivery.ProofPhoto.String)))
+}
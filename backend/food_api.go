@@ -11,7 +11,6 @@ import(
     "os"
 
 	"github.com/Bryanthai/ordersystem/internal/database"
-    "github.com/Bryanthai/ordersystem/internal/auth"
 )
 
 // CREATE NEW FOOD
@@ -22,17 +21,7 @@ func createFoodHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Create food request received from user:", username)
 
@@ -41,7 +30,7 @@ func createFoodHandler(writer http.ResponseWriter, req *http.Request) {
         Message string `json:"message"`
     }
 
-    err = req.ParseMultipartForm(10 << 20)
+    err := req.ParseMultipartForm(10 << 20)
     if err != nil {
         http.Error(writer, "Invalid form data", http.StatusBadRequest)
         return
@@ -94,12 +83,6 @@ func createFoodHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     var infoStruct sql.NullString
     infoStruct.String = info
     infoStruct.Valid = info != ""
@@ -153,17 +136,7 @@ func alterFoodHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Alter food request received from user:", username)
 
@@ -196,12 +169,6 @@ func alterFoodHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
 	var infoStruct sql.NullString
 	infoStruct.String = foodReq.Info
 	infoStruct.Valid = foodReq.Info != ""
@@ -257,17 +224,7 @@ func deleteFoodHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Delete food request received from user:", username)
 
@@ -293,11 +250,6 @@ func deleteFoodHandler(writer http.ResponseWriter, req *http.Request) {
     defer db.Close()
 
     queries := database.New(db)
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
     err = queries.DeleteFood(context.Background(), delReq.FoodName)
     if err != nil {
         http.Error(writer, "Failed to delete food item", http.StatusInternalServerError)
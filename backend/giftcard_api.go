@@ -0,0 +1,638 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/giftcard"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+)
+
+// Cards expire a year after they are issued unless an admin says otherwise
+const giftCardValidityDays = 365
+
+func recordGiftCardTransaction(queries *database.Queries, cardID, accountID int32, kind string, amount, balanceAfter float64, intentID sql.NullInt32, note string) error {
+    err := queries.CreateGiftCardTransaction(context.Background(), database.CreateGiftCardTransactionParams{
+        CardID:       cardID,
+        AccountID:    sql.NullInt32{Int32: accountID, Valid: accountID != 0},
+        Kind:         kind,
+        Amount:       amount,
+        BalanceAfter: balanceAfter,
+        IntentID:     intentID,
+        Note:         sql.NullString{String: note, Valid: note != ""},
+    })
+    if err != nil {
+        return fmt.Errorf("failed to record gift card transaction: %w", err)
+    }
+    return nil
+}
+
+// createGiftCard stores a new card under a freshly generated code
+func createGiftCard(queries *database.Queries, params database.CreateGiftCardParams) (database.GiftCard, error) {
+    code, err := giftcard.Generate(nil)
+    if err != nil {
+        return database.GiftCard{}, err
+    }
+    params.Code = code
+    params.Balance = params.InitialAmount
+    if err := queries.CreateGiftCard(context.Background(), params); err != nil {
+        return database.GiftCard{}, fmt.Errorf("failed to create gift card: %w", err)
+    }
+    return queries.GetLastInsertedGiftCard(context.Background())
+}
+
+// activateGiftCard releases a purchased card once its payment succeeds
+func activateGiftCard(queries *database.Queries, intent database.PaymentIntent) error {
+    rows, err := queries.ActivateGiftCard(context.Background(), intent.GiftCardID.Int32)
+    if err != nil {
+        return fmt.Errorf("failed to activate gift card: %w", err)
+    }
+    if rows == 0 {
+        return fmt.Errorf("gift card %d is not awaiting payment", intent.GiftCardID.Int32)
+    }
+    card, err := queries.GetGiftCard(context.Background(), intent.GiftCardID.Int32)
+    if err != nil {
+        return fmt.Errorf("failed to retrieve gift card: %w", err)
+    }
+    return recordGiftCardTransaction(queries, card.CardID, intent.UserID, giftcard.TxPurchase, card.InitialAmount, card.Balance, sql.NullInt32{Int32: intent.IntentID, Valid: true}, "")
+}
+
+// voidGiftCard cancels a card and writes off whatever balance it had left
+func voidGiftCard(queries *database.Queries, cardID, accountID int32, intentID sql.NullInt32, note string) error {
+    card, err := queries.GetGiftCard(context.Background(), cardID)
+    if err != nil {
+        return fmt.Errorf("failed to retrieve gift card: %w", err)
+    }
+    if card.Status == giftcard.StatusVoid {
+        return nil
+    }
+    if err := queries.VoidGiftCard(context.Background(), cardID); err != nil {
+        return fmt.Errorf("failed to void gift card: %w", err)
+    }
+    return recordGiftCardTransaction(queries, cardID, accountID, giftcard.TxVoid, card.Balance, 0, intentID, note)
+}
+
+// PURCHASE GIFT CARD
+func purchaseGiftCardHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Purchase gift card request received from user:", username)
+
+    type PurchaseRequest struct {
+        Amount  float64 `json:"amount"`
+        Message string  `json:"message"`
+    }
+    type PurchaseResponse struct {
+        Success  bool              `json:"success"`
+        GiftCard database.GiftCard `json:"gift_card"`
+        Message  string            `json:"message"`
+    }
+
+    var purchaseReq PurchaseRequest
+    if err := json.NewDecoder(req.Body).Decode(&purchaseReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if err := giftcard.CheckAmount(purchaseReq.Amount); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // The card stays pending until the wallet payment goes through
+    card, err := createGiftCard(queries, database.CreateGiftCardParams{
+        InitialAmount: payment.RoundAmount(purchaseReq.Amount),
+        Status:        giftcard.StatusPending,
+        Message:       sql.NullString{String: purchaseReq.Message, Valid: purchaseReq.Message != ""},
+        PurchasedBy:   sql.NullInt32{Int32: userID, Valid: true},
+        ExpiresAt:     sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, giftCardValidityDays), Valid: true},
+    })
+    if err != nil {
+        log.Println("Error creating gift card:", err)
+        http.Error(writer, "Failed to create gift card", http.StatusInternalServerError)
+        return
+    }
+
+    wallet := paymentProviders(queries)["wallet"]
+    intent, err := startPayment(queries, wallet, database.CreatePaymentIntentParams{
+        UserID:     userID,
+        GiftCardID: sql.NullInt32{Int32: card.CardID, Valid: true},
+        Purpose:    payment.PurposeGiftCard,
+        Amount:     card.InitialAmount,
+    })
+    if err != nil {
+        log.Println("Error starting payment:", err)
+        voidGiftCard(queries, card.CardID, userID, sql.NullInt32{}, "payment could not be started")
+        http.Error(writer, "Failed to create payment", http.StatusInternalServerError)
+        return
+    }
+    _, err = confirmPayment(db, queries, wallet, intent, payment.Method{})
+    if !writePaymentError(writer, err) {
+        return
+    }
+
+    card, err = queries.GetGiftCard(context.Background(), card.CardID)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve gift card", http.StatusInternalServerError)
+        return
+    }
+
+    resp := PurchaseResponse{Success: true, GiftCard: card, Message: "Gift card purchased successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ISSUE GIFT CARD
+func issueGiftCardHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Issue gift card request received from user:", username)
+
+    type IssueRequest struct {
+        Amount        float64 `json:"amount"`
+        Message       string  `json:"message"`
+        ExpiresInDays int     `json:"expires_in_days"`
+    }
+    type IssueResponse struct {
+        Success  bool              `json:"success"`
+        GiftCard database.GiftCard `json:"gift_card"`
+        Message  string            `json:"message"`
+    }
+
+    var issueReq IssueRequest
+    if err := json.NewDecoder(req.Body).Decode(&issueReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if err := giftcard.CheckAmount(issueReq.Amount); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if issueReq.ExpiresInDays < 0 {
+        http.Error(writer, "Invalid expires_in_days", http.StatusBadRequest)
+        return
+    }
+    if issueReq.ExpiresInDays == 0 {
+        issueReq.ExpiresInDays = giftCardValidityDays
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    card, err := createGiftCard(qtx, database.CreateGiftCardParams{
+        InitialAmount: payment.RoundAmount(issueReq.Amount),
+        Status:        giftcard.StatusActive,
+        Message:       sql.NullString{String: issueReq.Message, Valid: issueReq.Message != ""},
+        IssuedBy:      sql.NullInt32{Int32: userID, Valid: true},
+        ExpiresAt:     sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, issueReq.ExpiresInDays), Valid: true},
+    })
+    if err != nil {
+        log.Println("Error creating gift card:", err)
+        http.Error(writer, "Failed to create gift card", http.StatusInternalServerError)
+        return
+    }
+    err = recordGiftCardTransaction(qtx, card.CardID, userID, giftcard.TxIssue, card.InitialAmount, card.Balance, sql.NullInt32{}, "")
+    if err != nil {
+        http.Error(writer, "Failed to record gift card transaction", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to issue gift card", http.StatusInternalServerError)
+        return
+    }
+
+    resp := IssueResponse{Success: true, GiftCard: card, Message: "Gift card issued successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// REDEEM GIFT CARD
+func redeemGiftCardHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Redeem gift card request received from user:", username)
+
+    type RedeemRequest struct {
+        Code   string  `json:"code"`
+        Amount float64 `json:"amount"`
+    }
+    type RedeemResponse struct {
+        Success          bool    `json:"success"`
+        Redeemed         float64 `json:"redeemed"`
+        RemainingBalance float64 `json:"remaining_balance"`
+        WalletBalance    float64 `json:"wallet_balance"`
+        Message          string  `json:"message"`
+    }
+
+    var redeemReq RedeemRequest
+    if err := json.NewDecoder(req.Body).Decode(&redeemReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    code, err := giftcard.Normalize(redeemReq.Code)
+    if err != nil {
+        http.Error(writer, "Invalid gift card code", http.StatusBadRequest)
+        return
+    }
+    log.Println("Redeeming gift card", giftcard.Mask(code))
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account := currentAccount(req)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    // Lock the card so two redemptions can't spend the same balance
+    card, err := qtx.GetGiftCardByCodeForUpdate(context.Background(), code)
+    if err == sql.ErrNoRows {
+        http.Error(writer, "Invalid gift card code", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        http.Error(writer, "Failed to retrieve gift card", http.StatusInternalServerError)
+        return
+    }
+
+    var expiresAt time.Time
+    if card.ExpiresAt.Valid {
+        expiresAt = card.ExpiresAt.Time
+    }
+    amount, err := giftcard.RedeemAmount(card.Status, card.Balance, expiresAt, time.Now(), redeemReq.Amount)
+    if err != nil {
+        status := http.StatusBadRequest
+        if errors.Is(err, giftcard.ErrExpired) || errors.Is(err, giftcard.ErrNotActive) || errors.Is(err, giftcard.ErrNoBalance) {
+            status = http.StatusConflict
+        }
+        http.Error(writer, err.Error(), status)
+        return
+    }
+    remaining := billing.FromCents(billing.ToCents(card.Balance) - billing.ToCents(amount))
+
+    err = qtx.UpdateGiftCardBalance(context.Background(), database.UpdateGiftCardBalanceParams{
+        Balance: remaining,
+        CardID:  card.CardID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update gift card", http.StatusInternalServerError)
+        return
+    }
+    // The wallet column can't hold more than maxBalance
+    rows, err := qtx.CreditAccountBalance(context.Background(), database.CreditAccountBalanceParams{
+        Amount:     amount,
+        ID:         userID,
+        MaxBalance: maxBalance,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update user balance", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, fmt.Sprintf("Wallet balance cannot exceed %.2f", maxBalance), http.StatusConflict)
+        return
+    }
+    err = recordGiftCardTransaction(qtx, card.CardID, userID, giftcard.TxRedeem, amount, remaining, sql.NullInt32{}, "")
+    if err != nil {
+        http.Error(writer, "Failed to record gift card transaction", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to redeem gift card", http.StatusInternalServerError)
+        return
+    }
+
+    account, err = queries.GetAccountByID(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve account", http.StatusInternalServerError)
+        return
+    }
+
+    resp := RedeemResponse{
+        Success:          true,
+        Redeemed:         amount,
+        RemainingBalance: remaining,
+        WalletBalance:    account.Balance,
+        Message:          "Gift card redeemed successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GIFT CARD BALANCE
+func giftCardBalanceHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Gift card balance request received from user:", username)
+
+    code, err := giftcard.Normalize(req.URL.Query().Get("code"))
+    if err != nil {
+        http.Error(writer, "Invalid gift card code", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    card, err := queries.GetGiftCardByCode(context.Background(), code)
+    if err != nil {
+        http.Error(writer, "Invalid gift card code", http.StatusNotFound)
+        return
+    }
+
+    // Only what a holder of the code needs to know
+    resp := struct {
+        Success   bool         `json:"success"`
+        Code      string       `json:"code"`
+        Balance   float64      `json:"balance"`
+        Status    string       `json:"status"`
+        ExpiresAt sql.NullTime `json:"expires_at"`
+        Message   string       `json:"message"`
+    }{
+        Success:   true,
+        Code:      card.Code,
+        Balance:   card.Balance,
+        Status:    card.Status,
+        ExpiresAt: card.ExpiresAt,
+        Message:   "Gift card balance retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET PURCHASED GIFT CARDS
+func getMyGiftCardsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get purchased gift cards request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    cards, err := queries.GetGiftCardsByPurchaser(context.Background(), sql.NullInt32{Int32: userID, Valid: true})
+    if err != nil {
+        http.Error(writer, "Failed to get gift cards", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success   bool                `json:"success"`
+        GiftCards []database.GiftCard `json:"gift_cards"`
+        Message   string              `json:"message"`
+    }{
+        Success:   true,
+        GiftCards: cards,
+        Message:   "Gift cards retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET ALL GIFT CARDS
+func getAllGiftCardsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get all gift cards request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    cards, err := queries.GetAllGiftCards(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get gift cards", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success   bool                `json:"success"`
+        GiftCards []database.GiftCard `json:"gift_cards"`
+        Message   string              `json:"message"`
+    }{
+        Success:   true,
+        GiftCards: cards,
+        Message:   "Gift cards retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET GIFT CARD TRANSACTIONS
+func getGiftCardTransactionsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get gift card transactions request received from user:", username)
+
+    cardIDStr := req.URL.Query().Get("card_id")
+    if cardIDStr == "" {
+        http.Error(writer, "Missing card_id query parameter", http.StatusBadRequest)
+        return
+    }
+    var cardID int32
+    if _, err := fmt.Sscanf(cardIDStr, "%d", &cardID); err != nil {
+        http.Error(writer, "Invalid card_id", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    card, err := queries.GetGiftCard(context.Background(), cardID)
+    if err != nil {
+        http.Error(writer, "Gift card not found", http.StatusNotFound)
+        return
+    }
+    transactions, err := queries.GetGiftCardTransactions(context.Background(), cardID)
+    if err != nil {
+        http.Error(writer, "Failed to get gift card transactions", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success      bool                           `json:"success"`
+        GiftCard     database.GiftCard              `json:"gift_card"`
+        Transactions []database.GiftCardTransaction `json:"transactions"`
+        Message      string                         `json:"message"`
+    }{
+        Success:      true,
+        GiftCard:     card,
+        Transactions: transactions,
+        Message:      "Gift card transactions retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// VOID GIFT CARD
+func voidGiftCardHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Void gift card request received from user:", username)
+
+    type VoidRequest struct {
+        CardID int32  `json:"card_id"`
+        Note   string `json:"note"`
+    }
+    type VoidResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var voidReq VoidRequest
+    if err := json.NewDecoder(req.Body).Decode(&voidReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    card, err := queries.GetGiftCard(context.Background(), voidReq.CardID)
+    if err != nil || card.Status != giftcard.StatusActive {
+        http.Error(writer, "Only active gift cards can be voided", http.StatusBadRequest)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+
+    if err := voidGiftCard(queries.WithTx(tx), card.CardID, userID, sql.NullInt32{}, voidReq.Note); err != nil {
+        log.Println("Error voiding gift card:", err)
+        http.Error(writer, "Failed to void gift card", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to void gift card", http.StatusInternalServerError)
+        return
+    }
+
+    resp := VoidResponse{Success: true, Message: "Gift card voided"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,480 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/hours"
+)
+
+// holidayAllTypes marks a holiday that applies to every order type
+const holidayAllTypes = "all"
+
+var orderTypes = []string{orderTypeDineIn, orderTypeTakeaway, orderTypeDelivery}
+
+func validOrderType(orderType string) bool {
+    for _, t := range orderTypes {
+        if t == orderType {
+            return true
+        }
+    }
+    return false
+}
+
+// parseWeekday reads a day name such as "monday"
+func parseWeekday(name string) (time.Weekday, bool) {
+    for day := time.Sunday; day <= time.Saturday; day++ {
+        if strings.EqualFold(name, day.String()) {
+            return day, true
+        }
+    }
+    return 0, false
+}
+
+// splitPeriod gives the opening and closing times of p as they are stored
+func splitPeriod(p hours.Period) (string, string) {
+    opens, closes, _ := strings.Cut(p.String(), "-")
+    return opens, closes
+}
+
+// holidayDate is the date of a holiday as it is stored, at midnight UTC
+func holidayDate(t time.Time) time.Time {
+    y, m, d := t.Date()
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// holidayPeriods are the hours the store keeps on a holiday; none when it
+// is closed all day
+func holidayPeriods(holiday database.StoreHoliday) ([]hours.Period, error) {
+    if !holiday.OpensAt.Valid {
+        return nil, nil
+    }
+    p, err := hours.ParsePeriod(holiday.OpensAt.String + "-" + holiday.ClosesAt.String)
+    if err != nil {
+        return nil, err
+    }
+    return []hours.Period{p}, nil
+}
+
+// loadCalendars reads the opening hours of every order type. A holiday for
+// one order type takes the place of a holiday for all of them on that date.
+func loadCalendars(queries *database.Queries) (map[string]hours.Calendar, error) {
+    rows, err := queries.GetOpeningHours(context.Background())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get opening hours: %w", err)
+    }
+    yesterday := time.Now().In(storeLocation).AddDate(0, 0, -1)
+    holidays, err := queries.GetStoreHolidays(context.Background(), holidayDate(yesterday))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get holidays: %w", err)
+    }
+
+    weeks := make(map[string]map[time.Weekday][]hours.Period)
+    for _, row := range rows {
+        p, err := hours.ParsePeriod(row.OpensAt + "-" + row.ClosesAt)
+        if err != nil {
+            log.Printf("Skipping opening hours %d: %v", row.HoursID, err)
+            continue
+        }
+        if weeks[row.OrderType] == nil {
+            weeks[row.OrderType] = make(map[time.Weekday][]hours.Period)
+        }
+        day := time.Weekday(row.Weekday)
+        weeks[row.OrderType][day] = append(weeks[row.OrderType][day], p)
+    }
+
+    calendars := make(map[string]hours.Calendar, len(orderTypes))
+    for _, orderType := range orderTypes {
+        calendar := hours.Calendar{Week: weeks[orderType], Exceptions: make(map[string][]hours.Period)}
+        for _, match := range []string{holidayAllTypes, orderType} {
+            for _, holiday := range holidays {
+                if holiday.OrderType != match {
+                    continue
+                }
+                periods, err := holidayPeriods(holiday)
+                if err != nil {
+                    log.Printf("Skipping holiday %d: %v", holiday.HolidayID, err)
+                    continue
+                }
+                calendar.Exceptions[holiday.HolidayDate.Format(time.DateOnly)] = periods
+            }
+        }
+        calendars[orderType] = calendar
+    }
+    return calendars, nil
+}
+
+// storeClosedMessage tells a customer when they can order again
+func storeClosedMessage(calendar hours.Calendar, orderType string, now time.Time) string {
+    kind := strings.Replace(orderType, "_", "-", 1)
+    opens, _, ok := calendar.NextOpen(now)
+    if !ok {
+        return fmt.Sprintf("We're closed for %s orders", kind)
+    }
+    return fmt.Sprintf("We're closed for %s orders right now, we open again at %s", kind, opens.In(storeLocation).Format("Mon 2 Jan 15:04"))
+}
+
+// storeHours is whether one order type is open and for how long
+type storeHours struct {
+    Open     bool       `json:"open"`
+    NextOpen *time.Time `json:"next_open,omitempty"`
+    ClosesAt *time.Time `json:"closes_at,omitempty"`
+}
+
+// STORE STATUS
+func storeStatusHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Store status request received")
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    calendars, err := loadCalendars(queries)
+    if err != nil {
+        log.Println("Error loading opening hours:", err)
+        http.Error(writer, "Failed to get opening hours", http.StatusInternalServerError)
+        return
+    }
+
+    now := time.Now().In(storeLocation)
+    status := make(map[string]storeHours, len(orderTypes))
+    for _, orderType := range orderTypes {
+        var entry storeHours
+        opens, closes, ok := calendars[orderType].NextOpen(now)
+        if ok {
+            entry.Open = !opens.After(now)
+            if !entry.Open {
+                entry.NextOpen = &opens
+            }
+            entry.ClosesAt = &closes
+        }
+        status[orderType] = entry
+    }
+
+    resp := struct {
+        Success    bool                  `json:"success"`
+        TimeZone   string                `json:"time_zone"`
+        Now        time.Time             `json:"now"`
+        OrderTypes map[string]storeHours `json:"order_types"`
+        Message    string                `json:"message"`
+    }{
+        Success:    true,
+        TimeZone:   storeLocation.String(),
+        Now:        now,
+        OrderTypes: status,
+        Message:    "Store status retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET OPENING HOURS
+func getOpeningHoursHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get opening hours request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetOpeningHours(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get opening hours", http.StatusInternalServerError)
+        return
+    }
+    holidays, err := queries.GetStoreHolidays(context.Background(), holidayDate(time.Now().In(storeLocation)))
+    if err != nil {
+        http.Error(writer, "Failed to get holidays", http.StatusInternalServerError)
+        return
+    }
+
+    // Order types without hours are left out; they are always open
+    week := make(map[string]map[string][]string)
+    for _, row := range rows {
+        if week[row.OrderType] == nil {
+            week[row.OrderType] = make(map[string][]string)
+        }
+        day := strings.ToLower(time.Weekday(row.Weekday).String())
+        week[row.OrderType][day] = append(week[row.OrderType][day], row.OpensAt+"-"+row.ClosesAt)
+    }
+
+    resp := struct {
+        Success  bool                           `json:"success"`
+        TimeZone string                         `json:"time_zone"`
+        Hours    map[string]map[string][]string `json:"hours"`
+        Holidays []database.StoreHoliday        `json:"holidays"`
+        Message  string                         `json:"message"`
+    }{
+        Success:  true,
+        TimeZone: storeLocation.String(),
+        Hours:    week,
+        Holidays: holidays,
+        Message:  "Opening hours retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// SET OPENING HOURS
+func setOpeningHoursHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Set opening hours request received from user:", username)
+
+    type OpeningHoursRequest struct {
+        OrderType string              `json:"order_type"`
+        Days      map[string][]string `json:"days"`
+    }
+    type OpeningHoursResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var hoursReq OpeningHoursRequest
+    if err := json.NewDecoder(req.Body).Decode(&hoursReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if !validOrderType(hoursReq.OrderType) {
+        http.Error(writer, "Invalid order_type", http.StatusBadRequest)
+        return
+    }
+    var params []database.CreateOpeningHoursParams
+    for name, periods := range hoursReq.Days {
+        day, ok := parseWeekday(name)
+        if !ok {
+            http.Error(writer, "Invalid day "+name, http.StatusBadRequest)
+            return
+        }
+        for _, value := range periods {
+            p, err := hours.ParsePeriod(value)
+            if err != nil {
+                http.Error(writer, err.Error(), http.StatusBadRequest)
+                return
+            }
+            opens, closes := splitPeriod(p)
+            params = append(params, database.CreateOpeningHoursParams{
+                OrderType: hoursReq.OrderType,
+                Weekday:   int32(day),
+                OpensAt:   opens,
+                ClosesAt:  closes,
+            })
+        }
+    }
+    // A week without hours would read as open around the clock
+    if len(params) == 0 {
+        http.Error(writer, `Give opening hours for at least one day, or "00:00-24:00" to stay open`, http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // The new week replaces the old one as a whole
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    if err := qtx.DeleteOpeningHours(context.Background(), hoursReq.OrderType); err != nil {
+        http.Error(writer, "Failed to update opening hours", http.StatusInternalServerError)
+        return
+    }
+    for _, param := range params {
+        if err := qtx.CreateOpeningHours(context.Background(), param); err != nil {
+            http.Error(writer, "Failed to update opening hours", http.StatusInternalServerError)
+            return
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to update opening hours", http.StatusInternalServerError)
+        return
+    }
+
+    resp := OpeningHoursResponse{Success: true, Message: "Opening hours updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// SAVE HOLIDAY
+func saveHolidayHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Save holiday request received from user:", username)
+
+    type HolidayRequest struct {
+        Date      string `json:"date"`
+        OrderType string `json:"order_type"`
+        Hours     string `json:"hours"`
+        Note      string `json:"note"`
+    }
+    type HolidayResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var holidayReq HolidayRequest
+    if err := json.NewDecoder(req.Body).Decode(&holidayReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    date, err := time.Parse(time.DateOnly, holidayReq.Date)
+    if err != nil {
+        http.Error(writer, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+        return
+    }
+    if holidayReq.OrderType == "" {
+        holidayReq.OrderType = holidayAllTypes
+    }
+    if holidayReq.OrderType != holidayAllTypes && !validOrderType(holidayReq.OrderType) {
+        http.Error(writer, "Invalid order_type", http.StatusBadRequest)
+        return
+    }
+    holidayReq.Note = strings.TrimSpace(holidayReq.Note)
+    if len(holidayReq.Note) > 255 {
+        http.Error(writer, "Note is too long", http.StatusBadRequest)
+        return
+    }
+    // Without hours the store is closed all day
+    var opensAt, closesAt sql.NullString
+    if holidayReq.Hours != "" {
+        p, err := hours.ParsePeriod(holidayReq.Hours)
+        if err != nil {
+            http.Error(writer, err.Error(), http.StatusBadRequest)
+            return
+        }
+        opens, closes := splitPeriod(p)
+        opensAt = sql.NullString{String: opens, Valid: true}
+        closesAt = sql.NullString{String: closes, Valid: true}
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.SaveStoreHoliday(context.Background(), database.SaveStoreHolidayParams{
+        HolidayDate: date,
+        OrderType:   holidayReq.OrderType,
+        OpensAt:     opensAt,
+        ClosesAt:    closesAt,
+        Note:        holidayReq.Note,
+        CreatedBy:   sql.NullInt32{Int32: userID, Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to save holiday", http.StatusInternalServerError)
+        return
+    }
+
+    resp := HolidayResponse{Success: true, Message: "Holiday saved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// DELETE HOLIDAY
+func deleteHolidayHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Delete holiday request received from user:", username)
+
+    type DeleteHolidayRequest struct {
+        HolidayID int32 `json:"holiday_id"`
+    }
+    type DeleteHolidayResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var deleteReq DeleteHolidayRequest
+    if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.DeleteStoreHoliday(context.Background(), deleteReq.HolidayID)
+    if err != nil {
+        http.Error(writer, "Failed to delete holiday", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Invalid holiday ID", http.StatusBadRequest)
+        return
+    }
+
+    resp := DeleteHolidayResponse{Success: true, Message: "Holiday deleted successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
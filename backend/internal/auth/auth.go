@@ -6,11 +6,16 @@ import (
 	"strconv"
 	"fmt"
 	"net/http"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"unicode"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-const key = "kp7Nbll8hKieGO2L1EQyOphkwJDVH0xD/tOp+DssAJ0Ll1t+jFnqdxK2BOmrAlMzQehX/2v4nt1xdDyXuU0CQQ=="
-
 func HashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -23,33 +28,119 @@ func CheckPasswordHash(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-func MakeJWT(userID int32, username string, expiresIn time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    "takeaway-dine_in-system",
-		Subject:   username,
-		ID:        strconv.Itoa(int(userID)),
-		ExpiresAt: &jwt.NumericDate{
-			Time: time.Now().Add(expiresIn),
-		},
-		IssuedAt:  &jwt.NumericDate{
-			Time: time.Now(),
+// Password limits. bcrypt ignores everything past 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+var ErrWeakPassword = errors.New("weak password")
+
+// CheckPasswordStrength reports why password is not good enough for the
+// account called username
+func CheckPasswordStrength(password, username string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrWeakPassword, MaxPasswordLength)
+	}
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return fmt.Errorf("%w: must contain both letters and digits", ErrWeakPassword)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("%w: must not contain the username", ErrWeakPassword)
+	}
+	return nil
+}
+
+// Claims are what an access token says about its holder. SessionID is the
+// login session the token was issued for, or 0 for tokens without one.
+type Claims struct {
+	Username  string
+	UserID    int32
+	SessionID int32
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID int32 `json:"sid,omitempty"`
+}
+
+// MakeJWT issues an access token signed with the set's signing key and
+// tagged with its key ID
+func (ks *KeySet) MakeJWT(userID int32, username string, sessionID int32, expiresIn time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(ks.signing.method, tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ks.issuer,
+			Subject:   username,
+			ID:        strconv.Itoa(int(userID)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
+		SessionID: sessionID,
 	})
-	tokenString, err := token.SignedString([]byte(key))
-	return tokenString, err
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signKey)
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (string, int32, error) {
-	var token *jwt.Token
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(tokenSecret), nil
+// ParseJWT checks an access token and returns its claims. The token must
+// name a known key, use that key's algorithm, come from the set's issuer
+// and carry an expiry that has not passed.
+func (ks *KeySet) ParseJWT(tokenString string) (Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(ks.methods()),
+		jwt.WithIssuer(ks.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	token, err := parser.ParseWithClaims(tokenString, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrWrongAlgorithm
+		}
+		return key.verifyKey, nil
 	})
 	if err != nil {
-		return "", 0, err
+		return Claims{}, err
+	}
+
+	claims := token.Claims.(*tokenClaims)
+	id, err := strconv.ParseInt(claims.ID, 10, 32)
+	if err != nil || claims.Subject == "" {
+		return Claims{}, fmt.Errorf("Invalid subject in token")
 	}
+	return Claims{Username: claims.Subject, UserID: int32(id), SessionID: claims.SessionID}, nil
+}
+
+// MakeToken returns a random opaque token for refresh tokens and reset
+// links. Only its hash is stored.
+func MakeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	id, err := strconv.ParseInt(token.Claims.(*jwt.RegisteredClaims).ID, 10, 32)
-	return token.Claims.(*jwt.RegisteredClaims).Subject, int32(id), nil
+// HashToken is the SHA-256 of token in hex, as stored server-side
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
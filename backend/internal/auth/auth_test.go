@@ -1,69 +1,269 @@
 package auth
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
+const testIssuer = "takeaway-dine_in-system"
+
+func testKey(t *testing.T, id string) Key {
+	t.Helper()
+	key, err := NewHMACKey(id, []byte(strings.Repeat(id, 32)))
+	if err != nil {
+		t.Fatalf("NewHMACKey: %v", err)
+	}
+	return key
+}
+
+func testKeySet(t *testing.T, signing Key, others ...Key) *KeySet {
+	t.Helper()
+	ks, err := NewKeySet(testIssuer, signing, others...)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	return ks
+}
+
 func TestValidateJWT_ValidToken(t *testing.T) {
+	ks := testKeySet(t, testKey(t, "a"))
 	userID := int32(123)
 	username := "testuser"
 	expiresIn := time.Minute * 5
 
-	// Generate a valid token using the same key
-	tokenString, err := MakeJWT(userID, username, expiresIn)
+	tokenString, err := ks.MakeJWT(userID, username, 42, expiresIn)
 	if err != nil {
 		t.Fatalf("failed to create JWT: %v", err)
 	}
 
-	gotUsername, gotUserID, err := ValidateJWT(tokenString, key)
+	claims, err := ks.ParseJWT(tokenString)
 	if err != nil {
-		t.Fatalf("ValidateJWT returned error: %v", err)
+		t.Fatalf("ParseJWT returned error: %v", err)
+	}
+	if claims.Username != username {
+		t.Errorf("expected username %q, got %q", username, claims.Username)
 	}
-	if gotUsername != username {
-		t.Errorf("expected username %q, got %q", username, gotUsername)
+	if claims.UserID != userID {
+		t.Errorf("expected userID %d, got %d", userID, claims.UserID)
 	}
-	if gotUserID != userID {
-		t.Errorf("expected userID %d, got %d", userID, gotUserID)
+	if claims.SessionID != 42 {
+		t.Errorf("expected session 42, got %d", claims.SessionID)
 	}
 }
 
 func TestValidateJWT_InvalidSignature(t *testing.T) {
-	userID := int32(456)
-	username := "anotheruser"
-	expiresIn := time.Minute * 5
-
-	tokenString, err := MakeJWT(userID, username, expiresIn)
+	tokenString, err := testKeySet(t, testKey(t, "a")).MakeJWT(456, "anotheruser", 1, time.Minute*5)
 	if err != nil {
 		t.Fatalf("failed to create JWT: %v", err)
 	}
 
-	// Use a wrong secret
-	_, _, err = ValidateJWT(tokenString, "wrong_secret")
-	if err == nil {
+	// Same key ID, different secret
+	other, err := NewHMACKey("a", []byte(strings.Repeat("z", 32)))
+	if err != nil {
+		t.Fatalf("NewHMACKey: %v", err)
+	}
+	if _, err := testKeySet(t, other).ParseJWT(tokenString); err == nil {
 		t.Error("expected error for invalid signature, got nil")
 	}
 }
 
 func TestValidateJWT_ExpiredToken(t *testing.T) {
-	userID := int32(789)
-	username := "expireduser"
-	expiresIn := -time.Minute // already expired
-
-	tokenString, err := MakeJWT(userID, username, expiresIn)
+	ks := testKeySet(t, testKey(t, "a"))
+	tokenString, err := ks.MakeJWT(789, "expireduser", 1, -time.Minute)
 	if err != nil {
 		t.Fatalf("failed to create JWT: %v", err)
 	}
 
-	_, _, err = ValidateJWT(tokenString, key)
-	if err == nil {
+	if _, err := ks.ParseJWT(tokenString); err == nil {
 		t.Error("expected error for expired token, got nil")
 	}
 }
 
 func TestValidateJWT_MalformedToken(t *testing.T) {
-	_, _, err := ValidateJWT("not.a.jwt.token", key)
-	if err == nil {
+	if _, err := testKeySet(t, testKey(t, "a")).ParseJWT("not.a.jwt.token"); err == nil {
 		t.Error("expected error for malformed token, got nil")
 	}
 }
+
+func TestParseJWT_Rotation(t *testing.T) {
+	old, current := testKey(t, "old"), testKey(t, "new")
+	tokenString, err := testKeySet(t, old).MakeJWT(1, "user", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create JWT: %v", err)
+	}
+
+	// Tokens from the retiring key still work while it is listed
+	if _, err := testKeySet(t, current, old).ParseJWT(tokenString); err != nil {
+		t.Errorf("expected token from old key to verify, got %v", err)
+	}
+	_, err = testKeySet(t, current).ParseJWT(tokenString)
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey once the old key is dropped, got %v", err)
+	}
+}
+
+func TestParseJWT_StrictClaims(t *testing.T) {
+	key := testKey(t, "a")
+	ks := testKeySet(t, key)
+	sign := func(method jwt.SigningMethod, header map[string]interface{}, claims jwt.RegisteredClaims, secret interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		for k, v := range header {
+			token.Header[k] = v
+		}
+		s, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+	valid := jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Subject:   "user",
+		ID:        "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	secret := key.signKey
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "someone-else"
+
+	cases := map[string]string{
+		"missing kid":     sign(jwt.SigningMethodHS256, nil, valid, secret),
+		"wrong algorithm": sign(jwt.SigningMethodHS512, map[string]interface{}{"kid": "a"}, valid, secret),
+		"no expiry":       sign(jwt.SigningMethodHS256, map[string]interface{}{"kid": "a"}, noExpiry, secret),
+		"wrong issuer":    sign(jwt.SigningMethodHS256, map[string]interface{}{"kid": "a"}, wrongIssuer, secret),
+		"alg none":        sign(jwt.SigningMethodNone, map[string]interface{}{"kid": "a"}, valid, jwt.UnsafeAllowNoneSignatureType),
+	}
+	for name, tokenString := range cases {
+		if _, err := ks.ParseJWT(tokenString); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+	if _, err := ks.ParseJWT(sign(jwt.SigningMethodHS256, map[string]interface{}{"kid": "a"}, valid, secret)); err != nil {
+		t.Errorf("expected hand-built token to verify, got %v", err)
+	}
+}
+
+func TestParsePEMKey_EdDSA(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+
+	signing, err := ParsePEMKey("ed", AlgEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		t.Fatalf("ParsePEMKey private: %v", err)
+	}
+	verifying, err := ParsePEMKey("ed", AlgEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatalf("ParsePEMKey public: %v", err)
+	}
+	if !signing.CanSign() || verifying.CanSign() {
+		t.Fatal("only the private key should sign")
+	}
+	if _, err := NewKeySet(testIssuer, verifying); err == nil {
+		t.Error("expected a public key to be refused as the signing key")
+	}
+
+	tokenString, err := testKeySet(t, signing).MakeJWT(5, "user", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create JWT: %v", err)
+	}
+	// A service holding only the public key can verify it
+	other := testKey(t, "hs")
+	if _, err := testKeySet(t, other, verifying).ParseJWT(tokenString); err != nil {
+		t.Errorf("expected EdDSA token to verify with the public key, got %v", err)
+	}
+}
+
+func TestParseKeySpec(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("s", 32)))
+	files := map[string][]byte{}
+	readFile := func(path string) ([]byte, error) {
+		data, ok := files[path]
+		if !ok {
+			return nil, errors.New("no such file")
+		}
+		return data, nil
+	}
+
+	keys, err := ParseKeySpec("k1:HS256:"+secret+", k2:HS256:"+secret, readFile)
+	if err != nil {
+		t.Fatalf("ParseKeySpec: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k1" || keys[1].ID != "k2" {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+
+	bad := []string{
+		"k1:HS256",
+		"k1:HS256:not base64!",
+		"k1:HS256:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"k1:RS256:/missing.pem",
+		"k1:ES256:" + secret,
+	}
+	for _, spec := range bad {
+		if _, err := ParseKeySpec(spec, readFile); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKeySpec(%q) = %v, want ErrInvalidKey", spec, err)
+		}
+	}
+}
+
+func TestMakeToken(t *testing.T) {
+	first, err := MakeToken()
+	if err != nil {
+		t.Fatalf("MakeToken returned error: %v", err)
+	}
+	second, err := MakeToken()
+	if err != nil {
+		t.Fatalf("MakeToken returned error: %v", err)
+	}
+	if first == second {
+		t.Error("expected different refresh tokens")
+	}
+	if HashToken(first) != HashToken(first) || HashToken(first) == HashToken(second) {
+		t.Error("expected hashes to identify tokens")
+	}
+	if len(HashToken(first)) != 64 {
+		t.Errorf("expected a 64 character hash, got %d", len(HashToken(first)))
+	}
+}
+
+func TestCheckPasswordStrength(t *testing.T) {
+	cases := []struct {
+		password string
+		ok       bool
+	}{
+		{"abc123", false},
+		{"abcdefgh", false},
+		{"12345678", false},
+		{"lee2025pass", false},
+		{"Lee2025Pass", false},
+		{strings.Repeat("a1", 37), false},
+		{"correct9horse", true},
+		{"пароль2025", true},
+	}
+	for _, c := range cases {
+		err := CheckPasswordStrength(c.password, "lee")
+		if c.ok && err != nil {
+			t.Errorf("CheckPasswordStrength(%q) = %v, want nil", c.password, err)
+		}
+		if !c.ok && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("CheckPasswordStrength(%q) = %v, want ErrWeakPassword", c.password, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minSecretLength is the shortest HS256 secret accepted, in bytes
+const minSecretLength = 32
+
+var (
+	ErrUnknownKey     = errors.New("token signed with an unknown key")
+	ErrWrongAlgorithm = errors.New("token algorithm does not match its key")
+	ErrInvalidKey     = errors.New("invalid signing key")
+)
+
+// Key is one signing or verification key, identified by its key ID
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds private material
+func (k Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey makes an HS256 key from a shared secret
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) < minSecretLength {
+		return Key{}, fmt.Errorf("%w: %s: secret must be at least %d bytes", ErrInvalidKey, id, minSecretLength)
+	}
+	return Key{ID: id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewRandomHMACKey makes an HS256 key that only lives as long as the process
+func NewRandomHMACKey(id string) (Key, error) {
+	secret := make([]byte, minSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, err
+	}
+	return NewHMACKey(id, secret)
+}
+
+// ParsePEMKey reads an RS256 or EdDSA key. A private key can sign and
+// verify; a public key only verifies tokens signed elsewhere.
+func ParsePEMKey(id, alg string, data []byte) (Key, error) {
+	switch alg {
+	case AlgRS256:
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			return Key{ID: id, method: jwt.SigningMethodRS256, signKey: private, verifyKey: &private.PublicKey}, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("%w: %s: %v", ErrInvalidKey, id, err)
+		}
+		return Key{ID: id, method: jwt.SigningMethodRS256, verifyKey: public}, nil
+	case AlgEdDSA:
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			signer := private.(interface{ Public() crypto.PublicKey })
+			return Key{ID: id, method: jwt.SigningMethodEdDSA, signKey: private, verifyKey: signer.Public()}, nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("%w: %s: %v", ErrInvalidKey, id, err)
+		}
+		return Key{ID: id, method: jwt.SigningMethodEdDSA, verifyKey: public}, nil
+	}
+	return Key{}, fmt.Errorf("%w: %s: unsupported algorithm %q", ErrInvalidKey, id, alg)
+}
+
+// KeySet signs access tokens with one key and accepts tokens from any of
+// its keys, so a new key can be rolled out while tokens signed with the
+// old one are still in use
+type KeySet struct {
+	issuer  string
+	signing Key
+	keys    map[string]Key
+}
+
+// NewKeySet signs with signing and also verifies with others
+func NewKeySet(issuer string, signing Key, others ...Key) (*KeySet, error) {
+	if issuer == "" {
+		return nil, fmt.Errorf("%w: issuer is required", ErrInvalidKey)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("%w: %s cannot sign", ErrInvalidKey, signing.ID)
+	}
+	ks := &KeySet{issuer: issuer, signing: signing, keys: map[string]Key{signing.ID: signing}}
+	for _, key := range others {
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKey, key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+	return ks, nil
+}
+
+// SigningKeyID is the ID of the key new tokens are signed with
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+func (ks *KeySet) methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range ks.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// ParseKeySpec reads keys written as "kid:alg:value", separated by commas.
+// For HS256 the value is a base64 secret, otherwise the path of a PEM file.
+func ParseKeySpec(spec string, readFile func(string) ([]byte, error)) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("%w: expected kid:alg:value, got %q", ErrInvalidKey, entry)
+		}
+		id, alg, value := parts[0], parts[1], parts[2]
+		var key Key
+		var err error
+		if alg == AlgHS256 {
+			var secret []byte
+			secret, err = base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: secret is not base64", ErrInvalidKey, id)
+			}
+			key, err = NewHMACKey(id, secret)
+		} else {
+			var data []byte
+			data, err = readFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidKey, id, err)
+			}
+			key, err = ParsePEMKey(id, alg, data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// LoadKeySet builds a key set from a key spec, signing with the key named
+// signingID or, when it is empty, the first key
+func LoadKeySet(issuer, spec, signingID string) (*KeySet, error) {
+	keys, err := ParseKeySpec(spec, os.ReadFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys configured", ErrInvalidKey)
+	}
+	if signingID == "" {
+		signingID = keys[0].ID
+	}
+	var others []Key
+	var signing *Key
+	for i := range keys {
+		if keys[i].ID == signingID && signing == nil {
+			signing = &keys[i]
+			continue
+		}
+		others = append(others, keys[i])
+	}
+	if signing == nil {
+		return nil, fmt.Errorf("%w: signing key %q is not configured", ErrInvalidKey, signingID)
+	}
+	return NewKeySet(issuer, *signing, others...)
+}
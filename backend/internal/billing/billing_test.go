@@ -0,0 +1,161 @@
+package billing
+
+import (
+	"errors"
+	"testing"
+)
+
+func sumCents(amounts []float64) int64 {
+	var sum int64
+	for _, a := range amounts {
+		sum += ToCents(a)
+	}
+	return sum
+}
+
+func TestSplitEven_DistributesRemainder(t *testing.T) {
+	shares, err := SplitEven(100, 3)
+	if err != nil {
+		t.Fatalf("SplitEven returned error: %v", err)
+	}
+	want := []float64{33.34, 33.33, 33.33}
+	for i := range want {
+		if shares[i] != want[i] {
+			t.Errorf("share %d: expected %v, got %v", i, want[i], shares[i])
+		}
+	}
+	if sumCents(shares) != 10000 {
+		t.Errorf("shares do not add up to total: %v", shares)
+	}
+}
+
+func TestSplitEven_RejectsSinglePerson(t *testing.T) {
+	if _, err := SplitEven(10, 1); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit, got %v", err)
+	}
+}
+
+func TestSplitCustom(t *testing.T) {
+	if _, err := SplitCustom(30, []float64{10, 20}); err != nil {
+		t.Errorf("expected exact amounts to be accepted, got %v", err)
+	}
+	if _, err := SplitCustom(30, []float64{10, 10}); !errors.Is(err, ErrAmountsMismatch) {
+		t.Errorf("expected ErrAmountsMismatch, got %v", err)
+	}
+	if _, err := SplitCustom(30, []float64{35, -5}); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for negative share, got %v", err)
+	}
+}
+
+func TestSplitByItems(t *testing.T) {
+	lines := []Line{
+		{ItemID: 1, Quantity: 2, UnitPrice: 5},
+		{ItemID: 2, Quantity: 1, UnitPrice: 12.5},
+	}
+	groups := [][]Assignment{
+		{{ItemID: 1, Quantity: 1}},
+		{{ItemID: 1, Quantity: 1}, {ItemID: 2, Quantity: 1}},
+	}
+
+	shares, err := SplitByItems(lines, groups, 22.5)
+	if err != nil {
+		t.Fatalf("SplitByItems returned error: %v", err)
+	}
+	if shares[0] != 5 || shares[1] != 17.5 {
+		t.Errorf("expected [5 17.5], got %v", shares)
+	}
+
+	// A fee on top of the items is spread proportionally
+	shares, err = SplitByItems(lines, groups, 27.5)
+	if err != nil {
+		t.Fatalf("SplitByItems returned error: %v", err)
+	}
+	if sumCents(shares) != 2750 {
+		t.Errorf("shares do not add up to total: %v", shares)
+	}
+}
+
+func TestSplitByItems_RequiresFullAssignment(t *testing.T) {
+	lines := []Line{{ItemID: 1, Quantity: 2, UnitPrice: 5}}
+
+	partial := [][]Assignment{{{ItemID: 1, Quantity: 1}}, {{ItemID: 1, Quantity: 0}}}
+	if _, err := SplitByItems(lines, partial, 10); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for unassigned quantity, got %v", err)
+	}
+
+	over := [][]Assignment{{{ItemID: 1, Quantity: 2}}, {{ItemID: 1, Quantity: 1}}}
+	if _, err := SplitByItems(lines, over, 10); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for over-assigned item, got %v", err)
+	}
+
+	unknown := [][]Assignment{{{ItemID: 1, Quantity: 2}}, {{ItemID: 9, Quantity: 1}}}
+	if _, err := SplitByItems(lines, unknown, 10); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for unknown item, got %v", err)
+	}
+}
+
+func TestTipAmount(t *testing.T) {
+	cases := []struct {
+		kind  string
+		value float64
+		want  float64
+	}{
+		{TipFixed, 5, 5},
+		{TipPercentage, 10, 4.25},
+		{TipPercentage, 12.5, 5.31},
+	}
+	for _, c := range cases {
+		got, err := TipAmount(42.5, c.kind, c.value)
+		if err != nil {
+			t.Fatalf("TipAmount(%s, %v) failed: %v", c.kind, c.value, err)
+		}
+		if got != c.want {
+			t.Errorf("TipAmount(%s, %v) = %v, want %v", c.kind, c.value, got, c.want)
+		}
+	}
+}
+
+func TestTipAmount_Rejects(t *testing.T) {
+	for _, c := range []struct {
+		kind  string
+		value float64
+	}{
+		{TipFixed, 0},
+		{TipFixed, -1},
+		{TipPercentage, 150},
+		{"cash", 5},
+	} {
+		if _, err := TipAmount(42.5, c.kind, c.value); !errors.Is(err, ErrInvalidTip) {
+			t.Errorf("TipAmount(%s, %v) error = %v, want ErrInvalidTip", c.kind, c.value, err)
+		}
+	}
+}
+
+func TestAllocatePool(t *testing.T) {
+	got := AllocatePool(10, 3)
+	want := []float64{3.34, 3.33, 3.33}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("AllocatePool(10, 3) = %v, want %v", got, want)
+		}
+	}
+	if AllocatePool(10, 0) != nil {
+		t.Error("AllocatePool with no recipients should return nil")
+	}
+}
+
+func TestIncludedTax(t *testing.T) {
+	cases := []struct {
+		gross, rate, net, tax float64
+	}{
+		{110, 10, 100, 10},
+		{42.5, 8, 39.35, 3.15},
+		{19.99, 0, 19.99, 0},
+	}
+	for _, c := range cases {
+		net, tax := IncludedTax(c.gross, c.rate)
+		if net != c.net || tax != c.tax {
+			t.Errorf("IncludedTax(%v, %v) = %v, %v, want %v, %v", c.gross, c.rate, net, tax, c.net, c.tax)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrInvalidSplit    = errors.New("invalid bill split")
+	ErrAmountsMismatch = errors.New("share amounts do not add up to the bill total")
+)
+
+// Line is one ordered item on a bill.
+type Line struct {
+	ItemID    int32
+	Quantity  int32
+	UnitPrice float64
+}
+
+// Assignment puts some quantity of a line on one share.
+type Assignment struct {
+	ItemID   int32 `json:"item_id"`
+	Quantity int32 `json:"quantity"`
+}
+
+// ToCents converts a money amount to whole cents.
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromCents converts whole cents back to a money amount.
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// SplitEven divides total between people. Leftover cents go to the first
+// shares so the shares always add up to the total exactly.
+func SplitEven(total float64, people int) ([]float64, error) {
+	if people < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 people", ErrInvalidSplit)
+	}
+	cents := ToCents(total)
+	if cents < int64(people) {
+		return nil, fmt.Errorf("%w: total too small to split %d ways", ErrInvalidSplit, people)
+	}
+
+	return divideCents(cents, people), nil
+}
+
+// divideCents splits cents into n parts that differ by at most one cent,
+// larger parts first.
+func divideCents(cents int64, n int) []float64 {
+	base := cents / int64(n)
+	remainder := cents % int64(n)
+	parts := make([]float64, n)
+	for i := range parts {
+		part := base
+		if int64(i) < remainder {
+			part++
+		}
+		parts[i] = FromCents(part)
+	}
+	return parts
+}
+
+// SplitCustom checks that explicitly chosen amounts cover total exactly.
+func SplitCustom(total float64, amounts []float64) ([]float64, error) {
+	if len(amounts) < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 shares", ErrInvalidSplit)
+	}
+	var sum int64
+	shares := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		cents := ToCents(amount)
+		if cents <= 0 {
+			return nil, fmt.Errorf("%w: share %d must be positive", ErrInvalidSplit, i+1)
+		}
+		sum += cents
+		shares[i] = FromCents(cents)
+	}
+	if sum != ToCents(total) {
+		return nil, ErrAmountsMismatch
+	}
+	return shares, nil
+}
+
+// SplitByItems prices each group of assignments. Every line must be assigned
+// in full across the groups, and extra charges on the bill (such as fees not
+// tied to an item) are spread over the groups in proportion to their items.
+func SplitByItems(lines []Line, groups [][]Assignment, total float64) ([]float64, error) {
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 shares", ErrInvalidSplit)
+	}
+
+	byID := make(map[int32]Line, len(lines))
+	remaining := make(map[int32]int32, len(lines))
+	var itemsTotal int64
+	for _, line := range lines {
+		byID[line.ItemID] = line
+		remaining[line.ItemID] = line.Quantity
+		itemsTotal += ToCents(line.UnitPrice * float64(line.Quantity))
+	}
+
+	groupCents := make([]int64, len(groups))
+	for g, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("%w: share %d has no items", ErrInvalidSplit, g+1)
+		}
+		for _, a := range group {
+			line, ok := byID[a.ItemID]
+			if !ok {
+				return nil, fmt.Errorf("%w: item %d is not on this order", ErrInvalidSplit, a.ItemID)
+			}
+			if a.Quantity <= 0 || a.Quantity > remaining[a.ItemID] {
+				return nil, fmt.Errorf("%w: item %d assigned more than was ordered", ErrInvalidSplit, a.ItemID)
+			}
+			remaining[a.ItemID] -= a.Quantity
+			groupCents[g] += ToCents(line.UnitPrice * float64(a.Quantity))
+		}
+	}
+	for id, left := range remaining {
+		if left != 0 {
+			return nil, fmt.Errorf("%w: item %d is not fully assigned", ErrInvalidSplit, id)
+		}
+	}
+
+	// Spread any difference between the bill and its items proportionally,
+	// giving the rounding remainder to the last share
+	extra := ToCents(total) - itemsTotal
+	var spread int64
+	shares := make([]float64, len(groups))
+	for g := range groups {
+		add := int64(0)
+		if itemsTotal > 0 && g < len(groups)-1 {
+			add = int64(math.Round(float64(extra) * float64(groupCents[g]) / float64(itemsTotal)))
+		} else if g == len(groups)-1 {
+			add = extra - spread
+		}
+		spread += add
+		shares[g] = FromCents(groupCents[g] + add)
+	}
+	return shares, nil
+}
@@ -0,0 +1,12 @@
+package billing
+
+// IncludedTax splits a tax-inclusive amount into its net part and the tax it
+// contains at ratePercent, rounded so that net + tax == gross to the cent.
+func IncludedTax(gross, ratePercent float64) (net, tax float64) {
+	grossCents := ToCents(gross)
+	if ratePercent <= 0 {
+		return FromCents(grossCents), 0
+	}
+	netCents := ToCents(FromCents(grossCents) * 100 / (100 + ratePercent))
+	return FromCents(netCents), FromCents(grossCents - netCents)
+}
@@ -0,0 +1,59 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	TipFixed      = "fixed"
+	TipPercentage = "percentage"
+
+	// Tip pools decide who a tip is paid out to.
+	PoolRider = "rider"
+	PoolStaff = "staff"
+
+	// MaxTipPercent guards against typos like 150 instead of 15.
+	MaxTipPercent = 100
+)
+
+var ErrInvalidTip = errors.New("invalid tip")
+
+// TipAmount works out the tip for a bill of base. A fixed tip is value
+// itself, a percentage tip is value percent of base rounded to the cent.
+func TipAmount(base float64, kind string, value float64) (float64, error) {
+	var cents int64
+	switch kind {
+	case TipFixed:
+		cents = ToCents(value)
+	case TipPercentage:
+		if value > MaxTipPercent {
+			return 0, fmt.Errorf("%w: at most %d%%", ErrInvalidTip, MaxTipPercent)
+		}
+		cents = ToCents(base * value / 100)
+	default:
+		return 0, fmt.Errorf("%w: unknown kind %q", ErrInvalidTip, kind)
+	}
+	if value <= 0 || cents <= 0 {
+		return 0, fmt.Errorf("%w: amount must be positive", ErrInvalidTip)
+	}
+	return FromCents(cents), nil
+}
+
+// TipPool picks the pool for an order type. Delivery tips go to the rider,
+// everything else is shared by the staff.
+func TipPool(orderType string) string {
+	if orderType == "delivery" {
+		return PoolRider
+	}
+	return PoolStaff
+}
+
+// AllocatePool shares total evenly between recipients, leftover cents going
+// to the first ones. It returns nil when there is nobody to pay.
+func AllocatePool(total float64, recipients int) []float64 {
+	if recipients <= 0 {
+		return nil
+	}
+	return divideCents(ToCents(total), recipients)
+}
@@ -10,15 +10,112 @@ import (
 )
 
 type Account struct {
-	ID              int32
-	Username        string
-	Password        string
-	Email           string
-	Address         string
-	Balance         float64
-	IsAdmin         bool
-	UserTag         sql.NullString
-	UserPhoneNumber int64
+	ID                 int32
+	Username           string
+	Password           string
+	Email              string
+	Address            string
+	Balance            float64
+	IsAdmin            bool
+	UserTag            sql.NullString
+	UserPhoneNumber    int64
+	EmailVerifiedAt    sql.NullTime
+	PhoneVerifiedAt    sql.NullTime
+	TwoFactorEnabledAt sql.NullTime
+	Status             string
+	StatusReason       string
+	SuspendedUntil     sql.NullTime
+}
+
+type AccountRole struct {
+	UserID    int32
+	Role      string
+	GrantedBy sql.NullInt32
+	GrantedAt sql.NullTime
+}
+
+type Address struct {
+	AddressID int32
+	UserID    int32
+	Label     string
+	Recipient string
+	Phone     string
+	Line1     string
+	Line2     string
+	City      string
+	Postcode  string
+	Lat       sql.NullFloat64
+	Lng       sql.NullFloat64
+	IsDefault bool
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+type AuditLog struct {
+	AuditID      int32
+	ActorID      sql.NullInt32
+	TargetUserID sql.NullInt32
+	Action       string
+	Reason       string
+	Details      sql.NullString
+	CreatedAt    time.Time
+}
+
+type BillShare struct {
+	ShareID   int32
+	OrderID   int32
+	Label     string
+	Amount    float64
+	IsPaid    bool
+	IntentID  sql.NullInt32
+	PaidBy    sql.NullInt32
+	PaidAt    sql.NullTime
+	CreatedAt sql.NullTime
+}
+
+type DeletionRequest struct {
+	RequestID    int32
+	UserID       int32
+	Ip           string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+	CancelledAt  sql.NullTime
+	CompletedAt  sql.NullTime
+}
+
+type Delivery struct {
+	DeliveryID    int32
+	OrderID       int32
+	RiderID       int32
+	Status        string
+	AssignedBy    sql.NullInt32
+	AssignedAt    time.Time
+	PickedUpAt    sql.NullTime
+	DeliveredAt   sql.NullTime
+	FailedAt      sql.NullTime
+	FailureReason sql.NullString
+	ProofNote     sql.NullString
+	ProofPhoto    sql.NullString
+}
+
+type DeliveryZone struct {
+	ZoneID    int32
+	Name      string
+	Kind      string
+	RadiusKm  sql.NullFloat64
+	Polygon   sql.NullString
+	BaseFee   float64
+	FeePerKm  float64
+	MinOrder  float64
+	IsActive  bool
+	CreatedAt sql.NullTime
+}
+
+type DiningTable struct {
+	TableNumber int32
+	Seats       int32
+	IsActive    bool
+	CreatedAt   sql.NullTime
 }
 
 type Food struct {
@@ -31,31 +128,356 @@ type Food struct {
 	Info        sql.NullString
 	Ingredients string
 	TimeNeeded  int32
+	Station     string
+}
+
+type GiftCard struct {
+	CardID        int32
+	Code          string
+	InitialAmount float64
+	Balance       float64
+	Status        string
+	Message       sql.NullString
+	IssuedBy      sql.NullInt32
+	PurchasedBy   sql.NullInt32
+	ExpiresAt     sql.NullTime
+	CreatedAt     sql.NullTime
+}
+
+type GiftCardTransaction struct {
+	TransactionID int32
+	CardID        int32
+	AccountID     sql.NullInt32
+	Kind          string
+	Amount        float64
+	BalanceAfter  float64
+	IntentID      sql.NullInt32
+	Note          sql.NullString
+	CreatedAt     sql.NullTime
+}
+
+type IdempotencyKey struct {
+	UserID       int32
+	IdemKey      string
+	RequestHash  string
+	Status       string
+	ResponseCode int32
+	ContentType  string
+	ResponseBody sql.NullString
+	CreatedAt    sql.NullTime
+	ExpiresAt    time.Time
 }
 
 type Item struct {
+	ItemID    int32
+	OrderID   int32
+	FoodID    int32
+	Quantity  int32
+	Rating    sql.NullInt32
+	FoodName  string
+	UnitPrice float64
+	Modifiers string
+}
+
+type KitchenSetting struct {
+	SettingsID           int32
+	WindowMinutes        int32
+	MaxItems             int32
+	MaxPrepMinutes       int32
+	PushbackAfterMinutes int32
+	PauseAfterMinutes    int32
+	TakeawayPaused       bool
+	DeliveryPaused       bool
+	ResumeAt             sql.NullTime
+	UpdatedBy            sql.NullInt32
+	UpdatedAt            sql.NullTime
+}
+
+type LoginAttempt struct {
+	AttemptID int32
+	UserID    sql.NullInt32
+	Username  string
+	Ip        string
+	UserAgent string
+	Success   bool
+	Reason    string
+	CreatedAt time.Time
+}
+
+type LoginChallenge struct {
+	ChallengeID      int32
+	UserID           int32
+	TokenHash        string
+	ExpiresInSeconds int32
+	Attempts         int32
+	Ip               string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	UsedAt           sql.NullTime
+}
+
+type LoginThrottle struct {
+	Scope         string
+	Subject       string
+	Failures      int32
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+}
+
+type MailOutbox struct {
+	MailID        int32
+	Recipient     string
+	Subject       string
+	Body          string
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	ClaimedAt     sql.NullTime
+	SentAt        sql.NullTime
+	CreatedAt     sql.NullTime
+	UserID        sql.NullInt32
+}
+
+type Notification struct {
+	NotificationID int32
+	UserID         int32
+	OrderID        sql.NullInt32
+	Kind           string
+	Message        string
+	ReadAt         sql.NullTime
+	CreatedAt      sql.NullTime
+}
+
+type OpeningHour struct {
+	HoursID   int32
+	OrderType string
+	Weekday   int32
+	OpensAt   string
+	ClosesAt  string
+	CreatedAt sql.NullTime
+}
+
+type Order struct {
+	OrderID            int32
+	UserID             int32
+	OrderInfo          string
+	Feedback           sql.NullString
+	OrderTime          sql.NullTime
+	EstimatedTime      time.Time
+	IsDone             bool
+	IsRanged           bool
+	DeliveryAddress    sql.NullString
+	Deleted            bool
+	IsPaid             bool
+	OrderType          string
+	TableNumber        sql.NullInt32
+	FinishedBy         sql.NullInt32
+	FinishedAt         sql.NullTime
+	InvoiceBillingName sql.NullString
+	InvoiceTaxID       sql.NullString
+	ZoneID             sql.NullInt32
+	DeliveryLat        sql.NullFloat64
+	DeliveryLng        sql.NullFloat64
+	DeliveryDistanceKm sql.NullFloat64
+	DeliveryFee        float64
+	AddressID          sql.NullInt32
+	DeliveryRecipient  sql.NullString
+	DeliveryPhone      sql.NullString
+	ScheduledFor       sql.NullTime
+	ReleaseAt          sql.NullTime
+	ReleasedAt         sql.NullTime
+	RemindedAt         sql.NullTime
+}
+
+type OrderSlot struct {
+	SlotStart time.Time
+	Booked    int32
+}
+
+type PasswordReset struct {
+	ResetID   int32
+	UserID    int32
+	TokenHash string
+	Ip        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type PaymentEvent struct {
+	EventID   int32
+	IntentID  int32
+	EventType string
+	Status    string
+	Amount    float64
+	Detail    sql.NullString
+	CreatedAt sql.NullTime
+}
+
+type PaymentIntent struct {
+	IntentID      int32
+	UserID        int32
+	OrderID       sql.NullInt32
+	Purpose       string
+	Provider      string
+	ProviderRef   string
+	Amount        float64
+	Status        string
+	CreatedAt     sql.NullTime
+	UpdatedAt     sql.NullTime
+	ShareID       sql.NullInt32
+	GiftCardID    sql.NullInt32
+	ReservationID sql.NullInt32
+}
+
+type PrintJob struct {
+	JobID         int32
+	OrderID       sql.NullInt32
+	Station       string
+	Kind          string
+	Payload       []byte
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	ClaimedAt     sql.NullTime
+	PrintedAt     sql.NullTime
+	ReprintOf     sql.NullInt32
+	CreatedAt     sql.NullTime
+}
+
+type Printer struct {
+	PrinterID int32
+	Name      string
+	Station   string
+	Address   string
+	IsActive  bool
+	CreatedAt sql.NullTime
+}
+
+type RecoveryCode struct {
+	CodeID    int32
+	UserID    int32
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Reservation struct {
+	ReservationID int32
+	UserID        int32
+	TableNumber   int32
+	PartySize     int32
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Status        string
+	Note          string
+	Deposit       float64
+	DepositStatus string
+	OrderID       sql.NullInt32
+	SeatedAt      sql.NullTime
+	CancelledAt   sql.NullTime
+	CreatedAt     sql.NullTime
+}
+
+type Rider struct {
+	UserID    int32
+	Status    string
+	StatusAt  sql.NullTime
+	Lat       sql.NullFloat64
+	Lng       sql.NullFloat64
+	LocatedAt sql.NullTime
+	IsActive  bool
+	CreatedAt sql.NullTime
+}
+
+type RiderLocation struct {
+	LocationID int32
+	RiderID    int32
+	DeliveryID sql.NullInt32
+	Lat        float64
+	Lng        float64
+	CreatedAt  sql.NullTime
+}
+
+type ShareItem struct {
+	ShareID  int32
 	ItemID   int32
-	OrderID  int32
-	FoodID   int32
 	Quantity int32
-	Rating   sql.NullInt32
 }
 
-type Order struct {
-	OrderID         int32
-	UserID          int32
-	OrderInfo       string
-	Feedback        sql.NullString
-	OrderTime       sql.NullTime
-	EstimatedTime   time.Time
-	IsDone          bool
-	IsRanged        bool
-	DeliveryAddress sql.NullString
-	Deleted         bool
-	IsPaid          bool
+type StoreHoliday struct {
+	HolidayID   int32
+	HolidayDate time.Time
+	OrderType   string
+	OpensAt     sql.NullString
+	ClosesAt    sql.NullString
+	Note        string
+	CreatedBy   sql.NullInt32
+	CreatedAt   sql.NullTime
 }
 
 type Tag struct {
 	Tag      string
 	FoodName string
 }
+
+type Tip struct {
+	TipID       int32
+	OrderID     int32
+	UserID      int32
+	IntentID    sql.NullInt32
+	Kind        string
+	Rate        sql.NullFloat64
+	Amount      float64
+	Pool        string
+	RecipientID sql.NullInt32
+	Status      string
+	CreatedAt   sql.NullTime
+}
+
+type TwoFactorSecret struct {
+	UserID    int32
+	Secret    string
+	LastStep  int64
+	CreatedAt time.Time
+}
+
+type UserSession struct {
+	SessionID    int32
+	UserID       int32
+	RefreshHash  string
+	PreviousHash sql.NullString
+	UserAgent    string
+	Ip           string
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	ExpiresAt    time.Time
+	RevokedAt    sql.NullTime
+}
+
+type Verification struct {
+	VerificationID int32
+	UserID         int32
+	Channel        string
+	Target         string
+	CodeHash       string
+	Attempts       int32
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	UsedAt         sql.NullTime
+}
+
+type Waitlist struct {
+	EntryID     int32
+	UserID      sql.NullInt32
+	Name        string
+	Phone       string
+	PartySize   int32
+	Status      string
+	TableNumber sql.NullInt32
+	NotifiedAt  sql.NullTime
+	SeatedAt    sql.NullTime
+	CreatedAt   sql.NullTime
+}
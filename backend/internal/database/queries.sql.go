@@ -8,8 +8,96 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const activateGiftCard = `-- name: ActivateGiftCard :execrows
+UPDATE gift_cards
+SET
+    status = 'active'
+WHERE
+    card_id = ? AND status = 'pending'
+`
+
+func (q *Queries) ActivateGiftCard(ctx context.Context, cardID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, activateGiftCard, cardID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addAccountBalance = `-- name: AddAccountBalance :exec
+UPDATE accounts
+SET
+    balance = balance + ?
+WHERE
+    id = ?
+`
+
+type AddAccountBalanceParams struct {
+	Balance float64
+	ID      int32
+}
+
+func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) error {
+	_, err := q.db.ExecContext(ctx, addAccountBalance, arg.Balance, arg.ID)
+	return err
+}
+
+const addLoginChallengeAttempt = `-- name: AddLoginChallengeAttempt :exec
+UPDATE login_challenges
+SET
+    attempts = attempts + 1
+WHERE
+    challenge_id = ?
+`
+
+func (q *Queries) AddLoginChallengeAttempt(ctx context.Context, challengeID int32) error {
+	_, err := q.db.ExecContext(ctx, addLoginChallengeAttempt, challengeID)
+	return err
+}
+
+const addLoginFailure = `-- name: AddLoginFailure :exec
+INSERT INTO login_throttles (scope, subject, failures, last_failure_at, blocked_until)
+VALUES (?, ?, 1, ?, ?)
+ON DUPLICATE KEY UPDATE
+    failures = IF(last_failure_at < ?, 1, failures + 1),
+    last_failure_at = ?
+`
+
+type AddLoginFailureParams struct {
+	Scope       string
+	Subject     string
+	Now         time.Time
+	ResetBefore time.Time
+}
+
+func (q *Queries) AddLoginFailure(ctx context.Context, arg AddLoginFailureParams) error {
+	_, err := q.db.ExecContext(ctx, addLoginFailure,
+		arg.Scope,
+		arg.Subject,
+		arg.Now,
+		arg.Now,
+		arg.ResetBefore,
+		arg.Now,
+	)
+	return err
+}
+
+const addVerificationAttempt = `-- name: AddVerificationAttempt :exec
+UPDATE verifications
+SET
+    attempts = attempts + 1
+WHERE
+    verification_id = ?
+`
+
+func (q *Queries) AddVerificationAttempt(ctx context.Context, verificationID int32) error {
+	_, err := q.db.ExecContext(ctx, addVerificationAttempt, verificationID)
+	return err
+}
+
 const alterAccount = `-- name: AlterAccount :exec
 UPDATE accounts
 SET
@@ -73,252 +161,5240 @@ func (q *Queries) AlterFood(ctx context.Context, arg AlterFoodParams) error {
 	return err
 }
 
-const createAccount = `-- name: CreateAccount :exec
-INSERT INTO accounts (username, password, email, address, user_phone_number)
-VALUES (
-    ?,
-    ?,
-    ?,
-    ?,
-    ?
-)
+const anonymizeAccount = `-- name: AnonymizeAccount :exec
+UPDATE accounts
+SET
+    username = ?,
+    password = ?,
+    email = ?,
+    address = '',
+    user_phone_number = 0,
+    user_tag = NULL,
+    email_verified_at = NULL,
+    phone_verified_at = NULL,
+    two_factor_enabled_at = NULL,
+    status = 'deleted',
+    status_reason = ?,
+    suspended_until = NULL
+WHERE
+    id = ?
 `
 
-type CreateAccountParams struct {
-	Username        string
-	Password        string
-	Email           string
-	Address         string
-	UserPhoneNumber int64
+type AnonymizeAccountParams struct {
+	Username     string
+	Password     string
+	Email        string
+	StatusReason string
+	ID           int32
 }
 
-func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) error {
-	_, err := q.db.ExecContext(ctx, createAccount,
+func (q *Queries) AnonymizeAccount(ctx context.Context, arg AnonymizeAccountParams) error {
+	_, err := q.db.ExecContext(ctx, anonymizeAccount,
 		arg.Username,
 		arg.Password,
 		arg.Email,
-		arg.Address,
-		arg.UserPhoneNumber,
+		arg.StatusReason,
+		arg.ID,
 	)
 	return err
 }
 
-const createFood = `-- name: CreateFood :exec
-INSERT INTO food (food_name, price, info, ingredients, time_needed, picture, description, long_range)
-VALUES (
-    ?,
-    ?,
-    ?,
-    ?,
-    ?,
-    ?,
-    ?,
-    ?
-)
+const anonymizeLoginAttempts = `-- name: AnonymizeLoginAttempts :exec
+UPDATE login_attempts
+SET
+    username = ?,
+    ip = '',
+    user_agent = ''
+WHERE
+    user_id = ?
 `
 
-type CreateFoodParams struct {
-	FoodName    string
-	Price       float64
-	Info        sql.NullString
-	Ingredients string
-	TimeNeeded  int32
-	Picture     sql.NullString
-	Description string
-	LongRange   bool
+type AnonymizeLoginAttemptsParams struct {
+	Username string
+	UserID   sql.NullInt32
 }
 
-func (q *Queries) CreateFood(ctx context.Context, arg CreateFoodParams) error {
-	_, err := q.db.ExecContext(ctx, createFood,
-		arg.FoodName,
-		arg.Price,
-		arg.Info,
-		arg.Ingredients,
-		arg.TimeNeeded,
-		arg.Picture,
-		arg.Description,
-		arg.LongRange,
-	)
+func (q *Queries) AnonymizeLoginAttempts(ctx context.Context, arg AnonymizeLoginAttemptsParams) error {
+	_, err := q.db.ExecContext(ctx, anonymizeLoginAttempts, arg.Username, arg.UserID)
 	return err
 }
 
-const createOrder = `-- name: CreateOrder :exec
-INSERT INTO orders (user_id, order_info, is_ranged, delivery_address)
-VALUES (
-    ?,
-    ?,
-    ?,
-    ?
-)
+const anonymizeWaitlist = `-- name: AnonymizeWaitlist :exec
+UPDATE waitlist
+SET
+    name = 'Deleted',
+    phone = ''
+WHERE
+    user_id = ?
 `
 
-type CreateOrderParams struct {
-	UserID          int32
-	OrderInfo       string
-	IsRanged        bool
-	DeliveryAddress sql.NullString
-}
-
-func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error {
-	_, err := q.db.ExecContext(ctx, createOrder,
-		arg.UserID,
-		arg.OrderInfo,
-		arg.IsRanged,
-		arg.DeliveryAddress,
-	)
+func (q *Queries) AnonymizeWaitlist(ctx context.Context, userID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, anonymizeWaitlist, userID)
 	return err
 }
 
-const createOrderedItem = `-- name: CreateOrderedItem :exec
-INSERT INTO items (order_id, food_id, quantity)
-VALUES (
-    ?,
-    ?,
-    ?
-)
+const bookOrderSlot = `-- name: BookOrderSlot :execrows
+UPDATE order_slots
+SET
+    booked = booked + 1
+WHERE
+    slot_start = ? AND booked < ?
 `
 
-type CreateOrderedItemParams struct {
-	OrderID  int32
-	FoodID   int32
-	Quantity int32
+type BookOrderSlotParams struct {
+	SlotStart time.Time
+	Capacity  int32
 }
 
-func (q *Queries) CreateOrderedItem(ctx context.Context, arg CreateOrderedItemParams) error {
-	_, err := q.db.ExecContext(ctx, createOrderedItem, arg.OrderID, arg.FoodID, arg.Quantity)
-	return err
+func (q *Queries) BookOrderSlot(ctx context.Context, arg BookOrderSlotParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bookOrderSlot, arg.SlotStart, arg.Capacity)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteFood = `-- name: DeleteFood :exec
-DELETE FROM food
-WHERE food_name = ?
+const cancelDeletionRequests = `-- name: CancelDeletionRequests :execrows
+UPDATE deletion_requests
+SET
+    cancelled_at = ?
+WHERE
+    user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL
 `
 
-func (q *Queries) DeleteFood(ctx context.Context, foodName string) error {
-	_, err := q.db.ExecContext(ctx, deleteFood, foodName)
-	return err
+type CancelDeletionRequestsParams struct {
+	CancelledAt sql.NullTime
+	UserID      int32
 }
 
-const deleteOrder = `-- name: DeleteOrder :exec
-UPDATE orders
+func (q *Queries) CancelDeletionRequests(ctx context.Context, arg CancelDeletionRequestsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelDeletionRequests, arg.CancelledAt, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelDelivery = `-- name: CancelDelivery :execrows
+UPDATE deliveries
 SET
-    deleted = true
+    status = 'cancelled',
+    failure_reason = ?
 WHERE
-    order_id = ?
+    delivery_id = ? AND status = 'assigned'
 `
 
-func (q *Queries) DeleteOrder(ctx context.Context, orderID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteOrder, orderID)
-	return err
+type CancelDeliveryParams struct {
+	FailureReason sql.NullString
+	DeliveryID    int32
 }
 
-const deleteTag = `-- name: DeleteTag :exec
-DELETE FROM tags
-WHERE food_name = ?
-`
-
-func (q *Queries) DeleteTag(ctx context.Context, foodName string) error {
-	_, err := q.db.ExecContext(ctx, deleteTag, foodName)
-	return err
+func (q *Queries) CancelDelivery(ctx context.Context, arg CancelDeliveryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelDelivery, arg.FailureReason, arg.DeliveryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getAccount = `-- name: GetAccount :one
-SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number FROM accounts WHERE username = ?
+const cancelReservation = `-- name: CancelReservation :execrows
+UPDATE reservations
+SET
+    status = 'cancelled',
+    cancelled_at = ?
+WHERE
+    reservation_id = ? AND status IN ('pending', 'booked')
 `
 
-func (q *Queries) GetAccount(ctx context.Context, username string) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccount, username)
-	var i Account
-	err := row.Scan(
-		&i.ID,
-		&i.Username,
-		&i.Password,
-		&i.Email,
-		&i.Address,
-		&i.Balance,
-		&i.IsAdmin,
-		&i.UserTag,
-		&i.UserPhoneNumber,
-	)
-	return i, err
+type CancelReservationParams struct {
+	CancelledAt   sql.NullTime
+	ReservationID int32
+}
+
+func (q *Queries) CancelReservation(ctx context.Context, arg CancelReservationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelReservation, arg.CancelledAt, arg.ReservationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getAdminAccount = `-- name: GetAdminAccount :one
-SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number FROM accounts WHERE is_admin = true
+const claimBillShare = `-- name: ClaimBillShare :execrows
+UPDATE bill_shares
+SET
+    intent_id = ?
+WHERE
+    share_id = ? AND is_paid = false AND intent_id IS NULL
 `
 
-func (q *Queries) GetAdminAccount(ctx context.Context) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAdminAccount)
-	var i Account
-	err := row.Scan(
-		&i.ID,
-		&i.Username,
-		&i.Password,
-		&i.Email,
-		&i.Address,
-		&i.Balance,
-		&i.IsAdmin,
-		&i.UserTag,
-		&i.UserPhoneNumber,
-	)
-	return i, err
+type ClaimBillShareParams struct {
+	IntentID sql.NullInt32
+	ShareID  int32
 }
 
-const getAllAccounts = `-- name: GetAllAccounts :many
-SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number FROM accounts WHERE is_admin = false
+func (q *Queries) ClaimBillShare(ctx context.Context, arg ClaimBillShareParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimBillShare, arg.IntentID, arg.ShareID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimMail = `-- name: ClaimMail :execrows
+UPDATE mail_outbox
+SET
+    status = 'sending',
+    attempts = attempts + 1,
+    claimed_at = ?
+WHERE
+    mail_id = ? AND status = 'queued'
 `
 
-func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, getAllAccounts)
+type ClaimMailParams struct {
+	ClaimedAt sql.NullTime
+	MailID    int32
+}
+
+func (q *Queries) ClaimMail(ctx context.Context, arg ClaimMailParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimMail, arg.ClaimedAt, arg.MailID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Account
-	for rows.Next() {
-		var i Account
-		if err := rows.Scan(
-			&i.ID,
-			&i.Username,
-			&i.Password,
-			&i.Email,
-			&i.Address,
-			&i.Balance,
-			&i.IsAdmin,
-			&i.UserTag,
-			&i.UserPhoneNumber,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getAllDeletedOrdersByUser = `-- name: GetAllDeletedOrdersByUser :many
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE user_id = ? AND deleted = true
+const claimPrintJob = `-- name: ClaimPrintJob :execrows
+UPDATE print_jobs
+SET
+    status = 'printing',
+    attempts = attempts + 1,
+    claimed_at = ?
+WHERE
+    job_id = ? AND status = 'queued'
 `
 
-func (q *Queries) GetAllDeletedOrdersByUser(ctx context.Context, userID int32) ([]Order, error) {
-	rows, err := q.db.QueryContext(ctx, getAllDeletedOrdersByUser, userID)
+type ClaimPrintJobParams struct {
+	ClaimedAt sql.NullTime
+	JobID     int32
+}
+
+func (q *Queries) ClaimPrintJob(ctx context.Context, arg ClaimPrintJobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimPrintJob, arg.ClaimedAt, arg.JobID)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	var items []Order
-	for rows.Next() {
-		var i Order
-		if err := rows.Scan(
-			&i.OrderID,
-			&i.UserID,
-			&i.OrderInfo,
-			&i.Feedback,
+	return result.RowsAffected()
+}
+
+const clearDefaultAddress = `-- name: ClearDefaultAddress :exec
+UPDATE addresses
+SET
+    is_default = false
+WHERE
+    user_id = ?
+`
+
+func (q *Queries) ClearDefaultAddress(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, clearDefaultAddress, userID)
+	return err
+}
+
+const clearDeliveryProofsByUser = `-- name: ClearDeliveryProofsByUser :exec
+UPDATE deliveries
+SET
+    proof_note = NULL,
+    proof_photo = NULL
+WHERE
+    order_id IN (SELECT order_id FROM orders WHERE user_id = ?)
+`
+
+func (q *Queries) ClearDeliveryProofsByUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, clearDeliveryProofsByUser, userID)
+	return err
+}
+
+const clearGiftCardMessages = `-- name: ClearGiftCardMessages :exec
+UPDATE gift_cards
+SET
+    message = NULL
+WHERE
+    purchased_by = ?
+`
+
+func (q *Queries) ClearGiftCardMessages(ctx context.Context, purchasedBy sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, clearGiftCardMessages, purchasedBy)
+	return err
+}
+
+const clearLoginThrottle = `-- name: ClearLoginThrottle :execrows
+DELETE FROM login_throttles
+WHERE scope = ? AND subject = ?
+`
+
+type ClearLoginThrottleParams struct {
+	Scope   string
+	Subject string
+}
+
+func (q *Queries) ClearLoginThrottle(ctx context.Context, arg ClearLoginThrottleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearLoginThrottle, arg.Scope, arg.Subject)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const clearOrderPersonalData = `-- name: ClearOrderPersonalData :exec
+UPDATE orders
+SET
+    order_info = '',
+    feedback = NULL,
+    invoice_billing_name = NULL,
+    invoice_tax_id = NULL,
+    delivery_address = NULL,
+    delivery_lat = NULL,
+    delivery_lng = NULL,
+    delivery_recipient = NULL,
+    delivery_phone = NULL
+WHERE
+    user_id = ?
+`
+
+func (q *Queries) ClearOrderPersonalData(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, clearOrderPersonalData, userID)
+	return err
+}
+
+const clearReservationNotes = `-- name: ClearReservationNotes :exec
+UPDATE reservations
+SET
+    note = ''
+WHERE
+    user_id = ?
+`
+
+func (q *Queries) ClearReservationNotes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, clearReservationNotes, userID)
+	return err
+}
+
+const completeDeletionRequest = `-- name: CompleteDeletionRequest :exec
+UPDATE deletion_requests
+SET
+    completed_at = ?
+WHERE
+    request_id = ?
+`
+
+type CompleteDeletionRequestParams struct {
+	CompletedAt sql.NullTime
+	RequestID   int32
+}
+
+func (q *Queries) CompleteDeletionRequest(ctx context.Context, arg CompleteDeletionRequestParams) error {
+	_, err := q.db.ExecContext(ctx, completeDeletionRequest, arg.CompletedAt, arg.RequestID)
+	return err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+    status = 'completed',
+    response_code = ?,
+    content_type = ?,
+    response_body = ?
+WHERE
+    user_id = ? AND idem_key = ?
+`
+
+type CompleteIdempotencyKeyParams struct {
+	ResponseCode int32
+	ContentType  string
+	ResponseBody sql.NullString
+	UserID       int32
+	IdemKey      string
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.ResponseCode,
+		arg.ContentType,
+		arg.ResponseBody,
+		arg.UserID,
+		arg.IdemKey,
+	)
+	return err
+}
+
+const countActiveOrderIntents = `-- name: CountActiveOrderIntents :one
+SELECT COUNT(*) FROM payment_intents
+WHERE order_id = ? AND purpose = 'order' AND status IN ('pending', 'processing', 'succeeded', 'refunding')
+`
+
+func (q *Queries) CountActiveOrderIntents(ctx context.Context, orderID sql.NullInt32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveOrderIntents, orderID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countActiveTips = `-- name: CountActiveTips :one
+SELECT COUNT(*) FROM tips WHERE order_id = ? AND status IN ('pending', 'paid')
+`
+
+func (q *Queries) CountActiveTips(ctx context.Context, orderID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveTips, orderID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countAddresses = `-- name: CountAddresses :one
+SELECT COUNT(*) FROM addresses WHERE user_id = ?
+`
+
+func (q *Queries) CountAddresses(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAddresses, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countBillShares = `-- name: CountBillShares :one
+SELECT COUNT(*) AS share_count,
+    CAST(COALESCE(SUM(is_paid = true OR intent_id IS NOT NULL), 0) AS SIGNED) AS locked_count,
+    CAST(COALESCE(SUM(is_paid = false), 0) AS SIGNED) AS unpaid_count
+FROM bill_shares
+WHERE order_id = ?
+`
+
+type CountBillSharesRow struct {
+	ShareCount  int64
+	LockedCount int64
+	UnpaidCount int64
+}
+
+func (q *Queries) CountBillShares(ctx context.Context, orderID int32) (CountBillSharesRow, error) {
+	row := q.db.QueryRowContext(ctx, countBillShares, orderID)
+	var i CountBillSharesRow
+	err := row.Scan(&i.ShareCount, &i.LockedCount, &i.UnpaidCount)
+	return i, err
+}
+
+const countOrdersByUser = `-- name: CountOrdersByUser :one
+SELECT COUNT(*) FROM orders WHERE user_id = ?
+`
+
+func (q *Queries) CountOrdersByUser(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOrdersByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPasswordResetsSince = `-- name: CountPasswordResetsSince :one
+SELECT COUNT(*) FROM password_resets
+WHERE user_id = ? AND created_at > ?
+`
+
+type CountPasswordResetsSinceParams struct {
+	UserID    int32
+	CreatedAt time.Time
+}
+
+func (q *Queries) CountPasswordResetsSince(ctx context.Context, arg CountPasswordResetsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPasswordResetsSince, arg.UserID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRecoveryCodes = `-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = ? AND used_at IS NULL
+`
+
+func (q *Queries) CountRecoveryCodes(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRole = `-- name: CountRole :one
+SELECT COUNT(*) FROM account_roles
+WHERE role = ?
+`
+
+func (q *Queries) CountRole(ctx context.Context, role string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRole, role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAccount = `-- name: CreateAccount :exec
+INSERT INTO accounts (username, password, email, address, user_phone_number)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateAccountParams struct {
+	Username        string
+	Password        string
+	Email           string
+	Address         string
+	UserPhoneNumber int64
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) error {
+	_, err := q.db.ExecContext(ctx, createAccount,
+		arg.Username,
+		arg.Password,
+		arg.Email,
+		arg.Address,
+		arg.UserPhoneNumber,
+	)
+	return err
+}
+
+const createAddress = `-- name: CreateAddress :exec
+INSERT INTO addresses (user_id, label, recipient, phone, line1, line2, city, postcode, lat, lng, is_default)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAddressParams struct {
+	UserID    int32
+	Label     string
+	Recipient string
+	Phone     string
+	Line1     string
+	Line2     string
+	City      string
+	Postcode  string
+	Lat       sql.NullFloat64
+	Lng       sql.NullFloat64
+	IsDefault bool
+}
+
+func (q *Queries) CreateAddress(ctx context.Context, arg CreateAddressParams) error {
+	_, err := q.db.ExecContext(ctx, createAddress,
+		arg.UserID,
+		arg.Label,
+		arg.Recipient,
+		arg.Phone,
+		arg.Line1,
+		arg.Line2,
+		arg.City,
+		arg.Postcode,
+		arg.Lat,
+		arg.Lng,
+		arg.IsDefault,
+	)
+	return err
+}
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+INSERT INTO audit_log (actor_id, target_user_id, action, reason, details, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateAuditLogParams struct {
+	ActorID      sql.NullInt32
+	TargetUserID sql.NullInt32
+	Action       string
+	Reason       string
+	Details      sql.NullString
+	CreatedAt    time.Time
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditLog,
+		arg.ActorID,
+		arg.TargetUserID,
+		arg.Action,
+		arg.Reason,
+		arg.Details,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createBillShare = `-- name: CreateBillShare :exec
+INSERT INTO bill_shares (order_id, label, amount)
+VALUES (
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateBillShareParams struct {
+	OrderID int32
+	Label   string
+	Amount  float64
+}
+
+func (q *Queries) CreateBillShare(ctx context.Context, arg CreateBillShareParams) error {
+	_, err := q.db.ExecContext(ctx, createBillShare, arg.OrderID, arg.Label, arg.Amount)
+	return err
+}
+
+const createDeletionRequest = `-- name: CreateDeletionRequest :exec
+INSERT INTO deletion_requests (user_id, ip, requested_at, scheduled_for)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateDeletionRequestParams struct {
+	UserID       int32
+	Ip           string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+}
+
+func (q *Queries) CreateDeletionRequest(ctx context.Context, arg CreateDeletionRequestParams) error {
+	_, err := q.db.ExecContext(ctx, createDeletionRequest,
+		arg.UserID,
+		arg.Ip,
+		arg.RequestedAt,
+		arg.ScheduledFor,
+	)
+	return err
+}
+
+const createDelivery = `-- name: CreateDelivery :exec
+INSERT INTO deliveries (order_id, rider_id, assigned_by, assigned_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateDeliveryParams struct {
+	OrderID    int32
+	RiderID    int32
+	AssignedBy sql.NullInt32
+	AssignedAt time.Time
+}
+
+func (q *Queries) CreateDelivery(ctx context.Context, arg CreateDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createDelivery,
+		arg.OrderID,
+		arg.RiderID,
+		arg.AssignedBy,
+		arg.AssignedAt,
+	)
+	return err
+}
+
+const createDeliveryZone = `-- name: CreateDeliveryZone :exec
+INSERT INTO delivery_zones (name, kind, radius_km, polygon, base_fee, fee_per_km, min_order)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateDeliveryZoneParams struct {
+	Name     string
+	Kind     string
+	RadiusKm sql.NullFloat64
+	Polygon  sql.NullString
+	BaseFee  float64
+	FeePerKm float64
+	MinOrder float64
+}
+
+func (q *Queries) CreateDeliveryZone(ctx context.Context, arg CreateDeliveryZoneParams) error {
+	_, err := q.db.ExecContext(ctx, createDeliveryZone,
+		arg.Name,
+		arg.Kind,
+		arg.RadiusKm,
+		arg.Polygon,
+		arg.BaseFee,
+		arg.FeePerKm,
+		arg.MinOrder,
+	)
+	return err
+}
+
+const createFood = `-- name: CreateFood :exec
+INSERT INTO food (food_name, price, info, ingredients, time_needed, picture, description, long_range)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateFoodParams struct {
+	FoodName    string
+	Price       float64
+	Info        sql.NullString
+	Ingredients string
+	TimeNeeded  int32
+	Picture     sql.NullString
+	Description string
+	LongRange   bool
+}
+
+func (q *Queries) CreateFood(ctx context.Context, arg CreateFoodParams) error {
+	_, err := q.db.ExecContext(ctx, createFood,
+		arg.FoodName,
+		arg.Price,
+		arg.Info,
+		arg.Ingredients,
+		arg.TimeNeeded,
+		arg.Picture,
+		arg.Description,
+		arg.LongRange,
+	)
+	return err
+}
+
+const createGiftCard = `-- name: CreateGiftCard :exec
+INSERT INTO gift_cards (code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateGiftCardParams struct {
+	Code          string
+	InitialAmount float64
+	Balance       float64
+	Status        string
+	Message       sql.NullString
+	IssuedBy      sql.NullInt32
+	PurchasedBy   sql.NullInt32
+	ExpiresAt     sql.NullTime
+}
+
+func (q *Queries) CreateGiftCard(ctx context.Context, arg CreateGiftCardParams) error {
+	_, err := q.db.ExecContext(ctx, createGiftCard,
+		arg.Code,
+		arg.InitialAmount,
+		arg.Balance,
+		arg.Status,
+		arg.Message,
+		arg.IssuedBy,
+		arg.PurchasedBy,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createGiftCardTransaction = `-- name: CreateGiftCardTransaction :exec
+INSERT INTO gift_card_transactions (card_id, account_id, kind, amount, balance_after, intent_id, note)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateGiftCardTransactionParams struct {
+	CardID       int32
+	AccountID    sql.NullInt32
+	Kind         string
+	Amount       float64
+	BalanceAfter float64
+	IntentID     sql.NullInt32
+	Note         sql.NullString
+}
+
+func (q *Queries) CreateGiftCardTransaction(ctx context.Context, arg CreateGiftCardTransactionParams) error {
+	_, err := q.db.ExecContext(ctx, createGiftCardTransaction,
+		arg.CardID,
+		arg.AccountID,
+		arg.Kind,
+		arg.Amount,
+		arg.BalanceAfter,
+		arg.IntentID,
+		arg.Note,
+	)
+	return err
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (user_id, username, ip, user_agent, success, reason, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateLoginAttemptParams struct {
+	UserID    sql.NullInt32
+	Username  string
+	Ip        string
+	UserAgent string
+	Success   bool
+	Reason    string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginAttempt,
+		arg.UserID,
+		arg.Username,
+		arg.Ip,
+		arg.UserAgent,
+		arg.Success,
+		arg.Reason,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createLoginChallenge = `-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenges (user_id, token_hash, expires_in_seconds, ip, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateLoginChallengeParams struct {
+	UserID           int32
+	TokenHash        string
+	ExpiresInSeconds int32
+	Ip               string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+}
+
+func (q *Queries) CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginChallenge,
+		arg.UserID,
+		arg.TokenHash,
+		arg.ExpiresInSeconds,
+		arg.Ip,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createMail = `-- name: CreateMail :exec
+INSERT INTO mail_outbox (user_id, recipient, subject, body, next_attempt_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateMailParams struct {
+	UserID        sql.NullInt32
+	Recipient     string
+	Subject       string
+	Body          string
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) CreateMail(ctx context.Context, arg CreateMailParams) error {
+	_, err := q.db.ExecContext(ctx, createMail,
+		arg.UserID,
+		arg.Recipient,
+		arg.Subject,
+		arg.Body,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const createNotification = `-- name: CreateNotification :exec
+INSERT INTO notifications (user_id, order_id, kind, message)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateNotificationParams struct {
+	UserID  int32
+	OrderID sql.NullInt32
+	Kind    string
+	Message string
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) error {
+	_, err := q.db.ExecContext(ctx, createNotification,
+		arg.UserID,
+		arg.OrderID,
+		arg.Kind,
+		arg.Message,
+	)
+	return err
+}
+
+const createOpeningHours = `-- name: CreateOpeningHours :exec
+INSERT INTO opening_hours (order_type, weekday, opens_at, closes_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateOpeningHoursParams struct {
+	OrderType string
+	Weekday   int32
+	OpensAt   string
+	ClosesAt  string
+}
+
+func (q *Queries) CreateOpeningHours(ctx context.Context, arg CreateOpeningHoursParams) error {
+	_, err := q.db.ExecContext(ctx, createOpeningHours,
+		arg.OrderType,
+		arg.Weekday,
+		arg.OpensAt,
+		arg.ClosesAt,
+	)
+	return err
+}
+
+const createOrder = `-- name: CreateOrder :exec
+INSERT INTO orders (
+    user_id, order_info, is_ranged, delivery_address, order_type, table_number,
+    invoice_billing_name, invoice_tax_id,
+    zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee,
+    address_id, delivery_recipient, delivery_phone,
+    scheduled_for, release_at
+)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateOrderParams struct {
+	UserID             int32
+	OrderInfo          string
+	IsRanged           bool
+	DeliveryAddress    sql.NullString
+	OrderType          string
+	TableNumber        sql.NullInt32
+	InvoiceBillingName sql.NullString
+	InvoiceTaxID       sql.NullString
+	ZoneID             sql.NullInt32
+	DeliveryLat        sql.NullFloat64
+	DeliveryLng        sql.NullFloat64
+	DeliveryDistanceKm sql.NullFloat64
+	DeliveryFee        float64
+	AddressID          sql.NullInt32
+	DeliveryRecipient  sql.NullString
+	DeliveryPhone      sql.NullString
+	ScheduledFor       sql.NullTime
+	ReleaseAt          sql.NullTime
+}
+
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error {
+	_, err := q.db.ExecContext(ctx, createOrder,
+		arg.UserID,
+		arg.OrderInfo,
+		arg.IsRanged,
+		arg.DeliveryAddress,
+		arg.OrderType,
+		arg.TableNumber,
+		arg.InvoiceBillingName,
+		arg.InvoiceTaxID,
+		arg.ZoneID,
+		arg.DeliveryLat,
+		arg.DeliveryLng,
+		arg.DeliveryDistanceKm,
+		arg.DeliveryFee,
+		arg.AddressID,
+		arg.DeliveryRecipient,
+		arg.DeliveryPhone,
+		arg.ScheduledFor,
+		arg.ReleaseAt,
+	)
+	return err
+}
+
+const createOrderSlot = `-- name: CreateOrderSlot :exec
+INSERT INTO order_slots (slot_start)
+VALUES (?)
+ON DUPLICATE KEY UPDATE slot_start = slot_start
+`
+
+func (q *Queries) CreateOrderSlot(ctx context.Context, slotStart time.Time) error {
+	_, err := q.db.ExecContext(ctx, createOrderSlot, slotStart)
+	return err
+}
+
+const createOrderedItem = `-- name: CreateOrderedItem :exec
+INSERT INTO items (order_id, food_id, quantity, food_name, unit_price, modifiers)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateOrderedItemParams struct {
+	OrderID   int32
+	FoodID    int32
+	Quantity  int32
+	FoodName  string
+	UnitPrice float64
+	Modifiers string
+}
+
+func (q *Queries) CreateOrderedItem(ctx context.Context, arg CreateOrderedItemParams) error {
+	_, err := q.db.ExecContext(ctx, createOrderedItem,
+		arg.OrderID,
+		arg.FoodID,
+		arg.Quantity,
+		arg.FoodName,
+		arg.UnitPrice,
+		arg.Modifiers,
+	)
+	return err
+}
+
+const createPasswordReset = `-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (user_id, token_hash, ip, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreatePasswordResetParams struct {
+	UserID    int32
+	TokenHash string
+	Ip        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordReset,
+		arg.UserID,
+		arg.TokenHash,
+		arg.Ip,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createPaymentEvent = `-- name: CreatePaymentEvent :exec
+INSERT INTO payment_events (intent_id, event_type, status, amount, detail)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreatePaymentEventParams struct {
+	IntentID  int32
+	EventType string
+	Status    string
+	Amount    float64
+	Detail    sql.NullString
+}
+
+func (q *Queries) CreatePaymentEvent(ctx context.Context, arg CreatePaymentEventParams) error {
+	_, err := q.db.ExecContext(ctx, createPaymentEvent,
+		arg.IntentID,
+		arg.EventType,
+		arg.Status,
+		arg.Amount,
+		arg.Detail,
+	)
+	return err
+}
+
+const createPaymentIntent = `-- name: CreatePaymentIntent :exec
+INSERT INTO payment_intents (user_id, order_id, share_id, gift_card_id, reservation_id, purpose, provider, amount)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreatePaymentIntentParams struct {
+	UserID        int32
+	OrderID       sql.NullInt32
+	ShareID       sql.NullInt32
+	GiftCardID    sql.NullInt32
+	ReservationID sql.NullInt32
+	Purpose       string
+	Provider      string
+	Amount        float64
+}
+
+func (q *Queries) CreatePaymentIntent(ctx context.Context, arg CreatePaymentIntentParams) error {
+	_, err := q.db.ExecContext(ctx, createPaymentIntent,
+		arg.UserID,
+		arg.OrderID,
+		arg.ShareID,
+		arg.GiftCardID,
+		arg.ReservationID,
+		arg.Purpose,
+		arg.Provider,
+		arg.Amount,
+	)
+	return err
+}
+
+const createPrintJob = `-- name: CreatePrintJob :exec
+INSERT INTO print_jobs (order_id, station, kind, payload, next_attempt_at, reprint_of)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreatePrintJobParams struct {
+	OrderID       sql.NullInt32
+	Station       string
+	Kind          string
+	Payload       []byte
+	NextAttemptAt time.Time
+	ReprintOf     sql.NullInt32
+}
+
+func (q *Queries) CreatePrintJob(ctx context.Context, arg CreatePrintJobParams) error {
+	_, err := q.db.ExecContext(ctx, createPrintJob,
+		arg.OrderID,
+		arg.Station,
+		arg.Kind,
+		arg.Payload,
+		arg.NextAttemptAt,
+		arg.ReprintOf,
+	)
+	return err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash, created_at)
+VALUES (?, ?, ?)
+`
+
+type CreateRecoveryCodeParams struct {
+	UserID    int32
+	CodeHash  string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createRecoveryCode, arg.UserID, arg.CodeHash, arg.CreatedAt)
+	return err
+}
+
+const createReservation = `-- name: CreateReservation :exec
+INSERT INTO reservations (user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateReservationParams struct {
+	UserID        int32
+	TableNumber   int32
+	PartySize     int32
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Status        string
+	Note          string
+	Deposit       float64
+	DepositStatus string
+}
+
+func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationParams) error {
+	_, err := q.db.ExecContext(ctx, createReservation,
+		arg.UserID,
+		arg.TableNumber,
+		arg.PartySize,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Status,
+		arg.Note,
+		arg.Deposit,
+		arg.DepositStatus,
+	)
+	return err
+}
+
+const createRiderLocation = `-- name: CreateRiderLocation :exec
+INSERT INTO rider_locations (rider_id, delivery_id, lat, lng)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateRiderLocationParams struct {
+	RiderID    int32
+	DeliveryID sql.NullInt32
+	Lat        float64
+	Lng        float64
+}
+
+func (q *Queries) CreateRiderLocation(ctx context.Context, arg CreateRiderLocationParams) error {
+	_, err := q.db.ExecContext(ctx, createRiderLocation,
+		arg.RiderID,
+		arg.DeliveryID,
+		arg.Lat,
+		arg.Lng,
+	)
+	return err
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO user_sessions (user_id, refresh_hash, user_agent, ip, created_at, last_used_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	UserID      int32
+	RefreshHash string
+	UserAgent   string
+	Ip          string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.UserID,
+		arg.RefreshHash,
+		arg.UserAgent,
+		arg.Ip,
+		arg.CreatedAt,
+		arg.LastUsedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createShareItem = `-- name: CreateShareItem :exec
+INSERT INTO share_items (share_id, item_id, quantity)
+VALUES (
+    ?,
+    ?,
+    ?
+)
+`
+
+type CreateShareItemParams struct {
+	ShareID  int32
+	ItemID   int32
+	Quantity int32
+}
+
+func (q *Queries) CreateShareItem(ctx context.Context, arg CreateShareItemParams) error {
+	_, err := q.db.ExecContext(ctx, createShareItem, arg.ShareID, arg.ItemID, arg.Quantity)
+	return err
+}
+
+const createTip = `-- name: CreateTip :exec
+INSERT INTO tips (order_id, user_id, intent_id, kind, rate, amount, pool, recipient_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateTipParams struct {
+	OrderID     int32
+	UserID      int32
+	IntentID    sql.NullInt32
+	Kind        string
+	Rate        sql.NullFloat64
+	Amount      float64
+	Pool        string
+	RecipientID sql.NullInt32
+}
+
+func (q *Queries) CreateTip(ctx context.Context, arg CreateTipParams) error {
+	_, err := q.db.ExecContext(ctx, createTip,
+		arg.OrderID,
+		arg.UserID,
+		arg.IntentID,
+		arg.Kind,
+		arg.Rate,
+		arg.Amount,
+		arg.Pool,
+		arg.RecipientID,
+	)
+	return err
+}
+
+const createVerification = `-- name: CreateVerification :exec
+INSERT INTO verifications (user_id, channel, target, code_hash, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateVerificationParams struct {
+	UserID    int32
+	Channel   string
+	Target    string
+	CodeHash  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateVerification(ctx context.Context, arg CreateVerificationParams) error {
+	_, err := q.db.ExecContext(ctx, createVerification,
+		arg.UserID,
+		arg.Channel,
+		arg.Target,
+		arg.CodeHash,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createWaitlistEntry = `-- name: CreateWaitlistEntry :exec
+INSERT INTO waitlist (user_id, name, phone, party_size)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateWaitlistEntryParams struct {
+	UserID    sql.NullInt32
+	Name      string
+	Phone     string
+	PartySize int32
+}
+
+func (q *Queries) CreateWaitlistEntry(ctx context.Context, arg CreateWaitlistEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createWaitlistEntry,
+		arg.UserID,
+		arg.Name,
+		arg.Phone,
+		arg.PartySize,
+	)
+	return err
+}
+
+const creditAccountBalance = `-- name: CreditAccountBalance :execrows
+UPDATE accounts
+SET
+    balance = balance + ?
+WHERE
+    id = ? AND ROUND(balance + ?, 2) <= ?
+`
+
+type CreditAccountBalanceParams struct {
+	Amount     float64
+	ID         int32
+	MaxBalance float64
+}
+
+func (q *Queries) CreditAccountBalance(ctx context.Context, arg CreditAccountBalanceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, creditAccountBalance,
+		arg.Amount,
+		arg.ID,
+		arg.Amount,
+		arg.MaxBalance,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deactivateRider = `-- name: DeactivateRider :execrows
+UPDATE riders
+SET
+    is_active = false,
+    status = 'offline'
+WHERE
+    user_id = ?
+`
+
+func (q *Queries) DeactivateRider(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deactivateRider, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deductAccountBalance = `-- name: DeductAccountBalance :execrows
+UPDATE accounts
+SET
+    balance = balance - ?
+WHERE
+    id = ? AND balance >= ?
+`
+
+type DeductAccountBalanceParams struct {
+	Amount float64
+	ID     int32
+}
+
+func (q *Queries) DeductAccountBalance(ctx context.Context, arg DeductAccountBalanceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deductAccountBalance, arg.Amount, arg.ID, arg.Amount)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAccount = `-- name: DeleteAccount :execrows
+DELETE FROM accounts WHERE id = ?
+`
+
+func (q *Queries) DeleteAccount(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAccount, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAddress = `-- name: DeleteAddress :execrows
+DELETE FROM addresses WHERE address_id = ? AND user_id = ?
+`
+
+type DeleteAddressParams struct {
+	AddressID int32
+	UserID    int32
+}
+
+func (q *Queries) DeleteAddress(ctx context.Context, arg DeleteAddressParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAddress, arg.AddressID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAddressesByUser = `-- name: DeleteAddressesByUser :exec
+DELETE FROM addresses WHERE user_id = ?
+`
+
+func (q *Queries) DeleteAddressesByUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAddressesByUser, userID)
+	return err
+}
+
+const deleteBillShares = `-- name: DeleteBillShares :exec
+DELETE FROM bill_shares
+WHERE order_id = ?
+`
+
+func (q *Queries) DeleteBillShares(ctx context.Context, orderID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteBillShares, orderID)
+	return err
+}
+
+const deleteDeliveryZone = `-- name: DeleteDeliveryZone :exec
+DELETE FROM delivery_zones WHERE zone_id = ?
+`
+
+func (q *Queries) DeleteDeliveryZone(ctx context.Context, zoneID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteDeliveryZone, zoneID)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, expiresAt)
+	return err
+}
+
+const deleteExpiredLoginChallenges = `-- name: DeleteExpiredLoginChallenges :exec
+DELETE FROM login_challenges
+WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredLoginChallenges(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredLoginChallenges, expiresAt)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
+DELETE FROM user_sessions
+WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSessions, expiresAt)
+	return err
+}
+
+const deleteFood = `-- name: DeleteFood :exec
+DELETE FROM food
+WHERE food_name = ?
+`
+
+func (q *Queries) DeleteFood(ctx context.Context, foodName string) error {
+	_, err := q.db.ExecContext(ctx, deleteFood, foodName)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ? AND idem_key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID  int32
+	IdemKey string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.IdemKey)
+	return err
+}
+
+const deleteMailByUser = `-- name: DeleteMailByUser :exec
+DELETE FROM mail_outbox WHERE user_id = ?
+`
+
+func (q *Queries) DeleteMailByUser(ctx context.Context, userID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, deleteMailByUser, userID)
+	return err
+}
+
+const deleteNotificationsByUser = `-- name: DeleteNotificationsByUser :exec
+DELETE FROM notifications WHERE user_id = ?
+`
+
+func (q *Queries) DeleteNotificationsByUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteNotificationsByUser, userID)
+	return err
+}
+
+const deleteOpeningHours = `-- name: DeleteOpeningHours :exec
+DELETE FROM opening_hours WHERE order_type = ?
+`
+
+func (q *Queries) DeleteOpeningHours(ctx context.Context, orderType string) error {
+	_, err := q.db.ExecContext(ctx, deleteOpeningHours, orderType)
+	return err
+}
+
+const deleteOrder = `-- name: DeleteOrder :exec
+UPDATE orders
+SET
+    deleted = true
+WHERE
+    order_id = ?
+`
+
+func (q *Queries) DeleteOrder(ctx context.Context, orderID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteOrder, orderID)
+	return err
+}
+
+const deletePasswordResets = `-- name: DeletePasswordResets :exec
+DELETE FROM password_resets WHERE user_id = ?
+`
+
+func (q *Queries) DeletePasswordResets(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePasswordResets, userID)
+	return err
+}
+
+const deletePrinter = `-- name: DeletePrinter :exec
+DELETE FROM printers WHERE printer_id = ?
+`
+
+func (q *Queries) DeletePrinter(ctx context.Context, printerID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePrinter, printerID)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteSessionsByUser = `-- name: DeleteSessionsByUser :exec
+DELETE FROM user_sessions WHERE user_id = ?
+`
+
+func (q *Queries) DeleteSessionsByUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsByUser, userID)
+	return err
+}
+
+const deleteStaleLoginThrottles = `-- name: DeleteStaleLoginThrottles :exec
+DELETE FROM login_throttles
+WHERE last_failure_at < ? AND blocked_until < ?
+`
+
+type DeleteStaleLoginThrottlesParams struct {
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+}
+
+func (q *Queries) DeleteStaleLoginThrottles(ctx context.Context, arg DeleteStaleLoginThrottlesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleLoginThrottles, arg.LastFailureAt, arg.BlockedUntil)
+	return err
+}
+
+const deleteStoreHoliday = `-- name: DeleteStoreHoliday :execrows
+DELETE FROM store_holidays WHERE holiday_id = ?
+`
+
+func (q *Queries) DeleteStoreHoliday(ctx context.Context, holidayID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStoreHoliday, holidayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTag = `-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE food_name = ?
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, foodName string) error {
+	_, err := q.db.ExecContext(ctx, deleteTag, foodName)
+	return err
+}
+
+const deleteTwoFactorSecret = `-- name: DeleteTwoFactorSecret :exec
+DELETE FROM two_factor_secrets
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteTwoFactorSecret(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTwoFactorSecret, userID)
+	return err
+}
+
+const deleteVerifications = `-- name: DeleteVerifications :exec
+DELETE FROM verifications WHERE user_id = ?
+`
+
+func (q *Queries) DeleteVerifications(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteVerifications, userID)
+	return err
+}
+
+const failMail = `-- name: FailMail :exec
+UPDATE mail_outbox
+SET
+    status = 'failed',
+    body = '',
+    last_error = ?
+WHERE
+    mail_id = ?
+`
+
+type FailMailParams struct {
+	LastError sql.NullString
+	MailID    int32
+}
+
+func (q *Queries) FailMail(ctx context.Context, arg FailMailParams) error {
+	_, err := q.db.ExecContext(ctx, failMail, arg.LastError, arg.MailID)
+	return err
+}
+
+const failPrintJob = `-- name: FailPrintJob :exec
+UPDATE print_jobs
+SET
+    status = 'failed',
+    last_error = ?
+WHERE
+    job_id = ?
+`
+
+type FailPrintJobParams struct {
+	LastError sql.NullString
+	JobID     int32
+}
+
+func (q *Queries) FailPrintJob(ctx context.Context, arg FailPrintJobParams) error {
+	_, err := q.db.ExecContext(ctx, failPrintJob, arg.LastError, arg.JobID)
+	return err
+}
+
+const freeOrderSlot = `-- name: FreeOrderSlot :exec
+UPDATE order_slots
+SET
+    booked = booked - 1
+WHERE
+    slot_start = ? AND booked > 0
+`
+
+func (q *Queries) FreeOrderSlot(ctx context.Context, slotStart time.Time) error {
+	_, err := q.db.ExecContext(ctx, freeOrderSlot, slotStart)
+	return err
+}
+
+const getAccount = `-- name: GetAccount :one
+SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number, email_verified_at, phone_verified_at, two_factor_enabled_at, status, status_reason, suspended_until FROM accounts WHERE username = ?
+`
+
+func (q *Queries) GetAccount(ctx context.Context, username string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccount, username)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Email,
+		&i.Address,
+		&i.Balance,
+		&i.IsAdmin,
+		&i.UserTag,
+		&i.UserPhoneNumber,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabledAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getAccountByEmail = `-- name: GetAccountByEmail :one
+SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number, email_verified_at, phone_verified_at, two_factor_enabled_at, status, status_reason, suspended_until FROM accounts WHERE email = ?
+`
+
+func (q *Queries) GetAccountByEmail(ctx context.Context, email string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByEmail, email)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Email,
+		&i.Address,
+		&i.Balance,
+		&i.IsAdmin,
+		&i.UserTag,
+		&i.UserPhoneNumber,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabledAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getAccountByID = `-- name: GetAccountByID :one
+SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number, email_verified_at, phone_verified_at, two_factor_enabled_at, status, status_reason, suspended_until FROM accounts WHERE id = ?
+`
+
+func (q *Queries) GetAccountByID(ctx context.Context, id int32) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByID, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Email,
+		&i.Address,
+		&i.Balance,
+		&i.IsAdmin,
+		&i.UserTag,
+		&i.UserPhoneNumber,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabledAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getAccountRoles = `-- name: GetAccountRoles :many
+SELECT role FROM account_roles
+WHERE user_id = ?
+ORDER BY role
+`
+
+func (q *Queries) GetAccountRoles(ctx context.Context, userID int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountRoles, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		items = append(items, role)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveDeliveriesByRider = `-- name: GetActiveDeliveriesByRider :many
+SELECT delivery_id, order_id, rider_id, status, assigned_by, assigned_at, picked_up_at, delivered_at, failed_at, failure_reason, proof_note, proof_photo FROM deliveries
+WHERE rider_id = ? AND status IN ('assigned', 'picked_up')
+ORDER BY delivery_id
+`
+
+func (q *Queries) GetActiveDeliveriesByRider(ctx context.Context, riderID int32) ([]Delivery, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveDeliveriesByRider, riderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Delivery
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.DeliveryID,
+			&i.OrderID,
+			&i.RiderID,
+			&i.Status,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.PickedUpAt,
+			&i.DeliveredAt,
+			&i.FailedAt,
+			&i.FailureReason,
+			&i.ProofNote,
+			&i.ProofPhoto,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveDeliveryZones = `-- name: GetActiveDeliveryZones :many
+SELECT zone_id, name, kind, radius_km, polygon, base_fee, fee_per_km, min_order, is_active, created_at FROM delivery_zones WHERE is_active = true ORDER BY zone_id
+`
+
+func (q *Queries) GetActiveDeliveryZones(ctx context.Context) ([]DeliveryZone, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveDeliveryZones)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeliveryZone
+	for rows.Next() {
+		var i DeliveryZone
+		if err := rows.Scan(
+			&i.ZoneID,
+			&i.Name,
+			&i.Kind,
+			&i.RadiusKm,
+			&i.Polygon,
+			&i.BaseFee,
+			&i.FeePerKm,
+			&i.MinOrder,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveDiningTablesForUpdate = `-- name: GetActiveDiningTablesForUpdate :many
+SELECT table_number, seats, is_active, created_at FROM dining_tables WHERE is_active = true ORDER BY table_number FOR UPDATE
+`
+
+func (q *Queries) GetActiveDiningTablesForUpdate(ctx context.Context) ([]DiningTable, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveDiningTablesForUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiningTable
+	for rows.Next() {
+		var i DiningTable
+		if err := rows.Scan(
+			&i.TableNumber,
+			&i.Seats,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActivePrinterByStation = `-- name: GetActivePrinterByStation :one
+SELECT printer_id, name, station, address, is_active, created_at FROM printers WHERE station = ? AND is_active = true
+`
+
+func (q *Queries) GetActivePrinterByStation(ctx context.Context, station string) (Printer, error) {
+	row := q.db.QueryRowContext(ctx, getActivePrinterByStation, station)
+	var i Printer
+	err := row.Scan(
+		&i.PrinterID,
+		&i.Name,
+		&i.Station,
+		&i.Address,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveSession = `-- name: GetActiveSession :one
+SELECT session_id, user_id, refresh_hash, previous_hash, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM user_sessions
+WHERE session_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?
+`
+
+type GetActiveSessionParams struct {
+	SessionID int32
+	UserID    int32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) GetActiveSession(ctx context.Context, arg GetActiveSessionParams) (UserSession, error) {
+	row := q.db.QueryRowContext(ctx, getActiveSession, arg.SessionID, arg.UserID, arg.ExpiresAt)
+	var i UserSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.RefreshHash,
+		&i.PreviousHash,
+		&i.UserAgent,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getActiveSessionsByUser = `-- name: GetActiveSessionsByUser :many
+SELECT session_id, user_id, refresh_hash, previous_hash, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM user_sessions
+WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+ORDER BY last_used_at DESC
+`
+
+type GetActiveSessionsByUserParams struct {
+	UserID    int32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) GetActiveSessionsByUser(ctx context.Context, arg GetActiveSessionsByUserParams) ([]UserSession, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveSessionsByUser, arg.UserID, arg.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSession
+	for rows.Next() {
+		var i UserSession
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.UserID,
+			&i.RefreshHash,
+			&i.PreviousHash,
+			&i.UserAgent,
+			&i.Ip,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveWaitlistEntryByUser = `-- name: GetActiveWaitlistEntryByUser :one
+SELECT entry_id, user_id, name, phone, party_size, status, table_number, notified_at, seated_at, created_at FROM waitlist
+WHERE user_id = ? AND status IN ('waiting', 'notified')
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveWaitlistEntryByUser(ctx context.Context, userID sql.NullInt32) (Waitlist, error) {
+	row := q.db.QueryRowContext(ctx, getActiveWaitlistEntryByUser, userID)
+	var i Waitlist
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.Name,
+		&i.Phone,
+		&i.PartySize,
+		&i.Status,
+		&i.TableNumber,
+		&i.NotifiedAt,
+		&i.SeatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAddress = `-- name: GetAddress :one
+SELECT address_id, user_id, label, recipient, phone, line1, line2, city, postcode, lat, lng, is_default, created_at, updated_at FROM addresses WHERE address_id = ? AND user_id = ?
+`
+
+type GetAddressParams struct {
+	AddressID int32
+	UserID    int32
+}
+
+func (q *Queries) GetAddress(ctx context.Context, arg GetAddressParams) (Address, error) {
+	row := q.db.QueryRowContext(ctx, getAddress, arg.AddressID, arg.UserID)
+	var i Address
+	err := row.Scan(
+		&i.AddressID,
+		&i.UserID,
+		&i.Label,
+		&i.Recipient,
+		&i.Phone,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Postcode,
+		&i.Lat,
+		&i.Lng,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAddressesByUser = `-- name: GetAddressesByUser :many
+SELECT address_id, user_id, label, recipient, phone, line1, line2, city, postcode, lat, lng, is_default, created_at, updated_at FROM addresses WHERE user_id = ? ORDER BY is_default DESC, address_id
+`
+
+func (q *Queries) GetAddressesByUser(ctx context.Context, userID int32) ([]Address, error) {
+	rows, err := q.db.QueryContext(ctx, getAddressesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Address
+	for rows.Next() {
+		var i Address
+		if err := rows.Scan(
+			&i.AddressID,
+			&i.UserID,
+			&i.Label,
+			&i.Recipient,
+			&i.Phone,
+			&i.Line1,
+			&i.Line2,
+			&i.City,
+			&i.Postcode,
+			&i.Lat,
+			&i.Lng,
+			&i.IsDefault,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllAccounts = `-- name: GetAllAccounts :many
+SELECT id, username, password, email, address, balance, is_admin, user_tag, user_phone_number, email_verified_at, phone_verified_at, two_factor_enabled_at, status, status_reason, suspended_until FROM accounts WHERE is_admin = false
+`
+
+func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Password,
+			&i.Email,
+			&i.Address,
+			&i.Balance,
+			&i.IsAdmin,
+			&i.UserTag,
+			&i.UserPhoneNumber,
+			&i.EmailVerifiedAt,
+			&i.PhoneVerifiedAt,
+			&i.TwoFactorEnabledAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllDeletedOrdersByUser = `-- name: GetAllDeletedOrdersByUser :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE user_id = ? AND deleted = true
+`
+
+func (q *Queries) GetAllDeletedOrdersByUser(ctx context.Context, userID int32) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getAllDeletedOrdersByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllFood = `-- name: GetAllFood :many
+SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed, station FROM food
+`
+
+func (q *Queries) GetAllFood(ctx context.Context) ([]Food, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFood)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Food
+	for rows.Next() {
+		var i Food
+		if err := rows.Scan(
+			&i.FoodID,
+			&i.FoodName,
+			&i.Price,
+			&i.Picture,
+			&i.LongRange,
+			&i.Description,
+			&i.Info,
+			&i.Ingredients,
+			&i.TimeNeeded,
+			&i.Station,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllFoodLongRange = `-- name: GetAllFoodLongRange :many
+SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed, station FROM food WHERE long_range = true
+`
+
+func (q *Queries) GetAllFoodLongRange(ctx context.Context) ([]Food, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFoodLongRange)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Food
+	for rows.Next() {
+		var i Food
+		if err := rows.Scan(
+			&i.FoodID,
+			&i.FoodName,
+			&i.Price,
+			&i.Picture,
+			&i.LongRange,
+			&i.Description,
+			&i.Info,
+			&i.Ingredients,
+			&i.TimeNeeded,
+			&i.Station,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllFoodTags = `-- name: GetAllFoodTags :many
+SELECT DISTINCT tag FROM tags
+`
+
+func (q *Queries) GetAllFoodTags(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFoodTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllGiftCards = `-- name: GetAllGiftCards :many
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards ORDER BY card_id DESC
+`
+
+func (q *Queries) GetAllGiftCards(ctx context.Context) ([]GiftCard, error) {
+	rows, err := q.db.QueryContext(ctx, getAllGiftCards)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftCard
+	for rows.Next() {
+		var i GiftCard
+		if err := rows.Scan(
+			&i.CardID,
+			&i.Code,
+			&i.InitialAmount,
+			&i.Balance,
+			&i.Status,
+			&i.Message,
+			&i.IssuedBy,
+			&i.PurchasedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllOrderedItems = `-- name: GetAllOrderedItems :many
+SELECT item_id, order_id, food_id, quantity, rating, food_name, unit_price, modifiers FROM items
+`
+
+func (q *Queries) GetAllOrderedItems(ctx context.Context) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrderedItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Item
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.OrderID,
+			&i.FoodID,
+			&i.Quantity,
+			&i.Rating,
+			&i.FoodName,
+			&i.UnitPrice,
+			&i.Modifiers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllOrders = `-- name: GetAllOrders :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE deleted = false ORDER BY order_time DESC
+`
+
+func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllOrdersByUser = `-- name: GetAllOrdersByUser :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE user_id = ? AND deleted = false
+`
+
+func (q *Queries) GetAllOrdersByUser(ctx context.Context, userID int32) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrdersByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllOrdersNotDone = `-- name: GetAllOrdersNotDone :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE is_done = false AND deleted = false AND (scheduled_for IS NULL OR released_at IS NOT NULL)
+ORDER BY order_time DESC
+`
+
+func (q *Queries) GetAllOrdersNotDone(ctx context.Context) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrdersNotDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditLog = `-- name: GetAuditLog :many
+SELECT audit_id, actor_id, target_user_id, action, reason, details, created_at FROM audit_log
+WHERE
+    (? IS NULL OR target_user_id = ?) AND
+    (? IS NULL OR actor_id = ?)
+ORDER BY created_at DESC, audit_id DESC
+LIMIT ?
+`
+
+type GetAuditLogParams struct {
+	TargetUserID sql.NullInt32
+	ActorID      sql.NullInt32
+	Limit        int32
+}
+
+func (q *Queries) GetAuditLog(ctx context.Context, arg GetAuditLogParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLog,
+		arg.TargetUserID,
+		arg.TargetUserID,
+		arg.ActorID,
+		arg.ActorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.AuditID,
+			&i.ActorID,
+			&i.TargetUserID,
+			&i.Action,
+			&i.Reason,
+			&i.Details,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAverageRating = `-- name: GetAverageRating :one
+SELECT AVG(rating) AS average_rating
+FROM items
+WHERE food_id = ? AND rating IS NOT NULL
+`
+
+func (q *Queries) GetAverageRating(ctx context.Context, foodID int32) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getAverageRating, foodID)
+	var average_rating interface{}
+	err := row.Scan(&average_rating)
+	return average_rating, err
+}
+
+const getAverageSpendingByAllUsers = `-- name: GetAverageSpendingByAllUsers :one
+SELECT AVG(total_price) AS average_spending
+FROM (
+    SELECT SUM(items.unit_price * items.quantity) AS total_price
+    FROM orders
+    JOIN items ON orders.order_id = items.order_id
+    GROUP BY orders.user_id, orders.order_id
+) AS spending
+`
+
+func (q *Queries) GetAverageSpendingByAllUsers(ctx context.Context) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getAverageSpendingByAllUsers)
+	var average_spending interface{}
+	err := row.Scan(&average_spending)
+	return average_spending, err
+}
+
+const getAverageSpendingByUser = `-- name: GetAverageSpendingByUser :one
+SELECT AVG(total_price) AS average_spending
+FROM (
+    SELECT SUM(items.unit_price * items.quantity) AS total_price
+    FROM orders
+    JOIN items ON orders.order_id = items.order_id
+    WHERE orders.user_id = ?
+    GROUP BY orders.order_id
+) AS spending
+`
+
+func (q *Queries) GetAverageSpendingByUser(ctx context.Context, userID int32) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getAverageSpendingByUser, userID)
+	var average_spending interface{}
+	err := row.Scan(&average_spending)
+	return average_spending, err
+}
+
+const getBillShare = `-- name: GetBillShare :one
+SELECT share_id, order_id, label, amount, is_paid, intent_id, paid_by, paid_at, created_at FROM bill_shares WHERE share_id = ?
+`
+
+func (q *Queries) GetBillShare(ctx context.Context, shareID int32) (BillShare, error) {
+	row := q.db.QueryRowContext(ctx, getBillShare, shareID)
+	var i BillShare
+	err := row.Scan(
+		&i.ShareID,
+		&i.OrderID,
+		&i.Label,
+		&i.Amount,
+		&i.IsPaid,
+		&i.IntentID,
+		&i.PaidBy,
+		&i.PaidAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBillSharesByOrder = `-- name: GetBillSharesByOrder :many
+SELECT share_id, order_id, label, amount, is_paid, intent_id, paid_by, paid_at, created_at FROM bill_shares WHERE order_id = ? ORDER BY share_id
+`
+
+func (q *Queries) GetBillSharesByOrder(ctx context.Context, orderID int32) ([]BillShare, error) {
+	rows, err := q.db.QueryContext(ctx, getBillSharesByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BillShare
+	for rows.Next() {
+		var i BillShare
+		if err := rows.Scan(
+			&i.ShareID,
+			&i.OrderID,
+			&i.Label,
+			&i.Amount,
+			&i.IsPaid,
+			&i.IntentID,
+			&i.PaidBy,
+			&i.PaidAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletionRequestForUpdate = `-- name: GetDeletionRequestForUpdate :one
+SELECT request_id, user_id, ip, requested_at, scheduled_for, cancelled_at, completed_at FROM deletion_requests
+WHERE request_id = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetDeletionRequestForUpdate(ctx context.Context, requestID int32) (DeletionRequest, error) {
+	row := q.db.QueryRowContext(ctx, getDeletionRequestForUpdate, requestID)
+	var i DeletionRequest
+	err := row.Scan(
+		&i.RequestID,
+		&i.UserID,
+		&i.Ip,
+		&i.RequestedAt,
+		&i.ScheduledFor,
+		&i.CancelledAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getDeliveries = `-- name: GetDeliveries :many
+SELECT delivery_id, order_id, rider_id, status, assigned_by, assigned_at, picked_up_at, delivered_at, failed_at, failure_reason, proof_note, proof_photo FROM deliveries
+WHERE ? = '' OR status = ?
+ORDER BY delivery_id DESC
+LIMIT ?
+`
+
+type GetDeliveriesParams struct {
+	Status string
+	Limit  int32
+}
+
+func (q *Queries) GetDeliveries(ctx context.Context, arg GetDeliveriesParams) ([]Delivery, error) {
+	rows, err := q.db.QueryContext(ctx, getDeliveries, arg.Status, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Delivery
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.DeliveryID,
+			&i.OrderID,
+			&i.RiderID,
+			&i.Status,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.PickedUpAt,
+			&i.DeliveredAt,
+			&i.FailedAt,
+			&i.FailureReason,
+			&i.ProofNote,
+			&i.ProofPhoto,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDelivery = `-- name: GetDelivery :one
+SELECT delivery_id, order_id, rider_id, status, assigned_by, assigned_at, picked_up_at, delivered_at, failed_at, failure_reason, proof_note, proof_photo FROM deliveries WHERE delivery_id = ?
+`
+
+func (q *Queries) GetDelivery(ctx context.Context, deliveryID int32) (Delivery, error) {
+	row := q.db.QueryRowContext(ctx, getDelivery, deliveryID)
+	var i Delivery
+	err := row.Scan(
+		&i.DeliveryID,
+		&i.OrderID,
+		&i.RiderID,
+		&i.Status,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.PickedUpAt,
+		&i.DeliveredAt,
+		&i.FailedAt,
+		&i.FailureReason,
+		&i.ProofNote,
+		&i.ProofPhoto,
+	)
+	return i, err
+}
+
+const getDeliveryProofPhotosByUser = `-- name: GetDeliveryProofPhotosByUser :many
+SELECT proof_photo FROM deliveries
+WHERE proof_photo IS NOT NULL AND order_id IN (SELECT order_id FROM orders WHERE user_id = ?)
+`
+
+func (q *Queries) GetDeliveryProofPhotosByUser(ctx context.Context, userID int32) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, getDeliveryProofPhotosByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullString
+	for rows.Next() {
+		var proof_photo sql.NullString
+		if err := rows.Scan(&proof_photo); err != nil {
+			return nil, err
+		}
+		items = append(items, proof_photo)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeliveryZone = `-- name: GetDeliveryZone :one
+SELECT zone_id, name, kind, radius_km, polygon, base_fee, fee_per_km, min_order, is_active, created_at FROM delivery_zones WHERE zone_id = ?
+`
+
+func (q *Queries) GetDeliveryZone(ctx context.Context, zoneID int32) (DeliveryZone, error) {
+	row := q.db.QueryRowContext(ctx, getDeliveryZone, zoneID)
+	var i DeliveryZone
+	err := row.Scan(
+		&i.ZoneID,
+		&i.Name,
+		&i.Kind,
+		&i.RadiusKm,
+		&i.Polygon,
+		&i.BaseFee,
+		&i.FeePerKm,
+		&i.MinOrder,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDeliveryZones = `-- name: GetDeliveryZones :many
+SELECT zone_id, name, kind, radius_km, polygon, base_fee, fee_per_km, min_order, is_active, created_at FROM delivery_zones ORDER BY zone_id
+`
+
+func (q *Queries) GetDeliveryZones(ctx context.Context) ([]DeliveryZone, error) {
+	rows, err := q.db.QueryContext(ctx, getDeliveryZones)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeliveryZone
+	for rows.Next() {
+		var i DeliveryZone
+		if err := rows.Scan(
+			&i.ZoneID,
+			&i.Name,
+			&i.Kind,
+			&i.RadiusKm,
+			&i.Polygon,
+			&i.BaseFee,
+			&i.FeePerKm,
+			&i.MinOrder,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDiningTables = `-- name: GetDiningTables :many
+SELECT table_number, seats, is_active, created_at FROM dining_tables ORDER BY table_number
+`
+
+func (q *Queries) GetDiningTables(ctx context.Context) ([]DiningTable, error) {
+	rows, err := q.db.QueryContext(ctx, getDiningTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiningTable
+	for rows.Next() {
+		var i DiningTable
+		if err := rows.Scan(
+			&i.TableNumber,
+			&i.Seats,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueDeletionRequests = `-- name: GetDueDeletionRequests :many
+SELECT request_id, user_id, ip, requested_at, scheduled_for, cancelled_at, completed_at FROM deletion_requests
+WHERE scheduled_for <= ? AND cancelled_at IS NULL AND completed_at IS NULL
+ORDER BY scheduled_for
+LIMIT ?
+`
+
+type GetDueDeletionRequestsParams struct {
+	ScheduledFor time.Time
+	Limit        int32
+}
+
+func (q *Queries) GetDueDeletionRequests(ctx context.Context, arg GetDueDeletionRequestsParams) ([]DeletionRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getDueDeletionRequests, arg.ScheduledFor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeletionRequest
+	for rows.Next() {
+		var i DeletionRequest
+		if err := rows.Scan(
+			&i.RequestID,
+			&i.UserID,
+			&i.Ip,
+			&i.RequestedAt,
+			&i.ScheduledFor,
+			&i.CancelledAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueMail = `-- name: GetDueMail :many
+SELECT mail_id, recipient, subject, body, status, attempts, last_error, next_attempt_at, claimed_at, sent_at, created_at, user_id FROM mail_outbox
+WHERE status = 'queued' AND next_attempt_at <= ?
+ORDER BY mail_id
+LIMIT ?
+`
+
+type GetDueMailParams struct {
+	NextAttemptAt time.Time
+	Limit         int32
+}
+
+func (q *Queries) GetDueMail(ctx context.Context, arg GetDueMailParams) ([]MailOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, getDueMail, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MailOutbox
+	for rows.Next() {
+		var i MailOutbox
+		if err := rows.Scan(
+			&i.MailID,
+			&i.Recipient,
+			&i.Subject,
+			&i.Body,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.ClaimedAt,
+			&i.SentAt,
+			&i.CreatedAt,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDuePrintJobs = `-- name: GetDuePrintJobs :many
+SELECT job_id, order_id, station, kind, payload, status, attempts, last_error, next_attempt_at, claimed_at, printed_at, reprint_of, created_at FROM print_jobs
+WHERE status = 'queued' AND next_attempt_at <= ?
+ORDER BY job_id
+LIMIT ?
+`
+
+type GetDuePrintJobsParams struct {
+	NextAttemptAt time.Time
+	Limit         int32
+}
+
+func (q *Queries) GetDuePrintJobs(ctx context.Context, arg GetDuePrintJobsParams) ([]PrintJob, error) {
+	rows, err := q.db.QueryContext(ctx, getDuePrintJobs, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PrintJob
+	for rows.Next() {
+		var i PrintJob
+		if err := rows.Scan(
+			&i.JobID,
+			&i.OrderID,
+			&i.Station,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.ClaimedAt,
+			&i.PrintedAt,
+			&i.ReprintOf,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFood = `-- name: GetFood :one
+SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed, station FROM food WHERE food_name = ?
+`
+
+func (q *Queries) GetFood(ctx context.Context, foodName string) (Food, error) {
+	row := q.db.QueryRowContext(ctx, getFood, foodName)
+	var i Food
+	err := row.Scan(
+		&i.FoodID,
+		&i.FoodName,
+		&i.Price,
+		&i.Picture,
+		&i.LongRange,
+		&i.Description,
+		&i.Info,
+		&i.Ingredients,
+		&i.TimeNeeded,
+		&i.Station,
+	)
+	return i, err
+}
+
+const getFoodById = `-- name: GetFoodById :one
+SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed, station FROM food WHERE food_id = ?
+`
+
+func (q *Queries) GetFoodById(ctx context.Context, foodID int32) (Food, error) {
+	row := q.db.QueryRowContext(ctx, getFoodById, foodID)
+	var i Food
+	err := row.Scan(
+		&i.FoodID,
+		&i.FoodName,
+		&i.Price,
+		&i.Picture,
+		&i.LongRange,
+		&i.Description,
+		&i.Info,
+		&i.Ingredients,
+		&i.TimeNeeded,
+		&i.Station,
+	)
+	return i, err
+}
+
+const getFoodByTag = `-- name: GetFoodByTag :many
+SELECT DISTINCT food.food_id, food.food_name, food.price, food.picture, food.long_range, food.description, food.info, food.ingredients, food.time_needed, food.station
+FROM food
+JOIN tags ON food.food_name = tags.food_name
+WHERE tags.tag = ?
+`
+
+func (q *Queries) GetFoodByTag(ctx context.Context, tag string) ([]Food, error) {
+	rows, err := q.db.QueryContext(ctx, getFoodByTag, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Food
+	for rows.Next() {
+		var i Food
+		if err := rows.Scan(
+			&i.FoodID,
+			&i.FoodName,
+			&i.Price,
+			&i.Picture,
+			&i.LongRange,
+			&i.Description,
+			&i.Info,
+			&i.Ingredients,
+			&i.TimeNeeded,
+			&i.Station,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFoodTagByFoodName = `-- name: GetFoodTagByFoodName :many
+SELECT tag
+FROM tags
+WHERE food_name = ?
+`
+
+func (q *Queries) GetFoodTagByFoodName(ctx context.Context, foodName string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getFoodTagByFoodName, foodName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftCard = `-- name: GetGiftCard :one
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards WHERE card_id = ?
+`
+
+func (q *Queries) GetGiftCard(ctx context.Context, cardID int32) (GiftCard, error) {
+	row := q.db.QueryRowContext(ctx, getGiftCard, cardID)
+	var i GiftCard
+	err := row.Scan(
+		&i.CardID,
+		&i.Code,
+		&i.InitialAmount,
+		&i.Balance,
+		&i.Status,
+		&i.Message,
+		&i.IssuedBy,
+		&i.PurchasedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGiftCardByCode = `-- name: GetGiftCardByCode :one
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards WHERE code = ?
+`
+
+func (q *Queries) GetGiftCardByCode(ctx context.Context, code string) (GiftCard, error) {
+	row := q.db.QueryRowContext(ctx, getGiftCardByCode, code)
+	var i GiftCard
+	err := row.Scan(
+		&i.CardID,
+		&i.Code,
+		&i.InitialAmount,
+		&i.Balance,
+		&i.Status,
+		&i.Message,
+		&i.IssuedBy,
+		&i.PurchasedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGiftCardByCodeForUpdate = `-- name: GetGiftCardByCodeForUpdate :one
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards WHERE code = ? FOR UPDATE
+`
+
+func (q *Queries) GetGiftCardByCodeForUpdate(ctx context.Context, code string) (GiftCard, error) {
+	row := q.db.QueryRowContext(ctx, getGiftCardByCodeForUpdate, code)
+	var i GiftCard
+	err := row.Scan(
+		&i.CardID,
+		&i.Code,
+		&i.InitialAmount,
+		&i.Balance,
+		&i.Status,
+		&i.Message,
+		&i.IssuedBy,
+		&i.PurchasedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGiftCardForUpdate = `-- name: GetGiftCardForUpdate :one
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards WHERE card_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetGiftCardForUpdate(ctx context.Context, cardID int32) (GiftCard, error) {
+	row := q.db.QueryRowContext(ctx, getGiftCardForUpdate, cardID)
+	var i GiftCard
+	err := row.Scan(
+		&i.CardID,
+		&i.Code,
+		&i.InitialAmount,
+		&i.Balance,
+		&i.Status,
+		&i.Message,
+		&i.IssuedBy,
+		&i.PurchasedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGiftCardTransactions = `-- name: GetGiftCardTransactions :many
+SELECT transaction_id, card_id, account_id, kind, amount, balance_after, intent_id, note, created_at FROM gift_card_transactions WHERE card_id = ? ORDER BY transaction_id
+`
+
+func (q *Queries) GetGiftCardTransactions(ctx context.Context, cardID int32) ([]GiftCardTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, getGiftCardTransactions, cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftCardTransaction
+	for rows.Next() {
+		var i GiftCardTransaction
+		if err := rows.Scan(
+			&i.TransactionID,
+			&i.CardID,
+			&i.AccountID,
+			&i.Kind,
+			&i.Amount,
+			&i.BalanceAfter,
+			&i.IntentID,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftCardsByPurchaser = `-- name: GetGiftCardsByPurchaser :many
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards WHERE purchased_by = ? ORDER BY card_id DESC
+`
+
+func (q *Queries) GetGiftCardsByPurchaser(ctx context.Context, purchasedBy sql.NullInt32) ([]GiftCard, error) {
+	rows, err := q.db.QueryContext(ctx, getGiftCardsByPurchaser, purchasedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftCard
+	for rows.Next() {
+		var i GiftCard
+		if err := rows.Scan(
+			&i.CardID,
+			&i.Code,
+			&i.InitialAmount,
+			&i.Balance,
+			&i.Status,
+			&i.Message,
+			&i.IssuedBy,
+			&i.PurchasedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHeldReservations = `-- name: GetHeldReservations :many
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations
+WHERE status IN ('pending', 'booked')
+    AND starts_at < ? AND ends_at > ?
+    AND reservation_id <> ?
+`
+
+type GetHeldReservationsParams struct {
+	PeriodEnd   time.Time
+	PeriodStart time.Time
+	ExcludeID   int32
+}
+
+func (q *Queries) GetHeldReservations(ctx context.Context, arg GetHeldReservationsParams) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, getHeldReservations, arg.PeriodEnd, arg.PeriodStart, arg.ExcludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ReservationID,
+			&i.UserID,
+			&i.TableNumber,
+			&i.PartySize,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Status,
+			&i.Note,
+			&i.Deposit,
+			&i.DepositStatus,
+			&i.OrderID,
+			&i.SeatedAt,
+			&i.CancelledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, idem_key, request_hash, status, response_code, content_type, response_body, created_at, expires_at FROM idempotency_keys
+WHERE user_id = ? AND idem_key = ?
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID  int32
+	IdemKey string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.IdemKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.IdemKey,
+		&i.RequestHash,
+		&i.Status,
+		&i.ResponseCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getIdleRidersForUpdate = `-- name: GetIdleRidersForUpdate :many
+SELECT user_id, status, status_at, lat, lng, located_at, is_active, created_at FROM riders
+WHERE is_active = true AND status = 'available'
+    AND NOT EXISTS (
+        SELECT 1 FROM deliveries
+        WHERE deliveries.rider_id = riders.user_id AND deliveries.status IN ('assigned', 'picked_up')
+    )
+FOR UPDATE
+`
+
+func (q *Queries) GetIdleRidersForUpdate(ctx context.Context) ([]Rider, error) {
+	rows, err := q.db.QueryContext(ctx, getIdleRidersForUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Rider
+	for rows.Next() {
+		var i Rider
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Status,
+			&i.StatusAt,
+			&i.Lat,
+			&i.Lng,
+			&i.LocatedAt,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getItemsByUser = `-- name: GetItemsByUser :many
+SELECT items.item_id, items.order_id, items.food_id, items.quantity, items.rating, items.food_name, items.unit_price, items.modifiers FROM items
+JOIN orders ON items.order_id = orders.order_id
+WHERE orders.user_id = ?
+ORDER BY items.order_id, items.item_id
+`
+
+func (q *Queries) GetItemsByUser(ctx context.Context, userID int32) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, getItemsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Item
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.OrderID,
+			&i.FoodID,
+			&i.Quantity,
+			&i.Rating,
+			&i.FoodName,
+			&i.UnitPrice,
+			&i.Modifiers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getKitchenBacklog = `-- name: GetKitchenBacklog :one
+SELECT
+    CAST(COALESCE(SUM(items.quantity), 0) AS SIGNED) AS items,
+    CAST(COALESCE(SUM(items.quantity * food.time_needed), 0) AS SIGNED) AS prep_minutes
+FROM orders
+JOIN items ON items.order_id = orders.order_id
+JOIN food ON food.food_id = items.food_id
+WHERE orders.is_done = false AND orders.deleted = false
+    AND (orders.scheduled_for IS NULL OR orders.released_at IS NOT NULL)
+`
+
+type GetKitchenBacklogRow struct {
+	Items       int64
+	PrepMinutes int64
+}
+
+func (q *Queries) GetKitchenBacklog(ctx context.Context) (GetKitchenBacklogRow, error) {
+	row := q.db.QueryRowContext(ctx, getKitchenBacklog)
+	var i GetKitchenBacklogRow
+	err := row.Scan(&i.Items, &i.PrepMinutes)
+	return i, err
+}
+
+const getKitchenSettings = `-- name: GetKitchenSettings :one
+SELECT settings_id, window_minutes, max_items, max_prep_minutes, pushback_after_minutes, pause_after_minutes, takeaway_paused, delivery_paused, resume_at, updated_by, updated_at FROM kitchen_settings WHERE settings_id = 1
+`
+
+func (q *Queries) GetKitchenSettings(ctx context.Context) (KitchenSetting, error) {
+	row := q.db.QueryRowContext(ctx, getKitchenSettings)
+	var i KitchenSetting
+	err := row.Scan(
+		&i.SettingsID,
+		&i.WindowMinutes,
+		&i.MaxItems,
+		&i.MaxPrepMinutes,
+		&i.PushbackAfterMinutes,
+		&i.PauseAfterMinutes,
+		&i.TakeawayPaused,
+		&i.DeliveryPaused,
+		&i.ResumeAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedAddress = `-- name: GetLastInsertedAddress :one
+SELECT address_id, user_id, label, recipient, phone, line1, line2, city, postcode, lat, lng, is_default, created_at, updated_at FROM addresses
+WHERE address_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedAddress(ctx context.Context) (Address, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedAddress)
+	var i Address
+	err := row.Scan(
+		&i.AddressID,
+		&i.UserID,
+		&i.Label,
+		&i.Recipient,
+		&i.Phone,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Postcode,
+		&i.Lat,
+		&i.Lng,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedBillShare = `-- name: GetLastInsertedBillShare :one
+SELECT share_id, order_id, label, amount, is_paid, intent_id, paid_by, paid_at, created_at FROM bill_shares
+WHERE share_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedBillShare(ctx context.Context) (BillShare, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedBillShare)
+	var i BillShare
+	err := row.Scan(
+		&i.ShareID,
+		&i.OrderID,
+		&i.Label,
+		&i.Amount,
+		&i.IsPaid,
+		&i.IntentID,
+		&i.PaidBy,
+		&i.PaidAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedDelivery = `-- name: GetLastInsertedDelivery :one
+SELECT delivery_id, order_id, rider_id, status, assigned_by, assigned_at, picked_up_at, delivered_at, failed_at, failure_reason, proof_note, proof_photo FROM deliveries
+WHERE delivery_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedDelivery(ctx context.Context) (Delivery, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedDelivery)
+	var i Delivery
+	err := row.Scan(
+		&i.DeliveryID,
+		&i.OrderID,
+		&i.RiderID,
+		&i.Status,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.PickedUpAt,
+		&i.DeliveredAt,
+		&i.FailedAt,
+		&i.FailureReason,
+		&i.ProofNote,
+		&i.ProofPhoto,
+	)
+	return i, err
+}
+
+const getLastInsertedGiftCard = `-- name: GetLastInsertedGiftCard :one
+SELECT card_id, code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at, created_at FROM gift_cards
+WHERE card_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedGiftCard(ctx context.Context) (GiftCard, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedGiftCard)
+	var i GiftCard
+	err := row.Scan(
+		&i.CardID,
+		&i.Code,
+		&i.InitialAmount,
+		&i.Balance,
+		&i.Status,
+		&i.Message,
+		&i.IssuedBy,
+		&i.PurchasedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedOrder = `-- name: GetLastInsertedOrder :one
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE order_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedOrder(ctx context.Context) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedOrder)
+	var i Order
+	err := row.Scan(
+		&i.OrderID,
+		&i.UserID,
+		&i.OrderInfo,
+		&i.Feedback,
+		&i.OrderTime,
+		&i.EstimatedTime,
+		&i.IsDone,
+		&i.IsRanged,
+		&i.DeliveryAddress,
+		&i.Deleted,
+		&i.IsPaid,
+		&i.OrderType,
+		&i.TableNumber,
+		&i.FinishedBy,
+		&i.FinishedAt,
+		&i.InvoiceBillingName,
+		&i.InvoiceTaxID,
+		&i.ZoneID,
+		&i.DeliveryLat,
+		&i.DeliveryLng,
+		&i.DeliveryDistanceKm,
+		&i.DeliveryFee,
+		&i.AddressID,
+		&i.DeliveryRecipient,
+		&i.DeliveryPhone,
+		&i.ScheduledFor,
+		&i.ReleaseAt,
+		&i.ReleasedAt,
+		&i.RemindedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedPaymentIntent = `-- name: GetLastInsertedPaymentIntent :one
+SELECT intent_id, user_id, order_id, purpose, provider, provider_ref, amount, status, created_at, updated_at, share_id, gift_card_id, reservation_id FROM payment_intents
+WHERE intent_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedPaymentIntent(ctx context.Context) (PaymentIntent, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedPaymentIntent)
+	var i PaymentIntent
+	err := row.Scan(
+		&i.IntentID,
+		&i.UserID,
+		&i.OrderID,
+		&i.Purpose,
+		&i.Provider,
+		&i.ProviderRef,
+		&i.Amount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShareID,
+		&i.GiftCardID,
+		&i.ReservationID,
+	)
+	return i, err
+}
+
+const getLastInsertedPrintJobID = `-- name: GetLastInsertedPrintJobID :one
+SELECT job_id FROM print_jobs
+WHERE job_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedPrintJobID(ctx context.Context) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedPrintJobID)
+	var job_id int32
+	err := row.Scan(&job_id)
+	return job_id, err
+}
+
+const getLastInsertedReservation = `-- name: GetLastInsertedReservation :one
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations
+WHERE reservation_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedReservation(ctx context.Context) (Reservation, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedReservation)
+	var i Reservation
+	err := row.Scan(
+		&i.ReservationID,
+		&i.UserID,
+		&i.TableNumber,
+		&i.PartySize,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Status,
+		&i.Note,
+		&i.Deposit,
+		&i.DepositStatus,
+		&i.OrderID,
+		&i.SeatedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastInsertedWaitlistEntry = `-- name: GetLastInsertedWaitlistEntry :one
+SELECT entry_id, user_id, name, phone, party_size, status, table_number, notified_at, seated_at, created_at FROM waitlist
+WHERE entry_id = LAST_INSERT_ID()
+`
+
+func (q *Queries) GetLastInsertedWaitlistEntry(ctx context.Context) (Waitlist, error) {
+	row := q.db.QueryRowContext(ctx, getLastInsertedWaitlistEntry)
+	var i Waitlist
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.Name,
+		&i.Phone,
+		&i.PartySize,
+		&i.Status,
+		&i.TableNumber,
+		&i.NotifiedAt,
+		&i.SeatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestDeliveryByOrder = `-- name: GetLatestDeliveryByOrder :one
+SELECT delivery_id, order_id, rider_id, status, assigned_by, assigned_at, picked_up_at, delivered_at, failed_at, failure_reason, proof_note, proof_photo FROM deliveries WHERE order_id = ? ORDER BY delivery_id DESC LIMIT 1
+`
+
+func (q *Queries) GetLatestDeliveryByOrder(ctx context.Context, orderID int32) (Delivery, error) {
+	row := q.db.QueryRowContext(ctx, getLatestDeliveryByOrder, orderID)
+	var i Delivery
+	err := row.Scan(
+		&i.DeliveryID,
+		&i.OrderID,
+		&i.RiderID,
+		&i.Status,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.PickedUpAt,
+		&i.DeliveredAt,
+		&i.FailedAt,
+		&i.FailureReason,
+		&i.ProofNote,
+		&i.ProofPhoto,
+	)
+	return i, err
+}
+
+const getLatestVerification = `-- name: GetLatestVerification :one
+SELECT verification_id, user_id, channel, target, code_hash, attempts, created_at, expires_at, used_at FROM verifications
+WHERE user_id = ? AND channel = ?
+ORDER BY created_at DESC, verification_id DESC
+LIMIT 1
+`
+
+type GetLatestVerificationParams struct {
+	UserID  int32
+	Channel string
+}
+
+func (q *Queries) GetLatestVerification(ctx context.Context, arg GetLatestVerificationParams) (Verification, error) {
+	row := q.db.QueryRowContext(ctx, getLatestVerification, arg.UserID, arg.Channel)
+	var i Verification
+	err := row.Scan(
+		&i.VerificationID,
+		&i.UserID,
+		&i.Channel,
+		&i.Target,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getLatestVerificationForUpdate = `-- name: GetLatestVerificationForUpdate :one
+SELECT verification_id, user_id, channel, target, code_hash, attempts, created_at, expires_at, used_at FROM verifications
+WHERE user_id = ? AND channel = ?
+ORDER BY created_at DESC, verification_id DESC
+LIMIT 1
+FOR UPDATE
+`
+
+type GetLatestVerificationForUpdateParams struct {
+	UserID  int32
+	Channel string
+}
+
+func (q *Queries) GetLatestVerificationForUpdate(ctx context.Context, arg GetLatestVerificationForUpdateParams) (Verification, error) {
+	row := q.db.QueryRowContext(ctx, getLatestVerificationForUpdate, arg.UserID, arg.Channel)
+	var i Verification
+	err := row.Scan(
+		&i.VerificationID,
+		&i.UserID,
+		&i.Channel,
+		&i.Target,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getLoginAttemptsByIP = `-- name: GetLoginAttemptsByIP :many
+SELECT attempt_id, user_id, username, ip, user_agent, success, reason, created_at FROM login_attempts
+WHERE ip = ?
+ORDER BY created_at DESC, attempt_id DESC
+LIMIT ?
+`
+
+type GetLoginAttemptsByIPParams struct {
+	Ip    string
+	Limit int32
+}
+
+func (q *Queries) GetLoginAttemptsByIP(ctx context.Context, arg GetLoginAttemptsByIPParams) ([]LoginAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, getLoginAttemptsByIP, arg.Ip, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginAttempt
+	for rows.Next() {
+		var i LoginAttempt
+		if err := rows.Scan(
+			&i.AttemptID,
+			&i.UserID,
+			&i.Username,
+			&i.Ip,
+			&i.UserAgent,
+			&i.Success,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoginAttemptsByUser = `-- name: GetLoginAttemptsByUser :many
+SELECT attempt_id, user_id, username, ip, user_agent, success, reason, created_at FROM login_attempts
+WHERE user_id = ?
+ORDER BY created_at DESC, attempt_id DESC
+LIMIT ?
+`
+
+type GetLoginAttemptsByUserParams struct {
+	UserID sql.NullInt32
+	Limit  int32
+}
+
+func (q *Queries) GetLoginAttemptsByUser(ctx context.Context, arg GetLoginAttemptsByUserParams) ([]LoginAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, getLoginAttemptsByUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginAttempt
+	for rows.Next() {
+		var i LoginAttempt
+		if err := rows.Scan(
+			&i.AttemptID,
+			&i.UserID,
+			&i.Username,
+			&i.Ip,
+			&i.UserAgent,
+			&i.Success,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoginChallengeForUpdate = `-- name: GetLoginChallengeForUpdate :one
+SELECT challenge_id, user_id, token_hash, expires_in_seconds, attempts, ip, created_at, expires_at, used_at FROM login_challenges
+WHERE token_hash = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetLoginChallengeForUpdate(ctx context.Context, tokenHash string) (LoginChallenge, error) {
+	row := q.db.QueryRowContext(ctx, getLoginChallengeForUpdate, tokenHash)
+	var i LoginChallenge
+	err := row.Scan(
+		&i.ChallengeID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresInSeconds,
+		&i.Attempts,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getLoginThrottle = `-- name: GetLoginThrottle :one
+SELECT scope, subject, failures, last_failure_at, blocked_until FROM login_throttles
+WHERE scope = ? AND subject = ?
+`
+
+type GetLoginThrottleParams struct {
+	Scope   string
+	Subject string
+}
+
+func (q *Queries) GetLoginThrottle(ctx context.Context, arg GetLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRowContext(ctx, getLoginThrottle, arg.Scope, arg.Subject)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Scope,
+		&i.Subject,
+		&i.Failures,
+		&i.LastFailureAt,
+		&i.BlockedUntil,
+	)
+	return i, err
+}
+
+const getLongestTimeNeededFoodInOrder = `-- name: GetLongestTimeNeededFoodInOrder :one
+SELECT MAX(food.time_needed) AS longest_time_needed
+FROM food
+JOIN items ON food.food_id = items.food_id
+WHERE items.order_id = ?
+`
+
+func (q *Queries) GetLongestTimeNeededFoodInOrder(ctx context.Context, orderID int32) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getLongestTimeNeededFoodInOrder, orderID)
+	var longest_time_needed interface{}
+	err := row.Scan(&longest_time_needed)
+	return longest_time_needed, err
+}
+
+const getMostOrderedFood = `-- name: GetMostOrderedFood :many
+SELECT items.food_name, COUNT(items.food_id) AS order_count
+FROM items
+GROUP BY items.food_name
+ORDER BY order_count DESC
+`
+
+type GetMostOrderedFoodRow struct {
+	FoodName   string
+	OrderCount int64
+}
+
+func (q *Queries) GetMostOrderedFood(ctx context.Context) ([]GetMostOrderedFoodRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMostOrderedFood)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMostOrderedFoodRow
+	for rows.Next() {
+		var i GetMostOrderedFoodRow
+		if err := rows.Scan(&i.FoodName, &i.OrderCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMostOrderedTag = `-- name: GetMostOrderedTag :one
+SELECT tag, COUNT(*) AS count
+FROM tags
+JOIN items ON tags.food_name = items.food_name
+JOIN orders ON items.order_id = orders.order_id
+WHERE orders.deleted = false
+GROUP BY tag
+ORDER BY count DESC
+LIMIT 1
+`
+
+type GetMostOrderedTagRow struct {
+	Tag   string
+	Count int64
+}
+
+func (q *Queries) GetMostOrderedTag(ctx context.Context) (GetMostOrderedTagRow, error) {
+	row := q.db.QueryRowContext(ctx, getMostOrderedTag)
+	var i GetMostOrderedTagRow
+	err := row.Scan(&i.Tag, &i.Count)
+	return i, err
+}
+
+const getNotificationsByUser = `-- name: GetNotificationsByUser :many
+SELECT notification_id, user_id, order_id, kind, message, read_at, created_at FROM notifications
+WHERE user_id = ?
+ORDER BY notification_id DESC
+LIMIT ?
+`
+
+type GetNotificationsByUserParams struct {
+	UserID int32
+	Limit  int32
+}
+
+func (q *Queries) GetNotificationsByUser(ctx context.Context, arg GetNotificationsByUserParams) ([]Notification, error) {
+	rows, err := q.db.QueryContext(ctx, getNotificationsByUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Notification
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.NotificationID,
+			&i.UserID,
+			&i.OrderID,
+			&i.Kind,
+			&i.Message,
+			&i.ReadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOccupiedTables = `-- name: GetOccupiedTables :many
+SELECT table_number FROM orders
+WHERE order_type = 'dine_in' AND deleted = false AND is_paid = false AND table_number IS NOT NULL
+UNION
+SELECT table_number FROM reservations
+WHERE status = 'seated' AND order_id IS NULL AND seated_at >= ?
+`
+
+func (q *Queries) GetOccupiedTables(ctx context.Context, seatedSince sql.NullTime) ([]sql.NullInt32, error) {
+	rows, err := q.db.QueryContext(ctx, getOccupiedTables, seatedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullInt32
+	for rows.Next() {
+		var table_number sql.NullInt32
+		if err := rows.Scan(&table_number); err != nil {
+			return nil, err
+		}
+		items = append(items, table_number)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOpeningHours = `-- name: GetOpeningHours :many
+SELECT hours_id, order_type, weekday, opens_at, closes_at, created_at FROM opening_hours ORDER BY order_type, weekday, opens_at
+`
+
+func (q *Queries) GetOpeningHours(ctx context.Context) ([]OpeningHour, error) {
+	rows, err := q.db.QueryContext(ctx, getOpeningHours)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OpeningHour
+	for rows.Next() {
+		var i OpeningHour
+		if err := rows.Scan(
+			&i.HoursID,
+			&i.OrderType,
+			&i.Weekday,
+			&i.OpensAt,
+			&i.ClosesAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrder = `-- name: GetOrder :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE user_id = ?
+`
+
+func (q *Queries) GetOrder(ctx context.Context, userID int32) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getOrder, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderById = `-- name: GetOrderById :one
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE order_id = ?
+`
+
+func (q *Queries) GetOrderById(ctx context.Context, orderID int32) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderById, orderID)
+	var i Order
+	err := row.Scan(
+		&i.OrderID,
+		&i.UserID,
+		&i.OrderInfo,
+		&i.Feedback,
+		&i.OrderTime,
+		&i.EstimatedTime,
+		&i.IsDone,
+		&i.IsRanged,
+		&i.DeliveryAddress,
+		&i.Deleted,
+		&i.IsPaid,
+		&i.OrderType,
+		&i.TableNumber,
+		&i.FinishedBy,
+		&i.FinishedAt,
+		&i.InvoiceBillingName,
+		&i.InvoiceTaxID,
+		&i.ZoneID,
+		&i.DeliveryLat,
+		&i.DeliveryLng,
+		&i.DeliveryDistanceKm,
+		&i.DeliveryFee,
+		&i.AddressID,
+		&i.DeliveryRecipient,
+		&i.DeliveryPhone,
+		&i.ScheduledFor,
+		&i.ReleaseAt,
+		&i.ReleasedAt,
+		&i.RemindedAt,
+	)
+	return i, err
+}
+
+const getOrderByIdForUpdate = `-- name: GetOrderByIdForUpdate :one
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders WHERE order_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetOrderByIdForUpdate(ctx context.Context, orderID int32) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderByIdForUpdate, orderID)
+	var i Order
+	err := row.Scan(
+		&i.OrderID,
+		&i.UserID,
+		&i.OrderInfo,
+		&i.Feedback,
+		&i.OrderTime,
+		&i.EstimatedTime,
+		&i.IsDone,
+		&i.IsRanged,
+		&i.DeliveryAddress,
+		&i.Deleted,
+		&i.IsPaid,
+		&i.OrderType,
+		&i.TableNumber,
+		&i.FinishedBy,
+		&i.FinishedAt,
+		&i.InvoiceBillingName,
+		&i.InvoiceTaxID,
+		&i.ZoneID,
+		&i.DeliveryLat,
+		&i.DeliveryLng,
+		&i.DeliveryDistanceKm,
+		&i.DeliveryFee,
+		&i.AddressID,
+		&i.DeliveryRecipient,
+		&i.DeliveryPhone,
+		&i.ScheduledFor,
+		&i.ReleaseAt,
+		&i.ReleasedAt,
+		&i.RemindedAt,
+	)
+	return i, err
+}
+
+const getOrderSlots = `-- name: GetOrderSlots :many
+SELECT slot_start, booked FROM order_slots
+WHERE slot_start >= ? AND slot_start < ?
+ORDER BY slot_start
+`
+
+type GetOrderSlotsParams struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (q *Queries) GetOrderSlots(ctx context.Context, arg GetOrderSlotsParams) ([]OrderSlot, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderSlots, arg.PeriodStart, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderSlot
+	for rows.Next() {
+		var i OrderSlot
+		if err := rows.Scan(&i.SlotStart, &i.Booked); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderTotalPrice = `-- name: GetOrderTotalPrice :one
+SELECT SUM(items.unit_price * items.quantity) AS total_price
+FROM orders
+JOIN items ON orders.order_id = items.order_id
+WHERE orders.order_id = ?
+`
+
+func (q *Queries) GetOrderTotalPrice(ctx context.Context, orderID int32) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getOrderTotalPrice, orderID)
+	var total_price interface{}
+	err := row.Scan(&total_price)
+	return total_price, err
+}
+
+const getOrderedCountByFood = `-- name: GetOrderedCountByFood :one
+SELECT COUNT(*) AS ordered_count
+FROM items
+WHERE food_id = ?
+`
+
+func (q *Queries) GetOrderedCountByFood(ctx context.Context, foodID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getOrderedCountByFood, foodID)
+	var ordered_count int64
+	err := row.Scan(&ordered_count)
+	return ordered_count, err
+}
+
+const getOrderedItems = `-- name: GetOrderedItems :many
+SELECT item_id, order_id, food_id, quantity, rating, food_name, unit_price, modifiers FROM items WHERE order_id = ?
+`
+
+func (q *Queries) GetOrderedItems(ctx context.Context, orderID int32) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderedItems, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Item
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.OrderID,
+			&i.FoodID,
+			&i.Quantity,
+			&i.Rating,
+			&i.FoodName,
+			&i.UnitPrice,
+			&i.Modifiers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersByUserForExport = `-- name: GetOrdersByUserForExport :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE user_id = ?
+ORDER BY order_id
+`
+
+func (q *Queries) GetOrdersByUserForExport(ctx context.Context, userID int32) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getOrdersByUserForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersFinishedByStaff = `-- name: GetOrdersFinishedByStaff :many
+SELECT accounts.id, accounts.username, COUNT(*) AS orders_finished
+FROM orders
+JOIN accounts ON orders.finished_by = accounts.id
+WHERE orders.finished_at >= ? AND orders.finished_at < ?
+GROUP BY accounts.id, accounts.username
+ORDER BY accounts.id
+`
+
+type GetOrdersFinishedByStaffParams struct {
+	PeriodStart sql.NullTime
+	PeriodEnd   sql.NullTime
+}
+
+type GetOrdersFinishedByStaffRow struct {
+	ID             int32
+	Username       string
+	OrdersFinished int64
+}
+
+func (q *Queries) GetOrdersFinishedByStaff(ctx context.Context, arg GetOrdersFinishedByStaffParams) ([]GetOrdersFinishedByStaffRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrdersFinishedByStaff, arg.PeriodStart, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrdersFinishedByStaffRow
+	for rows.Next() {
+		var i GetOrdersFinishedByStaffRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.OrdersFinished); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersToRelease = `-- name: GetOrdersToRelease :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE release_at <= ? AND released_at IS NULL AND deleted = false
+ORDER BY release_at, order_id
+`
+
+func (q *Queries) GetOrdersToRelease(ctx context.Context, releaseAt sql.NullTime) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getOrdersToRelease, releaseAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersToRemind = `-- name: GetOrdersToRemind :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE scheduled_for > ? AND scheduled_for <= ?
+    AND reminded_at IS NULL AND deleted = false
+ORDER BY scheduled_for, order_id
+`
+
+type GetOrdersToRemindParams struct {
+	Now          sql.NullTime
+	RemindBefore sql.NullTime
+}
+
+func (q *Queries) GetOrdersToRemind(ctx context.Context, arg GetOrdersToRemindParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getOrdersToRemind, arg.Now, arg.RemindBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
+			&i.OrderTime,
+			&i.EstimatedTime,
+			&i.IsDone,
+			&i.IsRanged,
+			&i.DeliveryAddress,
+			&i.Deleted,
+			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaidTipsInPeriod = `-- name: GetPaidTipsInPeriod :many
+SELECT tip_id, order_id, user_id, intent_id, kind, rate, amount, pool, recipient_id, status, created_at FROM tips
+WHERE status = 'paid' AND created_at >= ? AND created_at < ?
+ORDER BY tip_id
+`
+
+type GetPaidTipsInPeriodParams struct {
+	PeriodStart sql.NullTime
+	PeriodEnd   sql.NullTime
+}
+
+func (q *Queries) GetPaidTipsInPeriod(ctx context.Context, arg GetPaidTipsInPeriodParams) ([]Tip, error) {
+	rows, err := q.db.QueryContext(ctx, getPaidTipsInPeriod, arg.PeriodStart, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tip
+	for rows.Next() {
+		var i Tip
+		if err := rows.Scan(
+			&i.TipID,
+			&i.OrderID,
+			&i.UserID,
+			&i.IntentID,
+			&i.Kind,
+			&i.Rate,
+			&i.Amount,
+			&i.Pool,
+			&i.RecipientID,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPasswordResetForUpdate = `-- name: GetPasswordResetForUpdate :one
+SELECT reset_id, user_id, token_hash, ip, created_at, expires_at, used_at FROM password_resets
+WHERE token_hash = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetPasswordResetForUpdate(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetForUpdate, tokenHash)
+	var i PasswordReset
+	err := row.Scan(
+		&i.ResetID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getPaymentEvents = `-- name: GetPaymentEvents :many
+SELECT event_id, intent_id, event_type, status, amount, detail, created_at FROM payment_events WHERE intent_id = ? ORDER BY event_id
+`
+
+func (q *Queries) GetPaymentEvents(ctx context.Context, intentID int32) ([]PaymentEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentEvents, intentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PaymentEvent
+	for rows.Next() {
+		var i PaymentEvent
+		if err := rows.Scan(
+			&i.EventID,
+			&i.IntentID,
+			&i.EventType,
+			&i.Status,
+			&i.Amount,
+			&i.Detail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentIntent = `-- name: GetPaymentIntent :one
+SELECT intent_id, user_id, order_id, purpose, provider, provider_ref, amount, status, created_at, updated_at, share_id, gift_card_id, reservation_id FROM payment_intents WHERE intent_id = ?
+`
+
+func (q *Queries) GetPaymentIntent(ctx context.Context, intentID int32) (PaymentIntent, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentIntent, intentID)
+	var i PaymentIntent
+	err := row.Scan(
+		&i.IntentID,
+		&i.UserID,
+		&i.OrderID,
+		&i.Purpose,
+		&i.Provider,
+		&i.ProviderRef,
+		&i.Amount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShareID,
+		&i.GiftCardID,
+		&i.ReservationID,
+	)
+	return i, err
+}
+
+const getPaymentIntentForUpdate = `-- name: GetPaymentIntentForUpdate :one
+SELECT intent_id, user_id, order_id, purpose, provider, provider_ref, amount, status, created_at, updated_at, share_id, gift_card_id, reservation_id FROM payment_intents WHERE intent_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetPaymentIntentForUpdate(ctx context.Context, intentID int32) (PaymentIntent, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentIntentForUpdate, intentID)
+	var i PaymentIntent
+	err := row.Scan(
+		&i.IntentID,
+		&i.UserID,
+		&i.OrderID,
+		&i.Purpose,
+		&i.Provider,
+		&i.ProviderRef,
+		&i.Amount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShareID,
+		&i.GiftCardID,
+		&i.ReservationID,
+	)
+	return i, err
+}
+
+const getPaymentIntentsByOrder = `-- name: GetPaymentIntentsByOrder :many
+SELECT intent_id, user_id, order_id, purpose, provider, provider_ref, amount, status, created_at, updated_at, share_id, gift_card_id, reservation_id FROM payment_intents WHERE order_id = ? ORDER BY created_at
+`
+
+func (q *Queries) GetPaymentIntentsByOrder(ctx context.Context, orderID sql.NullInt32) ([]PaymentIntent, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentIntentsByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PaymentIntent
+	for rows.Next() {
+		var i PaymentIntent
+		if err := rows.Scan(
+			&i.IntentID,
+			&i.UserID,
+			&i.OrderID,
+			&i.Purpose,
+			&i.Provider,
+			&i.ProviderRef,
+			&i.Amount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ShareID,
+			&i.GiftCardID,
+			&i.ReservationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingDeletionRequest = `-- name: GetPendingDeletionRequest :one
+SELECT request_id, user_id, ip, requested_at, scheduled_for, cancelled_at, completed_at FROM deletion_requests
+WHERE user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL
+ORDER BY request_id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetPendingDeletionRequest(ctx context.Context, userID int32) (DeletionRequest, error) {
+	row := q.db.QueryRowContext(ctx, getPendingDeletionRequest, userID)
+	var i DeletionRequest
+	err := row.Scan(
+		&i.RequestID,
+		&i.UserID,
+		&i.Ip,
+		&i.RequestedAt,
+		&i.ScheduledFor,
+		&i.CancelledAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getPrintJob = `-- name: GetPrintJob :one
+SELECT job_id, order_id, station, kind, payload, status, attempts, last_error, next_attempt_at, claimed_at, printed_at, reprint_of, created_at FROM print_jobs WHERE job_id = ?
+`
+
+func (q *Queries) GetPrintJob(ctx context.Context, jobID int32) (PrintJob, error) {
+	row := q.db.QueryRowContext(ctx, getPrintJob, jobID)
+	var i PrintJob
+	err := row.Scan(
+		&i.JobID,
+		&i.OrderID,
+		&i.Station,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.ClaimedAt,
+		&i.PrintedAt,
+		&i.ReprintOf,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPrintJobs = `-- name: GetPrintJobs :many
+SELECT job_id, order_id, station, kind, status, attempts, last_error, next_attempt_at, printed_at, reprint_of, created_at
+FROM print_jobs
+WHERE ? = '' OR status = ?
+ORDER BY job_id DESC
+LIMIT ?
+`
+
+type GetPrintJobsParams struct {
+	Status string
+	Limit  int32
+}
+
+type GetPrintJobsRow struct {
+	JobID         int32
+	OrderID       sql.NullInt32
+	Station       string
+	Kind          string
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	PrintedAt     sql.NullTime
+	ReprintOf     sql.NullInt32
+	CreatedAt     sql.NullTime
+}
+
+func (q *Queries) GetPrintJobs(ctx context.Context, arg GetPrintJobsParams) ([]GetPrintJobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrintJobs, arg.Status, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrintJobsRow
+	for rows.Next() {
+		var i GetPrintJobsRow
+		if err := rows.Scan(
+			&i.JobID,
+			&i.OrderID,
+			&i.Station,
+			&i.Kind,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.PrintedAt,
+			&i.ReprintOf,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPrinters = `-- name: GetPrinters :many
+SELECT printer_id, name, station, address, is_active, created_at FROM printers ORDER BY station
+`
+
+func (q *Queries) GetPrinters(ctx context.Context) ([]Printer, error) {
+	rows, err := q.db.QueryContext(ctx, getPrinters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Printer
+	for rows.Next() {
+		var i Printer
+		if err := rows.Scan(
+			&i.PrinterID,
+			&i.Name,
+			&i.Station,
+			&i.Address,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReservation = `-- name: GetReservation :one
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations WHERE reservation_id = ?
+`
+
+func (q *Queries) GetReservation(ctx context.Context, reservationID int32) (Reservation, error) {
+	row := q.db.QueryRowContext(ctx, getReservation, reservationID)
+	var i Reservation
+	err := row.Scan(
+		&i.ReservationID,
+		&i.UserID,
+		&i.TableNumber,
+		&i.PartySize,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Status,
+		&i.Note,
+		&i.Deposit,
+		&i.DepositStatus,
+		&i.OrderID,
+		&i.SeatedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReservationDeposit = `-- name: GetReservationDeposit :one
+SELECT intent_id, user_id, order_id, purpose, provider, provider_ref, amount, status, created_at, updated_at, share_id, gift_card_id, reservation_id FROM payment_intents
+WHERE reservation_id = ? AND purpose = 'reservation_deposit' AND status = 'succeeded'
+ORDER BY intent_id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetReservationDeposit(ctx context.Context, reservationID sql.NullInt32) (PaymentIntent, error) {
+	row := q.db.QueryRowContext(ctx, getReservationDeposit, reservationID)
+	var i PaymentIntent
+	err := row.Scan(
+		&i.IntentID,
+		&i.UserID,
+		&i.OrderID,
+		&i.Purpose,
+		&i.Provider,
+		&i.ProviderRef,
+		&i.Amount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShareID,
+		&i.GiftCardID,
+		&i.ReservationID,
+	)
+	return i, err
+}
+
+const getReservationForUpdate = `-- name: GetReservationForUpdate :one
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations WHERE reservation_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetReservationForUpdate(ctx context.Context, reservationID int32) (Reservation, error) {
+	row := q.db.QueryRowContext(ctx, getReservationForUpdate, reservationID)
+	var i Reservation
+	err := row.Scan(
+		&i.ReservationID,
+		&i.UserID,
+		&i.TableNumber,
+		&i.PartySize,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Status,
+		&i.Note,
+		&i.Deposit,
+		&i.DepositStatus,
+		&i.OrderID,
+		&i.SeatedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReservationsBetween = `-- name: GetReservationsBetween :many
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations
+WHERE starts_at >= ? AND starts_at < ?
+ORDER BY starts_at, table_number
+`
+
+type GetReservationsBetweenParams struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (q *Queries) GetReservationsBetween(ctx context.Context, arg GetReservationsBetweenParams) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, getReservationsBetween, arg.PeriodStart, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ReservationID,
+			&i.UserID,
+			&i.TableNumber,
+			&i.PartySize,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Status,
+			&i.Note,
+			&i.Deposit,
+			&i.DepositStatus,
+			&i.OrderID,
+			&i.SeatedAt,
+			&i.CancelledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReservationsByUser = `-- name: GetReservationsByUser :many
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations
+WHERE user_id = ?
+ORDER BY starts_at DESC
+`
+
+func (q *Queries) GetReservationsByUser(ctx context.Context, userID int32) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, getReservationsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ReservationID,
+			&i.UserID,
+			&i.TableNumber,
+			&i.PartySize,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Status,
+			&i.Note,
+			&i.Deposit,
+			&i.DepositStatus,
+			&i.OrderID,
+			&i.SeatedAt,
+			&i.CancelledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRider = `-- name: GetRider :one
+SELECT user_id, status, status_at, lat, lng, located_at, is_active, created_at FROM riders WHERE user_id = ? AND is_active = true
+`
+
+func (q *Queries) GetRider(ctx context.Context, userID int32) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, getRider, userID)
+	var i Rider
+	err := row.Scan(
+		&i.UserID,
+		&i.Status,
+		&i.StatusAt,
+		&i.Lat,
+		&i.Lng,
+		&i.LocatedAt,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRiderForUpdate = `-- name: GetRiderForUpdate :one
+SELECT user_id, status, status_at, lat, lng, located_at, is_active, created_at FROM riders WHERE user_id = ? AND is_active = true FOR UPDATE
+`
+
+func (q *Queries) GetRiderForUpdate(ctx context.Context, userID int32) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, getRiderForUpdate, userID)
+	var i Rider
+	err := row.Scan(
+		&i.UserID,
+		&i.Status,
+		&i.StatusAt,
+		&i.Lat,
+		&i.Lng,
+		&i.LocatedAt,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRiders = `-- name: GetRiders :many
+SELECT
+    riders.user_id, accounts.username, riders.status, riders.lat, riders.lng, riders.located_at, riders.is_active,
+    (SELECT COUNT(*) FROM deliveries
+     WHERE deliveries.rider_id = riders.user_id AND deliveries.status IN ('assigned', 'picked_up')) AS active_deliveries
+FROM riders
+JOIN accounts ON riders.user_id = accounts.id
+ORDER BY riders.user_id
+`
+
+type GetRidersRow struct {
+	UserID           int32
+	Username         string
+	Status           string
+	Lat              sql.NullFloat64
+	Lng              sql.NullFloat64
+	LocatedAt        sql.NullTime
+	IsActive         bool
+	ActiveDeliveries int64
+}
+
+func (q *Queries) GetRiders(ctx context.Context) ([]GetRidersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRiders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRidersRow
+	for rows.Next() {
+		var i GetRidersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Status,
+			&i.Lat,
+			&i.Lng,
+			&i.LocatedAt,
+			&i.IsActive,
+			&i.ActiveDeliveries,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScheduledOrders = `-- name: GetScheduledOrders :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE scheduled_for IS NOT NULL AND released_at IS NULL AND deleted = false
+ORDER BY scheduled_for, order_id
+`
+
+func (q *Queries) GetScheduledOrders(ctx context.Context) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduledOrders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.UserID,
+			&i.OrderInfo,
+			&i.Feedback,
 			&i.OrderTime,
 			&i.EstimatedTime,
 			&i.IsDone,
@@ -326,6 +5402,24 @@ func (q *Queries) GetAllDeletedOrdersByUser(ctx context.Context, userID int32) (
 			&i.DeliveryAddress,
 			&i.Deleted,
 			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -340,30 +5434,128 @@ func (q *Queries) GetAllDeletedOrdersByUser(ctx context.Context, userID int32) (
 	return items, nil
 }
 
-const getAllFood = `-- name: GetAllFood :many
-SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed FROM food
+const getSeatedReservationForTable = `-- name: GetSeatedReservationForTable :one
+SELECT reservation_id, user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status, order_id, seated_at, cancelled_at, created_at FROM reservations
+WHERE table_number = ? AND status = 'seated' AND order_id IS NULL
+    AND seated_at >= ?
+ORDER BY seated_at DESC
+LIMIT 1
 `
 
-func (q *Queries) GetAllFood(ctx context.Context) ([]Food, error) {
-	rows, err := q.db.QueryContext(ctx, getAllFood)
+type GetSeatedReservationForTableParams struct {
+	TableNumber int32
+	SeatedSince sql.NullTime
+}
+
+func (q *Queries) GetSeatedReservationForTable(ctx context.Context, arg GetSeatedReservationForTableParams) (Reservation, error) {
+	row := q.db.QueryRowContext(ctx, getSeatedReservationForTable, arg.TableNumber, arg.SeatedSince)
+	var i Reservation
+	err := row.Scan(
+		&i.ReservationID,
+		&i.UserID,
+		&i.TableNumber,
+		&i.PartySize,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Status,
+		&i.Note,
+		&i.Deposit,
+		&i.DepositStatus,
+		&i.OrderID,
+		&i.SeatedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSessionByPreviousHash = `-- name: GetSessionByPreviousHash :one
+SELECT session_id, user_id, refresh_hash, previous_hash, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM user_sessions
+WHERE previous_hash = ?
+`
+
+func (q *Queries) GetSessionByPreviousHash(ctx context.Context, previousHash sql.NullString) (UserSession, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByPreviousHash, previousHash)
+	var i UserSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.RefreshHash,
+		&i.PreviousHash,
+		&i.UserAgent,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getSessionByRefreshHash = `-- name: GetSessionByRefreshHash :one
+SELECT session_id, user_id, refresh_hash, previous_hash, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM user_sessions
+WHERE refresh_hash = ?
+`
+
+func (q *Queries) GetSessionByRefreshHash(ctx context.Context, refreshHash string) (UserSession, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByRefreshHash, refreshHash)
+	var i UserSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.RefreshHash,
+		&i.PreviousHash,
+		&i.UserAgent,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getSessionByRefreshHashForUpdate = `-- name: GetSessionByRefreshHashForUpdate :one
+SELECT session_id, user_id, refresh_hash, previous_hash, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM user_sessions
+WHERE refresh_hash = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetSessionByRefreshHashForUpdate(ctx context.Context, refreshHash string) (UserSession, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByRefreshHashForUpdate, refreshHash)
+	var i UserSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.UserID,
+		&i.RefreshHash,
+		&i.PreviousHash,
+		&i.UserAgent,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getShareItemsByOrder = `-- name: GetShareItemsByOrder :many
+SELECT share_items.share_id, share_items.item_id, share_items.quantity
+FROM share_items
+JOIN bill_shares ON share_items.share_id = bill_shares.share_id
+WHERE bill_shares.order_id = ?
+`
+
+func (q *Queries) GetShareItemsByOrder(ctx context.Context, orderID int32) ([]ShareItem, error) {
+	rows, err := q.db.QueryContext(ctx, getShareItemsByOrder, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Food
+	var items []ShareItem
 	for rows.Next() {
-		var i Food
-		if err := rows.Scan(
-			&i.FoodID,
-			&i.FoodName,
-			&i.Price,
-			&i.Picture,
-			&i.LongRange,
-			&i.Description,
-			&i.Info,
-			&i.Ingredients,
-			&i.TimeNeeded,
-		); err != nil {
+		var i ShareItem
+		if err := rows.Scan(&i.ShareID, &i.ItemID, &i.Quantity); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -377,29 +5569,37 @@ func (q *Queries) GetAllFood(ctx context.Context) ([]Food, error) {
 	return items, nil
 }
 
-const getAllFoodLongRange = `-- name: GetAllFoodLongRange :many
-SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed FROM food WHERE long_range = true
+const getStaffAccounts = `-- name: GetStaffAccounts :many
+SELECT account_roles.user_id, accounts.username, account_roles.role, account_roles.granted_by, account_roles.granted_at
+FROM account_roles
+JOIN accounts ON accounts.id = account_roles.user_id
+WHERE account_roles.role <> 'customer'
+ORDER BY accounts.username, account_roles.role
 `
 
-func (q *Queries) GetAllFoodLongRange(ctx context.Context) ([]Food, error) {
-	rows, err := q.db.QueryContext(ctx, getAllFoodLongRange)
+type GetStaffAccountsRow struct {
+	UserID    int32
+	Username  string
+	Role      string
+	GrantedBy sql.NullInt32
+	GrantedAt sql.NullTime
+}
+
+func (q *Queries) GetStaffAccounts(ctx context.Context) ([]GetStaffAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStaffAccounts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Food
+	var items []GetStaffAccountsRow
 	for rows.Next() {
-		var i Food
+		var i GetStaffAccountsRow
 		if err := rows.Scan(
-			&i.FoodID,
-			&i.FoodName,
-			&i.Price,
-			&i.Picture,
-			&i.LongRange,
-			&i.Description,
-			&i.Info,
-			&i.Ingredients,
-			&i.TimeNeeded,
+			&i.UserID,
+			&i.Username,
+			&i.Role,
+			&i.GrantedBy,
+			&i.GrantedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -414,23 +5614,34 @@ func (q *Queries) GetAllFoodLongRange(ctx context.Context) ([]Food, error) {
 	return items, nil
 }
 
-const getAllFoodTags = `-- name: GetAllFoodTags :many
-SELECT DISTINCT tag FROM tags
+const getStoreHolidays = `-- name: GetStoreHolidays :many
+SELECT holiday_id, holiday_date, order_type, opens_at, closes_at, note, created_by, created_at FROM store_holidays
+WHERE holiday_date >= ?
+ORDER BY holiday_date, order_type
 `
 
-func (q *Queries) GetAllFoodTags(ctx context.Context) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getAllFoodTags)
+func (q *Queries) GetStoreHolidays(ctx context.Context, fromDate time.Time) ([]StoreHoliday, error) {
+	rows, err := q.db.QueryContext(ctx, getStoreHolidays, fromDate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
-	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
+	var items []StoreHoliday
+	for rows.Next() {
+		var i StoreHoliday
+		if err := rows.Scan(
+			&i.HolidayID,
+			&i.HolidayDate,
+			&i.OrderType,
+			&i.OpensAt,
+			&i.ClosesAt,
+			&i.Note,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, tag)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -441,25 +5652,31 @@ func (q *Queries) GetAllFoodTags(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
-const getAllOrderedItems = `-- name: GetAllOrderedItems :many
-SELECT item_id, order_id, food_id, quantity, rating FROM items
+const getTipsByOrder = `-- name: GetTipsByOrder :many
+SELECT tip_id, order_id, user_id, intent_id, kind, rate, amount, pool, recipient_id, status, created_at FROM tips WHERE order_id = ? ORDER BY tip_id
 `
 
-func (q *Queries) GetAllOrderedItems(ctx context.Context) ([]Item, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOrderedItems)
+func (q *Queries) GetTipsByOrder(ctx context.Context, orderID int32) ([]Tip, error) {
+	rows, err := q.db.QueryContext(ctx, getTipsByOrder, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Item
+	var items []Tip
 	for rows.Next() {
-		var i Item
+		var i Tip
 		if err := rows.Scan(
-			&i.ItemID,
+			&i.TipID,
 			&i.OrderID,
-			&i.FoodID,
-			&i.Quantity,
-			&i.Rating,
+			&i.UserID,
+			&i.IntentID,
+			&i.Kind,
+			&i.Rate,
+			&i.Amount,
+			&i.Pool,
+			&i.RecipientID,
+			&i.Status,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -474,12 +5691,34 @@ func (q *Queries) GetAllOrderedItems(ctx context.Context) ([]Item, error) {
 	return items, nil
 }
 
-const getAllOrders = `-- name: GetAllOrders :many
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE deleted = false ORDER BY order_time DESC
+const getTwoFactorSecretForUpdate = `-- name: GetTwoFactorSecretForUpdate :one
+SELECT user_id, secret, last_step, created_at FROM two_factor_secrets
+WHERE user_id = ?
+FOR UPDATE
 `
 
-func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOrders)
+func (q *Queries) GetTwoFactorSecretForUpdate(ctx context.Context, userID int32) (TwoFactorSecret, error) {
+	row := q.db.QueryRowContext(ctx, getTwoFactorSecretForUpdate, userID)
+	var i TwoFactorSecret
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.LastStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUndispatchedDeliveryOrders = `-- name: GetUndispatchedDeliveryOrders :many
+SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid, order_type, table_number, finished_by, finished_at, invoice_billing_name, invoice_tax_id, zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee, address_id, delivery_recipient, delivery_phone, scheduled_for, release_at, released_at, reminded_at FROM orders
+WHERE is_ranged = true AND is_done = true AND deleted = false AND finished_at >= ?
+    AND NOT EXISTS (SELECT 1 FROM deliveries WHERE deliveries.order_id = orders.order_id
+        AND deliveries.status IN ('assigned', 'picked_up', 'delivered'))
+ORDER BY finished_at, order_id
+`
+
+func (q *Queries) GetUndispatchedDeliveryOrders(ctx context.Context, finishedAt sql.NullTime) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getUndispatchedDeliveryOrders, finishedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -499,6 +5738,92 @@ func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
 			&i.DeliveryAddress,
 			&i.Deleted,
 			&i.IsPaid,
+			&i.OrderType,
+			&i.TableNumber,
+			&i.FinishedBy,
+			&i.FinishedAt,
+			&i.InvoiceBillingName,
+			&i.InvoiceTaxID,
+			&i.ZoneID,
+			&i.DeliveryLat,
+			&i.DeliveryLng,
+			&i.DeliveryDistanceKm,
+			&i.DeliveryFee,
+			&i.AddressID,
+			&i.DeliveryRecipient,
+			&i.DeliveryPhone,
+			&i.ScheduledFor,
+			&i.ReleaseAt,
+			&i.ReleasedAt,
+			&i.RemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVerificationByHashForUpdate = `-- name: GetVerificationByHashForUpdate :one
+SELECT verification_id, user_id, channel, target, code_hash, attempts, created_at, expires_at, used_at FROM verifications
+WHERE code_hash = ? AND channel = ?
+FOR UPDATE
+`
+
+type GetVerificationByHashForUpdateParams struct {
+	CodeHash string
+	Channel  string
+}
+
+func (q *Queries) GetVerificationByHashForUpdate(ctx context.Context, arg GetVerificationByHashForUpdateParams) (Verification, error) {
+	row := q.db.QueryRowContext(ctx, getVerificationByHashForUpdate, arg.CodeHash, arg.Channel)
+	var i Verification
+	err := row.Scan(
+		&i.VerificationID,
+		&i.UserID,
+		&i.Channel,
+		&i.Target,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getWaitlist = `-- name: GetWaitlist :many
+SELECT entry_id, user_id, name, phone, party_size, status, table_number, notified_at, seated_at, created_at FROM waitlist
+WHERE status IN ('waiting', 'notified')
+ORDER BY created_at, entry_id
+`
+
+func (q *Queries) GetWaitlist(ctx context.Context) ([]Waitlist, error) {
+	rows, err := q.db.QueryContext(ctx, getWaitlist)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Waitlist
+	for rows.Next() {
+		var i Waitlist
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.UserID,
+			&i.Name,
+			&i.Phone,
+			&i.PartySize,
+			&i.Status,
+			&i.TableNumber,
+			&i.NotifiedAt,
+			&i.SeatedAt,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -513,518 +5838,946 @@ func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
 	return items, nil
 }
 
-const getAllOrdersByUser = `-- name: GetAllOrdersByUser :many
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE user_id = ? AND deleted = false
+const getWaitlistEntry = `-- name: GetWaitlistEntry :one
+SELECT entry_id, user_id, name, phone, party_size, status, table_number, notified_at, seated_at, created_at FROM waitlist WHERE entry_id = ?
+`
+
+func (q *Queries) GetWaitlistEntry(ctx context.Context, entryID int32) (Waitlist, error) {
+	row := q.db.QueryRowContext(ctx, getWaitlistEntry, entryID)
+	var i Waitlist
+	err := row.Scan(
+		&i.EntryID,
+		&i.UserID,
+		&i.Name,
+		&i.Phone,
+		&i.PartySize,
+		&i.Status,
+		&i.TableNumber,
+		&i.NotifiedAt,
+		&i.SeatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const grantRole = `-- name: GrantRole :exec
+INSERT IGNORE INTO account_roles (user_id, role, granted_by, granted_at)
+VALUES (?, ?, ?, ?)
+`
+
+type GrantRoleParams struct {
+	UserID    int32
+	Role      string
+	GrantedBy sql.NullInt32
+	GrantedAt sql.NullTime
+}
+
+func (q *Queries) GrantRole(ctx context.Context, arg GrantRoleParams) error {
+	_, err := q.db.ExecContext(ctx, grantRole,
+		arg.UserID,
+		arg.Role,
+		arg.GrantedBy,
+		arg.GrantedAt,
+	)
+	return err
+}
+
+const holdGiftCard = `-- name: HoldGiftCard :execrows
+UPDATE gift_cards
+SET
+    status = 'pending'
+WHERE
+    card_id = ? AND status = 'active'
+`
+
+func (q *Queries) HoldGiftCard(ctx context.Context, cardID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, holdGiftCard, cardID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const leaveWaitlist = `-- name: LeaveWaitlist :execrows
+UPDATE waitlist
+SET
+    status = 'left'
+WHERE
+    entry_id = ? AND status IN ('waiting', 'notified')
+`
+
+func (q *Queries) LeaveWaitlist(ctx context.Context, entryID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, leaveWaitlist, entryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const linkReservationOrder = `-- name: LinkReservationOrder :exec
+UPDATE reservations
+SET
+    order_id = ?
+WHERE
+    reservation_id = ?
+`
+
+type LinkReservationOrderParams struct {
+	OrderID       sql.NullInt32
+	ReservationID int32
+}
+
+func (q *Queries) LinkReservationOrder(ctx context.Context, arg LinkReservationOrderParams) error {
+	_, err := q.db.ExecContext(ctx, linkReservationOrder, arg.OrderID, arg.ReservationID)
+	return err
+}
+
+const markBillSharePaid = `-- name: MarkBillSharePaid :execrows
+UPDATE bill_shares
+SET
+    is_paid = true,
+    paid_by = ?,
+    paid_at = CURRENT_TIMESTAMP
+WHERE
+    share_id = ? AND intent_id = ? AND is_paid = false
+`
+
+type MarkBillSharePaidParams struct {
+	PaidBy   sql.NullInt32
+	ShareID  int32
+	IntentID sql.NullInt32
+}
+
+func (q *Queries) MarkBillSharePaid(ctx context.Context, arg MarkBillSharePaidParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markBillSharePaid, arg.PaidBy, arg.ShareID, arg.IntentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markDeliveryDelivered = `-- name: MarkDeliveryDelivered :execrows
+UPDATE deliveries
+SET
+    status = 'delivered',
+    delivered_at = ?,
+    proof_note = ?,
+    proof_photo = ?
+WHERE
+    delivery_id = ? AND status = 'picked_up'
+`
+
+type MarkDeliveryDeliveredParams struct {
+	DeliveredAt sql.NullTime
+	ProofNote   sql.NullString
+	ProofPhoto  sql.NullString
+	DeliveryID  int32
+}
+
+func (q *Queries) MarkDeliveryDelivered(ctx context.Context, arg MarkDeliveryDeliveredParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markDeliveryDelivered,
+		arg.DeliveredAt,
+		arg.ProofNote,
+		arg.ProofPhoto,
+		arg.DeliveryID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markDeliveryFailed = `-- name: MarkDeliveryFailed :execrows
+UPDATE deliveries
+SET
+    status = 'failed',
+    failed_at = ?,
+    failure_reason = ?,
+    proof_note = ?,
+    proof_photo = ?
+WHERE
+    delivery_id = ? AND status IN ('assigned', 'picked_up')
+`
+
+type MarkDeliveryFailedParams struct {
+	FailedAt      sql.NullTime
+	FailureReason sql.NullString
+	ProofNote     sql.NullString
+	ProofPhoto    sql.NullString
+	DeliveryID    int32
+}
+
+func (q *Queries) MarkDeliveryFailed(ctx context.Context, arg MarkDeliveryFailedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markDeliveryFailed,
+		arg.FailedAt,
+		arg.FailureReason,
+		arg.ProofNote,
+		arg.ProofPhoto,
+		arg.DeliveryID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markDeliveryPickedUp = `-- name: MarkDeliveryPickedUp :execrows
+UPDATE deliveries
+SET
+    status = 'picked_up',
+    picked_up_at = ?
+WHERE
+    delivery_id = ? AND status = 'assigned'
+`
+
+type MarkDeliveryPickedUpParams struct {
+	PickedUpAt sql.NullTime
+	DeliveryID int32
+}
+
+func (q *Queries) MarkDeliveryPickedUp(ctx context.Context, arg MarkDeliveryPickedUpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markDeliveryPickedUp, arg.PickedUpAt, arg.DeliveryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markMailSent = `-- name: MarkMailSent :exec
+UPDATE mail_outbox
+SET
+    status = 'sent',
+    body = '',
+    last_error = NULL,
+    sent_at = ?
+WHERE
+    mail_id = ?
+`
+
+type MarkMailSentParams struct {
+	SentAt sql.NullTime
+	MailID int32
+}
+
+func (q *Queries) MarkMailSent(ctx context.Context, arg MarkMailSentParams) error {
+	_, err := q.db.ExecContext(ctx, markMailSent, arg.SentAt, arg.MailID)
+	return err
+}
+
+const markNotificationsRead = `-- name: MarkNotificationsRead :exec
+UPDATE notifications
+SET
+    read_at = ?
+WHERE
+    user_id = ? AND read_at IS NULL
+`
+
+type MarkNotificationsReadParams struct {
+	ReadAt sql.NullTime
+	UserID int32
+}
+
+func (q *Queries) MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) error {
+	_, err := q.db.ExecContext(ctx, markNotificationsRead, arg.ReadAt, arg.UserID)
+	return err
+}
+
+const markOrderReleased = `-- name: MarkOrderReleased :execrows
+UPDATE orders
+SET
+    released_at = ?
+WHERE
+    order_id = ? AND released_at IS NULL
+`
+
+type MarkOrderReleasedParams struct {
+	ReleasedAt sql.NullTime
+	OrderID    int32
+}
+
+func (q *Queries) MarkOrderReleased(ctx context.Context, arg MarkOrderReleasedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOrderReleased, arg.ReleasedAt, arg.OrderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markOrderReminded = `-- name: MarkOrderReminded :execrows
+UPDATE orders
+SET
+    reminded_at = ?
+WHERE
+    order_id = ? AND reminded_at IS NULL
+`
+
+type MarkOrderRemindedParams struct {
+	RemindedAt sql.NullTime
+	OrderID    int32
+}
+
+func (q *Queries) MarkOrderReminded(ctx context.Context, arg MarkOrderRemindedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOrderReminded, arg.RemindedAt, arg.OrderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPrintJobPrinted = `-- name: MarkPrintJobPrinted :exec
+UPDATE print_jobs
+SET
+    status = 'printed',
+    last_error = NULL,
+    printed_at = ?
+WHERE
+    job_id = ?
+`
+
+type MarkPrintJobPrintedParams struct {
+	PrintedAt sql.NullTime
+	JobID     int32
+}
+
+func (q *Queries) MarkPrintJobPrinted(ctx context.Context, arg MarkPrintJobPrintedParams) error {
+	_, err := q.db.ExecContext(ctx, markPrintJobPrinted, arg.PrintedAt, arg.JobID)
+	return err
+}
+
+const newTag = `-- name: NewTag :exec
+INSERT INTO tags (tag, food_name)
+VALUES (
+    ?,
+    ?
+)
+`
+
+type NewTagParams struct {
+	Tag      string
+	FoodName string
+}
+
+func (q *Queries) NewTag(ctx context.Context, arg NewTagParams) error {
+	_, err := q.db.ExecContext(ctx, newTag, arg.Tag, arg.FoodName)
+	return err
+}
+
+const notifyWaitlistEntry = `-- name: NotifyWaitlistEntry :execrows
+UPDATE waitlist
+SET
+    status = 'notified',
+    table_number = ?,
+    notified_at = ?
+WHERE
+    entry_id = ? AND status IN ('waiting', 'notified')
+`
+
+type NotifyWaitlistEntryParams struct {
+	TableNumber sql.NullInt32
+	NotifiedAt  sql.NullTime
+	EntryID     int32
+}
+
+func (q *Queries) NotifyWaitlistEntry(ctx context.Context, arg NotifyWaitlistEntryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, notifyWaitlistEntry, arg.TableNumber, arg.NotifiedAt, arg.EntryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDeletedAccountMail = `-- name: PurgeDeletedAccountMail :exec
+DELETE FROM mail_outbox
+WHERE status IN ('sent', 'failed')
+    AND user_id IN (SELECT id FROM accounts WHERE status = 'deleted')
+`
+
+func (q *Queries) PurgeDeletedAccountMail(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, purgeDeletedAccountMail)
+	return err
+}
+
+const purgeMail = `-- name: PurgeMail :exec
+DELETE FROM mail_outbox
+WHERE status IN ('sent', 'failed') AND created_at < ?
+`
+
+func (q *Queries) PurgeMail(ctx context.Context, createdAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, purgeMail, createdAt)
+	return err
+}
+
+const rateFood = `-- name: RateFood :exec
+UPDATE items
+SET
+    rating = ?
+WHERE
+    food_id = ? AND order_id = ?
+`
+
+type RateFoodParams struct {
+	Rating  sql.NullInt32
+	FoodID  int32
+	OrderID int32
+}
+
+func (q *Queries) RateFood(ctx context.Context, arg RateFoodParams) error {
+	_, err := q.db.ExecContext(ctx, rateFood, arg.Rating, arg.FoodID, arg.OrderID)
+	return err
+}
+
+const rateOrder = `-- name: RateOrder :exec
+UPDATE orders
+SET
+    feedback = ?,
+    is_done = true
+WHERE
+    order_id = ?
+`
+
+type RateOrderParams struct {
+	Feedback sql.NullString
+	OrderID  int32
+}
+
+func (q *Queries) RateOrder(ctx context.Context, arg RateOrderParams) error {
+	_, err := q.db.ExecContext(ctx, rateOrder, arg.Feedback, arg.OrderID)
+	return err
+}
+
+const refundBillShare = `-- name: RefundBillShare :exec
+UPDATE bill_shares
+SET
+    is_paid = false,
+    intent_id = NULL,
+    paid_by = NULL,
+    paid_at = NULL
+WHERE
+    share_id = ?
 `
 
-func (q *Queries) GetAllOrdersByUser(ctx context.Context, userID int32) ([]Order, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOrdersByUser, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Order
-	for rows.Next() {
-		var i Order
-		if err := rows.Scan(
-			&i.OrderID,
-			&i.UserID,
-			&i.OrderInfo,
-			&i.Feedback,
-			&i.OrderTime,
-			&i.EstimatedTime,
-			&i.IsDone,
-			&i.IsRanged,
-			&i.DeliveryAddress,
-			&i.Deleted,
-			&i.IsPaid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) RefundBillShare(ctx context.Context, shareID int32) error {
+	_, err := q.db.ExecContext(ctx, refundBillShare, shareID)
+	return err
 }
 
-const getAllOrdersNotDone = `-- name: GetAllOrdersNotDone :many
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE is_done = false AND deleted = false ORDER BY order_time DESC
+const releaseBillShare = `-- name: ReleaseBillShare :exec
+UPDATE bill_shares
+SET
+    intent_id = NULL
+WHERE
+    share_id = ? AND intent_id = ? AND is_paid = false
 `
 
-func (q *Queries) GetAllOrdersNotDone(ctx context.Context) ([]Order, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOrdersNotDone)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Order
-	for rows.Next() {
-		var i Order
-		if err := rows.Scan(
-			&i.OrderID,
-			&i.UserID,
-			&i.OrderInfo,
-			&i.Feedback,
-			&i.OrderTime,
-			&i.EstimatedTime,
-			&i.IsDone,
-			&i.IsRanged,
-			&i.DeliveryAddress,
-			&i.Deleted,
-			&i.IsPaid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type ReleaseBillShareParams struct {
+	ShareID  int32
+	IntentID sql.NullInt32
 }
 
-const getAverageRating = `-- name: GetAverageRating :one
-SELECT AVG(rating) AS average_rating
-FROM items
-WHERE food_id = ? AND rating IS NOT NULL
+func (q *Queries) ReleaseBillShare(ctx context.Context, arg ReleaseBillShareParams) error {
+	_, err := q.db.ExecContext(ctx, releaseBillShare, arg.ShareID, arg.IntentID)
+	return err
+}
+
+const requeueStaleMail = `-- name: RequeueStaleMail :exec
+UPDATE mail_outbox
+SET
+    status = 'queued'
+WHERE
+    status = 'sending' AND claimed_at < ?
 `
 
-func (q *Queries) GetAverageRating(ctx context.Context, foodID int32) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getAverageRating, foodID)
-	var average_rating interface{}
-	err := row.Scan(&average_rating)
-	return average_rating, err
+func (q *Queries) RequeueStaleMail(ctx context.Context, claimedAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, requeueStaleMail, claimedAt)
+	return err
 }
 
-const getAverageSpendingByAllUsers = `-- name: GetAverageSpendingByAllUsers :one
-SELECT AVG(total_price) AS average_spending
-FROM (
-    SELECT SUM(food.price * items.quantity) AS total_price
-    FROM orders
-    JOIN items ON orders.order_id = items.order_id
-    JOIN food ON items.food_id = food.food_id
-    GROUP BY orders.user_id, orders.order_id
-) AS spending
+const requeueStalePrintJobs = `-- name: RequeueStalePrintJobs :exec
+UPDATE print_jobs
+SET
+    status = 'queued'
+WHERE
+    status = 'printing' AND claimed_at < ?
 `
 
-func (q *Queries) GetAverageSpendingByAllUsers(ctx context.Context) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getAverageSpendingByAllUsers)
-	var average_spending interface{}
-	err := row.Scan(&average_spending)
-	return average_spending, err
+func (q *Queries) RequeueStalePrintJobs(ctx context.Context, claimedAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, requeueStalePrintJobs, claimedAt)
+	return err
 }
 
-const getAverageSpendingByUser = `-- name: GetAverageSpendingByUser :one
-SELECT AVG(total_price) AS average_spending
-FROM (
-    SELECT SUM(food.price * items.quantity) AS total_price
-    FROM orders
-    JOIN items ON orders.order_id = items.order_id
-    JOIN food ON items.food_id = food.food_id
-    WHERE orders.user_id = ?
-    GROUP BY orders.order_id
-) AS spending
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :execrows
+INSERT IGNORE INTO idempotency_keys (user_id, idem_key, request_hash, expires_at)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?
+)
 `
 
-func (q *Queries) GetAverageSpendingByUser(ctx context.Context, userID int32) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getAverageSpendingByUser, userID)
-	var average_spending interface{}
-	err := row.Scan(&average_spending)
-	return average_spending, err
+type ReserveIdempotencyKeyParams struct {
+	UserID      int32
+	IdemKey     string
+	RequestHash string
+	ExpiresAt   time.Time
 }
 
-const getFood = `-- name: GetFood :one
-SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed FROM food WHERE food_name = ?
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reserveIdempotencyKey,
+		arg.UserID,
+		arg.IdemKey,
+		arg.RequestHash,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retryMail = `-- name: RetryMail :exec
+UPDATE mail_outbox
+SET
+    status = 'queued',
+    last_error = ?,
+    next_attempt_at = ?
+WHERE
+    mail_id = ?
 `
 
-func (q *Queries) GetFood(ctx context.Context, foodName string) (Food, error) {
-	row := q.db.QueryRowContext(ctx, getFood, foodName)
-	var i Food
-	err := row.Scan(
-		&i.FoodID,
-		&i.FoodName,
-		&i.Price,
-		&i.Picture,
-		&i.LongRange,
-		&i.Description,
-		&i.Info,
-		&i.Ingredients,
-		&i.TimeNeeded,
-	)
-	return i, err
+type RetryMailParams struct {
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	MailID        int32
 }
 
-const getFoodById = `-- name: GetFoodById :one
-SELECT food_id, food_name, price, picture, long_range, description, info, ingredients, time_needed FROM food WHERE food_id = ?
+func (q *Queries) RetryMail(ctx context.Context, arg RetryMailParams) error {
+	_, err := q.db.ExecContext(ctx, retryMail, arg.LastError, arg.NextAttemptAt, arg.MailID)
+	return err
+}
+
+const retryPrintJob = `-- name: RetryPrintJob :exec
+UPDATE print_jobs
+SET
+    status = 'queued',
+    last_error = ?,
+    next_attempt_at = ?
+WHERE
+    job_id = ?
 `
 
-func (q *Queries) GetFoodById(ctx context.Context, foodID int32) (Food, error) {
-	row := q.db.QueryRowContext(ctx, getFoodById, foodID)
-	var i Food
-	err := row.Scan(
-		&i.FoodID,
-		&i.FoodName,
-		&i.Price,
-		&i.Picture,
-		&i.LongRange,
-		&i.Description,
-		&i.Info,
-		&i.Ingredients,
-		&i.TimeNeeded,
-	)
-	return i, err
+type RetryPrintJobParams struct {
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	JobID         int32
 }
 
-const getFoodByTag = `-- name: GetFoodByTag :many
-SELECT DISTINCT food.food_id, food.food_name, food.price, food.picture, food.long_range, food.description, food.info, food.ingredients, food.time_needed
-FROM food
-JOIN tags ON food.food_name = tags.food_name
-WHERE tags.tag = ?
+func (q *Queries) RetryPrintJob(ctx context.Context, arg RetryPrintJobParams) error {
+	_, err := q.db.ExecContext(ctx, retryPrintJob, arg.LastError, arg.NextAttemptAt, arg.JobID)
+	return err
+}
+
+const revokeRole = `-- name: RevokeRole :execrows
+DELETE FROM account_roles
+WHERE user_id = ? AND role = ?
 `
 
-func (q *Queries) GetFoodByTag(ctx context.Context, tag string) ([]Food, error) {
-	rows, err := q.db.QueryContext(ctx, getFoodByTag, tag)
+type RevokeRoleParams struct {
+	UserID int32
+	Role   string
+}
+
+func (q *Queries) RevokeRole(ctx context.Context, arg RevokeRoleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeRole, arg.UserID, arg.Role)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	var items []Food
-	for rows.Next() {
-		var i Food
-		if err := rows.Scan(
-			&i.FoodID,
-			&i.FoodName,
-			&i.Price,
-			&i.Picture,
-			&i.LongRange,
-			&i.Description,
-			&i.Info,
-			&i.Ingredients,
-			&i.TimeNeeded,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getFoodTagByFoodName = `-- name: GetFoodTagByFoodName :many
-SELECT tag
-FROM tags
-WHERE food_name = ?
+const revokeSession = `-- name: RevokeSession :execrows
+UPDATE user_sessions
+SET
+    revoked_at = ?
+WHERE
+    session_id = ? AND user_id = ? AND revoked_at IS NULL
 `
 
-func (q *Queries) GetFoodTagByFoodName(ctx context.Context, foodName string) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getFoodTagByFoodName, foodName)
+type RevokeSessionParams struct {
+	RevokedAt sql.NullTime
+	SessionID int32
+	UserID    int32
+}
+
+func (q *Queries) RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeSession, arg.RevokedAt, arg.SessionID, arg.UserID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []string
-	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
-			return nil, err
-		}
-		items = append(items, tag)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
+}
+
+const revokeStaffRoles = `-- name: RevokeStaffRoles :exec
+DELETE FROM account_roles
+WHERE user_id = ? AND role <> 'customer'
+`
+
+func (q *Queries) RevokeStaffRoles(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, revokeStaffRoles, userID)
+	return err
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :exec
+UPDATE user_sessions
+SET
+    revoked_at = ?
+WHERE
+    user_id = ? AND revoked_at IS NULL
+`
+
+type RevokeUserSessionsParams struct {
+	RevokedAt sql.NullTime
+	UserID    int32
 }
 
-const getLastInsertedOrder = `-- name: GetLastInsertedOrder :one
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders
-WHERE order_id = LAST_INSERT_ID()
+func (q *Queries) RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessions, arg.RevokedAt, arg.UserID)
+	return err
+}
+
+const rotateSession = `-- name: RotateSession :exec
+UPDATE user_sessions
+SET
+    previous_hash = refresh_hash,
+    refresh_hash = ?,
+    last_used_at = ?,
+    expires_at = ?
+WHERE
+    session_id = ?
 `
 
-func (q *Queries) GetLastInsertedOrder(ctx context.Context) (Order, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertedOrder)
-	var i Order
-	err := row.Scan(
-		&i.OrderID,
-		&i.UserID,
-		&i.OrderInfo,
-		&i.Feedback,
-		&i.OrderTime,
-		&i.EstimatedTime,
-		&i.IsDone,
-		&i.IsRanged,
-		&i.DeliveryAddress,
-		&i.Deleted,
-		&i.IsPaid,
+type RotateSessionParams struct {
+	RefreshHash string
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+	SessionID   int32
+}
+
+func (q *Queries) RotateSession(ctx context.Context, arg RotateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, rotateSession,
+		arg.RefreshHash,
+		arg.LastUsedAt,
+		arg.ExpiresAt,
+		arg.SessionID,
 	)
-	return i, err
+	return err
 }
 
-const getLongestTimeNeededFoodInOrder = `-- name: GetLongestTimeNeededFoodInOrder :one
-SELECT MAX(food.time_needed) AS longest_time_needed
-FROM food
-JOIN items ON food.food_id = items.food_id
-WHERE items.order_id = ?
+const saveDiningTable = `-- name: SaveDiningTable :exec
+INSERT INTO dining_tables (table_number, seats, is_active)
+VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    seats = VALUES(seats),
+    is_active = VALUES(is_active)
 `
 
-func (q *Queries) GetLongestTimeNeededFoodInOrder(ctx context.Context, orderID int32) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getLongestTimeNeededFoodInOrder, orderID)
-	var longest_time_needed interface{}
-	err := row.Scan(&longest_time_needed)
-	return longest_time_needed, err
+type SaveDiningTableParams struct {
+	TableNumber int32
+	Seats       int32
+	IsActive    bool
 }
 
-const getMostOrderedFood = `-- name: GetMostOrderedFood :many
-SELECT food.food_name, COUNT(items.food_id) AS order_count
-FROM food
-JOIN items ON food.food_id = items.food_id
-GROUP BY food.food_name
-ORDER BY order_count DESC
+func (q *Queries) SaveDiningTable(ctx context.Context, arg SaveDiningTableParams) error {
+	_, err := q.db.ExecContext(ctx, saveDiningTable, arg.TableNumber, arg.Seats, arg.IsActive)
+	return err
+}
+
+const saveStoreHoliday = `-- name: SaveStoreHoliday :exec
+INSERT INTO store_holidays (holiday_date, order_type, opens_at, closes_at, note, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    opens_at = VALUES(opens_at),
+    closes_at = VALUES(closes_at),
+    note = VALUES(note),
+    created_by = VALUES(created_by)
 `
 
-type GetMostOrderedFoodRow struct {
-	FoodName   string
-	OrderCount int64
+type SaveStoreHolidayParams struct {
+	HolidayDate time.Time
+	OrderType   string
+	OpensAt     sql.NullString
+	ClosesAt    sql.NullString
+	Note        string
+	CreatedBy   sql.NullInt32
 }
 
-func (q *Queries) GetMostOrderedFood(ctx context.Context) ([]GetMostOrderedFoodRow, error) {
-	rows, err := q.db.QueryContext(ctx, getMostOrderedFood)
+func (q *Queries) SaveStoreHoliday(ctx context.Context, arg SaveStoreHolidayParams) error {
+	_, err := q.db.ExecContext(ctx, saveStoreHoliday,
+		arg.HolidayDate,
+		arg.OrderType,
+		arg.OpensAt,
+		arg.ClosesAt,
+		arg.Note,
+		arg.CreatedBy,
+	)
+	return err
+}
+
+const saveTwoFactorSecret = `-- name: SaveTwoFactorSecret :exec
+INSERT INTO two_factor_secrets (user_id, secret, last_step, created_at)
+VALUES (?, ?, 0, ?)
+ON DUPLICATE KEY UPDATE
+    secret = VALUES(secret),
+    last_step = 0,
+    created_at = VALUES(created_at)
+`
+
+type SaveTwoFactorSecretParams struct {
+	UserID    int32
+	Secret    string
+	CreatedAt time.Time
+}
+
+func (q *Queries) SaveTwoFactorSecret(ctx context.Context, arg SaveTwoFactorSecretParams) error {
+	_, err := q.db.ExecContext(ctx, saveTwoFactorSecret, arg.UserID, arg.Secret, arg.CreatedAt)
+	return err
+}
+
+const seatReservation = `-- name: SeatReservation :execrows
+UPDATE reservations
+SET
+    status = 'seated',
+    seated_at = ?,
+    order_id = ?
+WHERE
+    reservation_id = ? AND status = 'booked'
+`
+
+type SeatReservationParams struct {
+	SeatedAt      sql.NullTime
+	OrderID       sql.NullInt32
+	ReservationID int32
+}
+
+func (q *Queries) SeatReservation(ctx context.Context, arg SeatReservationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, seatReservation, arg.SeatedAt, arg.OrderID, arg.ReservationID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetMostOrderedFoodRow
-	for rows.Next() {
-		var i GetMostOrderedFoodRow
-		if err := rows.Scan(&i.FoodName, &i.OrderCount); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected()
+}
+
+const seatWaitlistEntry = `-- name: SeatWaitlistEntry :execrows
+UPDATE waitlist
+SET
+    status = 'seated',
+    table_number = COALESCE(?, table_number),
+    seated_at = ?
+WHERE
+    entry_id = ? AND status IN ('waiting', 'notified')
+`
+
+type SeatWaitlistEntryParams struct {
+	TableNumber sql.NullInt32
+	SeatedAt    sql.NullTime
+	EntryID     int32
+}
+
+func (q *Queries) SeatWaitlistEntry(ctx context.Context, arg SeatWaitlistEntryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, seatWaitlistEntry, arg.TableNumber, arg.SeatedAt, arg.EntryID)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getMostOrderedTag = `-- name: GetMostOrderedTag :one
-SELECT tag, COUNT(*) AS count
-FROM tags
-JOIN items ON tags.food_name = items.food_id
-JOIN orders ON items.order_id = orders.order_id
-WHERE orders.deleted = false
-GROUP BY tag
-ORDER BY count DESC
-LIMIT 1
+const setAccountStatus = `-- name: SetAccountStatus :exec
+UPDATE accounts
+SET
+    status = ?,
+    status_reason = ?,
+    suspended_until = ?
+WHERE
+    id = ?
 `
 
-type GetMostOrderedTagRow struct {
-	Tag   string
-	Count int64
+type SetAccountStatusParams struct {
+	Status         string
+	StatusReason   string
+	SuspendedUntil sql.NullTime
+	ID             int32
 }
 
-func (q *Queries) GetMostOrderedTag(ctx context.Context) (GetMostOrderedTagRow, error) {
-	row := q.db.QueryRowContext(ctx, getMostOrderedTag)
-	var i GetMostOrderedTagRow
-	err := row.Scan(&i.Tag, &i.Count)
-	return i, err
+func (q *Queries) SetAccountStatus(ctx context.Context, arg SetAccountStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setAccountStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.SuspendedUntil,
+		arg.ID,
+	)
+	return err
 }
 
-const getOrder = `-- name: GetOrder :many
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE user_id = ?
+const setDefaultAddress = `-- name: SetDefaultAddress :execrows
+UPDATE addresses
+SET
+    is_default = true
+WHERE
+    address_id = ? AND user_id = ?
 `
 
-func (q *Queries) GetOrder(ctx context.Context, userID int32) ([]Order, error) {
-	rows, err := q.db.QueryContext(ctx, getOrder, userID)
+type SetDefaultAddressParams struct {
+	AddressID int32
+	UserID    int32
+}
+
+func (q *Queries) SetDefaultAddress(ctx context.Context, arg SetDefaultAddressParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setDefaultAddress, arg.AddressID, arg.UserID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Order
-	for rows.Next() {
-		var i Order
-		if err := rows.Scan(
-			&i.OrderID,
-			&i.UserID,
-			&i.OrderInfo,
-			&i.Feedback,
-			&i.OrderTime,
-			&i.EstimatedTime,
-			&i.IsDone,
-			&i.IsRanged,
-			&i.DeliveryAddress,
-			&i.Deleted,
-			&i.IsPaid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getOrderById = `-- name: GetOrderById :one
-SELECT order_id, user_id, order_info, feedback, order_time, estimated_time, is_done, is_ranged, delivery_address, deleted, is_paid FROM orders WHERE order_id = ?
+const setEmailVerified = `-- name: SetEmailVerified :exec
+UPDATE accounts
+SET
+    email_verified_at = ?
+WHERE
+    id = ?
 `
 
-func (q *Queries) GetOrderById(ctx context.Context, orderID int32) (Order, error) {
-	row := q.db.QueryRowContext(ctx, getOrderById, orderID)
-	var i Order
-	err := row.Scan(
-		&i.OrderID,
-		&i.UserID,
-		&i.OrderInfo,
-		&i.Feedback,
-		&i.OrderTime,
-		&i.EstimatedTime,
-		&i.IsDone,
-		&i.IsRanged,
-		&i.DeliveryAddress,
-		&i.Deleted,
-		&i.IsPaid,
-	)
-	return i, err
+type SetEmailVerifiedParams struct {
+	EmailVerifiedAt sql.NullTime
+	ID              int32
 }
 
-const getOrderTotalPrice = `-- name: GetOrderTotalPrice :one
-SELECT SUM(food.price * items.quantity) AS total_price
-FROM orders
-JOIN items ON orders.order_id = items.order_id
-JOIN food ON items.food_id = food.food_id
-WHERE orders.order_id = ?
+func (q *Queries) SetEmailVerified(ctx context.Context, arg SetEmailVerifiedParams) error {
+	_, err := q.db.ExecContext(ctx, setEmailVerified, arg.EmailVerifiedAt, arg.ID)
+	return err
+}
+
+const setEstimatedTime = `-- name: SetEstimatedTime :exec
+UPDATE orders
+SET
+    estimated_time = ?
+WHERE
+    order_id = ?
 `
 
-func (q *Queries) GetOrderTotalPrice(ctx context.Context, orderID int32) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getOrderTotalPrice, orderID)
-	var total_price interface{}
-	err := row.Scan(&total_price)
-	return total_price, err
+type SetEstimatedTimeParams struct {
+	EstimatedTime time.Time
+	OrderID       int32
 }
 
-const getOrderedCountByFood = `-- name: GetOrderedCountByFood :one
-SELECT COUNT(*) AS ordered_count
-FROM items
-WHERE food_id = ?
+func (q *Queries) SetEstimatedTime(ctx context.Context, arg SetEstimatedTimeParams) error {
+	_, err := q.db.ExecContext(ctx, setEstimatedTime, arg.EstimatedTime, arg.OrderID)
+	return err
+}
+
+const setLoginBlockedUntil = `-- name: SetLoginBlockedUntil :exec
+UPDATE login_throttles
+SET
+    blocked_until = ?
+WHERE
+    scope = ? AND subject = ?
 `
 
-func (q *Queries) GetOrderedCountByFood(ctx context.Context, foodID int32) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getOrderedCountByFood, foodID)
-	var ordered_count int64
-	err := row.Scan(&ordered_count)
-	return ordered_count, err
+type SetLoginBlockedUntilParams struct {
+	BlockedUntil time.Time
+	Scope        string
+	Subject      string
 }
 
-const getOrderedItems = `-- name: GetOrderedItems :many
-SELECT item_id, order_id, food_id, quantity, rating FROM items WHERE order_id = ?
+func (q *Queries) SetLoginBlockedUntil(ctx context.Context, arg SetLoginBlockedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setLoginBlockedUntil, arg.BlockedUntil, arg.Scope, arg.Subject)
+	return err
+}
+
+const setOldestAddressDefault = `-- name: SetOldestAddressDefault :exec
+UPDATE addresses
+SET
+    is_default = true
+WHERE
+    user_id = ?
+ORDER BY address_id
+LIMIT 1
 `
 
-func (q *Queries) GetOrderedItems(ctx context.Context, orderID int32) ([]Item, error) {
-	rows, err := q.db.QueryContext(ctx, getOrderedItems, orderID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Item
-	for rows.Next() {
-		var i Item
-		if err := rows.Scan(
-			&i.ItemID,
-			&i.OrderID,
-			&i.FoodID,
-			&i.Quantity,
-			&i.Rating,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SetOldestAddressDefault(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, setOldestAddressDefault, userID)
+	return err
 }
 
-const newTag = `-- name: NewTag :exec
-INSERT INTO tags (tag, food_name)
-VALUES (
-    ?,
-    ?
-)
+const setPhoneVerified = `-- name: SetPhoneVerified :exec
+UPDATE accounts
+SET
+    phone_verified_at = ?
+WHERE
+    id = ?
 `
 
-type NewTagParams struct {
-	Tag      string
-	FoodName string
+type SetPhoneVerifiedParams struct {
+	PhoneVerifiedAt sql.NullTime
+	ID              int32
+}
+
+func (q *Queries) SetPhoneVerified(ctx context.Context, arg SetPhoneVerifiedParams) error {
+	_, err := q.db.ExecContext(ctx, setPhoneVerified, arg.PhoneVerifiedAt, arg.ID)
+	return err
+}
+
+const setRiderTipRecipient = `-- name: SetRiderTipRecipient :exec
+UPDATE tips
+SET
+    recipient_id = ?
+WHERE
+    order_id = ? AND pool = 'rider'
+`
+
+type SetRiderTipRecipientParams struct {
+	RecipientID sql.NullInt32
+	OrderID     int32
 }
 
-func (q *Queries) NewTag(ctx context.Context, arg NewTagParams) error {
-	_, err := q.db.ExecContext(ctx, newTag, arg.Tag, arg.FoodName)
+func (q *Queries) SetRiderTipRecipient(ctx context.Context, arg SetRiderTipRecipientParams) error {
+	_, err := q.db.ExecContext(ctx, setRiderTipRecipient, arg.RecipientID, arg.OrderID)
 	return err
 }
 
-const rateFood = `-- name: RateFood :exec
-UPDATE items
+const setTwoFactorEnabled = `-- name: SetTwoFactorEnabled :exec
+UPDATE accounts
 SET
-    rating = ?
+    two_factor_enabled_at = ?
 WHERE
-    food_id = ? AND order_id = ?
+    id = ?
 `
 
-type RateFoodParams struct {
-	Rating  sql.NullInt32
-	FoodID  int32
-	OrderID int32
+type SetTwoFactorEnabledParams struct {
+	TwoFactorEnabledAt sql.NullTime
+	ID                 int32
 }
 
-func (q *Queries) RateFood(ctx context.Context, arg RateFoodParams) error {
-	_, err := q.db.ExecContext(ctx, rateFood, arg.Rating, arg.FoodID, arg.OrderID)
+func (q *Queries) SetTwoFactorEnabled(ctx context.Context, arg SetTwoFactorEnabledParams) error {
+	_, err := q.db.ExecContext(ctx, setTwoFactorEnabled, arg.TwoFactorEnabledAt, arg.ID)
 	return err
 }
 
-const rateOrder = `-- name: RateOrder :exec
-UPDATE orders
+const setTwoFactorLastStep = `-- name: SetTwoFactorLastStep :exec
+UPDATE two_factor_secrets
 SET
-    feedback = ?,
-    is_done = true
+    last_step = ?
 WHERE
-    order_id = ?
+    user_id = ?
 `
 
-type RateOrderParams struct {
-	Feedback sql.NullString
-	OrderID  int32
+type SetTwoFactorLastStepParams struct {
+	LastStep int64
+	UserID   int32
 }
 
-func (q *Queries) RateOrder(ctx context.Context, arg RateOrderParams) error {
-	_, err := q.db.ExecContext(ctx, rateOrder, arg.Feedback, arg.OrderID)
+func (q *Queries) SetTwoFactorLastStep(ctx context.Context, arg SetTwoFactorLastStepParams) error {
+	_, err := q.db.ExecContext(ctx, setTwoFactorLastStep, arg.LastStep, arg.UserID)
 	return err
 }
 
@@ -1070,6 +6823,157 @@ func (q *Queries) TopThreeTagByUser(ctx context.Context, userID int32) ([]TopThr
 	return items, nil
 }
 
+const transitionPaymentIntent = `-- name: TransitionPaymentIntent :execrows
+UPDATE payment_intents
+SET
+    status = ?
+WHERE
+    intent_id = ? AND status = ?
+`
+
+type TransitionPaymentIntentParams struct {
+	NewStatus string
+	IntentID  int32
+	OldStatus string
+}
+
+func (q *Queries) TransitionPaymentIntent(ctx context.Context, arg TransitionPaymentIntentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transitionPaymentIntent, arg.NewStatus, arg.IntentID, arg.OldStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const transitionReservation = `-- name: TransitionReservation :execrows
+UPDATE reservations
+SET
+    status = ?
+WHERE
+    reservation_id = ? AND status = ?
+`
+
+type TransitionReservationParams struct {
+	NewStatus     string
+	ReservationID int32
+	OldStatus     string
+}
+
+func (q *Queries) TransitionReservation(ctx context.Context, arg TransitionReservationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transitionReservation, arg.NewStatus, arg.ReservationID, arg.OldStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateAccountPassword = `-- name: UpdateAccountPassword :exec
+UPDATE accounts
+SET
+    password = ?
+WHERE
+    id = ?
+`
+
+type UpdateAccountPasswordParams struct {
+	Password string
+	ID       int32
+}
+
+func (q *Queries) UpdateAccountPassword(ctx context.Context, arg UpdateAccountPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateAccountPassword, arg.Password, arg.ID)
+	return err
+}
+
+const updateAddress = `-- name: UpdateAddress :exec
+UPDATE addresses
+SET
+    label = ?,
+    recipient = ?,
+    phone = ?,
+    line1 = ?,
+    line2 = ?,
+    city = ?,
+    postcode = ?,
+    lat = ?,
+    lng = ?
+WHERE
+    address_id = ? AND user_id = ?
+`
+
+type UpdateAddressParams struct {
+	Label     string
+	Recipient string
+	Phone     string
+	Line1     string
+	Line2     string
+	City      string
+	Postcode  string
+	Lat       sql.NullFloat64
+	Lng       sql.NullFloat64
+	AddressID int32
+	UserID    int32
+}
+
+func (q *Queries) UpdateAddress(ctx context.Context, arg UpdateAddressParams) error {
+	_, err := q.db.ExecContext(ctx, updateAddress,
+		arg.Label,
+		arg.Recipient,
+		arg.Phone,
+		arg.Line1,
+		arg.Line2,
+		arg.City,
+		arg.Postcode,
+		arg.Lat,
+		arg.Lng,
+		arg.AddressID,
+		arg.UserID,
+	)
+	return err
+}
+
+const updateDeliveryZone = `-- name: UpdateDeliveryZone :exec
+UPDATE delivery_zones
+SET
+    name = ?,
+    kind = ?,
+    radius_km = ?,
+    polygon = ?,
+    base_fee = ?,
+    fee_per_km = ?,
+    min_order = ?,
+    is_active = ?
+WHERE
+    zone_id = ?
+`
+
+type UpdateDeliveryZoneParams struct {
+	Name     string
+	Kind     string
+	RadiusKm sql.NullFloat64
+	Polygon  sql.NullString
+	BaseFee  float64
+	FeePerKm float64
+	MinOrder float64
+	IsActive bool
+	ZoneID   int32
+}
+
+func (q *Queries) UpdateDeliveryZone(ctx context.Context, arg UpdateDeliveryZoneParams) error {
+	_, err := q.db.ExecContext(ctx, updateDeliveryZone,
+		arg.Name,
+		arg.Kind,
+		arg.RadiusKm,
+		arg.Polygon,
+		arg.BaseFee,
+		arg.FeePerKm,
+		arg.MinOrder,
+		arg.IsActive,
+		arg.ZoneID,
+	)
+	return err
+}
+
 const updateEstimatedTime = `-- name: UpdateEstimatedTime :exec
 UPDATE orders
 SET
@@ -1106,29 +7010,170 @@ func (q *Queries) UpdateFeedback(ctx context.Context, arg UpdateFeedbackParams)
 	return err
 }
 
+const updateFoodStation = `-- name: UpdateFoodStation :exec
+UPDATE food
+SET
+    station = ?
+WHERE
+    food_id = ?
+`
+
+type UpdateFoodStationParams struct {
+	Station string
+	FoodID  int32
+}
+
+func (q *Queries) UpdateFoodStation(ctx context.Context, arg UpdateFoodStationParams) error {
+	_, err := q.db.ExecContext(ctx, updateFoodStation, arg.Station, arg.FoodID)
+	return err
+}
+
+const updateGiftCardBalance = `-- name: UpdateGiftCardBalance :exec
+UPDATE gift_cards
+SET
+    balance = ?
+WHERE
+    card_id = ?
+`
+
+type UpdateGiftCardBalanceParams struct {
+	Balance float64
+	CardID  int32
+}
+
+func (q *Queries) UpdateGiftCardBalance(ctx context.Context, arg UpdateGiftCardBalanceParams) error {
+	_, err := q.db.ExecContext(ctx, updateGiftCardBalance, arg.Balance, arg.CardID)
+	return err
+}
+
+const updateKitchenCapacity = `-- name: UpdateKitchenCapacity :exec
+UPDATE kitchen_settings
+SET
+    window_minutes = ?,
+    max_items = ?,
+    max_prep_minutes = ?,
+    pushback_after_minutes = ?,
+    pause_after_minutes = ?,
+    updated_by = ?
+WHERE
+    settings_id = 1
+`
+
+type UpdateKitchenCapacityParams struct {
+	WindowMinutes        int32
+	MaxItems             int32
+	MaxPrepMinutes       int32
+	PushbackAfterMinutes int32
+	PauseAfterMinutes    int32
+	UpdatedBy            sql.NullInt32
+}
+
+func (q *Queries) UpdateKitchenCapacity(ctx context.Context, arg UpdateKitchenCapacityParams) error {
+	_, err := q.db.ExecContext(ctx, updateKitchenCapacity,
+		arg.WindowMinutes,
+		arg.MaxItems,
+		arg.MaxPrepMinutes,
+		arg.PushbackAfterMinutes,
+		arg.PauseAfterMinutes,
+		arg.UpdatedBy,
+	)
+	return err
+}
+
+const updateKitchenPause = `-- name: UpdateKitchenPause :exec
+UPDATE kitchen_settings
+SET
+    takeaway_paused = ?,
+    delivery_paused = ?,
+    resume_at = ?,
+    updated_by = ?
+WHERE
+    settings_id = 1
+`
+
+type UpdateKitchenPauseParams struct {
+	TakeawayPaused bool
+	DeliveryPaused bool
+	ResumeAt       sql.NullTime
+	UpdatedBy      sql.NullInt32
+}
+
+func (q *Queries) UpdateKitchenPause(ctx context.Context, arg UpdateKitchenPauseParams) error {
+	_, err := q.db.ExecContext(ctx, updateKitchenPause,
+		arg.TakeawayPaused,
+		arg.DeliveryPaused,
+		arg.ResumeAt,
+		arg.UpdatedBy,
+	)
+	return err
+}
+
 const updateOrderDoneStatus = `-- name: UpdateOrderDoneStatus :exec
 UPDATE orders
 SET
-    is_done = true
+    is_done = true,
+    finished_by = ?,
+    finished_at = CURRENT_TIMESTAMP
+WHERE
+    order_id = ?
+`
+
+type UpdateOrderDoneStatusParams struct {
+	FinishedBy sql.NullInt32
+	OrderID    int32
+}
+
+func (q *Queries) UpdateOrderDoneStatus(ctx context.Context, arg UpdateOrderDoneStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateOrderDoneStatus, arg.FinishedBy, arg.OrderID)
+	return err
+}
+
+const updateOrderInvoiceDetails = `-- name: UpdateOrderInvoiceDetails :exec
+UPDATE orders
+SET
+    invoice_billing_name = ?,
+    invoice_tax_id = ?
 WHERE
     order_id = ?
 `
 
-func (q *Queries) UpdateOrderDoneStatus(ctx context.Context, orderID int32) error {
-	_, err := q.db.ExecContext(ctx, updateOrderDoneStatus, orderID)
+type UpdateOrderInvoiceDetailsParams struct {
+	InvoiceBillingName sql.NullString
+	InvoiceTaxID       sql.NullString
+	OrderID            int32
+}
+
+func (q *Queries) UpdateOrderInvoiceDetails(ctx context.Context, arg UpdateOrderInvoiceDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, updateOrderInvoiceDetails, arg.InvoiceBillingName, arg.InvoiceTaxID, arg.OrderID)
 	return err
 }
 
-const updateOrderPayment = `-- name: UpdateOrderPayment :exec
+const updateOrderPayment = `-- name: UpdateOrderPayment :execrows
 UPDATE orders
 SET
     is_paid = true
+WHERE
+    order_id = ? AND is_paid = false
+`
+
+func (q *Queries) UpdateOrderPayment(ctx context.Context, orderID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateOrderPayment, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateOrderUnpaid = `-- name: UpdateOrderUnpaid :exec
+UPDATE orders
+SET
+    is_paid = false
 WHERE
     order_id = ?
 `
 
-func (q *Queries) UpdateOrderPayment(ctx context.Context, orderID int32) error {
-	_, err := q.db.ExecContext(ctx, updateOrderPayment, orderID)
+func (q *Queries) UpdateOrderUnpaid(ctx context.Context, orderID int32) error {
+	_, err := q.db.ExecContext(ctx, updateOrderUnpaid, orderID)
 	return err
 }
 
@@ -1150,6 +7195,142 @@ func (q *Queries) UpdateOrderUser(ctx context.Context, arg UpdateOrderUserParams
 	return err
 }
 
+const updatePaymentIntentProviderRef = `-- name: UpdatePaymentIntentProviderRef :exec
+UPDATE payment_intents
+SET
+    provider_ref = ?,
+    status = ?
+WHERE
+    intent_id = ?
+`
+
+type UpdatePaymentIntentProviderRefParams struct {
+	ProviderRef string
+	Status      string
+	IntentID    int32
+}
+
+func (q *Queries) UpdatePaymentIntentProviderRef(ctx context.Context, arg UpdatePaymentIntentProviderRefParams) error {
+	_, err := q.db.ExecContext(ctx, updatePaymentIntentProviderRef, arg.ProviderRef, arg.Status, arg.IntentID)
+	return err
+}
+
+const updateReservation = `-- name: UpdateReservation :exec
+UPDATE reservations
+SET
+    table_number = ?,
+    party_size = ?,
+    starts_at = ?,
+    ends_at = ?,
+    note = ?
+WHERE
+    reservation_id = ?
+`
+
+type UpdateReservationParams struct {
+	TableNumber   int32
+	PartySize     int32
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Note          string
+	ReservationID int32
+}
+
+func (q *Queries) UpdateReservation(ctx context.Context, arg UpdateReservationParams) error {
+	_, err := q.db.ExecContext(ctx, updateReservation,
+		arg.TableNumber,
+		arg.PartySize,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Note,
+		arg.ReservationID,
+	)
+	return err
+}
+
+const updateReservationDeposit = `-- name: UpdateReservationDeposit :exec
+UPDATE reservations
+SET
+    deposit_status = ?
+WHERE
+    reservation_id = ?
+`
+
+type UpdateReservationDepositParams struct {
+	DepositStatus string
+	ReservationID int32
+}
+
+func (q *Queries) UpdateReservationDeposit(ctx context.Context, arg UpdateReservationDepositParams) error {
+	_, err := q.db.ExecContext(ctx, updateReservationDeposit, arg.DepositStatus, arg.ReservationID)
+	return err
+}
+
+const updateRiderLocation = `-- name: UpdateRiderLocation :exec
+UPDATE riders
+SET
+    lat = ?,
+    lng = ?,
+    located_at = ?
+WHERE
+    user_id = ?
+`
+
+type UpdateRiderLocationParams struct {
+	Lat       sql.NullFloat64
+	Lng       sql.NullFloat64
+	LocatedAt sql.NullTime
+	UserID    int32
+}
+
+func (q *Queries) UpdateRiderLocation(ctx context.Context, arg UpdateRiderLocationParams) error {
+	_, err := q.db.ExecContext(ctx, updateRiderLocation,
+		arg.Lat,
+		arg.Lng,
+		arg.LocatedAt,
+		arg.UserID,
+	)
+	return err
+}
+
+const updateRiderStatus = `-- name: UpdateRiderStatus :exec
+UPDATE riders
+SET
+    status = ?,
+    status_at = ?
+WHERE
+    user_id = ?
+`
+
+type UpdateRiderStatusParams struct {
+	Status   string
+	StatusAt sql.NullTime
+	UserID   int32
+}
+
+func (q *Queries) UpdateRiderStatus(ctx context.Context, arg UpdateRiderStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateRiderStatus, arg.Status, arg.StatusAt, arg.UserID)
+	return err
+}
+
+const updateTipStatus = `-- name: UpdateTipStatus :exec
+UPDATE tips
+SET
+    status = ?
+WHERE
+    intent_id = ?
+`
+
+type UpdateTipStatusParams struct {
+	Status   string
+	IntentID sql.NullInt32
+}
+
+func (q *Queries) UpdateTipStatus(ctx context.Context, arg UpdateTipStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateTipStatus, arg.Status, arg.IntentID)
+	return err
+}
+
 const updateUserTagByID = `-- name: UpdateUserTagByID :exec
 UPDATE accounts
 SET
@@ -1167,3 +7348,125 @@ func (q *Queries) UpdateUserTagByID(ctx context.Context, arg UpdateUserTagByIDPa
 	_, err := q.db.ExecContext(ctx, updateUserTagByID, arg.UserTag, arg.ID)
 	return err
 }
+
+const upsertPrinter = `-- name: UpsertPrinter :exec
+INSERT INTO printers (name, station, address)
+VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    name = VALUES(name),
+    address = VALUES(address),
+    is_active = true
+`
+
+type UpsertPrinterParams struct {
+	Name    string
+	Station string
+	Address string
+}
+
+func (q *Queries) UpsertPrinter(ctx context.Context, arg UpsertPrinterParams) error {
+	_, err := q.db.ExecContext(ctx, upsertPrinter, arg.Name, arg.Station, arg.Address)
+	return err
+}
+
+const upsertRider = `-- name: UpsertRider :exec
+INSERT INTO riders (user_id)
+VALUES (?)
+ON DUPLICATE KEY UPDATE is_active = true
+`
+
+func (q *Queries) UpsertRider(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, upsertRider, userID)
+	return err
+}
+
+const useLoginChallenge = `-- name: UseLoginChallenge :exec
+UPDATE login_challenges
+SET
+    used_at = ?
+WHERE
+    challenge_id = ?
+`
+
+type UseLoginChallengeParams struct {
+	UsedAt      sql.NullTime
+	ChallengeID int32
+}
+
+func (q *Queries) UseLoginChallenge(ctx context.Context, arg UseLoginChallengeParams) error {
+	_, err := q.db.ExecContext(ctx, useLoginChallenge, arg.UsedAt, arg.ChallengeID)
+	return err
+}
+
+const usePasswordResets = `-- name: UsePasswordResets :exec
+UPDATE password_resets
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND used_at IS NULL
+`
+
+type UsePasswordResetsParams struct {
+	UsedAt sql.NullTime
+	UserID int32
+}
+
+func (q *Queries) UsePasswordResets(ctx context.Context, arg UsePasswordResetsParams) error {
+	_, err := q.db.ExecContext(ctx, usePasswordResets, arg.UsedAt, arg.UserID)
+	return err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND code_hash = ? AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UsedAt   sql.NullTime
+	UserID   int32
+	CodeHash string
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useRecoveryCode, arg.UsedAt, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const useVerifications = `-- name: UseVerifications :exec
+UPDATE verifications
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND channel = ? AND used_at IS NULL
+`
+
+type UseVerificationsParams struct {
+	UsedAt  sql.NullTime
+	UserID  int32
+	Channel string
+}
+
+func (q *Queries) UseVerifications(ctx context.Context, arg UseVerificationsParams) error {
+	_, err := q.db.ExecContext(ctx, useVerifications, arg.UsedAt, arg.UserID, arg.Channel)
+	return err
+}
+
+const voidGiftCard = `-- name: VoidGiftCard :exec
+UPDATE gift_cards
+SET
+    status = 'void',
+    balance = 0
+WHERE
+    card_id = ?
+`
+
+func (q *Queries) VoidGiftCard(ctx context.Context, cardID int32) error {
+	_, err := q.db.ExecContext(ctx, voidGiftCard, cardID)
+	return err
+}
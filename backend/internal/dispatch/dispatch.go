@@ -0,0 +1,84 @@
+// Package dispatch holds the rules for handing delivery orders to riders.
+package dispatch
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bryanthai/ordersystem/internal/geo"
+)
+
+// Rider statuses. A rider with an active delivery is busy whatever their
+// status says.
+const (
+	RiderOffline   = "offline"
+	RiderAvailable = "available"
+)
+
+// Delivery statuses
+const (
+	StatusAssigned  = "assigned"
+	StatusPickedUp  = "picked_up"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var ErrInvalidTransition = errors.New("invalid delivery status change")
+
+var transitions = map[string][]string{
+	StatusAssigned: {StatusPickedUp, StatusFailed, StatusCancelled},
+	StatusPickedUp: {StatusDelivered, StatusFailed},
+}
+
+// Transition checks that a delivery may move from one status to another.
+func Transition(from, to string) error {
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// Active reports whether a delivery in status still needs its rider.
+func Active(status string) bool {
+	return status == StatusAssigned || status == StatusPickedUp
+}
+
+// Candidate is a rider who could take a delivery.
+type Candidate struct {
+	RiderID        int32
+	Location       geo.Point
+	LocatedAt      time.Time // zero if the rider never sent a location
+	AvailableSince time.Time
+}
+
+// Nearest picks the candidate closest to origin. Riders whose last location
+// is older than maxAge are only used when nobody has a fresh location, in
+// which case whoever has been available longest goes first.
+func Nearest(candidates []Candidate, origin geo.Point, now time.Time, maxAge time.Duration) (Candidate, bool) {
+	var best Candidate
+	bestKm := -1.0
+	for _, c := range candidates {
+		if c.LocatedAt.IsZero() || now.Sub(c.LocatedAt) > maxAge {
+			continue
+		}
+		km := geo.DistanceKm(origin, c.Location)
+		if bestKm < 0 || km < bestKm || (km == bestKm && c.AvailableSince.Before(best.AvailableSince)) {
+			best, bestKm = c, km
+		}
+	}
+	if bestKm >= 0 {
+		return best, true
+	}
+
+	found := false
+	for _, c := range candidates {
+		if !found || c.AvailableSince.Before(best.AvailableSince) {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
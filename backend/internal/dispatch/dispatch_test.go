@@ -0,0 +1,79 @@
+package dispatch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bryanthai/ordersystem/internal/geo"
+)
+
+func TestTransition(t *testing.T) {
+	allowed := [][2]string{
+		{StatusAssigned, StatusPickedUp},
+		{StatusAssigned, StatusFailed},
+		{StatusAssigned, StatusCancelled},
+		{StatusPickedUp, StatusDelivered},
+		{StatusPickedUp, StatusFailed},
+	}
+	for _, tc := range allowed {
+		if err := Transition(tc[0], tc[1]); err != nil {
+			t.Errorf("Transition(%s, %s) = %v", tc[0], tc[1], err)
+		}
+	}
+
+	denied := [][2]string{
+		{StatusAssigned, StatusDelivered},
+		{StatusPickedUp, StatusCancelled},
+		{StatusDelivered, StatusFailed},
+		{StatusFailed, StatusPickedUp},
+		{StatusCancelled, StatusAssigned},
+	}
+	for _, tc := range denied {
+		if err := Transition(tc[0], tc[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Transition(%s, %s) = %v, want ErrInvalidTransition", tc[0], tc[1], err)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusAssigned:  true,
+		StatusPickedUp:  true,
+		StatusDelivered: false,
+		StatusFailed:    false,
+		StatusCancelled: false,
+	} {
+		if got := Active(status); got != want {
+			t.Errorf("Active(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	origin := geo.Point{Lat: 31.2990, Lng: 121.5010}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	maxAge := 10 * time.Minute
+
+	candidates := []Candidate{
+		{RiderID: 1, Location: geo.Point{Lat: 31.3300, Lng: 121.5010}, LocatedAt: now.Add(-time.Minute), AvailableSince: now.Add(-time.Hour)},
+		{RiderID: 2, Location: geo.Point{Lat: 31.3000, Lng: 121.5010}, LocatedAt: now.Add(-2 * time.Minute), AvailableSince: now.Add(-time.Minute)},
+		{RiderID: 3, Location: origin, LocatedAt: now.Add(-time.Hour), AvailableSince: now.Add(-2 * time.Hour)},
+		{RiderID: 4, AvailableSince: now.Add(-3 * time.Hour)},
+	}
+
+	got, ok := Nearest(candidates, origin, now, maxAge)
+	if !ok || got.RiderID != 2 {
+		t.Errorf("Nearest = %d, %v; want rider 2", got.RiderID, ok)
+	}
+
+	// Without fresh locations the longest available rider goes first
+	got, ok = Nearest(candidates[2:], origin, now, maxAge)
+	if !ok || got.RiderID != 4 {
+		t.Errorf("Nearest without fresh locations = %d, %v; want rider 4", got.RiderID, ok)
+	}
+
+	if _, ok := Nearest(nil, origin, now, maxAge); ok {
+		t.Error("Nearest with no candidates should find nobody")
+	}
+}
@@ -0,0 +1,105 @@
+package escpos
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ESC/POS control codes
+const (
+	esc = 0x1b
+	gs  = 0x1d
+)
+
+// Alignments for Align
+const (
+	Left   = 0
+	Center = 1
+	Right  = 2
+)
+
+// Builder assembles an ESC/POS print job. The zero value is ready to use.
+type Builder struct {
+	buf bytes.Buffer
+}
+
+// Init resets the printer to its default settings.
+func (b *Builder) Init() *Builder {
+	b.buf.Write([]byte{esc, '@'})
+	return b
+}
+
+// Align sets the alignment of the following lines.
+func (b *Builder) Align(alignment byte) *Builder {
+	b.buf.Write([]byte{esc, 'a', alignment})
+	return b
+}
+
+// Bold turns emphasised printing on or off.
+func (b *Builder) Bold(on bool) *Builder {
+	b.buf.Write([]byte{esc, 'E', flag(on)})
+	return b
+}
+
+// Large turns double width and height characters on or off.
+func (b *Builder) Large(on bool) *Builder {
+	size := byte(0x00)
+	if on {
+		size = 0x11
+	}
+	b.buf.Write([]byte{gs, '!', size})
+	return b
+}
+
+// Text prints s without a line break. Characters the printer's default code
+// page can't show are replaced with '?'.
+func (b *Builder) Text(s string) *Builder {
+	for _, c := range s {
+		switch {
+		case c == '\n':
+			b.buf.WriteByte('\n')
+		case c < 32 || c > 126:
+			b.buf.WriteByte('?')
+		default:
+			b.buf.WriteByte(byte(c))
+		}
+	}
+	return b
+}
+
+// Line prints s followed by a line break.
+func (b *Builder) Line(s string) *Builder {
+	return b.Text(s).Text("\n")
+}
+
+// Lines prints each line of a multi-line string.
+func (b *Builder) Lines(s string) *Builder {
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		b.Line(line)
+	}
+	return b
+}
+
+// Feed advances the paper by n lines.
+func (b *Builder) Feed(n byte) *Builder {
+	b.buf.Write([]byte{esc, 'd', n})
+	return b
+}
+
+// Cut feeds the paper past the cutter and makes a partial cut.
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{gs, 'V', 66, 0})
+	return b
+}
+
+// Bytes returns the job built so far.
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func flag(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
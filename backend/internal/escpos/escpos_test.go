@@ -0,0 +1,150 @@
+package escpos
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Bryanthai/ordersystem/internal/receipt"
+)
+
+func TestBuilder(t *testing.T) {
+	b := &Builder{}
+	got := b.Init().Align(Center).Bold(true).Line("Café").Cut().Bytes()
+	want := []byte{0x1b, '@', 0x1b, 'a', 1, 0x1b, 'E', 1, 'C', 'a', 'f', '?', '\n', 0x1d, 'V', 66, 0}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Builder produced % x, want % x", got, want)
+	}
+}
+
+func TestKitchenTicket(t *testing.T) {
+	ticket := KitchenTicket(Ticket{
+		OrderID:     17,
+		Station:     "grill",
+		OrderType:   "dine_in",
+		TableNumber: 4,
+		Notes:       "No onions\nAllergy: peanuts",
+		PlacedAt:    time.Date(2025, 1, 2, 12, 5, 0, 0, time.UTC),
+		Items: []TicketItem{
+			{Name: "Burger", Quantity: 2, Modifiers: []string{"extra cheese", "well done"}},
+		},
+	})
+	for _, want := range []string{"GRILL", "ORDER #17", "TABLE 4", "2 x Burger", "+ extra cheese", "+ well done", "Allergy: peanuts"} {
+		if !bytes.Contains(ticket, []byte(want)) {
+			t.Errorf("ticket is missing %q", want)
+		}
+	}
+	if !bytes.HasSuffix(ticket, []byte{0x1d, 'V', 66, 0}) {
+		t.Error("ticket does not end with a cut")
+	}
+	if bytes.Contains(ticket, []byte("DUE")) {
+		t.Error("ASAP ticket should not have a due time")
+	}
+
+	scheduled := KitchenTicket(Ticket{
+		OrderID:   18,
+		Station:   "grill",
+		OrderType: "takeaway",
+		PlacedAt:  time.Date(2025, 1, 2, 12, 5, 0, 0, time.UTC),
+		DueAt:     time.Date(2025, 1, 2, 18, 30, 0, 0, time.UTC),
+	})
+	if !bytes.Contains(scheduled, []byte("DUE 18:30")) {
+		t.Error("scheduled ticket is missing its due time")
+	}
+}
+
+func TestReceipt(t *testing.T) {
+	r := receipt.Receipt{
+		Business: receipt.Business{Name: "Noodle Bar"},
+		OrderID:  3,
+		Lines:    []receipt.Line{{Name: "Ramen", Quantity: 1, UnitPrice: 12}},
+		Subtotal: 12,
+		Total:    12,
+	}
+	job := Receipt(r)
+	if bytes.Count(job, []byte("Noodle Bar")) != 1 {
+		t.Error("business name should be printed once")
+	}
+	for _, want := range []string{"R-000003", "Ramen", "$12.00"} {
+		if !bytes.Contains(job, []byte(want)) {
+			t.Errorf("receipt is missing %q", want)
+		}
+	}
+}
+
+func TestMarkReprint(t *testing.T) {
+	job := KitchenTicket(Ticket{OrderID: 9, Station: "bar"})
+	reprint := MarkReprint(job)
+	if !bytes.HasSuffix(reprint, job) {
+		t.Error("reprint should contain the original job")
+	}
+	if !bytes.Contains(reprint[:len(reprint)-len(job)], []byte("*** REPRINT ***")) {
+		t.Error("reprint banner missing")
+	}
+}
+
+func TestSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	job := KitchenTicket(Ticket{OrderID: 1, Station: "kitchen", Items: []TicketItem{{Name: "Soup", Quantity: 1}}})
+	if err := Send(context.Background(), listener.Addr().String(), job, time.Second); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	select {
+	case data := <-received:
+		if !bytes.Equal(data, job) {
+			t.Error("printer received different bytes than were sent")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("printer received nothing")
+	}
+}
+
+func TestSend_PrinterOffline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	if err := Send(context.Background(), addr, []byte("x"), 200*time.Millisecond); err == nil {
+		t.Error("expected an error for an offline printer")
+	}
+}
+
+func TestAddress(t *testing.T) {
+	if got := Address("192.168.1.50"); got != "192.168.1.50:9100" {
+		t.Errorf("Address = %q", got)
+	}
+	if got := Address("printer.local:9101"); got != "printer.local:9101" {
+		t.Errorf("Address = %q", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 4: 80 * time.Second, 20: 10 * time.Minute}
+	for attempt, want := range cases {
+		if got := RetryDelay(attempt); got != want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
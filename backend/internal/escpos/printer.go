@@ -0,0 +1,51 @@
+package escpos
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DefaultPort is the raw printing port network printers listen on.
+const DefaultPort = "9100"
+
+// Address adds the default port to a printer address that has none.
+func Address(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, DefaultPort)
+}
+
+// Send writes a job to a network printer over raw TCP. Printers acknowledge
+// nothing, so a job counts as printed once all bytes are written.
+func Send(ctx context.Context, addr string, job []byte, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", Address(addr))
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(job); err != nil {
+		return fmt.Errorf("failed to send job to printer %s: %w", addr, err)
+	}
+	return nil
+}
+
+// RetryDelay is how long to wait before trying a failed job again. It doubles
+// with every attempt, from 10 seconds up to 10 minutes.
+func RetryDelay(attempt int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempt && delay < 10*time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > 10*time.Minute {
+		delay = 10 * time.Minute
+	}
+	return delay
+}
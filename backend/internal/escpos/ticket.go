@@ -0,0 +1,92 @@
+package escpos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Bryanthai/ordersystem/internal/receipt"
+)
+
+// TicketItem is one line on a kitchen ticket.
+type TicketItem struct {
+	Name      string
+	Quantity  int32
+	Modifiers []string
+}
+
+// Ticket is what a kitchen station needs to prepare its part of an order.
+type Ticket struct {
+	OrderID         int32
+	Station         string
+	OrderType       string
+	TableNumber     int32
+	DeliveryAddress string
+	Notes           string
+	PlacedAt        time.Time
+	DueAt           time.Time // zero unless the order was scheduled
+	Items           []TicketItem
+}
+
+// KitchenTicket renders a ticket in large type so it can be read from a
+// distance on the pass.
+func KitchenTicket(t Ticket) []byte {
+	b := &Builder{}
+	b.Init().Align(Center).Bold(true)
+	b.Line(strings.ToUpper(t.Station))
+	b.Large(true).Line(fmt.Sprintf("ORDER #%d", t.OrderID)).Large(false)
+	b.Bold(false).Line(t.PlacedAt.Format("2006-01-02 15:04")).Align(Left)
+
+	b.Bold(true)
+	switch {
+	case t.TableNumber > 0:
+		b.Large(true).Line(fmt.Sprintf("TABLE %d", t.TableNumber)).Large(false)
+	case t.OrderType != "":
+		b.Line(strings.ToUpper(strings.ReplaceAll(t.OrderType, "_", "-")))
+	}
+	if !t.DueAt.IsZero() {
+		b.Large(true).Line("DUE " + t.DueAt.Format("15:04")).Large(false)
+	}
+	b.Bold(false)
+	if t.DeliveryAddress != "" {
+		b.Line("Deliver to: " + t.DeliveryAddress)
+	}
+	b.Line(strings.Repeat("-", receipt.Width))
+
+	for _, item := range t.Items {
+		b.Large(true).Line(fmt.Sprintf("%d x %s", item.Quantity, item.Name)).Large(false)
+		for _, modifier := range item.Modifiers {
+			b.Line("   + " + modifier)
+		}
+	}
+
+	if t.Notes != "" {
+		b.Line(strings.Repeat("-", receipt.Width))
+		b.Bold(true).Line("NOTES:").Bold(false)
+		b.Lines(t.Notes)
+	}
+	return b.Feed(4).Cut().Bytes()
+}
+
+// Receipt renders a customer receipt using the same layout as the plain
+// text receipt, which is sized for 80mm paper.
+func Receipt(r receipt.Receipt) []byte {
+	b := &Builder{}
+	b.Init().Align(Center).Bold(true).Large(true).Line(r.Business.Name).Large(false).Bold(false)
+	b.Align(Left)
+	body := receipt.Text(r)
+	// The business name is already printed in large type
+	if first, rest, ok := strings.Cut(body, "\n"); ok && strings.TrimSpace(first) == r.Business.Name {
+		body = rest
+	}
+	b.Lines(body)
+	return b.Feed(4).Cut().Bytes()
+}
+
+// MarkReprint puts a banner above an already rendered job so a reprint can't
+// be mistaken for a new order.
+func MarkReprint(job []byte) []byte {
+	b := &Builder{}
+	b.Init().Align(Center).Bold(true).Line("*** REPRINT ***")
+	return append(b.Bytes(), job...)
+}
@@ -0,0 +1,50 @@
+// Package export packs the data kept about a customer into a download they
+// can take with them.
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidName = errors.New("invalid file name")
+
+// File is one part of an export, written as indented JSON
+type File struct {
+	Name string
+	Data any
+}
+
+// WriteJSON writes data as indented JSON
+func WriteJSON(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// WriteZIP writes files into a ZIP archive, in order, each named Name.json
+// and dated modified
+func WriteZIP(w io.Writer, files []File, modified time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		if f.Name == "" || strings.ContainsAny(f.Name, `/\`) || strings.Contains(f.Name, "..") {
+			return ErrInvalidName
+		}
+		part, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.Name + ".json",
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return err
+		}
+		if err := WriteJSON(part, f.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriteZIP(t *testing.T) {
+	var buf bytes.Buffer
+	files := []File{
+		{Name: "account", Data: map[string]string{"username": "alice"}},
+		{Name: "orders", Data: []int{1, 2}},
+	}
+	modified := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := WriteZIP(&buf, files, modified); err != nil {
+		t.Fatalf("WriteZIP: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "account.json" || zr.File[1].Name != "orders.json" {
+		t.Fatalf("unexpected files %v", zr.File)
+	}
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	var account map[string]string
+	if err := json.Unmarshal(b, &account); err != nil || account["username"] != "alice" {
+		t.Errorf("unexpected account.json %q", b)
+	}
+	if !zr.File[0].Modified.Equal(modified) {
+		t.Errorf("Modified = %s, want %s", zr.File[0].Modified, modified)
+	}
+}
+
+func TestWriteZIPRejectsPaths(t *testing.T) {
+	for _, name := range []string{"", "../account", "a/b", `a\b`} {
+		err := WriteZIP(io.Discard, []File{{Name: name, Data: 1}}, time.Now())
+		if !errors.Is(err, ErrInvalidName) {
+			t.Errorf("WriteZIP(%q) = %v, want ErrInvalidName", name, err)
+		}
+	}
+}
+
+func TestWriteJSONIndents(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if buf.String() != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
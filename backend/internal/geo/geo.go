@@ -0,0 +1,136 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const earthRadiusKm = 6371.0
+
+// Zone kinds
+const (
+	KindRadius  = "radius"
+	KindPolygon = "polygon"
+)
+
+var ErrInvalidZone = errors.New("invalid delivery zone")
+
+// Point is a position in decimal degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Valid reports whether p is a position on earth.
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// DistanceKm is the great circle distance between a and b.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// InPolygon reports whether p lies inside polygon, using the even-odd rule.
+// Zones are small enough that treating degrees as flat coordinates is fine.
+func InPolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Zone is an area the restaurant delivers to. Radius zones are measured from
+// the restaurant.
+type Zone struct {
+	ID       int32
+	Name     string
+	Kind     string
+	RadiusKm float64
+	Polygon  []Point
+	BaseFee  float64
+	FeePerKm float64
+	MinOrder float64
+}
+
+// Validate checks that the zone describes an area.
+func (z Zone) Validate() error {
+	switch z.Kind {
+	case KindRadius:
+		if z.RadiusKm <= 0 {
+			return fmt.Errorf("%w: radius_km must be positive", ErrInvalidZone)
+		}
+	case KindPolygon:
+		if len(z.Polygon) < 3 {
+			return fmt.Errorf("%w: a polygon needs at least 3 points", ErrInvalidZone)
+		}
+		for _, p := range z.Polygon {
+			if !p.Valid() {
+				return fmt.Errorf("%w: polygon point out of range", ErrInvalidZone)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: kind must be radius or polygon", ErrInvalidZone)
+	}
+	if z.BaseFee < 0 || z.FeePerKm < 0 || z.MinOrder < 0 {
+		return fmt.Errorf("%w: fees and minimum order can't be negative", ErrInvalidZone)
+	}
+	return nil
+}
+
+// Contains reports whether p is inside the zone of a restaurant at origin.
+func (z Zone) Contains(origin, p Point) bool {
+	switch z.Kind {
+	case KindRadius:
+		return DistanceKm(origin, p) <= z.RadiusKm
+	case KindPolygon:
+		return InPolygon(p, z.Polygon)
+	}
+	return false
+}
+
+// Fee is the delivery fee for a drop-off distanceKm from the restaurant,
+// rounded to the cent.
+func (z Zone) Fee(distanceKm float64) float64 {
+	return math.Round((z.BaseFee+z.FeePerKm*distanceKm)*100) / 100
+}
+
+// Quote is the delivery terms for one address.
+type Quote struct {
+	Zone       Zone
+	DistanceKm float64
+	Fee        float64
+}
+
+// Match finds the zones containing p and returns the one with the lowest fee,
+// so overlapping zones always work in the customer's favour.
+func Match(zones []Zone, origin, p Point) (Quote, bool) {
+	distance := DistanceKm(origin, p)
+	var best Quote
+	found := false
+	for _, z := range zones {
+		if !z.Contains(origin, p) {
+			continue
+		}
+		fee := z.Fee(distance)
+		if !found || fee < best.Fee {
+			best = Quote{Zone: z, DistanceKm: math.Round(distance*100) / 100, Fee: fee}
+			found = true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,143 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var origin = Point{Lat: 31.2990, Lng: 121.5010}
+
+func TestDistanceKm(t *testing.T) {
+	// The Bund to Pudong airport is about 32km in a straight line
+	got := DistanceKm(Point{31.2400, 121.4900}, Point{31.1443, 121.8083})
+	if math.Abs(got-32.0) > 1.0 {
+		t.Errorf("DistanceKm = %.2f, want about 32", got)
+	}
+	if DistanceKm(origin, origin) != 0 {
+		t.Error("distance to itself should be 0")
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	square := []Point{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	if !InPolygon(Point{0.5, 0.5}, square) {
+		t.Error("centre should be inside")
+	}
+	if InPolygon(Point{1.5, 0.5}, square) {
+		t.Error("point to the side should be outside")
+	}
+	// A U shape with a notch between the arms
+	u := []Point{{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}}
+	if InPolygon(Point{1.5, 2}, u) {
+		t.Error("point in the notch should be outside")
+	}
+	if !InPolygon(Point{0.5, 2}, u) {
+		t.Error("point in an arm should be inside")
+	}
+}
+
+func TestZoneValidate(t *testing.T) {
+	valid := []Zone{
+		{Kind: KindRadius, RadiusKm: 3},
+		{Kind: KindPolygon, Polygon: []Point{{0, 0}, {0, 1}, {1, 1}}},
+	}
+	for _, z := range valid {
+		if err := z.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", z, err)
+		}
+	}
+	invalid := []Zone{
+		{Kind: KindRadius},
+		{Kind: KindPolygon, Polygon: []Point{{0, 0}, {0, 1}}},
+		{Kind: KindPolygon, Polygon: []Point{{0, 0}, {0, 1}, {91, 1}}},
+		{Kind: KindRadius, RadiusKm: 3, BaseFee: -1},
+		{Kind: "circle", RadiusKm: 3},
+	}
+	for _, z := range invalid {
+		if err := z.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", z)
+		}
+	}
+}
+
+func TestMatch_PicksCheapestZone(t *testing.T) {
+	near := Point{Lat: origin.Lat + 0.01, Lng: origin.Lng}
+	far := Point{Lat: origin.Lat + 0.08, Lng: origin.Lng}
+	zones := []Zone{
+		{ID: 1, Kind: KindRadius, RadiusKm: 10, BaseFee: 6, FeePerKm: 0.5},
+		{ID: 2, Kind: KindRadius, RadiusKm: 3, BaseFee: 2},
+	}
+
+	quote, ok := Match(zones, origin, near)
+	if !ok || quote.Zone.ID != 2 || quote.Fee != 2 {
+		t.Errorf("near address got zone %d fee %v, want zone 2 fee 2", quote.Zone.ID, quote.Fee)
+	}
+	quote, ok = Match(zones, origin, far)
+	if !ok || quote.Zone.ID != 1 {
+		t.Fatalf("far address got zone %d, want zone 1", quote.Zone.ID)
+	}
+	if want := zones[0].Fee(DistanceKm(origin, far)); quote.Fee != want {
+		t.Errorf("far address fee = %v, want %v", quote.Fee, want)
+	}
+	if _, ok := Match(zones, origin, Point{Lat: origin.Lat + 1, Lng: origin.Lng}); ok {
+		t.Error("address 100km away should be out of every zone")
+	}
+}
+
+func TestStubGeocoder(t *testing.T) {
+	g := StubGeocoder{Origin: origin, RadiusKm: 5, Places: map[string]Point{"fudan university": origin}}
+	ctx := context.Background()
+
+	if p, err := g.Geocode(ctx, "31.25, 121.45"); err != nil || p != (Point{31.25, 121.45}) {
+		t.Errorf("literal coordinates = %v, %v", p, err)
+	}
+	if p, err := g.Geocode(ctx, "Fudan University"); err != nil || p != origin {
+		t.Errorf("known place = %v, %v", p, err)
+	}
+	a, _ := g.Geocode(ctx, "220 Handan Road")
+	b, _ := g.Geocode(ctx, "220 handan road")
+	if a != b {
+		t.Error("the same address should always land on the same point")
+	}
+	if d := DistanceKm(origin, a); d > 5.01 {
+		t.Errorf("made-up point is %.2fkm away, want at most 5", d)
+	}
+	if _, err := g.Geocode(ctx, "  "); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("empty address error = %v", err)
+	}
+}
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "ordersystem-test" {
+			t.Error("User-Agent not sent")
+		}
+		switch r.URL.Query().Get("q") {
+		case "1 Main Street":
+			w.Write([]byte(`[{"lat":"31.2304","lon":"121.4737","display_name":"1 Main Street"}]`))
+		case "broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	g := NominatimGeocoder{BaseURL: server.URL, UserAgent: "ordersystem-test", Client: server.Client()}
+	ctx := context.Background()
+
+	p, err := g.Geocode(ctx, "1 Main Street")
+	if err != nil || p != (Point{31.2304, 121.4737}) {
+		t.Errorf("Geocode = %v, %v", p, err)
+	}
+	if _, err := g.Geocode(ctx, "Nowhere"); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("unknown address error = %v", err)
+	}
+	if _, err := g.Geocode(ctx, "broken"); err == nil || errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("server error = %v, want a request failure", err)
+	}
+}
@@ -0,0 +1,117 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+// Geocoder turns a postal address into coordinates.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Point, error)
+}
+
+// ParsePoint reads an address written as "lat,lng".
+func ParsePoint(s string) (Point, bool) {
+	latStr, lngStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lng, err2 := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	p := Point{Lat: lat, Lng: lng}
+	if err1 != nil || err2 != nil || !p.Valid() {
+		return Point{}, false
+	}
+	return p, true
+}
+
+// StubGeocoder works offline for development and tests. Addresses written as
+// "lat,lng" are taken literally, known places are looked up, and anything
+// else lands on a made-up but stable point within RadiusKm of Origin.
+type StubGeocoder struct {
+	Origin   Point
+	RadiusKm float64
+	Places   map[string]Point
+}
+
+func (g StubGeocoder) Geocode(ctx context.Context, address string) (Point, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return Point{}, ErrAddressNotFound
+	}
+	if p, ok := ParsePoint(address); ok {
+		return p, nil
+	}
+	if p, ok := g.Places[strings.ToLower(address)]; ok {
+		return p, nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(address)))
+	sum := h.Sum64()
+	bearing := float64(sum%3600) / 3600 * 2 * math.Pi
+	distance := float64(sum>>32%1000) / 1000 * g.RadiusKm
+	return Point{
+		Lat: g.Origin.Lat + distance/111.32*math.Cos(bearing),
+		Lng: g.Origin.Lng + distance/(111.32*math.Cos(radians(g.Origin.Lat)))*math.Sin(bearing),
+	}, nil
+}
+
+// NominatimGeocoder looks addresses up with an OpenStreetMap Nominatim
+// server. The public server requires an identifying UserAgent.
+type NominatimGeocoder struct {
+	BaseURL   string
+	UserAgent string
+	Client    *http.Client
+}
+
+func (g NominatimGeocoder) Geocode(ctx context.Context, address string) (Point, error) {
+	if p, ok := ParsePoint(address); ok {
+		return p, nil
+	}
+	query := url.Values{"q": {address}, "format": {"jsonv2"}, "limit": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(g.BaseURL, "/")+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return Point{}, err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Point{}, fmt.Errorf("geocoding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Point{}, fmt.Errorf("geocoding request failed: %s", resp.Status)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Point{}, fmt.Errorf("invalid geocoding response: %w", err)
+	}
+	if len(results) == 0 {
+		return Point{}, ErrAddressNotFound
+	}
+	p, ok := ParsePoint(results[0].Lat + "," + results[0].Lon)
+	if !ok {
+		return Point{}, fmt.Errorf("invalid geocoding response: bad coordinates %q, %q", results[0].Lat, results[0].Lon)
+	}
+	return p, nil
+}
@@ -0,0 +1,145 @@
+package giftcard
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Card statuses
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+	StatusVoid    = "void"
+)
+
+// Kinds of gift card transactions
+const (
+	TxIssue    = "issue"
+	TxPurchase = "purchase"
+	TxRedeem   = "redeem"
+	TxVoid     = "void"
+)
+
+// Codes leave out 0, 1, I and O so they can be read out and typed back.
+const (
+	alphabet   = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	codeLength = 16
+	groupSize  = 4
+)
+
+// Limits on the value of a single card. A full card must fit in a wallet,
+// which holds at most 999.99.
+const (
+	MinAmount = 1
+	MaxAmount = 500
+)
+
+var (
+	ErrInvalidCode   = errors.New("invalid gift card code")
+	ErrInvalidAmount = errors.New("invalid gift card amount")
+	ErrNotActive     = errors.New("gift card is not active")
+	ErrExpired       = errors.New("gift card has expired")
+	ErrNoBalance     = errors.New("gift card has no balance left")
+	ErrOverdrawn     = errors.New("amount exceeds the gift card balance")
+)
+
+// Generate returns a new random code such as "7KQX-M2PD-9HTA-R4WE". It
+// carries 80 bits of randomness, so collisions are not worth checking for.
+func Generate(r io.Reader) (string, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	buf := make([]byte, codeLength)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("failed to generate gift card code: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return format(string(buf)), nil
+}
+
+// Normalize accepts a code the way a customer types it, in any case and with
+// or without dashes and spaces, and returns it in the stored form.
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != codeLength {
+		return "", ErrInvalidCode
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(alphabet, c) {
+			return "", ErrInvalidCode
+		}
+	}
+	return format(code), nil
+}
+
+func format(code string) string {
+	groups := make([]string, 0, codeLength/groupSize)
+	for i := 0; i < len(code); i += groupSize {
+		groups = append(groups, code[i:i+groupSize])
+	}
+	return strings.Join(groups, "-")
+}
+
+// Mask hides all but the last group of a code for logs and listings.
+func Mask(code string) string {
+	if len(code) <= groupSize {
+		return code
+	}
+	masked := []byte(code)
+	for i := 0; i < len(masked)-groupSize; i++ {
+		if masked[i] != '-' {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
+
+// CheckAmount validates the value of a new card.
+func CheckAmount(amount float64) error {
+	if amount < MinAmount || amount > MaxAmount {
+		return fmt.Errorf("%w: must be between %d and %d", ErrInvalidAmount, MinAmount, MaxAmount)
+	}
+	return nil
+}
+
+// RedeemAmount checks that a card can be redeemed at now and returns how much
+// to move to the wallet. A requested amount of zero redeems the whole
+// balance. expiresAt is the zero time for cards that never expire.
+func RedeemAmount(status string, balance float64, expiresAt, now time.Time, requested float64) (float64, error) {
+	if status != StatusActive {
+		return 0, ErrNotActive
+	}
+	if !expiresAt.IsZero() && !now.Before(expiresAt) {
+		return 0, ErrExpired
+	}
+	balanceCents := toCents(balance)
+	if balanceCents <= 0 {
+		return 0, ErrNoBalance
+	}
+	if requested < 0 {
+		return 0, ErrInvalidAmount
+	}
+	if requested == 0 {
+		return float64(balanceCents) / 100, nil
+	}
+	requestedCents := toCents(requested)
+	if requestedCents <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	if requestedCents > balanceCents {
+		return 0, ErrOverdrawn
+	}
+	return float64(requestedCents) / 100, nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
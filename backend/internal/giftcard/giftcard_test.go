@@ -0,0 +1,90 @@
+package giftcard
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerate_FormatAndRoundTrip(t *testing.T) {
+	code, err := Generate(nil)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(code) != 19 || code[4] != '-' || code[9] != '-' || code[14] != '-' {
+		t.Fatalf("Generate returned malformed code %q", code)
+	}
+	normalized, err := Normalize(code)
+	if err != nil || normalized != code {
+		t.Errorf("Normalize(%q) = %q, %v", code, normalized, err)
+	}
+}
+
+func TestGenerate_ShortReader(t *testing.T) {
+	if _, err := Generate(bytes.NewReader([]byte{1, 2, 3})); err == nil {
+		t.Error("expected an error when randomness runs out")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(" 7kqx m2pd-9hta r4we ")
+	if err != nil || got != "7KQX-M2PD-9HTA-R4WE" {
+		t.Errorf("Normalize = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "7KQX-M2PD-9HTA", "7KQX-M2PD-9HTA-R4W0", "7KQX-M2PD-9HTA-R4WEX"} {
+		if _, err := Normalize(bad); !errors.Is(err, ErrInvalidCode) {
+			t.Errorf("Normalize(%q) error = %v, want ErrInvalidCode", bad, err)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	if got := Mask("7KQX-M2PD-9HTA-R4WE"); got != "****-****-****-R4WE" {
+		t.Errorf("Mask = %q", got)
+	}
+}
+
+func TestRedeemAmount(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	if got, err := RedeemAmount(StatusActive, 25, later, now, 0); err != nil || got != 25 {
+		t.Errorf("full redemption = %v, %v", got, err)
+	}
+	if got, err := RedeemAmount(StatusActive, 25, time.Time{}, now, 10.5); err != nil || got != 10.5 {
+		t.Errorf("partial redemption = %v, %v", got, err)
+	}
+
+	cases := []struct {
+		name      string
+		status    string
+		balance   float64
+		expiresAt time.Time
+		requested float64
+		want      error
+	}{
+		{"pending", StatusPending, 25, later, 0, ErrNotActive},
+		{"void", StatusVoid, 25, later, 0, ErrNotActive},
+		{"expired", StatusActive, 25, now, 0, ErrExpired},
+		{"empty", StatusActive, 0, later, 0, ErrNoBalance},
+		{"negative", StatusActive, 25, later, -5, ErrInvalidAmount},
+		{"overdrawn", StatusActive, 25, later, 25.01, ErrOverdrawn},
+	}
+	for _, c := range cases {
+		if _, err := RedeemAmount(c.status, c.balance, c.expiresAt, now, c.requested); !errors.Is(err, c.want) {
+			t.Errorf("%s: error = %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestCheckAmount(t *testing.T) {
+	if err := CheckAmount(50); err != nil {
+		t.Errorf("CheckAmount(50) = %v", err)
+	}
+	for _, bad := range []float64{0, 0.5, 500.01, -20} {
+		if err := CheckAmount(bad); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("CheckAmount(%v) error = %v, want ErrInvalidAmount", bad, err)
+		}
+	}
+}
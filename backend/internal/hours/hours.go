@@ -0,0 +1,141 @@
+// Package hours works out when the store is open from its weekly opening
+// hours and the dates that differ from them.
+package hours
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrInvalidPeriod = errors.New("invalid opening hours")
+
+// lookAhead is how far NextOpen searches before giving up.
+const lookAhead = 366
+
+// Period is one stretch of opening on a day, as time since midnight. A
+// period running past midnight closes after 24h.
+type Period struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// ParsePeriod reads a period written as "10:00-22:00". A closing time
+// before the opening time, as in "18:00-02:00", is on the next day.
+func ParsePeriod(s string) (Period, error) {
+	var oh, om, ch, cm int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &oh, &om, &ch, &cm); err != nil {
+		return Period{}, fmt.Errorf("%w: %q", ErrInvalidPeriod, s)
+	}
+	if oh < 0 || oh > 23 || ch < 0 || ch > 24 || om < 0 || om > 59 || cm < 0 || cm > 59 || (ch == 24 && cm != 0) {
+		return Period{}, fmt.Errorf("%w: %q", ErrInvalidPeriod, s)
+	}
+	p := Period{
+		Open:  time.Duration(oh)*time.Hour + time.Duration(om)*time.Minute,
+		Close: time.Duration(ch)*time.Hour + time.Duration(cm)*time.Minute,
+	}
+	if p.Close == p.Open {
+		return Period{}, fmt.Errorf("%w: %q", ErrInvalidPeriod, s)
+	}
+	if p.Close < p.Open {
+		p.Close += 24 * time.Hour
+	}
+	return p, nil
+}
+
+// String writes p back the way ParsePeriod reads it.
+func (p Period) String() string {
+	close := p.Close % (24 * time.Hour)
+	if p.Close == 24*time.Hour {
+		close = p.Close
+	}
+	return clock(p.Open) + "-" + clock(close)
+}
+
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// Calendar holds the opening hours of one order type. A calendar without
+// weekly hours is open around the clock, apart from its exceptions.
+type Calendar struct {
+	Week       map[time.Weekday][]Period
+	Exceptions map[string][]Period // by "2006-01-02"; no periods is closed all day
+}
+
+// Periods are the opening periods starting on the day of date.
+func (c Calendar) Periods(date time.Time) []Period {
+	var periods []Period
+	if exception, ok := c.Exceptions[date.Format(time.DateOnly)]; ok {
+		periods = exception
+	} else if c.Week == nil {
+		periods = []Period{{Open: 0, Close: 24 * time.Hour}}
+	} else {
+		periods = c.Week[date.Weekday()]
+	}
+	sorted := append([]Period(nil), periods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Open < sorted[j].Open })
+	return sorted
+}
+
+// window is a period placed on a particular day.
+type window struct {
+	start, end time.Time
+}
+
+// windows lists the opening windows starting on the days from..from+days,
+// read in from's location.
+func (c Calendar) windows(from time.Time, days int) []window {
+	y, m, d := from.Date()
+	var out []window
+	for i := 0; i < days; i++ {
+		day := time.Date(y, m, d+i, 0, 0, 0, 0, from.Location())
+		for _, p := range c.Periods(day) {
+			// Wall clock times, so daylight saving days keep their hours
+			out = append(out, window{
+				start: time.Date(y, m, d+i, 0, int(p.Open/time.Minute), 0, 0, from.Location()),
+				end:   time.Date(y, m, d+i, 0, int(p.Close/time.Minute), 0, 0, from.Location()),
+			})
+		}
+	}
+	return out
+}
+
+// IsOpen reports whether the store is open at t, read in t's location.
+func (c Calendar) IsOpen(t time.Time) bool {
+	// Periods from the day before can run past midnight
+	for _, w := range c.windows(t.AddDate(0, 0, -1), 2) {
+		if !t.Before(w.start) && t.Before(w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen is the first time at or after t that the store is open, and when
+// it closes again. It reports false if the store stays closed for a year.
+func (c Calendar) NextOpen(t time.Time) (opens, closes time.Time, ok bool) {
+	windows := c.windows(t.AddDate(0, 0, -1), lookAhead+1)
+	for i, w := range windows {
+		if !t.Before(w.end) {
+			continue
+		}
+		opens, closes = w.start, w.end
+		if opens.Before(t) {
+			opens = t
+		}
+		// Back to back periods, like a day open until midnight followed by
+		// one opening at midnight, are one stretch of opening
+		for _, next := range windows[i+1:] {
+			if next.start.After(closes) {
+				break
+			}
+			if next.end.After(closes) {
+				closes = next.end
+			}
+		}
+		return opens, closes, true
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,120 @@
+package hours
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustPeriod(t *testing.T, s string) Period {
+	t.Helper()
+	p, err := ParsePeriod(s)
+	if err != nil {
+		t.Fatalf("ParsePeriod(%q): %v", s, err)
+	}
+	return p
+}
+
+func TestParsePeriod(t *testing.T) {
+	p := mustPeriod(t, "10:30-22:00")
+	if p.Open != 10*time.Hour+30*time.Minute || p.Close != 22*time.Hour {
+		t.Errorf("ParsePeriod = %+v", p)
+	}
+	if p.String() != "10:30-22:00" {
+		t.Errorf("String = %q", p.String())
+	}
+	overnight := mustPeriod(t, "18:00-02:00")
+	if overnight.Close != 26*time.Hour || overnight.String() != "18:00-02:00" {
+		t.Errorf("overnight period = %+v %q", overnight, overnight.String())
+	}
+	if mustPeriod(t, "00:00-24:00").String() != "00:00-24:00" {
+		t.Error("a whole day should write back as 00:00-24:00")
+	}
+	for _, bad := range []string{"", "10:00", "10:00-10:00", "25:00-26:00", "10:60-11:00", "10:00-24:30"} {
+		if _, err := ParsePeriod(bad); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ParsePeriod(%q) = %v, want ErrInvalidPeriod", bad, err)
+		}
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	loc := time.FixedZone("store", 8*3600)
+	c := Calendar{
+		Week: map[time.Weekday][]Period{
+			time.Monday: {mustPeriod(t, "17:00-22:00"), mustPeriod(t, "11:00-14:00")},
+			time.Friday: {mustPeriod(t, "18:00-02:00")},
+		},
+		Exceptions: map[string][]Period{
+			"2025-06-09": nil, // a closed Monday
+		},
+	}
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 6, day, hour, min, 0, 0, loc) }
+	cases := []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(2, 11, 0), true},  // Monday lunch opens
+		{at(2, 14, 0), false}, // and closes
+		{at(2, 18, 30), true}, // Monday dinner
+		{at(3, 12, 0), false}, // no hours on Tuesday
+		{at(6, 23, 0), true},  // Friday night
+		{at(7, 1, 59), true},  // runs into Saturday
+		{at(7, 2, 0), false},  // until 2am
+		{at(9, 12, 0), false}, // holiday
+	}
+	for _, c2 := range cases {
+		if got := c.IsOpen(c2.t); got != c2.want {
+			t.Errorf("IsOpen(%s) = %v, want %v", c2.t.Format("Mon 15:04"), got, c2.want)
+		}
+	}
+
+	if !(Calendar{}).IsOpen(at(3, 4, 0)) {
+		t.Error("a calendar without hours should always be open")
+	}
+	closed := Calendar{Exceptions: map[string][]Period{"2025-06-03": nil}}
+	if closed.IsOpen(at(3, 4, 0)) || !closed.IsOpen(at(4, 4, 0)) {
+		t.Error("exceptions should apply to a calendar without hours")
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	loc := time.FixedZone("store", 8*3600)
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 6, day, hour, min, 0, 0, loc) }
+	c := Calendar{
+		Week: map[time.Weekday][]Period{
+			time.Monday:  {mustPeriod(t, "11:00-14:00")},
+			time.Tuesday: {mustPeriod(t, "11:00-14:00")},
+		},
+		Exceptions: map[string][]Period{"2025-06-03": {mustPeriod(t, "12:00-13:00")}},
+	}
+
+	opens, closes, ok := c.NextOpen(at(2, 9, 0))
+	if !ok || !opens.Equal(at(2, 11, 0)) || !closes.Equal(at(2, 14, 0)) {
+		t.Errorf("NextOpen before opening = %v %v %v", opens, closes, ok)
+	}
+	opens, closes, ok = c.NextOpen(at(2, 12, 0))
+	if !ok || !opens.Equal(at(2, 12, 0)) || !closes.Equal(at(2, 14, 0)) {
+		t.Errorf("NextOpen while open = %v %v %v", opens, closes, ok)
+	}
+	opens, _, ok = c.NextOpen(at(2, 15, 0))
+	if !ok || !opens.Equal(at(3, 12, 0)) {
+		t.Errorf("NextOpen should use the exception's hours, got %v %v", opens, ok)
+	}
+	opens, _, ok = c.NextOpen(at(3, 14, 0))
+	if !ok || !opens.Equal(at(9, 11, 0)) {
+		t.Errorf("NextOpen should skip to next week, got %v %v", opens, ok)
+	}
+
+	allDay := Calendar{Week: map[time.Weekday][]Period{
+		time.Monday:  {mustPeriod(t, "00:00-24:00")},
+		time.Tuesday: {mustPeriod(t, "00:00-06:00")},
+	}}
+	_, closes, _ = allDay.NextOpen(at(2, 10, 0))
+	if !closes.Equal(at(3, 6, 0)) {
+		t.Errorf("back to back periods should join, closes = %v", closes)
+	}
+
+	if _, _, ok := (Calendar{Week: map[time.Weekday][]Period{}}).NextOpen(at(2, 9, 0)); ok {
+		t.Error("a store without any hours should never open")
+	}
+}
@@ -0,0 +1,191 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Header is the request header clients use to mark retries of one operation.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses served from a stored result.
+const ReplayedHeader = "Idempotent-Replayed"
+
+const maxKeyLength = 255
+
+// Record statuses
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+)
+
+// Record is the stored state of one idempotency key.
+type Record struct {
+	UserID       int32
+	Key          string
+	RequestHash  string
+	Status       string
+	ResponseCode int
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
+
+// Store persists idempotency keys per user.
+//
+// Reserve atomically claims a key, first forgetting any records that expired
+// before now. If the key is already held it returns the existing record and
+// false, so that exactly one of several concurrent requests wins.
+type Store interface {
+	Reserve(ctx context.Context, rec Record, now time.Time) (Record, bool, error)
+	Complete(ctx context.Context, rec Record) error
+	Release(ctx context.Context, userID int32, key string) error
+}
+
+// Middleware replays the first response for a repeated Idempotency-Key.
+type Middleware struct {
+	Store  Store
+	Window time.Duration
+
+	// UserID identifies the caller; requests it rejects are passed through
+	// untouched so the handler can answer them.
+	UserID func(req *http.Request) (int32, bool)
+
+	// Now is overridable in tests; defaults to time.Now.
+	Now func() time.Time
+}
+
+func (m *Middleware) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Wrap applies idempotency handling to next. Requests without the header are
+// served as usual.
+func (m *Middleware) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(Header)
+		if key == "" || req.Method == "OPTIONS" {
+			next(writer, req)
+			return
+		}
+		if len(key) > maxKeyLength {
+			http.Error(writer, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+		userID, ok := m.UserID(req)
+		if !ok {
+			next(writer, req)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(writer, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := m.now()
+		rec := Record{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hashRequest(req, body),
+			Status:      StatusInProgress,
+			ExpiresAt:   now.Add(m.Window),
+		}
+		existing, acquired, err := m.Store.Reserve(req.Context(), rec, now)
+		if err != nil {
+			log.Println("Error reserving idempotency key:", err)
+			http.Error(writer, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if !acquired {
+			replay(writer, rec, existing)
+			return
+		}
+
+		recorder := &recorder{ResponseWriter: writer, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				// Let the client retry after a failure or panic
+				if err := m.Store.Release(context.Background(), userID, key); err != nil {
+					log.Println("Error releasing idempotency key:", err)
+				}
+			}
+		}()
+
+		next(recorder, req)
+
+		if recorder.status >= http.StatusInternalServerError {
+			return
+		}
+		rec.Status = StatusCompleted
+		rec.ResponseCode = recorder.status
+		rec.ContentType = recorder.Header().Get("Content-Type")
+		rec.ResponseBody = recorder.body.Bytes()
+		if err := m.Store.Complete(context.Background(), rec); err != nil {
+			log.Println("Error storing idempotent response:", err)
+			return
+		}
+		completed = true
+	}
+}
+
+func replay(writer http.ResponseWriter, rec, existing Record) {
+	if existing.RequestHash != rec.RequestHash {
+		http.Error(writer, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if existing.Status != StatusCompleted {
+		writer.Header().Set("Retry-After", "1")
+		http.Error(writer, "A request with this Idempotency-Key is still being processed", http.StatusConflict)
+		return
+	}
+	if existing.ContentType != "" {
+		writer.Header().Set("Content-Type", existing.ContentType)
+	}
+	writer.Header().Set(ReplayedHeader, "true")
+	writer.WriteHeader(existing.ResponseCode)
+	writer.Write(existing.ResponseBody)
+}
+
+func hashRequest(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write([]byte(strconv.Itoa(len(body)) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes a response through while keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
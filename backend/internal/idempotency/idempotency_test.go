@@ -0,0 +1,180 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]Record)}
+}
+
+func storeKey(userID int32, key string) string {
+	return fmt.Sprintf("%d|%s", userID, key)
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, rec Record, now time.Time) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := storeKey(rec.UserID, rec.Key)
+	if existing, ok := s.records[k]; ok && existing.ExpiresAt.After(now) {
+		return existing, false, nil
+	}
+	s.records[k] = rec
+	return rec, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[storeKey(rec.UserID, rec.Key)] = rec
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, userID int32, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, storeKey(userID, key))
+	return nil
+}
+
+func newMiddleware(store Store) *Middleware {
+	return &Middleware{
+		Store:  store,
+		Window: time.Hour,
+		UserID: func(req *http.Request) (int32, bool) {
+			return 1, req.Header.Get("Authorization") != ""
+		},
+	}
+}
+
+func send(handler http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestWrap_ReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	handler := newMiddleware(newMemoryStore()).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"order_id":42}`))
+	})
+
+	first := send(handler, "abc", `{"x":1}`)
+	second := send(handler, "abc", `{"x":1}`)
+
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get(ReplayedHeader) != "true" {
+		t.Error("expected replayed response to be marked")
+	}
+	if second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected content type to be replayed, got %q", second.Header().Get("Content-Type"))
+	}
+}
+
+func TestWrap_WithoutKeyAlwaysRuns(t *testing.T) {
+	calls := 0
+	handler := newMiddleware(newMemoryStore()).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	send(handler, "", `{}`)
+	send(handler, "", `{}`)
+	if calls != 2 {
+		t.Errorf("expected handler to run twice, ran %d times", calls)
+	}
+}
+
+func TestWrap_RejectsDifferentRequestWithSameKey(t *testing.T) {
+	handler := newMiddleware(newMemoryStore()).Wrap(func(w http.ResponseWriter, r *http.Request) {})
+	send(handler, "abc", `{"amount":10}`)
+	resp := send(handler, "abc", `{"amount":20}`)
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+}
+
+func TestWrap_ConcurrentDuplicateGetsConflict(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := newMiddleware(newMemoryStore()).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send(handler, "abc", `{}`) }()
+	<-entered
+
+	dup := send(handler, "abc", `{}`)
+	if dup.Code != http.StatusConflict {
+		t.Errorf("expected status %d for in-flight duplicate, got %d", http.StatusConflict, dup.Code)
+	}
+	close(release)
+	if first := <-done; first.Body.String() != "done" {
+		t.Errorf("expected first request to complete, got %q", first.Body.String())
+	}
+
+	replayed := send(handler, "abc", `{}`)
+	if replayed.Body.String() != "done" {
+		t.Errorf("expected completed response to be replayed, got %q", replayed.Body.String())
+	}
+}
+
+func TestWrap_ServerErrorReleasesKey(t *testing.T) {
+	calls := 0
+	handler := newMiddleware(newMemoryStore()).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	send(handler, "abc", `{}`)
+	resp := send(handler, "abc", `{}`)
+	if calls != 2 || resp.Body.String() != "ok" {
+		t.Errorf("expected retry after server error to run handler again, calls=%d body=%q", calls, resp.Body.String())
+	}
+}
+
+func TestWrap_ExpiredKeyRunsAgain(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := newMiddleware(newMemoryStore())
+	m.Now = func() time.Time { return now }
+	calls := 0
+	handler := m.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	send(handler, "abc", `{}`)
+	now = now.Add(2 * time.Hour)
+	send(handler, "abc", `{}`)
+	if calls != 2 {
+		t.Errorf("expected expired key to allow a new request, ran %d times", calls)
+	}
+}
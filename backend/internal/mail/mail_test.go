@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	data, err := Format("shop@example.com", Message{To: "lee@example.com", Subject: "Hello", Body: "line one\nline two"}, now)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"From: shop@example.com\r\n",
+		"To: lee@example.com\r\n",
+		"Subject: Hello\r\n",
+		"Date: Sun, 01 Jun 2025 12:00:00 +0000\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestFormat_HeaderInjection(t *testing.T) {
+	msgs := []Message{
+		{To: "lee@example.com\r\nBcc: everyone@example.com", Subject: "Hi"},
+		{To: "lee@example.com", Subject: "Hi\nBcc: everyone@example.com"},
+	}
+	for _, msg := range msgs {
+		if _, err := Format("shop@example.com", msg, time.Now()); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("Format(%q, %q) = %v, want ErrInvalidHeader", msg.To, msg.Subject, err)
+		}
+	}
+}
+
+func TestWriterSender(t *testing.T) {
+	var buf bytes.Buffer
+	sender := &WriterSender{W: &buf, From: "shop@example.com"}
+	if err := sender.Send(context.Background(), Message{To: "lee@example.com", Subject: "Reset", Body: "token"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Subject: Reset") {
+		t.Errorf("expected message to be written, got %q", buf.String())
+	}
+}
+
+func TestDirSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	sender := DirSender{Dir: dir, From: "shop@example.com"}
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(context.Background(), Message{To: "lee@example.com", Subject: "Reset", Body: "token"}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected 2 saved messages, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil || !strings.Contains(string(data), "To: lee@example.com") {
+		t.Errorf("unexpected message %q (%v)", data, err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		10: time.Hour,
+	}
+	for attempt, want := range cases {
+		if got := RetryDelay(attempt); got != want {
+			t.Errorf("RetryDelay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
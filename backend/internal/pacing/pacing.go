@@ -0,0 +1,80 @@
+// Package pacing keeps the kitchen from taking on more orders than it can
+// cook, by pushing back promised times and pausing online ordering.
+package pacing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidSettings = errors.New("invalid kitchen capacity settings")
+
+// Load is an amount of cooking work.
+type Load struct {
+	Items       int `json:"items"`        // dishes, counting quantities
+	PrepMinutes int `json:"prep_minutes"` // cooking time of every dish added up
+}
+
+// Add returns the combined work of l and o.
+func (l Load) Add(o Load) Load {
+	return Load{Items: l.Items + o.Items, PrepMinutes: l.PrepMinutes + o.PrepMinutes}
+}
+
+// Settings describe what the kitchen can handle. A zero limit or threshold
+// is switched off.
+type Settings struct {
+	Window         time.Duration // capacity is measured per window
+	MaxItems       int           // dishes per window
+	MaxPrepMinutes int           // cooking minutes per window
+	PushbackAfter  time.Duration // queue wait before promised times move
+	PauseAfter     time.Duration // queue wait before online ordering pauses
+}
+
+// Validate checks the settings make sense.
+func (s Settings) Validate() error {
+	switch {
+	case s.Window <= 0:
+		return fmt.Errorf("%w: window must be positive", ErrInvalidSettings)
+	case s.MaxItems < 0 || s.MaxPrepMinutes < 0:
+		return fmt.Errorf("%w: capacity can't be negative", ErrInvalidSettings)
+	case s.PushbackAfter < 0 || s.PauseAfter < 0:
+		return fmt.Errorf("%w: thresholds can't be negative", ErrInvalidSettings)
+	}
+	return nil
+}
+
+// Wait is how long the kitchen needs to work through load at capacity.
+// Without any limit it is always zero.
+func (s Settings) Wait(load Load) time.Duration {
+	windows := 0.0
+	if s.MaxItems > 0 {
+		windows = math.Max(windows, float64(load.Items)/float64(s.MaxItems))
+	}
+	if s.MaxPrepMinutes > 0 {
+		windows = math.Max(windows, float64(load.PrepMinutes)/float64(s.MaxPrepMinutes))
+	}
+	return time.Duration(windows * float64(s.Window)).Round(time.Minute)
+}
+
+// Decision says how to treat a new order.
+type Decision struct {
+	Wait   time.Duration // time the kitchen needs for the backlog
+	Delay  time.Duration // added to the order's promised time
+	Paused bool          // online orders should be turned away
+}
+
+// Decide looks at the backlog the kitchen already has. Once the wait passes
+// PushbackAfter new orders are promised that much later, and once it passes
+// PauseAfter online ordering should pause until the kitchen catches up.
+func (s Settings) Decide(backlog Load) Decision {
+	d := Decision{Wait: s.Wait(backlog)}
+	if s.PushbackAfter > 0 && d.Wait >= s.PushbackAfter {
+		d.Delay = d.Wait
+	}
+	if s.PauseAfter > 0 && d.Wait >= s.PauseAfter {
+		d.Paused = true
+	}
+	return d
+}
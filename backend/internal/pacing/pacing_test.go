@@ -0,0 +1,68 @@
+package pacing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	s := Settings{Window: 15 * time.Minute, MaxItems: 20, MaxPrepMinutes: 120}
+
+	tests := []struct {
+		load Load
+		want time.Duration
+	}{
+		{Load{}, 0},
+		{Load{Items: 10, PrepMinutes: 60}, 8 * time.Minute},
+		{Load{Items: 40, PrepMinutes: 60}, 30 * time.Minute},
+		{Load{Items: 10, PrepMinutes: 360}, 45 * time.Minute},
+	}
+	for _, tc := range tests {
+		if got := s.Wait(tc.load); got != tc.want {
+			t.Errorf("Wait(%+v) = %v, want %v", tc.load, got, tc.want)
+		}
+	}
+
+	if got := (Settings{Window: 15 * time.Minute}).Wait(Load{Items: 500}); got != 0 {
+		t.Errorf("Wait without limits = %v, want 0", got)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	s := Settings{
+		Window:        15 * time.Minute,
+		MaxItems:      10,
+		PushbackAfter: 15 * time.Minute,
+		PauseAfter:    60 * time.Minute,
+	}
+
+	if d := s.Decide(Load{Items: 5}); d.Delay != 0 || d.Paused {
+		t.Errorf("light backlog: %+v", d)
+	}
+	if d := s.Decide(Load{Items: 20}); d.Delay != 30*time.Minute || d.Paused {
+		t.Errorf("busy backlog: %+v", d)
+	}
+	if d := s.Decide(Load{Items: 40}); d.Delay != 60*time.Minute || !d.Paused {
+		t.Errorf("overloaded backlog: %+v", d)
+	}
+
+	s.PauseAfter = 0
+	if d := s.Decide(Load{Items: 400}); d.Paused {
+		t.Error("pausing should be off when PauseAfter is 0")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Settings{Window: time.Minute}).Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	for _, bad := range []Settings{
+		{},
+		{Window: time.Minute, MaxItems: -1},
+		{Window: time.Minute, PauseAfter: -time.Minute},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeclinedCard is the test card number the mock gateway always declines.
+const DeclinedCard = "4000000000000002"
+
+// SignatureHeader carries the mock gateway's webhook signature.
+const SignatureHeader = "X-Mock-Signature"
+
+// signatureTolerance bounds how old a signed callback may be.
+const signatureTolerance = 5 * time.Minute
+
+// MockGateway imitates a card processor so the intent flow can be exercised
+// offline. Confirm and Refund report their result by POSTing a signed Event
+// to CallbackURL, the same way a real gateway calls back into the API.
+type MockGateway struct {
+	Secret      []byte
+	CallbackURL string
+	Client      *http.Client
+
+	// Now is overridable in tests; defaults to time.Now.
+	Now func() time.Time
+}
+
+func (g *MockGateway) Name() string {
+	return "mock_card"
+}
+
+func (g *MockGateway) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+func (g *MockGateway) CreateIntent(ctx context.Context, intent Intent) (Intent, error) {
+	ref := make([]byte, 8)
+	if _, err := rand.Read(ref); err != nil {
+		return intent, err
+	}
+	intent.ProviderRef = "mock_" + hex.EncodeToString(ref)
+	intent.Status = StatusPending
+	return intent, nil
+}
+
+func (g *MockGateway) Confirm(ctx context.Context, intent Intent, method Method) (Intent, error) {
+	card := strings.ReplaceAll(method.CardNumber, " ", "")
+	if !luhnValid(card) {
+		return intent, ErrInvalidCard
+	}
+
+	event := Event{
+		Type:        EventPaymentSucceeded,
+		IntentID:    intent.ID,
+		ProviderRef: intent.ProviderRef,
+		Amount:      intent.Amount,
+		CreatedAt:   g.now().Unix(),
+	}
+	if card == DeclinedCard {
+		event.Type = EventPaymentFailed
+		event.Reason = ErrCardDeclined.Error()
+	}
+
+	intent.Status = StatusProcessing
+	return intent, g.deliver(ctx, event)
+}
+
+func (g *MockGateway) Refund(ctx context.Context, intent Intent) (Intent, error) {
+	event := Event{
+		Type:        EventRefundSucceeded,
+		IntentID:    intent.ID,
+		ProviderRef: intent.ProviderRef,
+		Amount:      intent.Amount,
+		CreatedAt:   g.now().Unix(),
+	}
+	return intent, g.deliver(ctx, event)
+}
+
+// Sign produces the signature header value for a payload at the given time.
+func (g *MockGateway) Sign(payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + g.mac(ts, payload)
+}
+
+func (g *MockGateway) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	var ts, sig string
+	for _, part := range strings.Split(signature, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	if ts == "" || sig == "" {
+		return Event{}, ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Event{}, ErrInvalidSignature
+	}
+	age := g.now().Sub(time.Unix(unix, 0))
+	if age > signatureTolerance || age < -signatureTolerance {
+		return Event{}, fmt.Errorf("signature timestamp out of range: %w", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(sig), []byte(g.mac(ts, payload))) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return event, nil
+}
+
+func (g *MockGateway) mac(ts string, payload []byte) string {
+	m := hmac.New(sha256.New, g.Secret)
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(payload)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func (g *MockGateway) deliver(ctx context.Context, event Event) error {
+	if g.CallbackURL == "" {
+		return fmt.Errorf("mock gateway has no callback URL")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.CallbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, g.Sign(payload, g.now()))
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver callback: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func luhnValid(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// Purposes an intent can be created for
+const (
+	PurposeOrder     = "order"
+	PurposeRecharge  = "recharge"
+	PurposeBillShare = "bill_share"
+	PurposeTip       = "tip"
+	PurposeGiftCard  = "gift_card"
+	PurposeDeposit   = "reservation_deposit"
+)
+
+// Intent statuses
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusRefunding  = "refunding"
+	StatusRefunded   = "refunded"
+)
+
+// Webhook event types
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventRefundSucceeded  = "refund.succeeded"
+)
+
+var (
+	ErrUnsupported       = errors.New("operation not supported by provider")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrInsufficientFunds = errors.New("insufficient balance")
+	ErrCardDeclined      = errors.New("card declined")
+	ErrInvalidCard       = errors.New("invalid card number")
+	ErrInvalidSignature  = errors.New("invalid webhook signature")
+)
+
+// Intent is the provider-facing view of a payment_intents row.
+type Intent struct {
+	ID          int32   `json:"intent_id"`
+	UserID      int32   `json:"user_id"`
+	Purpose     string  `json:"purpose"`
+	Amount      float64 `json:"amount"`
+	Status      string  `json:"status"`
+	ProviderRef string  `json:"provider_ref"`
+}
+
+// Method carries the payment details supplied when confirming an intent.
+type Method struct {
+	CardNumber string `json:"card_number"`
+}
+
+// Event is a provider notification about an intent, delivered through a
+// signed webhook callback.
+type Event struct {
+	Type        string  `json:"type"`
+	IntentID    int32   `json:"intent_id"`
+	ProviderRef string  `json:"provider_ref"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason,omitempty"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+// Provider is implemented by every way of taking money from a customer.
+//
+// Confirm either settles the intent synchronously (StatusSucceeded or
+// StatusFailed) or returns StatusProcessing, in which case the final result
+// arrives later as an Event that must pass VerifyWebhook.
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, intent Intent) (Intent, error)
+	Confirm(ctx context.Context, intent Intent, method Method) (Intent, error)
+	Refund(ctx context.Context, intent Intent) (Intent, error)
+	VerifyWebhook(payload []byte, signature string) (Event, error)
+}
+
+// RoundAmount rounds a money amount to whole cents.
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
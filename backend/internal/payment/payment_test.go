@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeWallet struct {
+	balances map[int32]float64
+}
+
+func (w *fakeWallet) Debit(ctx context.Context, userID int32, amount float64) error {
+	if w.balances[userID] < amount {
+		return ErrInsufficientFunds
+	}
+	w.balances[userID] -= amount
+	return nil
+}
+
+func (w *fakeWallet) Credit(ctx context.Context, userID int32, amount float64) error {
+	w.balances[userID] += amount
+	return nil
+}
+
+func TestWalletProvider_ConfirmAndRefund(t *testing.T) {
+	wallet := &fakeWallet{balances: map[int32]float64{1: 20}}
+	p := WalletProvider{Wallet: wallet}
+
+	intent, err := p.CreateIntent(context.Background(), Intent{ID: 7, UserID: 1, Purpose: PurposeOrder, Amount: 12.5})
+	if err != nil {
+		t.Fatalf("CreateIntent returned error: %v", err)
+	}
+	intent, err = p.Confirm(context.Background(), intent, Method{})
+	if err != nil {
+		t.Fatalf("Confirm returned error: %v", err)
+	}
+	if intent.Status != StatusSucceeded {
+		t.Errorf("expected status %q, got %q", StatusSucceeded, intent.Status)
+	}
+	if wallet.balances[1] != 7.5 {
+		t.Errorf("expected balance 7.5, got %v", wallet.balances[1])
+	}
+
+	intent, err = p.Refund(context.Background(), intent)
+	if err != nil {
+		t.Fatalf("Refund returned error: %v", err)
+	}
+	if intent.Status != StatusRefunded || wallet.balances[1] != 20 {
+		t.Errorf("expected refunded intent and balance 20, got %q and %v", intent.Status, wallet.balances[1])
+	}
+}
+
+func TestWalletProvider_InsufficientFunds(t *testing.T) {
+	p := WalletProvider{Wallet: &fakeWallet{balances: map[int32]float64{1: 5}}}
+
+	intent, err := p.Confirm(context.Background(), Intent{ID: 1, UserID: 1, Amount: 10}, Method{})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+	if intent.Status != StatusFailed {
+		t.Errorf("expected status %q, got %q", StatusFailed, intent.Status)
+	}
+}
+
+func TestWalletProvider_RejectsNonPositiveAmount(t *testing.T) {
+	wallet := &fakeWallet{balances: map[int32]float64{1: 20}}
+	p := WalletProvider{Wallet: wallet}
+
+	for _, amount := range []float64{0, -5} {
+		intent := Intent{ID: 1, UserID: 1, Purpose: PurposeOrder, Amount: amount}
+		if _, err := p.CreateIntent(context.Background(), intent); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("CreateIntent(%v): expected ErrInvalidAmount, got %v", amount, err)
+		}
+		if _, err := p.Confirm(context.Background(), intent, Method{}); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Confirm(%v): expected ErrInvalidAmount, got %v", amount, err)
+		}
+		if _, err := p.Refund(context.Background(), intent); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Refund(%v): expected ErrInvalidAmount, got %v", amount, err)
+		}
+	}
+	if wallet.balances[1] != 20 {
+		t.Errorf("expected balance to stay 20, got %v", wallet.balances[1])
+	}
+}
+
+func TestWalletProvider_RejectsRecharge(t *testing.T) {
+	p := WalletProvider{Wallet: &fakeWallet{}}
+	_, err := p.CreateIntent(context.Background(), Intent{Purpose: PurposeRecharge})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func newCallbackServer(t *testing.T, g *MockGateway, events chan<- Event) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		event, err := g.VerifyWebhook(payload, r.Header.Get(SignatureHeader))
+		if err != nil {
+			t.Errorf("callback failed verification: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events <- event
+	}))
+}
+
+func TestMockGateway_SignedCallback(t *testing.T) {
+	g := &MockGateway{Secret: []byte("test-secret")}
+	events := make(chan Event, 1)
+	server := newCallbackServer(t, g, events)
+	defer server.Close()
+	g.CallbackURL = server.URL
+
+	intent, err := g.CreateIntent(context.Background(), Intent{ID: 3, UserID: 1, Purpose: PurposeRecharge, Amount: 50})
+	if err != nil {
+		t.Fatalf("CreateIntent returned error: %v", err)
+	}
+	intent, err = g.Confirm(context.Background(), intent, Method{CardNumber: "4242 4242 4242 4242"})
+	if err != nil {
+		t.Fatalf("Confirm returned error: %v", err)
+	}
+	if intent.Status != StatusProcessing {
+		t.Errorf("expected status %q, got %q", StatusProcessing, intent.Status)
+	}
+
+	event := <-events
+	if event.Type != EventPaymentSucceeded || event.IntentID != 3 || event.Amount != 50 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.ProviderRef != intent.ProviderRef {
+		t.Errorf("expected provider ref %q, got %q", intent.ProviderRef, event.ProviderRef)
+	}
+}
+
+func TestMockGateway_DeclinedCard(t *testing.T) {
+	g := &MockGateway{Secret: []byte("test-secret")}
+	events := make(chan Event, 1)
+	server := newCallbackServer(t, g, events)
+	defer server.Close()
+	g.CallbackURL = server.URL
+
+	_, err := g.Confirm(context.Background(), Intent{ID: 4, Amount: 10}, Method{CardNumber: DeclinedCard})
+	if err != nil {
+		t.Fatalf("Confirm returned error: %v", err)
+	}
+	if event := <-events; event.Type != EventPaymentFailed {
+		t.Errorf("expected %q event, got %q", EventPaymentFailed, event.Type)
+	}
+}
+
+func TestMockGateway_InvalidCard(t *testing.T) {
+	g := &MockGateway{Secret: []byte("test-secret"), CallbackURL: "http://127.0.0.1:0"}
+	_, err := g.Confirm(context.Background(), Intent{ID: 5, Amount: 10}, Method{CardNumber: "1234 5678"})
+	if !errors.Is(err, ErrInvalidCard) {
+		t.Errorf("expected ErrInvalidCard, got %v", err)
+	}
+}
+
+func TestMockGateway_VerifyWebhookRejectsTampering(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := &MockGateway{Secret: []byte("test-secret"), Now: func() time.Time { return now }}
+	payload := []byte(`{"type":"payment.succeeded","intent_id":1,"amount":10}`)
+	signature := g.Sign(payload, now)
+
+	if _, err := g.VerifyWebhook(payload, signature); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	tampered := []byte(`{"type":"payment.succeeded","intent_id":1,"amount":1000}`)
+	if _, err := g.VerifyWebhook(tampered, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered payload, got %v", err)
+	}
+
+	other := &MockGateway{Secret: []byte("other-secret")}
+	if _, err := g.VerifyWebhook(payload, other.Sign(payload, now)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+
+	stale := g.Sign(payload, now.Add(-time.Hour))
+	if _, err := g.VerifyWebhook(payload, stale); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for stale signature, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+)
+
+// Wallet moves money in and out of an account balance. Debit must fail with
+// ErrInsufficientFunds instead of letting the balance go negative.
+type Wallet interface {
+	Debit(ctx context.Context, userID int32, amount float64) error
+	Credit(ctx context.Context, userID int32, amount float64) error
+}
+
+// WalletProvider pays from the customer's stored balance. It settles
+// synchronously and never sends webhooks.
+type WalletProvider struct {
+	Wallet Wallet
+}
+
+func (p WalletProvider) Name() string {
+	return "wallet"
+}
+
+func (p WalletProvider) CreateIntent(ctx context.Context, intent Intent) (Intent, error) {
+	if intent.Purpose == PurposeRecharge {
+		return intent, fmt.Errorf("wallet cannot fund a recharge: %w", ErrUnsupported)
+	}
+	if intent.Amount <= 0 {
+		return intent, ErrInvalidAmount
+	}
+	intent.ProviderRef = fmt.Sprintf("wallet_%d", intent.ID)
+	intent.Status = StatusPending
+	return intent, nil
+}
+
+func (p WalletProvider) Confirm(ctx context.Context, intent Intent, method Method) (Intent, error) {
+	if intent.Amount <= 0 {
+		intent.Status = StatusFailed
+		return intent, ErrInvalidAmount
+	}
+	err := p.Wallet.Debit(ctx, intent.UserID, intent.Amount)
+	if err != nil {
+		intent.Status = StatusFailed
+		return intent, err
+	}
+	intent.Status = StatusSucceeded
+	return intent, nil
+}
+
+func (p WalletProvider) Refund(ctx context.Context, intent Intent) (Intent, error) {
+	if intent.Amount <= 0 {
+		return intent, ErrInvalidAmount
+	}
+	err := p.Wallet.Credit(ctx, intent.UserID, intent.Amount)
+	if err != nil {
+		return intent, err
+	}
+	intent.Status = StatusRefunded
+	return intent, nil
+}
+
+func (p WalletProvider) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	return Event{}, ErrUnsupported
+}
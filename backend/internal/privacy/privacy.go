@@ -0,0 +1,84 @@
+// Package privacy erases the personal data of an anonymized account while
+// keeping the rows the books need: the account itself, its orders, items
+// and payments.
+package privacy
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Account is the account being erased. Username is the name it had before
+// it was anonymized, since some records are keyed by it.
+type Account struct {
+	ID        int32
+	Username  string
+	Anonymous string
+}
+
+// Store clears one kind of personal data for an account.
+type Store interface {
+	DeleteAddresses(ctx context.Context, userID int32) error
+	// ClearOrders clears order notes, feedback, delivery and invoice details
+	ClearOrders(ctx context.Context, userID int32) error
+	// ClearDeliveryProofs clears the proof notes and photos of the account's
+	// deliveries and returns the photo file names that were on them
+	ClearDeliveryProofs(ctx context.Context, userID int32) ([]string, error)
+	ClearGiftCardMessages(ctx context.Context, userID int32) error
+	ClearReservationNotes(ctx context.Context, userID int32) error
+	AnonymizeWaitlist(ctx context.Context, userID int32) error
+	DeleteNotifications(ctx context.Context, userID int32) error
+	RevokeStaffRoles(ctx context.Context, userID int32) error
+	DeleteTwoFactor(ctx context.Context, userID int32) error
+	DeleteVerifications(ctx context.Context, userID int32) error
+	DeleteSessions(ctx context.Context, userID int32) error
+	DeleteMail(ctx context.Context, userID int32) error
+	AnonymizeLoginHistory(ctx context.Context, userID int32, anonymous string) error
+	ClearLoginThrottle(ctx context.Context, username string) error
+}
+
+// Erase removes everything in store that identifies account, and deletes
+// its delivery proof photos from photoDir.
+func Erase(ctx context.Context, store Store, account Account, photoDir string) error {
+	steps := []func(context.Context, int32) error{
+		store.DeleteAddresses,
+		store.ClearOrders,
+		store.ClearGiftCardMessages,
+		store.ClearReservationNotes,
+		store.AnonymizeWaitlist,
+		store.DeleteNotifications,
+		store.RevokeStaffRoles,
+		store.DeleteTwoFactor,
+		store.DeleteVerifications,
+		store.DeleteSessions,
+		store.DeleteMail,
+	}
+	for _, step := range steps {
+		if err := step(ctx, account.ID); err != nil {
+			return err
+		}
+	}
+	if err := store.AnonymizeLoginHistory(ctx, account.ID, account.Anonymous); err != nil {
+		return err
+	}
+	if err := store.ClearLoginThrottle(ctx, account.Username); err != nil {
+		return err
+	}
+
+	photos, err := store.ClearDeliveryProofs(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	for _, name := range photos {
+		// Only the base name is trusted, as when the photo is served
+		err := os.Remove(filepath.Join(photoDir, filepath.Base(name)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Println("Error removing delivery photo:", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package privacy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memoryStore keeps, per user, which kinds of personal data are still there
+type memoryStore struct {
+	data      map[int32]map[string]bool
+	photos    map[int32][]string
+	logins    map[int32]string
+	throttles map[string]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		data:      make(map[int32]map[string]bool),
+		photos:    make(map[int32][]string),
+		logins:    make(map[int32]string),
+		throttles: make(map[string]bool),
+	}
+}
+
+var kinds = []string{
+	"addresses", "order_info", "feedback", "invoice_billing_name", "invoice_tax_id",
+	"delivery_address", "proof_note", "gift_card_message", "reservation_note", "waitlist_name",
+	"notifications", "staff_roles", "two_factor", "verifications", "sessions", "mail",
+}
+
+func (s *memoryStore) seed(userID int32, username, photo string) {
+	s.data[userID] = make(map[string]bool)
+	for _, kind := range kinds {
+		s.data[userID][kind] = true
+	}
+	s.photos[userID] = []string{photo}
+	s.logins[userID] = username
+	s.throttles[username] = true
+}
+
+func (s *memoryStore) clear(userID int32, kinds ...string) error {
+	for _, kind := range kinds {
+		delete(s.data[userID], kind)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteAddresses(ctx context.Context, userID int32) error {
+	return s.clear(userID, "addresses")
+}
+
+func (s *memoryStore) ClearOrders(ctx context.Context, userID int32) error {
+	return s.clear(userID, "order_info", "feedback", "invoice_billing_name", "invoice_tax_id", "delivery_address")
+}
+
+func (s *memoryStore) ClearDeliveryProofs(ctx context.Context, userID int32) ([]string, error) {
+	photos := s.photos[userID]
+	delete(s.photos, userID)
+	return photos, s.clear(userID, "proof_note")
+}
+
+func (s *memoryStore) ClearGiftCardMessages(ctx context.Context, userID int32) error {
+	return s.clear(userID, "gift_card_message")
+}
+
+func (s *memoryStore) ClearReservationNotes(ctx context.Context, userID int32) error {
+	return s.clear(userID, "reservation_note")
+}
+
+func (s *memoryStore) AnonymizeWaitlist(ctx context.Context, userID int32) error {
+	return s.clear(userID, "waitlist_name")
+}
+
+func (s *memoryStore) DeleteNotifications(ctx context.Context, userID int32) error {
+	return s.clear(userID, "notifications")
+}
+
+func (s *memoryStore) RevokeStaffRoles(ctx context.Context, userID int32) error {
+	return s.clear(userID, "staff_roles")
+}
+
+func (s *memoryStore) DeleteTwoFactor(ctx context.Context, userID int32) error {
+	return s.clear(userID, "two_factor")
+}
+
+func (s *memoryStore) DeleteVerifications(ctx context.Context, userID int32) error {
+	return s.clear(userID, "verifications")
+}
+
+func (s *memoryStore) DeleteSessions(ctx context.Context, userID int32) error {
+	return s.clear(userID, "sessions")
+}
+
+func (s *memoryStore) DeleteMail(ctx context.Context, userID int32) error {
+	return s.clear(userID, "mail")
+}
+
+func (s *memoryStore) AnonymizeLoginHistory(ctx context.Context, userID int32, anonymous string) error {
+	s.logins[userID] = anonymous
+	return nil
+}
+
+func (s *memoryStore) ClearLoginThrottle(ctx context.Context, username string) error {
+	delete(s.throttles, username)
+	return nil
+}
+
+func TestErase(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"delivery_1.jpg", "delivery_2.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("photo"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := newMemoryStore()
+	store.seed(5, "alice", "delivery_1.jpg")
+	store.seed(6, "bob", "delivery_2.jpg")
+
+	err := Erase(context.Background(), store, Account{ID: 5, Username: "alice", Anonymous: "deleted-5"}, dir)
+	if err != nil {
+		t.Fatalf("Erase returned error: %v", err)
+	}
+
+	for _, kind := range kinds {
+		if store.data[5][kind] {
+			t.Errorf("%s was not erased", kind)
+		}
+		if !store.data[6][kind] {
+			t.Errorf("%s of another account was erased", kind)
+		}
+	}
+	if len(store.photos[5]) != 0 {
+		t.Errorf("expected proof photos to be cleared, got %v", store.photos[5])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "delivery_1.jpg")); !os.IsNotExist(err) {
+		t.Errorf("expected proof photo file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "delivery_2.jpg")); err != nil {
+		t.Errorf("another account's photo file was touched: %v", err)
+	}
+	if store.logins[5] != "deleted-5" {
+		t.Errorf("expected login history under deleted-5, got %q", store.logins[5])
+	}
+	if store.throttles["alice"] {
+		t.Error("login throttle for the old username was not cleared")
+	}
+	if !store.throttles["bob"] {
+		t.Error("another account's login throttle was cleared")
+	}
+}
+
+func TestErase_MissingPhotoFile(t *testing.T) {
+	store := newMemoryStore()
+	store.seed(5, "alice", "gone.jpg")
+
+	err := Erase(context.Background(), store, Account{ID: 5, Username: "alice", Anonymous: "deleted-5"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("Erase returned error: %v", err)
+	}
+}
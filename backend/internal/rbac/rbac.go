@@ -0,0 +1,124 @@
+// Package rbac decides what each staff role may do.
+package rbac
+
+import "sort"
+
+// Roles. Every account is a customer; the others are granted on top.
+const (
+	RoleOwner    = "owner"
+	RoleManager  = "manager"
+	RoleCashier  = "cashier"
+	RoleKitchen  = "kitchen"
+	RoleRider    = "rider"
+	RoleCustomer = "customer"
+)
+
+// Permissions checked by the routes that need them
+const (
+	PermMenu         = "menu.manage"
+	PermOrdersView   = "orders.view"
+	PermOrdersPrep   = "orders.prepare"
+	PermOrdersManage = "orders.manage"
+	PermRefunds      = "payments.refund"
+	PermReports      = "reports.view"
+	PermGiftCards    = "giftcards.manage"
+	PermPrinters     = "printers.manage"
+	PermDelivery     = "delivery.manage"
+	PermRide         = "delivery.ride"
+	PermKitchen      = "kitchen.manage"
+	PermStore        = "store.manage"
+	PermReservations = "reservations.manage"
+	PermUsers        = "users.view"
+	PermUsersManage  = "users.manage"
+	PermRoles        = "roles.manage"
+)
+
+var all = []string{
+	PermMenu, PermOrdersView, PermOrdersPrep, PermOrdersManage, PermRefunds,
+	PermReports, PermGiftCards, PermPrinters, PermDelivery, PermRide,
+	PermKitchen, PermStore, PermReservations, PermUsers, PermUsersManage,
+	PermRoles,
+}
+
+var grants = map[string][]string{
+	RoleOwner:    all,
+	RoleManager:  all,
+	RoleCashier:  {PermOrdersView, PermOrdersPrep, PermReservations},
+	RoleKitchen:  {PermOrdersPrep, PermKitchen},
+	RoleRider:    {PermRide},
+	RoleCustomer: nil,
+}
+
+// Valid reports whether role exists.
+func Valid(role string) bool {
+	_, ok := grants[role]
+	return ok
+}
+
+// Can reports whether an account holding roles has permission.
+func Can(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, granted := range grants[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions lists everything roles allow, sorted.
+func Permissions(roles []string) []string {
+	seen := make(map[string]bool)
+	var perms []string
+	for _, role := range roles {
+		for _, p := range grants[role] {
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// Staff reports whether roles include anything beyond customer.
+func Staff(roles []string) bool {
+	for _, role := range roles {
+		if role != RoleCustomer && Valid(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAssign reports whether an account holding roles may grant or revoke
+// role. Owners manage every role; managers manage the roles below them.
+func CanAssign(roles []string, role string) bool {
+	if !Valid(role) {
+		return false
+	}
+	for _, held := range roles {
+		switch held {
+		case RoleOwner:
+			return true
+		case RoleManager:
+			if role != RoleOwner && role != RoleManager {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequiresTwoFactor reports whether roles are powerful enough that the
+// account must use two-factor authentication: owners and managers.
+func RequiresTwoFactor(roles []string) bool {
+	for _, role := range roles {
+		if role == RoleOwner || role == RoleManager {
+			return true
+		}
+	}
+	return false
+}
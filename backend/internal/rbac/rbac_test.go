@@ -0,0 +1,83 @@
+package rbac
+
+import "testing"
+
+func TestCan(t *testing.T) {
+	cases := []struct {
+		roles      []string
+		permission string
+		want       bool
+	}{
+		{[]string{RoleOwner}, PermRoles, true},
+		{[]string{RoleManager}, PermRefunds, true},
+		{[]string{RoleCashier}, PermReservations, true},
+		{[]string{RoleCashier}, PermRefunds, false},
+		{[]string{RoleKitchen}, PermOrdersPrep, true},
+		{[]string{RoleKitchen}, PermMenu, false},
+		{[]string{RoleRider}, PermRide, true},
+		{[]string{RoleCustomer}, PermOrdersView, false},
+		{[]string{RoleCustomer, RoleRider}, PermRide, true},
+		{nil, PermRide, false},
+		{[]string{"wizard"}, PermMenu, false},
+	}
+	for _, c := range cases {
+		if got := Can(c.roles, c.permission); got != c.want {
+			t.Errorf("Can(%v, %s) = %v, want %v", c.roles, c.permission, got, c.want)
+		}
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	got := Permissions([]string{RoleKitchen, RoleCashier})
+	want := []string{PermKitchen, PermOrdersPrep, PermOrdersView, PermReservations}
+	if len(got) != len(want) {
+		t.Fatalf("Permissions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Permissions = %v, want %v", got, want)
+		}
+	}
+	if len(Permissions([]string{RoleCustomer})) != 0 {
+		t.Error("customers should have no staff permissions")
+	}
+}
+
+func TestStaff(t *testing.T) {
+	if Staff([]string{RoleCustomer}) || Staff(nil) {
+		t.Error("customers are not staff")
+	}
+	if !Staff([]string{RoleCustomer, RoleRider}) {
+		t.Error("riders are staff")
+	}
+}
+
+func TestRequiresTwoFactor(t *testing.T) {
+	if !RequiresTwoFactor([]string{RoleCustomer, RoleOwner}) || !RequiresTwoFactor([]string{RoleManager}) {
+		t.Error("owners and managers need two-factor authentication")
+	}
+	if RequiresTwoFactor([]string{RoleCustomer, RoleCashier, RoleKitchen, RoleRider}) {
+		t.Error("other roles do not")
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	cases := []struct {
+		roles []string
+		role  string
+		want  bool
+	}{
+		{[]string{RoleOwner}, RoleOwner, true},
+		{[]string{RoleOwner}, RoleManager, true},
+		{[]string{RoleManager}, RoleCashier, true},
+		{[]string{RoleManager}, RoleManager, false},
+		{[]string{RoleManager}, RoleOwner, false},
+		{[]string{RoleCashier}, RoleKitchen, false},
+		{[]string{RoleOwner}, "wizard", false},
+	}
+	for _, c := range cases {
+		if got := CanAssign(c.roles, c.role); got != c.want {
+			t.Errorf("CanAssign(%v, %s) = %v, want %v", c.roles, c.role, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money": money,
+	"orderType": func(s string) string {
+		return strings.ReplaceAll(s, "_", "-")
+	},
+	"rate": func(r float64) string {
+		return fmt.Sprintf("%g%%", r)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: sans-serif; max-width: 28em; margin: 2em auto; color: #222; }
+h1, h2, .center { text-align: center; }
+h1 { margin-bottom: 0.2em; }
+h2 { font-size: 1em; letter-spacing: 0.1em; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: 0.15em 0; vertical-align: top; }
+td.amount { text-align: right; white-space: nowrap; }
+tr.total td { font-weight: bold; border-top: 1px solid #222; }
+.muted { color: #666; font-size: 0.9em; }
+hr { border: 0; border-top: 1px dashed #999; }
+</style>
+</head>
+<body>
+<h1>{{.Business.Name}}</h1>
+<div class="center muted">
+{{- with .Business.Address}}<div>{{.}}</div>{{end}}
+{{- with .Business.Phone}}<div>{{.}}</div>{{end}}
+{{- with .Business.TaxID}}<div>Tax ID: {{.}}</div>{{end}}
+</div>
+<h2>{{.Title}}</h2>
+<table>
+<tr><td>Receipt no.</td><td class="amount">{{.Number}}</td></tr>
+<tr><td>Order no.</td><td class="amount">#{{.OrderID}}</td></tr>
+<tr><td>Date</td><td class="amount">{{.IssuedAt.Format "2006-01-02 15:04"}}</td></tr>
+{{- with .OrderType}}<tr><td>Order type</td><td class="amount">{{orderType .}}</td></tr>{{end}}
+{{- if gt .TableNumber 0}}<tr><td>Table</td><td class="amount">{{.TableNumber}}</td></tr>{{end}}
+{{- with .Customer}}<tr><td>Customer</td><td class="amount">{{.}}</td></tr>{{end}}
+{{- with .Invoice}}
+<tr><td>Bill to</td><td class="amount">{{.BillingName}}</td></tr>
+{{- with .TaxID}}<tr><td>Customer tax ID</td><td class="amount">{{.}}</td></tr>{{end}}
+{{- end}}
+</table>
+<hr>
+<table>
+{{- range .Lines}}
+<tr><td>{{.Name}}{{if ne .Quantity 1}}<div class="muted">{{.Quantity}} x {{money .UnitPrice}}</div>{{end}}</td><td class="amount">{{money .Total}}</td></tr>
+{{- end}}
+</table>
+<hr>
+<table>
+<tr><td>Subtotal</td><td class="amount">{{money .Subtotal}}</td></tr>
+{{- range .Charges}}
+<tr><td>{{.Label}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+<tr class="total"><td>Total</td><td class="amount">{{money .Total}}</td></tr>
+{{- range .Taxes}}
+<tr class="muted"><td>incl. {{.Label}} {{rate .Rate}} on {{money .Net}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+{{- if gt .Tip 0.0}}
+<tr><td>Tip</td><td class="amount">{{money .Tip}}</td></tr>
+{{- end}}
+</table>
+<hr>
+<table>
+{{- range .Payments}}
+<tr><td>Paid by {{.Method}}{{with .Reference}} <span class="muted">({{.}})</span>{{end}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+</table>
+<p class="center">Thank you for dining with us!</p>
+</body>
+</html>
+`))
+
+// HTML renders the receipt as a standalone HTML page.
+func HTML(r Receipt) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,183 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Width is the number of characters in a line of the text receipt.
+const Width = 48
+
+// Business is the restaurant printed at the top of every receipt.
+type Business struct {
+	Name    string
+	Address string
+	Phone   string
+	TaxID   string
+}
+
+// Invoice holds company details a customer asked to have on the receipt.
+type Invoice struct {
+	BillingName string
+	TaxID       string
+}
+
+// Line is one ordered item at the price it was ordered for.
+type Line struct {
+	Name      string
+	Quantity  int32
+	UnitPrice float64
+}
+
+// Total is the price of the whole line.
+func (l Line) Total() float64 {
+	return float64(l.Quantity) * l.UnitPrice
+}
+
+// Charge is an amount added on top of the items, such as a delivery fee.
+type Charge struct {
+	Label  string
+	Amount float64
+}
+
+// Tax is the tax contained in the total at one rate.
+type Tax struct {
+	Label  string
+	Rate   float64
+	Net    float64
+	Amount float64
+}
+
+// Payment is one payment that settled the order.
+type Payment struct {
+	Method    string
+	Reference string
+	Amount    float64
+}
+
+// Receipt is everything printed on a receipt. Amounts are tax inclusive.
+type Receipt struct {
+	Business    Business
+	OrderID     int32
+	IssuedAt    time.Time
+	OrderType   string
+	TableNumber int32
+	Customer    string
+	Invoice     *Invoice
+	Lines       []Line
+	Charges     []Charge
+	Subtotal    float64
+	Taxes       []Tax
+	Total       float64
+	Tip         float64
+	Payments    []Payment
+}
+
+// Number is the receipt number printed on the receipt and used in file names.
+func (r Receipt) Number() string {
+	return fmt.Sprintf("R-%06d", r.OrderID)
+}
+
+// Title is the heading of the receipt.
+func (r Receipt) Title() string {
+	if r.Invoice != nil {
+		return "TAX INVOICE"
+	}
+	return "RECEIPT"
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// row puts left and right on one line, truncating left if they don't fit.
+func row(left, right string) string {
+	space := Width - utf8.RuneCountInString(right) - 1
+	if runes := []rune(left); len(runes) > space {
+		left = string(runes[:space])
+	}
+	padding := Width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	return left + strings.Repeat(" ", padding) + right
+}
+
+func center(text string) string {
+	length := utf8.RuneCountInString(text)
+	if length >= Width {
+		return text
+	}
+	return strings.Repeat(" ", (Width-length)/2) + text
+}
+
+// Text renders the receipt as fixed width plain text.
+func Text(r Receipt) string {
+	var b strings.Builder
+	rule := strings.Repeat("-", Width)
+	line := func(s string) {
+		b.WriteString(strings.TrimRight(s, " "))
+		b.WriteString("\n")
+	}
+
+	line(center(r.Business.Name))
+	for _, s := range []string{r.Business.Address, r.Business.Phone} {
+		if s != "" {
+			line(center(s))
+		}
+	}
+	if r.Business.TaxID != "" {
+		line(center("Tax ID: " + r.Business.TaxID))
+	}
+	line(rule)
+	line(center(r.Title()))
+	line(row("Receipt no.", r.Number()))
+	line(row("Order no.", fmt.Sprintf("#%d", r.OrderID)))
+	line(row("Date", r.IssuedAt.Format("2006-01-02 15:04")))
+	if r.OrderType != "" {
+		line(row("Order type", strings.ReplaceAll(r.OrderType, "_", "-")))
+	}
+	if r.TableNumber > 0 {
+		line(row("Table", fmt.Sprintf("%d", r.TableNumber)))
+	}
+	if r.Customer != "" {
+		line(row("Customer", r.Customer))
+	}
+	if r.Invoice != nil {
+		line(row("Bill to", r.Invoice.BillingName))
+		if r.Invoice.TaxID != "" {
+			line(row("Customer tax ID", r.Invoice.TaxID))
+		}
+	}
+	line(rule)
+
+	for _, l := range r.Lines {
+		line(row(l.Name, money(l.Total())))
+		if l.Quantity != 1 {
+			line(fmt.Sprintf("  %d x %s", l.Quantity, money(l.UnitPrice)))
+		}
+	}
+	line(rule)
+	line(row("Subtotal", money(r.Subtotal)))
+	for _, c := range r.Charges {
+		line(row(c.Label, money(c.Amount)))
+	}
+	line(row("TOTAL", money(r.Total)))
+	for _, t := range r.Taxes {
+		line(row(fmt.Sprintf("  incl. %s %g%% on %s", t.Label, t.Rate, money(t.Net)), money(t.Amount)))
+	}
+	if r.Tip > 0 {
+		line(row("Tip", money(r.Tip)))
+	}
+	line(rule)
+
+	for _, p := range r.Payments {
+		label := "Paid by " + p.Method
+		if p.Reference != "" {
+			label += " (" + p.Reference + ")"
+		}
+		line(row(label, money(p.Amount)))
+	}
+	line("")
+	line(center("Thank you for dining with us!"))
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReceipt() Receipt {
+	return Receipt{
+		Business:    Business{Name: "Takeaway & Dine-in", Address: "1 Main Street", TaxID: "TAX-001"},
+		OrderID:     42,
+		IssuedAt:    time.Date(2025, 3, 14, 18, 30, 0, 0, time.UTC),
+		OrderType:   "dine_in",
+		TableNumber: 7,
+		Customer:    "alice",
+		Invoice:     &Invoice{BillingName: "Acme <Ltd>", TaxID: "GB123"},
+		Lines: []Line{
+			{Name: "Fried Rice", Quantity: 2, UnitPrice: 8.5},
+			{Name: "Lemon Tea (large)", Quantity: 1, UnitPrice: 3},
+		},
+		Subtotal: 20,
+		Taxes:    []Tax{{Label: "VAT", Rate: 10, Net: 18.18, Amount: 1.82}},
+		Total:    20,
+		Tip:      2,
+		Payments: []Payment{{Method: "Wallet", Amount: 22}},
+	}
+}
+
+func TestText(t *testing.T) {
+	text := Text(sampleReceipt())
+	for _, want := range []string{
+		"TAX INVOICE",
+		"R-000042",
+		"#42",
+		"2025-03-14 18:30",
+		"Table",
+		"Acme <Ltd>",
+		"GB123",
+		"2 x $8.50",
+		"$17.00",
+		"incl. VAT 10% on $18.18",
+		"$1.82",
+		"Tip",
+		"Paid by Wallet",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text receipt is missing %q:\n%s", want, text)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if len([]rune(line)) > Width {
+			t.Errorf("line longer than %d characters: %q", Width, line)
+		}
+	}
+}
+
+func TestText_TruncatesLongNames(t *testing.T) {
+	r := sampleReceipt()
+	r.Lines = []Line{{Name: strings.Repeat("Extremely long dish name ", 4), Quantity: 1, UnitPrice: 5}}
+	for _, line := range strings.Split(Text(r), "\n") {
+		if len([]rune(line)) > Width {
+			t.Errorf("line longer than %d characters: %q", Width, line)
+		}
+	}
+}
+
+func TestHTML_EscapesContent(t *testing.T) {
+	page, err := HTML(sampleReceipt())
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	if bytes.Contains(page, []byte("Acme <Ltd>")) {
+		t.Error("billing name was not escaped")
+	}
+	if !bytes.Contains(page, []byte("Acme &lt;Ltd&gt;")) {
+		t.Error("escaped billing name missing")
+	}
+	if !bytes.Contains(page, []byte("$17.00")) {
+		t.Error("line total missing")
+	}
+}
+
+func TestPDF_Structure(t *testing.T) {
+	doc := PDF(sampleReceipt())
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("PDF header or trailer missing")
+	}
+	if !bytes.Contains(doc, []byte(`(Lemon Tea \(large\)`)) {
+		t.Error("parentheses in text were not escaped")
+	}
+
+	// Every xref entry must point at the object it names
+	xrefAt := bytes.LastIndex(doc, []byte("startxref\n"))
+	start, err := strconv.Atoi(strings.Fields(string(doc[xrefAt+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(doc[start:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := strings.Split(string(doc[start:]), "\n")[3:]
+	for i := 1; !strings.HasPrefix(entries[i-1], "trailer"); i++ {
+		offset, _ := strconv.Atoi(entries[i-1][:10])
+		if !bytes.HasPrefix(doc[offset:], []byte(fmt.Sprintf("%d 0 obj", i))) {
+			t.Errorf("xref entry %d points at the wrong place", i)
+		}
+	}
+}
+
+func TestPDF_PaginatesLongReceipts(t *testing.T) {
+	r := sampleReceipt()
+	for i := 0; i < 100; i++ {
+		r.Lines = append(r.Lines, Line{Name: fmt.Sprintf("Dish %d", i), Quantity: 1, UnitPrice: 1})
+	}
+	doc := PDF(r)
+	if !bytes.Contains(doc, []byte("/Count 3")) {
+		t.Error("expected a long receipt to span 3 pages")
+	}
+}
@@ -0,0 +1,146 @@
+// Package reservation finds tables for parties booking ahead or waiting to
+// be seated.
+package reservation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Reservation statuses. A pending reservation holds its table while the
+// deposit is taken.
+const (
+	StatusPending   = "pending"
+	StatusBooked    = "booked"
+	StatusSeated    = "seated"
+	StatusCancelled = "cancelled"
+	StatusNoShow    = "no_show"
+)
+
+// Deposit statuses
+const (
+	DepositNone      = "none"
+	DepositPending   = "pending"
+	DepositPaid      = "paid"
+	DepositRefunded  = "refunded"
+	DepositForfeited = "forfeited"
+)
+
+// Waitlist statuses
+const (
+	WaitWaiting  = "waiting"
+	WaitNotified = "notified"
+	WaitSeated   = "seated"
+	WaitLeft     = "left"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid reservation status change")
+	ErrNoTable           = errors.New("no table is free for this party")
+)
+
+var transitions = map[string][]string{
+	StatusPending: {StatusBooked, StatusCancelled},
+	StatusBooked:  {StatusSeated, StatusCancelled, StatusNoShow},
+}
+
+// Transition checks that a reservation may move from one status to another.
+func Transition(from, to string) error {
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// Holds reports whether a reservation in status keeps its table.
+func Holds(status string) bool {
+	return status == StatusPending || status == StatusBooked
+}
+
+// Table is a table guests can be seated at.
+type Table struct {
+	Number int32 `json:"table_number"`
+	Seats  int   `json:"seats"`
+}
+
+// Booking is time a table is already taken for.
+type Booking struct {
+	Table int32
+	Start time.Time
+	End   time.Time
+}
+
+// Free lists the tables that seat party and aren't booked between start and
+// end, smallest first so large tables stay free for large parties.
+func Free(tables []Table, bookings []Booking, party int, start, end time.Time) []Table {
+	taken := make(map[int32]bool)
+	for _, b := range bookings {
+		if b.Start.Before(end) && start.Before(b.End) {
+			taken[b.Table] = true
+		}
+	}
+	var free []Table
+	for _, t := range tables {
+		if t.Seats >= party && !taken[t.Number] {
+			free = append(free, t)
+		}
+	}
+	sort.Slice(free, func(i, j int) bool {
+		if free[i].Seats != free[j].Seats {
+			return free[i].Seats < free[j].Seats
+		}
+		return free[i].Number < free[j].Number
+	})
+	return free
+}
+
+// Pick chooses the table for a party, or fails with ErrNoTable.
+func Pick(tables []Table, bookings []Booking, party int, start, end time.Time) (Table, error) {
+	free := Free(tables, bookings, party, start, end)
+	if len(free) == 0 {
+		return Table{}, ErrNoTable
+	}
+	return free[0], nil
+}
+
+// Option is a time a party could book and how many tables would fit them.
+type Option struct {
+	Start  time.Time `json:"start"`
+	Tables int       `json:"tables"`
+}
+
+// Options lists the starts at which a table is free for party for length.
+func Options(tables []Table, bookings []Booking, party int, starts []time.Time, length time.Duration) []Option {
+	var options []Option
+	for _, start := range starts {
+		if n := len(Free(tables, bookings, party, start, start.Add(length))); n > 0 {
+			options = append(options, Option{Start: start, Tables: n})
+		}
+	}
+	return options
+}
+
+// Wait estimates how long a walk-in party waits when ahead parties are
+// already waiting for the same tables, free of them are free now and each
+// of the suitable tables turns over every turnover. It reports false if no
+// table seats the party.
+func Wait(ahead, free, suitable int, turnover time.Duration) (time.Duration, bool) {
+	if suitable <= 0 {
+		return 0, false
+	}
+	if ahead < free {
+		return 0, true
+	}
+	rounds := (ahead-free)/suitable + 1
+	return time.Duration(rounds) * turnover, true
+}
+
+// Refundable reports whether a deposit is returned when a reservation
+// starting at start is cancelled at now.
+func Refundable(start, now time.Time, notice time.Duration) bool {
+	return !now.Add(notice).After(start)
+}
@@ -0,0 +1,96 @@
+package reservation
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	if err := Transition(StatusBooked, StatusSeated); err != nil {
+		t.Errorf("booked to seated: %v", err)
+	}
+	if err := Transition(StatusPending, StatusBooked); err != nil {
+		t.Errorf("pending to booked: %v", err)
+	}
+	for _, bad := range [][2]string{{StatusPending, StatusSeated}, {StatusCancelled, StatusBooked}, {StatusSeated, StatusNoShow}} {
+		if err := Transition(bad[0], bad[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s to %s = %v, want ErrInvalidTransition", bad[0], bad[1], err)
+		}
+	}
+	if !Holds(StatusPending) || !Holds(StatusBooked) || Holds(StatusNoShow) {
+		t.Error("Holds is wrong")
+	}
+}
+
+func TestFree(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2025, 6, 1, hour, min, 0, 0, time.UTC) }
+	tables := []Table{{Number: 1, Seats: 6}, {Number: 2, Seats: 2}, {Number: 3, Seats: 4}, {Number: 4, Seats: 4}}
+	bookings := []Booking{
+		{Table: 3, Start: at(18, 0), End: at(19, 30)},
+		{Table: 2, Start: at(19, 30), End: at(21, 0)},
+	}
+
+	free := Free(tables, bookings, 3, at(19, 0), at(20, 30))
+	if len(free) != 2 || free[0].Number != 4 || free[1].Number != 1 {
+		t.Errorf("Free = %+v, want tables 4 then 1", free)
+	}
+
+	// Back to back bookings don't overlap
+	table, err := Pick(tables, bookings, 2, at(18, 0), at(19, 30))
+	if err != nil || table.Number != 2 {
+		t.Errorf("Pick = %+v, %v, want table 2", table, err)
+	}
+
+	if _, err := Pick(tables, bookings, 8, at(12, 0), at(13, 30)); !errors.Is(err, ErrNoTable) {
+		t.Errorf("Pick for 8 = %v, want ErrNoTable", err)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2025, 6, 1, hour, min, 0, 0, time.UTC) }
+	tables := []Table{{Number: 1, Seats: 4}}
+	bookings := []Booking{{Table: 1, Start: at(19, 0), End: at(20, 30)}}
+	starts := []time.Time{at(17, 0), at(17, 30), at(18, 0), at(20, 30)}
+
+	options := Options(tables, bookings, 2, starts, 90*time.Minute)
+	if len(options) != 3 || !options[0].Start.Equal(at(17, 0)) || !options[1].Start.Equal(at(17, 30)) || !options[2].Start.Equal(at(20, 30)) {
+		t.Errorf("Options = %+v", options)
+	}
+}
+
+func TestWait(t *testing.T) {
+	turn := 45 * time.Minute
+	cases := []struct {
+		ahead, free, suitable int
+		want                  time.Duration
+	}{
+		{0, 1, 3, 0},
+		{2, 3, 3, 0},
+		{0, 0, 3, turn},
+		{3, 0, 3, 2 * turn},
+		{4, 1, 2, 2 * turn},
+	}
+	for _, c := range cases {
+		got, ok := Wait(c.ahead, c.free, c.suitable, turn)
+		if !ok || got != c.want {
+			t.Errorf("Wait(%d, %d, %d) = %v, %v, want %v", c.ahead, c.free, c.suitable, got, ok, c.want)
+		}
+	}
+	if _, ok := Wait(0, 0, 0, turn); ok {
+		t.Error("a party no table seats should not get a wait")
+	}
+}
+
+func TestRefundable(t *testing.T) {
+	start := time.Date(2025, 6, 2, 19, 0, 0, 0, time.UTC)
+	if !Refundable(start, start.Add(-25*time.Hour), 24*time.Hour) {
+		t.Error("cancelling a day ahead should be refunded")
+	}
+	if !Refundable(start, start.Add(-24*time.Hour), 24*time.Hour) {
+		t.Error("cancelling exactly at the notice should be refunded")
+	}
+	if Refundable(start, start.Add(-2*time.Hour), 24*time.Hour) {
+		t.Error("late cancellations should not be refunded")
+	}
+}
@@ -0,0 +1,100 @@
+// Package schedule works out which pickup and delivery slots customers can
+// book ahead of time.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SlotLength is the length of one bookable window.
+const SlotLength = 15 * time.Minute
+
+var (
+	ErrInvalidSlot  = errors.New("scheduled time must start a 15 minute slot")
+	ErrTooSoon      = errors.New("scheduled time is too soon")
+	ErrTooFar       = errors.New("scheduled time is too far ahead")
+	ErrClosed       = errors.New("scheduled time is outside service hours")
+	ErrInvalidHours = errors.New("invalid service hours")
+)
+
+// Rules limit which slots can be booked.
+type Rules struct {
+	MinLead  time.Duration // least notice on top of cooking time
+	MaxAhead time.Duration // furthest a slot can be booked
+	Capacity int           // orders per slot
+	Open     time.Duration // first slot of the day, as time since midnight
+	Close    time.Duration // service ends, as time since midnight
+}
+
+// Slot is a bookable window and how many more orders it takes.
+type Slot struct {
+	Start     time.Time `json:"start"`
+	Remaining int       `json:"remaining"`
+}
+
+// SlotStart is the start of the slot t falls in.
+func SlotStart(t time.Time) time.Time {
+	return t.Truncate(SlotLength)
+}
+
+// ParseHours reads service hours written as "10:00-22:00".
+func ParseHours(s string) (open, close time.Duration, err error) {
+	var oh, om, ch, cm int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &oh, &om, &ch, &cm); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidHours, s)
+	}
+	open = time.Duration(oh)*time.Hour + time.Duration(om)*time.Minute
+	close = time.Duration(ch)*time.Hour + time.Duration(cm)*time.Minute
+	if om < 0 || om > 59 || cm < 0 || cm > 59 || open < 0 || close > 24*time.Hour || open >= close {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidHours, s)
+	}
+	return open, close, nil
+}
+
+// Check reports whether slot can still be booked at now for an order that
+// takes cook to prepare. Service hours are read in slot's location.
+func (r Rules) Check(slot, now time.Time, cook time.Duration) error {
+	if !slot.Equal(SlotStart(slot)) {
+		return ErrInvalidSlot
+	}
+	if slot.Before(now.Add(r.MinLead + cook)) {
+		return ErrTooSoon
+	}
+	if slot.After(now.Add(r.MaxAhead)) {
+		return ErrTooFar
+	}
+	y, m, d := slot.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, slot.Location())
+	if since := slot.Sub(midnight); since < r.Open || since+SlotLength > r.Close {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Day lists the slots on the day of date, in date's location, that can be
+// booked at now. booked holds the orders already in each slot, keyed by
+// the slot's Unix time.
+func (r Rules) Day(date, now time.Time, booked map[int64]int) []Slot {
+	y, m, d := date.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+	var slots []Slot
+	for start := midnight.Add(r.Open); !start.Add(SlotLength).After(midnight.Add(r.Close)); start = start.Add(SlotLength) {
+		if r.Check(start, now, 0) != nil {
+			continue
+		}
+		remaining := r.Capacity - booked[start.Unix()]
+		if remaining < 0 {
+			remaining = 0
+		}
+		slots = append(slots, Slot{Start: start, Remaining: remaining})
+	}
+	return slots
+}
+
+// ReleaseAt is when the kitchen should start an order due at slot so it is
+// ready on time.
+func ReleaseAt(slot time.Time, cook, buffer time.Duration) time.Time {
+	return slot.Add(-cook - buffer)
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var shanghai = time.FixedZone("CST", 8*3600)
+
+func rules() Rules {
+	return Rules{
+		MinLead:  30 * time.Minute,
+		MaxAhead: 7 * 24 * time.Hour,
+		Capacity: 2,
+		Open:     10 * time.Hour,
+		Close:    22 * time.Hour,
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	open, close, err := ParseHours("10:00-21:30")
+	if err != nil || open != 10*time.Hour || close != 21*time.Hour+30*time.Minute {
+		t.Errorf("ParseHours = %v, %v, %v", open, close, err)
+	}
+	for _, bad := range []string{"", "10-22", "22:00-10:00", "10:00-24:30", "10:75-22:00"} {
+		if _, _, err := ParseHours(bad); !errors.Is(err, ErrInvalidHours) {
+			t.Errorf("ParseHours(%q) = %v, want ErrInvalidHours", bad, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 3, 0, 0, shanghai)
+	cook := 20 * time.Minute
+
+	tests := []struct {
+		slot time.Time
+		want error
+	}{
+		{time.Date(2025, 6, 1, 13, 0, 0, 0, shanghai), nil},
+		{time.Date(2025, 6, 1, 13, 5, 0, 0, shanghai), ErrInvalidSlot},
+		{time.Date(2025, 6, 1, 12, 45, 0, 0, shanghai), ErrTooSoon},
+		{time.Date(2025, 6, 9, 12, 0, 0, 0, shanghai), ErrTooFar},
+		{time.Date(2025, 6, 2, 9, 45, 0, 0, shanghai), ErrClosed},
+		{time.Date(2025, 6, 2, 21, 45, 0, 0, shanghai), nil},
+		{time.Date(2025, 6, 2, 22, 0, 0, 0, shanghai), ErrClosed},
+	}
+	for _, tc := range tests {
+		if err := rules().Check(tc.slot, now, cook); !errors.Is(err, tc.want) {
+			t.Errorf("Check(%s) = %v, want %v", tc.slot.Format("Jan 2 15:04"), err, tc.want)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	now := time.Date(2025, 6, 1, 20, 40, 0, 0, shanghai)
+	full := time.Date(2025, 6, 1, 21, 15, 0, 0, shanghai)
+	slots := rules().Day(now, now, map[int64]int{full.Unix(): 2})
+
+	var starts []string
+	for _, slot := range slots {
+		starts = append(starts, slot.Start.Format("15:04"))
+		if slot.Start.Equal(full) && slot.Remaining != 0 {
+			t.Errorf("full slot has %d remaining", slot.Remaining)
+		}
+		if !slot.Start.Equal(full) && slot.Remaining != 2 {
+			t.Errorf("slot %s has %d remaining, want 2", slot.Start.Format("15:04"), slot.Remaining)
+		}
+	}
+	want := []string{"21:15", "21:30", "21:45"}
+	if len(starts) != len(want) {
+		t.Fatalf("Day = %v, want %v", starts, want)
+	}
+	for i := range want {
+		if starts[i] != want[i] {
+			t.Fatalf("Day = %v, want %v", starts, want)
+		}
+	}
+}
+
+func TestReleaseAt(t *testing.T) {
+	slot := time.Date(2025, 6, 1, 18, 30, 0, 0, shanghai)
+	got := ReleaseAt(slot, 25*time.Minute, 10*time.Minute)
+	if want := time.Date(2025, 6, 1, 17, 55, 0, 0, shanghai); !got.Equal(want) {
+		t.Errorf("ReleaseAt = %v, want %v", got, want)
+	}
+}
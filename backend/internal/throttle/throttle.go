@@ -0,0 +1,54 @@
+// Package throttle slows down repeated failed logins: a few mistakes are
+// free, then each further failure doubles the wait, and enough of them lock
+// the account or address out for a while.
+package throttle
+
+import "time"
+
+// Policy sets how quickly failures are punished
+type Policy struct {
+	// Free is how many failures are allowed before any wait
+	Free int
+	// Base is the wait after the first failure past Free; it doubles with
+	// each further failure up to Max
+	Base time.Duration
+	Max  time.Duration
+	// LockAfter failures lock out for LockFor
+	LockAfter int
+	LockFor   time.Duration
+	// Reset forgets failures older than this
+	Reset time.Duration
+}
+
+// Wait is how long to refuse logins after the given number of consecutive
+// failures
+func (p Policy) Wait(failures int) time.Duration {
+	if failures >= p.LockAfter && p.LockAfter > 0 {
+		return p.LockFor
+	}
+	if failures <= p.Free {
+		return 0
+	}
+	wait := p.Base
+	for i := p.Free + 1; i < failures; i++ {
+		wait *= 2
+		if wait >= p.Max {
+			return p.Max
+		}
+	}
+	if wait > p.Max {
+		return p.Max
+	}
+	return wait
+}
+
+// Locked reports whether failures reach the lockout
+func (p Policy) Locked(failures int) bool {
+	return p.LockAfter > 0 && failures >= p.LockAfter
+}
+
+// BlockedUntil is when the next attempt is allowed after failures, the last
+// of which happened at lastFailure
+func (p Policy) BlockedUntil(failures int, lastFailure time.Time) time.Time {
+	return lastFailure.Add(p.Wait(failures))
+}
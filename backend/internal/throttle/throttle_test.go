@@ -0,0 +1,56 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+var policy = Policy{
+	Free:      3,
+	Base:      time.Second,
+	Max:       20 * time.Second,
+	LockAfter: 10,
+	LockFor:   15 * time.Minute,
+	Reset:     time.Hour,
+}
+
+func TestWait(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{8, 16 * time.Second},
+		{9, 20 * time.Second},
+		{10, 15 * time.Minute},
+		{25, 15 * time.Minute},
+	}
+	for _, c := range cases {
+		if got := policy.Wait(c.failures); got != c.want {
+			t.Errorf("Wait(%d) = %s, want %s", c.failures, got, c.want)
+		}
+	}
+}
+
+func TestLocked(t *testing.T) {
+	if policy.Locked(9) || !policy.Locked(10) {
+		t.Error("expected lockout from the tenth failure")
+	}
+	if (Policy{Free: 1, Base: time.Second, Max: time.Second}).Locked(100) {
+		t.Error("a policy without LockAfter never locks")
+	}
+}
+
+func TestBlockedUntil(t *testing.T) {
+	last := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if got := policy.BlockedUntil(5, last); !got.Equal(last.Add(2 * time.Second)) {
+		t.Errorf("BlockedUntil = %s", got)
+	}
+	if got := policy.BlockedUntil(1, last); !got.Equal(last) {
+		t.Errorf("BlockedUntil = %s, want no wait", got)
+	}
+}
@@ -0,0 +1,100 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA1 secret from RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFCVectors(t *testing.T) {
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, c := range cases {
+		got, err := Code(rfcSecret, time.Unix(c.unix, 0))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != c.want {
+			t.Errorf("Code at %d = %s, want %s", c.unix, got, c.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	current, _ := Code(secret, now)
+	previous, _ := Code(secret, now.Add(-Period))
+	old, _ := Code(secret, now.Add(-3*Period))
+
+	step, ok := Validate(secret, current, now, 1, 0)
+	if !ok || step != Step(now) {
+		t.Fatalf("current code rejected")
+	}
+	if _, ok := Validate(secret, previous, now, 1, 0); !ok {
+		t.Error("code from the previous step should pass with skew 1")
+	}
+	if _, ok := Validate(secret, old, now, 1, 0); ok {
+		t.Error("code from three steps ago should fail")
+	}
+	if _, ok := Validate(secret, current, now, 1, step); ok {
+		t.Error("a used step should not be accepted again")
+	}
+	if _, ok := Validate(secret, "12345", now, 1, 0); ok {
+		t.Error("short code should fail")
+	}
+	if _, ok := Validate("not base32!", current, now, 1, 0); ok {
+		t.Error("invalid secret should fail")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Takeaway", "alice", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Takeaway:alice" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "Takeaway" || q.Get("digits") != "6" {
+		t.Errorf("unexpected parameters %v", q)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := RecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("RecoveryCodes: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, c := range codes {
+		if len(c) != 9 || c[4] != '-' {
+			t.Errorf("unexpected code %q", c)
+		}
+		if NormalizeRecoveryCode(strings.ToUpper(strings.Replace(c, "-", " ", 1))) != c {
+			t.Errorf("normalizing %q did not round-trip", c)
+		}
+		seen[c] = true
+	}
+	if len(seen) != len(codes) {
+		t.Error("expected distinct codes")
+	}
+}
@@ -0,0 +1,84 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		code, err := NewCode()
+		if err != nil {
+			t.Fatalf("NewCode: %v", err)
+		}
+		if len(code) != CodeLength || strings.Trim(code, "0123456789") != "" {
+			t.Fatalf("NewCode = %q, want %d digits", code, CodeLength)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected codes to differ")
+	}
+}
+
+func TestPhoneNumber(t *testing.T) {
+	if got := PhoneNumber(8613800000000); got != "+8613800000000" {
+		t.Errorf("PhoneNumber = %q", got)
+	}
+}
+
+func TestResendWait(t *testing.T) {
+	sent := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if got := ResendWait(sent, sent.Add(20*time.Second), time.Minute); got != 40*time.Second {
+		t.Errorf("ResendWait = %s, want 40s", got)
+	}
+	if got := ResendWait(sent, sent.Add(2*time.Minute), time.Minute); got != 0 {
+		t.Errorf("ResendWait = %s, want 0", got)
+	}
+}
+
+func TestWriterSMS(t *testing.T) {
+	var buf bytes.Buffer
+	sender := &WriterSMS{W: &buf}
+	if err := sender.Send(context.Background(), "+100", "code 123456"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if buf.String() != "SMS to +100: code 123456\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestWebhookSMS(t *testing.T) {
+	var got struct {
+		To   string `json:"to"`
+		Text string `json:"text"`
+	}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.To == "+999" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	sender := WebhookSMS{URL: server.URL, Token: "secret"}
+	if err := sender.Send(context.Background(), "+100", "hello"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.To != "+100" || got.Text != "hello" || auth != "Bearer secret" {
+		t.Errorf("unexpected request %+v, auth %q", got, auth)
+	}
+	if err := sender.Send(context.Background(), "+999", "hello"); !errors.Is(err, ErrSendFailed) {
+		t.Errorf("expected ErrSendFailed, got %v", err)
+	}
+}
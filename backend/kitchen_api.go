@@ -0,0 +1,305 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/pacing"
+)
+
+var (
+    errTakeawayPaused = errors.New("Takeaway ordering is paused right now")
+    errDeliveryPaused = errors.New("Delivery is paused right now")
+    errKitchenBusy    = errors.New("The kitchen is very busy, please try again in a little while")
+)
+
+// kitchenStatus is what customers see of the kitchen's workload
+type kitchenStatus struct {
+    TakeawayOpen bool       `json:"takeaway_open"`
+    DeliveryOpen bool       `json:"delivery_open"`
+    Busy         bool       `json:"busy"`
+    WaitMinutes  int        `json:"wait_minutes"`
+    ResumeAt     *time.Time `json:"resume_at,omitempty"`
+
+    decision pacing.Decision
+}
+
+// accepts checks whether a new order of orderType can be taken. Dine-in
+// guests are always served; scheduled orders are paced by their slot.
+func (k kitchenStatus) accepts(orderType string, scheduled bool) error {
+    switch {
+    case orderType == orderTypeDineIn:
+        return nil
+    case orderType == orderTypeTakeaway && !k.TakeawayOpen:
+        return errTakeawayPaused
+    case orderType == orderTypeDelivery && !k.DeliveryOpen:
+        return errDeliveryPaused
+    case k.Busy && !scheduled:
+        return errKitchenBusy
+    }
+    return nil
+}
+
+func kitchenSettings(row database.KitchenSetting) pacing.Settings {
+    return pacing.Settings{
+        Window:         time.Duration(row.WindowMinutes) * time.Minute,
+        MaxItems:       int(row.MaxItems),
+        MaxPrepMinutes: int(row.MaxPrepMinutes),
+        PushbackAfter:  time.Duration(row.PushbackAfterMinutes) * time.Minute,
+        PauseAfter:     time.Duration(row.PauseAfterMinutes) * time.Minute,
+    }
+}
+
+// getKitchenStatus weighs the orders the kitchen hasn't finished against its
+// capacity and any manual pause
+func getKitchenStatus(queries *database.Queries) (kitchenStatus, database.KitchenSetting, pacing.Load, error) {
+    row, err := queries.GetKitchenSettings(context.Background())
+    if err != nil {
+        return kitchenStatus{}, row, pacing.Load{}, fmt.Errorf("failed to get kitchen settings: %w", err)
+    }
+    backlog, err := queries.GetKitchenBacklog(context.Background())
+    if err != nil {
+        return kitchenStatus{}, row, pacing.Load{}, fmt.Errorf("failed to get kitchen backlog: %w", err)
+    }
+    load := pacing.Load{Items: int(backlog.Items), PrepMinutes: int(backlog.PrepMinutes)}
+    decision := kitchenSettings(row).Decide(load)
+
+    // A manual pause with a resume time lifts itself
+    paused := !row.ResumeAt.Valid || time.Now().Before(row.ResumeAt.Time)
+    status := kitchenStatus{
+        TakeawayOpen: !(row.TakeawayPaused && paused),
+        DeliveryOpen: !(row.DeliveryPaused && paused),
+        Busy:         decision.Paused,
+        WaitMinutes:  int(decision.Wait / time.Minute),
+        decision:     decision,
+    }
+    if (row.TakeawayPaused || row.DeliveryPaused) && paused && row.ResumeAt.Valid {
+        status.ResumeAt = &row.ResumeAt.Time
+    }
+    return status, row, load, nil
+}
+
+// KITCHEN STATUS
+func kitchenStatusHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Kitchen status request received")
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    status, _, _, err := getKitchenStatus(queries)
+    if err != nil {
+        log.Println("Error getting kitchen status:", err)
+        http.Error(writer, "Failed to get kitchen status", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool          `json:"success"`
+        Kitchen kitchenStatus `json:"kitchen"`
+        Message string        `json:"message"`
+    }{
+        Success: true,
+        Kitchen: status,
+        Message: "Kitchen status retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET KITCHEN SETTINGS
+func getKitchenSettingsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get kitchen settings request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    status, settings, backlog, err := getKitchenStatus(queries)
+    if err != nil {
+        log.Println("Error getting kitchen status:", err)
+        http.Error(writer, "Failed to get kitchen status", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success      bool                    `json:"success"`
+        Settings     database.KitchenSetting `json:"settings"`
+        Backlog      pacing.Load             `json:"backlog"`
+        Kitchen      kitchenStatus           `json:"kitchen"`
+        DelayMinutes int                     `json:"delay_minutes"`
+        Message      string                  `json:"message"`
+    }{
+        Success:      true,
+        Settings:     settings,
+        Backlog:      backlog,
+        Kitchen:      status,
+        DelayMinutes: int(status.decision.Delay / time.Minute),
+        Message:      "Kitchen settings retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// UPDATE KITCHEN CAPACITY
+func updateKitchenCapacityHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Update kitchen capacity request received from user:", username)
+
+    type KitchenCapacityRequest struct {
+        WindowMinutes        int32 `json:"window_minutes"`
+        MaxItems             int32 `json:"max_items"`
+        MaxPrepMinutes       int32 `json:"max_prep_minutes"`
+        PushbackAfterMinutes int32 `json:"pushback_after_minutes"`
+        PauseAfterMinutes    int32 `json:"pause_after_minutes"`
+    }
+    type KitchenCapacityResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var capacityReq KitchenCapacityRequest
+    if err := json.NewDecoder(req.Body).Decode(&capacityReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    settings := kitchenSettings(database.KitchenSetting{
+        WindowMinutes:        capacityReq.WindowMinutes,
+        MaxItems:             capacityReq.MaxItems,
+        MaxPrepMinutes:       capacityReq.MaxPrepMinutes,
+        PushbackAfterMinutes: capacityReq.PushbackAfterMinutes,
+        PauseAfterMinutes:    capacityReq.PauseAfterMinutes,
+    })
+    if err := settings.Validate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.UpdateKitchenCapacity(context.Background(), database.UpdateKitchenCapacityParams{
+        WindowMinutes:        capacityReq.WindowMinutes,
+        MaxItems:             capacityReq.MaxItems,
+        MaxPrepMinutes:       capacityReq.MaxPrepMinutes,
+        PushbackAfterMinutes: capacityReq.PushbackAfterMinutes,
+        PauseAfterMinutes:    capacityReq.PauseAfterMinutes,
+        UpdatedBy:            sql.NullInt32{Int32: userID, Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update kitchen capacity", http.StatusInternalServerError)
+        return
+    }
+
+    resp := KitchenCapacityResponse{Success: true, Message: "Kitchen capacity updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// PAUSE ONLINE ORDERING
+func pauseOrderingHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Pause ordering request received from user:", username)
+
+    type PauseOrderingRequest struct {
+        Takeaway        bool  `json:"takeaway"`
+        Delivery        bool  `json:"delivery"`
+        ResumeInMinutes int32 `json:"resume_in_minutes"`
+    }
+    type PauseOrderingResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var pauseReq PauseOrderingRequest
+    if err := json.NewDecoder(req.Body).Decode(&pauseReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if pauseReq.ResumeInMinutes < 0 {
+        http.Error(writer, "resume_in_minutes can't be negative", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // Without resume_in_minutes the pause lasts until it is switched off
+    var resumeAt sql.NullTime
+    if pauseReq.ResumeInMinutes > 0 && (pauseReq.Takeaway || pauseReq.Delivery) {
+        resumeAt = sql.NullTime{Time: time.Now().UTC().Add(time.Duration(pauseReq.ResumeInMinutes) * time.Minute), Valid: true}
+    }
+    err = queries.UpdateKitchenPause(context.Background(), database.UpdateKitchenPauseParams{
+        TakeawayPaused: pauseReq.Takeaway,
+        DeliveryPaused: pauseReq.Delivery,
+        ResumeAt:       resumeAt,
+        UpdatedBy:      sql.NullInt32{Int32: userID, Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update ordering pause", http.StatusInternalServerError)
+        return
+    }
+
+    resp := PauseOrderingResponse{Success: true, Message: "Online ordering updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
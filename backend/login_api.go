@@ -0,0 +1,380 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "fmt"
+    "log"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/throttle"
+)
+
+const (
+    throttleAccount = "account"
+    throttleIP      = "ip"
+)
+
+// Failed logins are counted per username and per client address; the address
+// limits are looser since many customers can share one
+var (
+    accountLoginPolicy = throttle.Policy{
+        Free:      envInt("LOGIN_FREE_ATTEMPTS", 3),
+        Base:      envDuration("LOGIN_BACKOFF_BASE", time.Second),
+        Max:       envDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+        LockAfter: envInt("LOGIN_LOCK_AFTER", 10),
+        LockFor:   envDuration("LOGIN_LOCK_DURATION", 15*time.Minute),
+        Reset:     envDuration("LOGIN_FAILURE_RESET", time.Hour),
+    }
+    ipLoginPolicy = throttle.Policy{
+        Free:      envInt("LOGIN_IP_FREE_ATTEMPTS", 20),
+        Base:      envDuration("LOGIN_BACKOFF_BASE", time.Second),
+        Max:       envDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+        LockAfter: envInt("LOGIN_IP_LOCK_AFTER", 100),
+        LockFor:   envDuration("LOGIN_LOCK_DURATION", 15*time.Minute),
+        Reset:     envDuration("LOGIN_FAILURE_RESET", time.Hour),
+    }
+)
+
+const errLoginFailed = "Invalid username or password"
+
+// dummyPasswordHash is checked against when the username does not exist, so
+// unknown usernames take as long to reject as wrong passwords
+var dummyPasswordHash, _ = auth.HashPassword("no-such-account-0")
+
+// loginSubject is the throttle key for a username, which does not need to
+// belong to an account
+func loginSubject(username string) string {
+    return truncate(strings.ToLower(strings.TrimSpace(username)), 64)
+}
+
+// loginWait is how much longer logins for username from ip are refused
+func loginWait(queries *database.Queries, username, ip string, now time.Time) (time.Duration, error) {
+    var wait time.Duration
+    for _, key := range [][2]string{{throttleAccount, loginSubject(username)}, {throttleIP, ip}} {
+        row, err := queries.GetLoginThrottle(context.Background(), database.GetLoginThrottleParams{
+            Scope:   key[0],
+            Subject: key[1],
+        })
+        if err == sql.ErrNoRows {
+            continue
+        }
+        if err != nil {
+            return 0, err
+        }
+        if left := row.BlockedUntil.Sub(now); left > wait {
+            wait = left
+        }
+    }
+    return wait, nil
+}
+
+// recordLoginFailure counts a failed login against the username and the
+// address and pushes back when they may try again
+func recordLoginFailure(queries *database.Queries, username, ip string, now time.Time) error {
+    keys := []struct {
+        scope   string
+        subject string
+        policy  throttle.Policy
+    }{
+        {throttleAccount, loginSubject(username), accountLoginPolicy},
+        {throttleIP, ip, ipLoginPolicy},
+    }
+    for _, key := range keys {
+        err := queries.AddLoginFailure(context.Background(), database.AddLoginFailureParams{
+            Scope:       key.scope,
+            Subject:     key.subject,
+            Now:         now,
+            ResetBefore: now.Add(-key.policy.Reset),
+        })
+        if err != nil {
+            return err
+        }
+        row, err := queries.GetLoginThrottle(context.Background(), database.GetLoginThrottleParams{
+            Scope:   key.scope,
+            Subject: key.subject,
+        })
+        if err != nil {
+            return err
+        }
+        if key.policy.Locked(int(row.Failures)) {
+            log.Printf("Login locked for %s %s after %d failures", key.scope, key.subject, row.Failures)
+        }
+        err = queries.SetLoginBlockedUntil(context.Background(), database.SetLoginBlockedUntilParams{
+            BlockedUntil: key.policy.BlockedUntil(int(row.Failures), now),
+            Scope:        key.scope,
+            Subject:      key.subject,
+        })
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// clearLoginFailures starts the username's count over after a right
+// password. Failures from the address keep counting.
+func clearLoginFailures(queries *database.Queries, username string) error {
+    _, err := queries.ClearLoginThrottle(context.Background(), database.ClearLoginThrottleParams{
+        Scope:   throttleAccount,
+        Subject: loginSubject(username),
+    })
+    return err
+}
+
+// verifyPassword re-checks the password of a signed-in account before a
+// sensitive change. Wrong passwords count as failed logins, so a stolen
+// access token can't be used to guess the password. It answers the request
+// itself with message and returns false unless the password was right.
+func verifyPassword(writer http.ResponseWriter, req *http.Request, queries *database.Queries, account database.Account, password, message string, now time.Time) bool {
+    ip := truncate(clientIP(req), 64)
+    wait, err := loginWait(queries, account.Username, ip, now)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return false
+    }
+    if wait > 0 {
+        writeLoginThrottled(writer, wait)
+        return false
+    }
+
+    if auth.CheckPasswordHash(password, account.Password) != nil {
+        log.Println("Wrong password for user:", account.Username)
+        if err := recordLoginFailure(queries, account.Username, ip, now); err != nil {
+            log.Println("Error recording login failure:", err)
+        }
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "wrong_password")
+        http.Error(writer, message, http.StatusBadRequest)
+        return false
+    }
+    if err := clearLoginFailures(queries, account.Username); err != nil {
+        log.Println("Error clearing login throttle:", err)
+    }
+    return true
+}
+
+// recordLoginAttempt adds a row to the login audit log
+func recordLoginAttempt(queries *database.Queries, req *http.Request, userID int32, username string, success bool, reason string) {
+    err := queries.CreateLoginAttempt(context.Background(), database.CreateLoginAttemptParams{
+        UserID:    sql.NullInt32{Int32: userID, Valid: userID != 0},
+        Username:  truncate(username, 30),
+        Ip:        truncate(clientIP(req), 64),
+        UserAgent: truncate(req.UserAgent(), 255),
+        Success:   success,
+        Reason:    reason,
+        CreatedAt: time.Now().UTC(),
+    })
+    if err != nil {
+        log.Println("Failed to record login attempt:", err)
+    }
+}
+
+// writeLoginThrottled answers a login that came too soon after failures
+func writeLoginThrottled(writer http.ResponseWriter, wait time.Duration) {
+    writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+    http.Error(writer, "Too many failed attempts, try again later", http.StatusTooManyRequests)
+}
+
+// purgeLoginThrottles drops counters that have been forgotten
+func purgeLoginThrottles() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Login throttles: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    now := time.Now().UTC()
+    err = database.New(db).DeleteStaleLoginThrottles(context.Background(), database.DeleteStaleLoginThrottlesParams{
+        LastFailureAt: now.Add(-accountLoginPolicy.Reset),
+        BlockedUntil:  now,
+    })
+    if err != nil {
+        log.Println("Login throttles: failed to delete stale rows:", err)
+    }
+}
+
+// ADMIN: UNLOCK LOGIN
+func unlockLoginHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Unlock login request received from user:", username)
+
+    type UnlockRequest struct {
+        UserID int32  `json:"user_id"`
+        IP     string `json:"ip"`
+    }
+    type UnlockResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var unlockReq UnlockRequest
+    if err := json.NewDecoder(req.Body).Decode(&unlockReq); err != nil || (unlockReq.UserID == 0 && unlockReq.IP == "") {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    var cleared int64
+    if unlockReq.UserID != 0 {
+        account, err := queries.GetAccountByID(context.Background(), unlockReq.UserID)
+        if err == sql.ErrNoRows {
+            http.Error(writer, "User not found", http.StatusNotFound)
+            return
+        }
+        if err != nil {
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        n, err := queries.ClearLoginThrottle(context.Background(), database.ClearLoginThrottleParams{
+            Scope:   throttleAccount,
+            Subject: loginSubject(account.Username),
+        })
+        if err != nil {
+            http.Error(writer, "Failed to unlock", http.StatusInternalServerError)
+            return
+        }
+        cleared += n
+        log.Printf("User %s unlocked logins for %s", username, account.Username)
+        if err := recordAudit(queries, adminID, account.ID, "login.unlock", "", nil); err != nil {
+            log.Println("Failed to record audit log:", err)
+        }
+    }
+    if unlockReq.IP != "" {
+        n, err := queries.ClearLoginThrottle(context.Background(), database.ClearLoginThrottleParams{
+            Scope:   throttleIP,
+            Subject: unlockReq.IP,
+        })
+        if err != nil {
+            http.Error(writer, "Failed to unlock", http.StatusInternalServerError)
+            return
+        }
+        cleared += n
+        log.Printf("User %s unlocked logins from %s", username, unlockReq.IP)
+    }
+
+    message := "Nothing was locked"
+    if cleared > 0 {
+        message = "Login unlocked"
+    }
+    resp := UnlockResponse{Success: true, Message: message}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: GET LOGIN ATTEMPTS
+func getLoginAttemptsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get login attempts request received from user:", username)
+
+    type LoginAttempt struct {
+        AttemptID int32     `json:"attempt_id"`
+        UserID    *int32    `json:"user_id"`
+        Username  string    `json:"username"`
+        IP        string    `json:"ip"`
+        UserAgent string    `json:"user_agent"`
+        Success   bool      `json:"success"`
+        Reason    string    `json:"reason"`
+        CreatedAt time.Time `json:"created_at"`
+    }
+    type GetLoginAttemptsResponse struct {
+        Success  bool           `json:"success"`
+        Attempts []LoginAttempt `json:"attempts"`
+        Message  string         `json:"message"`
+    }
+
+    var limit int32 = 100
+    if s := req.URL.Query().Get("limit"); s != "" {
+        if _, err := fmt.Sscanf(s, "%d", &limit); err != nil || limit <= 0 || limit > 500 {
+            http.Error(writer, "Invalid limit", http.StatusBadRequest)
+            return
+        }
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    var rows []database.LoginAttempt
+    if s := req.URL.Query().Get("user_id"); s != "" {
+        var userID int32
+        if _, err := fmt.Sscanf(s, "%d", &userID); err != nil {
+            http.Error(writer, "Invalid user_id", http.StatusBadRequest)
+            return
+        }
+        rows, err = queries.GetLoginAttemptsByUser(context.Background(), database.GetLoginAttemptsByUserParams{
+            UserID: sql.NullInt32{Int32: userID, Valid: true},
+            Limit:  limit,
+        })
+    } else if ip := req.URL.Query().Get("ip"); ip != "" {
+        rows, err = queries.GetLoginAttemptsByIP(context.Background(), database.GetLoginAttemptsByIPParams{
+            Ip:    ip,
+            Limit: limit,
+        })
+    } else {
+        http.Error(writer, "Missing user_id or ip", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        http.Error(writer, "Failed to get login attempts", http.StatusInternalServerError)
+        return
+    }
+
+    attempts := make([]LoginAttempt, 0, len(rows))
+    for _, row := range rows {
+        attempt := LoginAttempt{
+            AttemptID: row.AttemptID,
+            Username:  row.Username,
+            IP:        row.Ip,
+            UserAgent: row.UserAgent,
+            Success:   row.Success,
+            Reason:    row.Reason,
+            CreatedAt: row.CreatedAt,
+        }
+        if row.UserID.Valid {
+            id := row.UserID.Int32
+            attempt.UserID = &id
+        }
+        attempts = append(attempts, attempt)
+    }
+
+    resp := GetLoginAttemptsResponse{Success: true, Attempts: attempts, Message: "Login attempts retrieved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
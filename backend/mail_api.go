@@ -0,0 +1,139 @@
+package main
+
+import(
+	"database/sql"
+	"context"
+    "log"
+    "os"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/mail"
+)
+
+const (
+    // mailMaxAttempts is how often a message is tried before it is marked failed
+    mailMaxAttempts = 8
+    mailTimeout     = 30 * time.Second
+)
+
+// mailRetention is how long sent and failed messages stay in the outbox.
+// Their bodies, which can hold reset and verification links, are cleared as
+// soon as they are sent or given up on.
+var mailRetention = envDuration("MAIL_RETENTION", 30*24*time.Hour)
+
+// mailSender delivers the outbox. MAIL_TRANSPORT picks "smtp" (SMTP_ADDR,
+// SMTP_USERNAME, SMTP_PASSWORD), "file" (MAIL_DIR) or "stdout", the default.
+var mailSender = loadMailSender()
+
+func loadMailSender() mail.Sender {
+    from := envString("MAIL_FROM", "no-reply@localhost")
+    switch transport := envString("MAIL_TRANSPORT", "stdout"); transport {
+    case "smtp":
+        return mail.SMTPSender{
+            Addr:     envString("SMTP_ADDR", "localhost:25"),
+            From:     from,
+            Username: os.Getenv("SMTP_USERNAME"),
+            Password: os.Getenv("SMTP_PASSWORD"),
+        }
+    case "file":
+        return mail.DirSender{Dir: envString("MAIL_DIR", "./mail"), From: from}
+    case "stdout":
+        return &mail.WriterSender{W: os.Stdout, From: from}
+    default:
+        log.Printf("Invalid MAIL_TRANSPORT %q, using stdout", transport)
+        return &mail.WriterSender{W: os.Stdout, From: from}
+    }
+}
+
+// queueMail adds a message for the account userID to the outbox; the mail
+// worker sends it
+func queueMail(queries *database.Queries, userID int32, to, subject, body string) error {
+    return queries.CreateMail(context.Background(), database.CreateMailParams{
+        UserID:        sql.NullInt32{Int32: userID, Valid: userID != 0},
+        Recipient:     to,
+        Subject:       subject,
+        Body:          body,
+        NextAttemptAt: time.Now().UTC(),
+    })
+}
+
+// processMailOutbox sends queued messages, retrying failures with backoff
+func processMailOutbox() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Mail: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    now := time.Now().UTC()
+
+    // Messages left mid-send by a crash go back in the queue
+    err = queries.RequeueStaleMail(context.Background(), sql.NullTime{Time: now.Add(-2 * mailTimeout), Valid: true})
+    if err != nil {
+        log.Println("Mail: failed to requeue stale messages:", err)
+    }
+    err = queries.PurgeMail(context.Background(), sql.NullTime{Time: now.Add(-mailRetention), Valid: true})
+    if err != nil {
+        log.Println("Mail: failed to purge old messages:", err)
+    }
+    // The last message to an anonymized account goes once it is delivered
+    err = queries.PurgeDeletedAccountMail(context.Background())
+    if err != nil {
+        log.Println("Mail: failed to purge mail of deleted accounts:", err)
+    }
+
+    messages, err := queries.GetDueMail(context.Background(), database.GetDueMailParams{
+        NextAttemptAt: now,
+        Limit:         20,
+    })
+    if err != nil {
+        log.Println("Mail: failed to get messages:", err)
+        return
+    }
+
+    for _, message := range messages {
+        rows, err := queries.ClaimMail(context.Background(), database.ClaimMailParams{
+            ClaimedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+            MailID:    message.MailID,
+        })
+        if err != nil || rows == 0 {
+            continue
+        }
+        attempt := int(message.Attempts) + 1
+
+        ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+        err = mailSender.Send(ctx, mail.Message{To: message.Recipient, Subject: message.Subject, Body: message.Body})
+        cancel()
+        if err == nil {
+            err = queries.MarkMailSent(context.Background(), database.MarkMailSentParams{
+                SentAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+                MailID: message.MailID,
+            })
+            if err != nil {
+                log.Println("Mail: failed to mark message sent:", err)
+            }
+            continue
+        }
+
+        log.Printf("Mail: message %d attempt %d failed: %v", message.MailID, attempt, err)
+        lastError := truncate(err.Error(), 255)
+        if attempt >= mailMaxAttempts {
+            err = queries.FailMail(context.Background(), database.FailMailParams{
+                LastError: sql.NullString{String: lastError, Valid: true},
+                MailID:    message.MailID,
+            })
+        } else {
+            err = queries.RetryMail(context.Background(), database.RetryMailParams{
+                LastError:     sql.NullString{String: lastError, Valid: true},
+                NextAttemptAt: time.Now().UTC().Add(mail.RetryDelay(attempt)),
+                MailID:        message.MailID,
+            })
+        }
+        if err != nil {
+            log.Println("Mail: failed to update message:", err)
+        }
+    }
+}
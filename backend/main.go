@@ -1,51 +1,165 @@
 package main
 
 import _ "github.com/go-sql-driver/mysql"
+import _ "time/tzdata"
 
 import(
 	"log"
 	"net/http"
 	"fmt"
+	"time"
 
 	"github.com/rs/cors"
+
+	"github.com/Bryanthai/ordersystem/internal/rbac"
 )
 
 func initServeMux(serveMux *http.ServeMux) {
 	serveMux.HandleFunc("POST /users/login", loginHandler) //done
 	serveMux.HandleFunc("POST /users/register", registerHandler) //done
-	serveMux.HandleFunc("PUT /users/change-info", alterAccountHandler) //done
-	serveMux.HandleFunc("GET /users", getCurrentAccount) //done
+	serveMux.HandleFunc("POST /users/login/2fa", loginTwoFactorHandler)
+	serveMux.HandleFunc("GET /users/2fa", requireAuth(getTwoFactorHandler))
+	serveMux.HandleFunc("POST /users/2fa/enroll", requireAuth(enrollTwoFactorHandler))
+	serveMux.HandleFunc("POST /users/2fa/confirm", requireAuth(confirmTwoFactorHandler))
+	serveMux.HandleFunc("DELETE /users/2fa", requireAuth(disableTwoFactorHandler))
+	serveMux.HandleFunc("POST /users/2fa/recovery-codes", requireAuth(regenerateRecoveryCodesHandler))
+	serveMux.HandleFunc("POST /users/refresh", refreshTokenHandler)
+	serveMux.HandleFunc("PUT /users/password", requireAuth(changePasswordHandler))
+	serveMux.HandleFunc("POST /users/password/forgot", forgotPasswordHandler)
+	serveMux.HandleFunc("POST /users/password/reset", resetPasswordHandler)
+	serveMux.HandleFunc("GET /users/export", requireAuth(exportDataHandler))
+	serveMux.HandleFunc("GET /users/deletion", requireAuth(getDeletionHandler))
+	serveMux.HandleFunc("POST /users/deletion", requireAuth(requestDeletionHandler))
+	serveMux.HandleFunc("DELETE /users/deletion", requireAuth(cancelDeletionHandler))
+	serveMux.HandleFunc("GET /users/verify", requireAuth(getVerificationHandler))
+	serveMux.HandleFunc("POST /users/verify/email", verifyEmailHandler)
+	serveMux.HandleFunc("POST /users/verify/phone", requireAuth(verifyPhoneHandler))
+	serveMux.HandleFunc("POST /users/verify/resend", requireAuth(resendVerificationHandler))
+	serveMux.HandleFunc("POST /users/logout", requireAuth(logoutHandler))
+	serveMux.HandleFunc("GET /users/sessions", requireAuth(getSessionsHandler))
+	serveMux.HandleFunc("DELETE /users/sessions", requireAuth(revokeSessionHandler))
+	serveMux.HandleFunc("PUT /users/change-info", requireAuth(alterAccountHandler)) //done
+	serveMux.HandleFunc("GET /users", requireAuth(getCurrentAccount)) //done
+	serveMux.HandleFunc("GET /users/addresses", requireAuth(getAddressesHandler))
+	serveMux.HandleFunc("POST /users/addresses", requireAuth(saveAddressHandler))
+	serveMux.HandleFunc("PUT /users/addresses", requireAuth(saveAddressHandler))
+	serveMux.HandleFunc("DELETE /users/addresses", requireAuth(deleteAddressHandler))
+	serveMux.HandleFunc("GET /users/notifications", requireAuth(getNotificationsHandler))
+	serveMux.HandleFunc("PUT /users/notifications/read", requireAuth(readNotificationsHandler))
 	
-	serveMux.HandleFunc("PUT /payment", MakePayment) //done
-	serveMux.HandleFunc("PUT /recharge", RechargeAccount) //done
+	serveMux.HandleFunc("PUT /payment", requireAuth(withIdempotency(MakePayment))) //done
+	serveMux.HandleFunc("PUT /recharge", requireAuth(withIdempotency(RechargeAccount))) //done
+	serveMux.HandleFunc("POST /payments/intents", requireAuth(withIdempotency(createPaymentIntentHandler)))
+	serveMux.HandleFunc("POST /payments/intents/confirm", requireAuth(withIdempotency(confirmPaymentIntentHandler)))
+	serveMux.HandleFunc("GET /payments/intents", requireAuth(getPaymentIntentHandler))
+	serveMux.HandleFunc("POST /payments/webhook/{provider}", paymentWebhookHandler)
+	serveMux.HandleFunc("POST /giftcards/purchase", requireAuth(withIdempotency(purchaseGiftCardHandler)))
+	serveMux.HandleFunc("PUT /giftcards/redeem", requireAuth(withIdempotency(redeemGiftCardHandler)))
+	serveMux.HandleFunc("GET /giftcards/balance", requireAuth(giftCardBalanceHandler))
+	serveMux.HandleFunc("GET /giftcards/mine", requireAuth(getMyGiftCardsHandler))
+	serveMux.HandleFunc("GET /delivery/quote", requireAuth(deliveryQuoteHandler))
+	serveMux.HandleFunc("GET /kitchen/status", kitchenStatusHandler)
+	serveMux.HandleFunc("GET /store/status", storeStatusHandler)
+	serveMux.HandleFunc("GET /reservations/availability", reservationAvailabilityHandler)
+	serveMux.HandleFunc("POST /reservations", requireVerified(withIdempotency(createReservationHandler)))
+	serveMux.HandleFunc("PUT /reservations", requireAuth(updateReservationHandler))
+	serveMux.HandleFunc("DELETE /reservations", requireAuth(cancelReservationHandler))
+	serveMux.HandleFunc("GET /reservations", requireAuth(getReservationsHandler))
+	serveMux.HandleFunc("POST /waitlist", requireAuth(joinWaitlistHandler))
+	serveMux.HandleFunc("GET /waitlist", requireAuth(getWaitlistStatusHandler))
+	serveMux.HandleFunc("DELETE /waitlist", requireAuth(leaveWaitlistHandler))
+	serveMux.HandleFunc("PUT /rider/status", requirePermission(rbac.PermRide, riderStatusHandler))
+	serveMux.HandleFunc("POST /rider/location", requirePermission(rbac.PermRide, riderLocationHandler))
+	serveMux.HandleFunc("GET /rider/deliveries", requirePermission(rbac.PermRide, getRiderDeliveriesHandler))
+	serveMux.HandleFunc("PUT /rider/deliveries", requirePermission(rbac.PermRide, updateDeliveryHandler))
 
-	serveMux.HandleFunc("POST /foods", createFoodHandler) //done
-	serveMux.HandleFunc("PUT /foods/change-info", alterFoodHandler) //done
-	serveMux.HandleFunc("DELETE /foods", deleteFoodHandler) //done
+	serveMux.HandleFunc("POST /foods", requirePermission(rbac.PermMenu, createFoodHandler)) //done
+	serveMux.HandleFunc("PUT /foods/change-info", requirePermission(rbac.PermMenu, alterFoodHandler)) //done
+	serveMux.HandleFunc("DELETE /foods", requirePermission(rbac.PermMenu, deleteFoodHandler)) //done
 	serveMux.HandleFunc("GET /foods", getFoodByIdHandler) //done
 	serveMux.HandleFunc("GET /foods/tags", GetFoodTagByFoodNameHandler) //done
 
-	serveMux.HandleFunc("POST /orders", createOrderHandler) //done
-	serveMux.HandleFunc("DELETE /orders", deleteOrderHandler) //done
-	serveMux.HandleFunc("GET /orders/user", getOrderStatusHandler) //done
-	serveMux.HandleFunc("GET /orders/info", GetOrderByIdHandler) //done
-	serveMux.HandleFunc("PUT /orders/rate", rateOrderedItems) //done
-	serveMux.HandleFunc("PUT /orders/feedback", UpdateFeedback) //done
-	serveMux.HandleFunc("GET /orders/items", getOrderedItemsHandler) //done
-	serveMux.HandleFunc("PUT /orders/finish", finishOrder) //done
-	serveMux.HandleFunc("GET /orders/price", GetOrderTotalPrice) //done
-	serveMux.HandleFunc("GET /orders/all-items", GetAllOrderedItemsHandler) //done
+	serveMux.HandleFunc("POST /orders", requireVerified(withIdempotency(createOrderHandler))) //done
+	serveMux.HandleFunc("DELETE /orders", requirePermission(rbac.PermOrdersManage, deleteOrderHandler)) //done
+	serveMux.HandleFunc("GET /orders/user", requireAuth(getOrderStatusHandler)) //done
+	serveMux.HandleFunc("GET /orders/info", requireAuth(GetOrderByIdHandler)) //done
+	serveMux.HandleFunc("PUT /orders/rate", requireAuth(rateOrderedItems)) //done
+	serveMux.HandleFunc("PUT /orders/feedback", requireAuth(UpdateFeedback)) //done
+	serveMux.HandleFunc("GET /orders/items", requireAuth(getOrderedItemsHandler)) //done
+	serveMux.HandleFunc("PUT /orders/finish", requirePermission(rbac.PermOrdersPrep, finishOrder)) //done
+	serveMux.HandleFunc("GET /orders/price", requireAuth(GetOrderTotalPrice)) //done
+	serveMux.HandleFunc("GET /orders/all-items", requireAuth(GetAllOrderedItemsHandler)) //done
+	serveMux.HandleFunc("POST /orders/split", requireAuth(splitBillHandler))
+	serveMux.HandleFunc("DELETE /orders/split", requireAuth(cancelSplitHandler))
+	serveMux.HandleFunc("GET /orders/shares", requireAuth(getBillSharesHandler))
+	serveMux.HandleFunc("PUT /orders/shares/pay", requireAuth(withIdempotency(payBillShareHandler)))
+	serveMux.HandleFunc("POST /orders/tip", requireAuth(withIdempotency(tipOrderHandler)))
+	serveMux.HandleFunc("GET /orders/receipt", requireAuth(getReceiptHandler))
+	serveMux.HandleFunc("PUT /orders/invoice", requireAuth(updateInvoiceDetailsHandler))
+	serveMux.HandleFunc("GET /orders/delivery", requireAuth(getOrderDeliveryHandler))
+	serveMux.HandleFunc("GET /orders/delivery/photo", requireAuth(getDeliveryPhotoHandler))
+	serveMux.HandleFunc("GET /orders/slots", requireAuth(getSlotsHandler))
 
 	serveMux.HandleFunc("GET /menu", getAllFoodHandler) //done
 	serveMux.HandleFunc("GET /menu/rating-times-info", getFoodRatingandOrderedTimesByFoodID) //done
 	serveMux.HandleFunc("GET /menu/sort-type", getFoodByTypeHandler) //done
-	serveMux.HandleFunc("GET /menu/sort-by-usertag", getFoodByUserTag) //done
+	serveMux.HandleFunc("GET /menu/sort-by-usertag", requireAuth(getFoodByUserTag)) //done
 
-	serveMux.HandleFunc("GET /admin/users", GetAllUsers) //done
-	serveMux.HandleFunc("GET /admin/undone-orders", getAllUndoneOrder) //done
-	serveMux.HandleFunc("GET /admin/total-average", GetAverageSpendingAll) //done
-	serveMux.HandleFunc("GET /admin/total-average-by-user", GetAverageSpendingByUser) //done
-	serveMux.HandleFunc("GET /admin/orders-all", getAllOrdersHandler) //done
+	serveMux.HandleFunc("GET /admin/users", requirePermission(rbac.PermUsers, GetAllUsers)) //done
+	serveMux.HandleFunc("GET /admin/undone-orders", requirePermission(rbac.PermOrdersPrep, getAllUndoneOrder)) //done
+	serveMux.HandleFunc("GET /admin/scheduled-orders", requirePermission(rbac.PermOrdersView, getScheduledOrdersHandler))
+	serveMux.HandleFunc("GET /admin/total-average", requirePermission(rbac.PermReports, GetAverageSpendingAll)) //done
+	serveMux.HandleFunc("GET /admin/total-average-by-user", requirePermission(rbac.PermReports, GetAverageSpendingByUser)) //done
+	serveMux.HandleFunc("GET /admin/orders-all", requirePermission(rbac.PermOrdersView, getAllOrdersHandler)) //done
+	serveMux.HandleFunc("POST /admin/payments/refund", requirePermission(rbac.PermRefunds, refundPaymentHandler))
+	serveMux.HandleFunc("GET /admin/tips/report", requirePermission(rbac.PermReports, tipReportHandler))
+	serveMux.HandleFunc("POST /admin/giftcards", requirePermission(rbac.PermGiftCards, issueGiftCardHandler))
+	serveMux.HandleFunc("GET /admin/giftcards", requirePermission(rbac.PermGiftCards, getAllGiftCardsHandler))
+	serveMux.HandleFunc("DELETE /admin/giftcards", requirePermission(rbac.PermGiftCards, voidGiftCardHandler))
+	serveMux.HandleFunc("GET /admin/giftcards/transactions", requirePermission(rbac.PermGiftCards, getGiftCardTransactionsHandler))
+	serveMux.HandleFunc("POST /admin/printers", requirePermission(rbac.PermPrinters, setPrinterHandler))
+	serveMux.HandleFunc("GET /admin/printers", requirePermission(rbac.PermPrinters, getPrintersHandler))
+	serveMux.HandleFunc("DELETE /admin/printers", requirePermission(rbac.PermPrinters, deletePrinterHandler))
+	serveMux.HandleFunc("PUT /admin/foods/station", requirePermission(rbac.PermPrinters, setFoodStationHandler))
+	serveMux.HandleFunc("GET /admin/print/jobs", requirePermission(rbac.PermOrdersPrep, getPrintJobsHandler))
+	serveMux.HandleFunc("POST /admin/print/receipt", requirePermission(rbac.PermOrdersPrep, printReceiptHandler))
+	serveMux.HandleFunc("POST /admin/print/reprint", requirePermission(rbac.PermOrdersPrep, reprintHandler))
+	serveMux.HandleFunc("POST /admin/zones", requirePermission(rbac.PermDelivery, saveDeliveryZoneHandler))
+	serveMux.HandleFunc("PUT /admin/zones", requirePermission(rbac.PermDelivery, saveDeliveryZoneHandler))
+	serveMux.HandleFunc("GET /admin/zones", requirePermission(rbac.PermDelivery, getDeliveryZonesHandler))
+	serveMux.HandleFunc("DELETE /admin/zones", requirePermission(rbac.PermDelivery, deleteDeliveryZoneHandler))
+	serveMux.HandleFunc("POST /admin/riders", requirePermission(rbac.PermDelivery, addRiderHandler))
+	serveMux.HandleFunc("GET /admin/riders", requirePermission(rbac.PermDelivery, getRidersHandler))
+	serveMux.HandleFunc("DELETE /admin/riders", requirePermission(rbac.PermDelivery, removeRiderHandler))
+	serveMux.HandleFunc("POST /admin/deliveries/assign", requirePermission(rbac.PermDelivery, assignDeliveryHandler))
+	serveMux.HandleFunc("GET /admin/deliveries", requirePermission(rbac.PermDelivery, getDeliveriesHandler))
+	serveMux.HandleFunc("GET /admin/kitchen", requirePermission(rbac.PermKitchen, getKitchenSettingsHandler))
+	serveMux.HandleFunc("PUT /admin/kitchen/capacity", requirePermission(rbac.PermKitchen, updateKitchenCapacityHandler))
+	serveMux.HandleFunc("PUT /admin/kitchen/pause", requirePermission(rbac.PermKitchen, pauseOrderingHandler))
+	serveMux.HandleFunc("GET /admin/hours", requirePermission(rbac.PermStore, getOpeningHoursHandler))
+	serveMux.HandleFunc("PUT /admin/hours", requirePermission(rbac.PermStore, setOpeningHoursHandler))
+	serveMux.HandleFunc("POST /admin/holidays", requirePermission(rbac.PermStore, saveHolidayHandler))
+	serveMux.HandleFunc("DELETE /admin/holidays", requirePermission(rbac.PermStore, deleteHolidayHandler))
+	serveMux.HandleFunc("POST /admin/tables", requirePermission(rbac.PermStore, saveDiningTableHandler))
+	serveMux.HandleFunc("GET /admin/tables", requirePermission(rbac.PermStore, getDiningTablesHandler))
+	serveMux.HandleFunc("GET /admin/reservations", requirePermission(rbac.PermReservations, getAllReservationsHandler))
+	serveMux.HandleFunc("PUT /admin/reservations/seat", requirePermission(rbac.PermReservations, seatReservationHandler))
+	serveMux.HandleFunc("PUT /admin/reservations/no-show", requirePermission(rbac.PermReservations, noShowReservationHandler))
+	serveMux.HandleFunc("POST /admin/waitlist", requirePermission(rbac.PermReservations, addWalkInHandler))
+	serveMux.HandleFunc("GET /admin/waitlist", requirePermission(rbac.PermReservations, getWaitlistHandler))
+	serveMux.HandleFunc("PUT /admin/waitlist", requirePermission(rbac.PermReservations, updateWaitlistHandler))
+	serveMux.HandleFunc("GET /admin/staff", requirePermission(rbac.PermRoles, getStaffHandler))
+	serveMux.HandleFunc("GET /admin/users/roles", requirePermission(rbac.PermRoles, getUserRolesHandler))
+	serveMux.HandleFunc("POST /admin/users/roles", requirePermission(rbac.PermRoles, grantRoleHandler))
+	serveMux.HandleFunc("DELETE /admin/users/roles", requirePermission(rbac.PermRoles, revokeRoleHandler))
+	serveMux.HandleFunc("POST /admin/users/unlock", requirePermission(rbac.PermUsersManage, unlockLoginHandler))
+	serveMux.HandleFunc("GET /admin/users/logins", requirePermission(rbac.PermUsers, getLoginAttemptsHandler))
+	serveMux.HandleFunc("PUT /admin/users", requirePermission(rbac.PermUsersManage, editUserHandler))
+	serveMux.HandleFunc("DELETE /admin/users", requirePermission(rbac.PermUsersManage, deleteUserHandler))
+	serveMux.HandleFunc("PUT /admin/users/status", requirePermission(rbac.PermUsersManage, setUserStatusHandler))
+	serveMux.HandleFunc("POST /admin/users/wallet", requirePermission(rbac.PermUsersManage, withIdempotency(adjustWalletHandler)))
+	serveMux.HandleFunc("POST /admin/users/password-reset", requirePermission(rbac.PermUsersManage, adminResetPasswordHandler))
+	serveMux.HandleFunc("GET /admin/audit", requirePermission(rbac.PermUsers, getAuditLogHandler))
 
 	serveMux.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir("./static/images/food"))))
 }
@@ -53,12 +167,20 @@ func initServeMux(serveMux *http.ServeMux) {
 func main() {
 	serveMux := http.NewServeMux()
 	initServeMux(serveMux)
+	go runEvery("print queue", envDuration("PRINT_INTERVAL", 5*time.Second), processPrintQueue)
+	go runEvery("dispatch", envDuration("DISPATCH_INTERVAL", 30*time.Second), dispatchReadyDeliveries)
+	go runEvery("scheduler", envDuration("SCHEDULE_INTERVAL", time.Minute), processScheduledOrders)
+	go runEvery("mail", envDuration("MAIL_INTERVAL", 10*time.Second), processMailOutbox)
+	go runEvery("sessions", envDuration("SESSION_PURGE_INTERVAL", time.Hour), purgeSessions)
+	go runEvery("login throttles", envDuration("LOGIN_THROTTLE_PURGE_INTERVAL", time.Hour), purgeLoginThrottles)
+	go runEvery("login challenges", envDuration("LOGIN_CHALLENGE_PURGE_INTERVAL", time.Hour), purgeLoginChallenges)
+	go runEvery("deletions", envDuration("DELETION_INTERVAL", time.Hour), processDeletionRequests)
 	fmt.Println("Server is running on port 8080...")
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:8080", "http://localhost:5173"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Idempotency-Key"},
 		AllowCredentials: true,
 	})
 
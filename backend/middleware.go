@@ -0,0 +1,357 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"context"
+	"encoding/json"
+    "errors"
+    "log"
+    "os"
+    "strconv"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/idempotency"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+// envDuration reads a duration such as "24h" from the environment
+func envDuration(name string, fallback time.Duration) time.Duration {
+    value := os.Getenv(name)
+    if value == "" {
+        return fallback
+    }
+    d, err := time.ParseDuration(value)
+    if err != nil || d <= 0 {
+        log.Printf("Invalid %s %q, using %s", name, value, fallback)
+        return fallback
+    }
+    return d
+}
+
+// envString reads a setting from the environment
+func envString(name, fallback string) string {
+    if value := os.Getenv(name); value != "" {
+        return value
+    }
+    return fallback
+}
+
+// envFloat reads a non-negative number such as "8.5" from the environment
+func envFloat(name string, fallback float64) float64 {
+    value := os.Getenv(name)
+    if value == "" {
+        return fallback
+    }
+    f, err := strconv.ParseFloat(value, 64)
+    if err != nil || f < 0 {
+        log.Printf("Invalid %s %q, using %g", name, value, fallback)
+        return fallback
+    }
+    return f
+}
+
+// envCoordinate reads a latitude or longitude from the environment. It may
+// be negative but must lie within -limit..limit.
+func envCoordinate(name string, fallback, limit float64) float64 {
+    value := os.Getenv(name)
+    if value == "" {
+        return fallback
+    }
+    f, err := strconv.ParseFloat(value, 64)
+    if err != nil || !(f >= -limit && f <= limit) {
+        log.Printf("Invalid %s %q, using %g", name, value, fallback)
+        return fallback
+    }
+    return f
+}
+
+// envInt reads a positive whole number from the environment
+func envInt(name string, fallback int) int {
+    value := os.Getenv(name)
+    if value == "" {
+        return fallback
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil || n <= 0 {
+        log.Printf("Invalid %s %q, using %d", name, value, fallback)
+        return fallback
+    }
+    return n
+}
+
+// idempotencyStore keeps idempotency keys in the idempotency_keys table
+type idempotencyStore struct{}
+
+func (idempotencyStore) Reserve(ctx context.Context, rec idempotency.Record, now time.Time) (idempotency.Record, bool, error) {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        return rec, false, err
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.DeleteExpiredIdempotencyKeys(ctx, now)
+    if err != nil {
+        return rec, false, err
+    }
+    rows, err := queries.ReserveIdempotencyKey(ctx, database.ReserveIdempotencyKeyParams{
+        UserID:      rec.UserID,
+        IdemKey:     rec.Key,
+        RequestHash: rec.RequestHash,
+        ExpiresAt:   rec.ExpiresAt,
+    })
+    if err != nil {
+        return rec, false, err
+    }
+    if rows == 1 {
+        return rec, true, nil
+    }
+
+    existing, err := queries.GetIdempotencyKey(ctx, database.GetIdempotencyKeyParams{
+        UserID:  rec.UserID,
+        IdemKey: rec.Key,
+    })
+    if errors.Is(err, sql.ErrNoRows) {
+        // Released between our insert and read; report it as in progress
+        // rather than racing for it again
+        return idempotency.Record{UserID: rec.UserID, Key: rec.Key, RequestHash: rec.RequestHash, Status: idempotency.StatusInProgress}, false, nil
+    }
+    if err != nil {
+        return rec, false, err
+    }
+    return idempotency.Record{
+        UserID:       existing.UserID,
+        Key:          existing.IdemKey,
+        RequestHash:  existing.RequestHash,
+        Status:       existing.Status,
+        ResponseCode: int(existing.ResponseCode),
+        ContentType:  existing.ContentType,
+        ResponseBody: []byte(existing.ResponseBody.String),
+        ExpiresAt:    existing.ExpiresAt,
+    }, false, nil
+}
+
+func (idempotencyStore) Complete(ctx context.Context, rec idempotency.Record) error {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    return queries.CompleteIdempotencyKey(ctx, database.CompleteIdempotencyKeyParams{
+        ResponseCode: int32(rec.ResponseCode),
+        ContentType:  rec.ContentType,
+        ResponseBody: sql.NullString{String: string(rec.ResponseBody), Valid: true},
+        UserID:       rec.UserID,
+        IdemKey:      rec.Key,
+    })
+}
+
+func (idempotencyStore) Release(ctx context.Context, userID int32, key string) error {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    return queries.DeleteIdempotencyKey(ctx, database.DeleteIdempotencyKeyParams{
+        UserID:  userID,
+        IdemKey: key,
+    })
+}
+
+var idempotencyMiddleware = &idempotency.Middleware{
+    Store:  idempotencyStore{},
+    Window: envDuration("IDEMPOTENCY_WINDOW", 24*time.Hour),
+    // Idempotent routes sit behind requireAuth
+    UserID: func(req *http.Request) (int32, bool) {
+        _, userID := currentUser(req)
+        return userID, userID != 0
+    },
+}
+
+// withIdempotency makes retries carrying the same Idempotency-Key header
+// replay the first response instead of repeating the operation
+func withIdempotency(handler http.HandlerFunc) http.HandlerFunc {
+    return idempotencyMiddleware.Wrap(handler)
+}
+
+type contextKey int
+
+const (
+    accountKey contextKey = iota
+    rolesKey
+    sessionKey
+    heldBackKey
+)
+
+// currentAccount is the signed-in account requireAuth loaded for req
+func currentAccount(req *http.Request) database.Account {
+    account, _ := req.Context().Value(accountKey).(database.Account)
+    return account
+}
+
+// currentUser is the username and ID of the signed-in account
+func currentUser(req *http.Request) (string, int32) {
+    account := currentAccount(req)
+    return account.Username, account.ID
+}
+
+// currentSessionID is the login session the access token belongs to
+func currentSessionID(req *http.Request) int32 {
+    sessionID, _ := req.Context().Value(sessionKey).(int32)
+    return sessionID
+}
+
+// currentRoles are the roles of the signed-in account
+func currentRoles(req *http.Request) []string {
+    roles, _ := req.Context().Value(rolesKey).([]string)
+    return roles
+}
+
+// effectiveRoles are the roles the account may act with. Owner and manager
+// only count once it has two-factor authentication turned on.
+func effectiveRoles(roles []string, account database.Account) []string {
+    if account.TwoFactorEnabledAt.Valid || !rbac.RequiresTwoFactor(roles) {
+        return roles
+    }
+    kept := make([]string, 0, len(roles))
+    for _, role := range roles {
+        if !rbac.RequiresTwoFactor([]string{role}) {
+            kept = append(kept, role)
+        }
+    }
+    return kept
+}
+
+// twoFactorHeldBack reports whether roles were left out of currentRoles
+// because the account has not turned on two-factor authentication
+func twoFactorHeldBack(req *http.Request) bool {
+    heldBack, _ := req.Context().Value(heldBackKey).(bool)
+    return heldBack
+}
+
+// writeAuthError answers a request that failed authentication or
+// authorization
+func writeAuthError(writer http.ResponseWriter, status int, message string) {
+    enableCORS(writer)
+    writer.Header().Set("Content-Type", "application/json")
+    writer.WriteHeader(status)
+    json.NewEncoder(writer).Encode(struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }{false, message})
+}
+
+// requireAuth validates the bearer token, loads the account and its roles
+// once and hands them to handler through the request context
+func requireAuth(handler http.HandlerFunc) http.HandlerFunc {
+    return func(writer http.ResponseWriter, req *http.Request) {
+        if req.Method == http.MethodOptions {
+            handler(writer, req)
+            return
+        }
+
+        token, err := auth.GetBearerToken(req.Header)
+        if err != nil {
+            writeAuthError(writer, http.StatusUnauthorized, "Invalid or missing token")
+            return
+        }
+        claims, err := tokenKeys.ParseJWT(token)
+        if err != nil || claims.SessionID == 0 {
+            writeAuthError(writer, http.StatusUnauthorized, "Invalid token")
+            return
+        }
+        username, userID := claims.Username, claims.UserID
+
+        db, err := sql.Open("mysql", dbURL)
+        if err != nil {
+            enableCORS(writer)
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        defer db.Close()
+
+        queries := database.New(db)
+
+        account, err := queries.GetAccountByID(req.Context(), userID)
+        if err == sql.ErrNoRows || (err == nil && account.Username != username) {
+            writeAuthError(writer, http.StatusUnauthorized, "Unknown account")
+            return
+        }
+        if err != nil {
+            enableCORS(writer)
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        if blocked := accountBlocked(account, time.Now().UTC()); blocked != "" {
+            writeAuthError(writer, http.StatusForbidden, blocked)
+            return
+        }
+        // Logging out revokes the session, and with it every access token
+        // issued for it
+        _, err = queries.GetActiveSession(req.Context(), database.GetActiveSessionParams{
+            SessionID: claims.SessionID,
+            UserID:    userID,
+            ExpiresAt: time.Now().UTC(),
+        })
+        if err == sql.ErrNoRows {
+            writeAuthError(writer, http.StatusUnauthorized, "Session expired")
+            return
+        }
+        if err != nil {
+            enableCORS(writer)
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        roles, err := queries.GetAccountRoles(req.Context(), userID)
+        if err != nil {
+            enableCORS(writer)
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+
+        effective := effectiveRoles(roles, account)
+        ctx := context.WithValue(req.Context(), accountKey, account)
+        ctx = context.WithValue(ctx, rolesKey, effective)
+        ctx = context.WithValue(ctx, heldBackKey, len(effective) != len(roles))
+        ctx = context.WithValue(ctx, sessionKey, claims.SessionID)
+        handler(writer, req.WithContext(ctx))
+    }
+}
+
+// requirePermission only lets accounts whose roles grant permission reach
+// handler. Missing or invalid tokens get 401, other accounts get 403.
+func requirePermission(permission string, handler http.HandlerFunc) http.HandlerFunc {
+    return requireAuth(func(writer http.ResponseWriter, req *http.Request) {
+        if req.Method != http.MethodOptions && !rbac.Can(currentRoles(req), permission) {
+            username, _ := currentUser(req)
+            log.Printf("User %s lacks %s for %s %s", username, permission, req.Method, req.URL.Path)
+            if twoFactorHeldBack(req) {
+                writeAuthError(writer, http.StatusForbidden, "Two-factor authentication required")
+                return
+            }
+            writeAuthError(writer, http.StatusForbidden, "Forbidden")
+            return
+        }
+        handler(writer, req)
+    })
+}
+
+// requireVerified is requireAuth for routes that customers may only use once
+// they have confirmed their email address and phone number
+func requireVerified(handler http.HandlerFunc) http.HandlerFunc {
+    return requireAuth(func(writer http.ResponseWriter, req *http.Request) {
+        if req.Method != http.MethodOptions && !isVerified(currentAccount(req)) {
+            writeAuthError(writer, http.StatusForbidden, "Verify your email and phone number first")
+            return
+        }
+        handler(writer, req)
+    })
+}
@@ -5,11 +5,16 @@ import(
 	"net/http"
 	"encoding/json"
 	"context"
+    "errors"
     "log"
     "fmt"
+    "strings"
+    "time"
 
 	"github.com/Bryanthai/ordersystem/internal/database"
-    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+    "github.com/Bryanthai/ordersystem/internal/geo"
+    "github.com/Bryanthai/ordersystem/internal/schedule"
 )
 
 func updateUserTag(userID int32) error {
@@ -46,6 +51,30 @@ func updateUserTag(userID int32) error {
     return nil
 }
 
+const (
+    orderTypeDineIn   = "dine_in"
+    orderTypeTakeaway = "takeaway"
+    orderTypeDelivery = "delivery"
+)
+
+// orderAmountDue totals an order from the prices snapshotted on its items
+// plus its delivery fee
+func orderAmountDue(queries *database.Queries, orderID int32) (float64, error) {
+    order, err := queries.GetOrderById(context.Background(), orderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get order: %w", err)
+    }
+    items, err := queries.GetOrderedItems(context.Background(), orderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get ordered items: %w", err)
+    }
+    total := order.DeliveryFee
+    for _, item := range items {
+        total += item.UnitPrice * float64(item.Quantity)
+    }
+    return payment.RoundAmount(total), nil
+}
+
 // CREATE NEW ORDER
 func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
     enableCORS(writer)
@@ -54,17 +83,7 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, userID := currentUser(req)
 
     log.Println("Create order request received from user:", username)
 
@@ -72,15 +91,24 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
         OrderInfo string `json:"order_info"`
         IsRanged  bool   `json:"is_ranged"`
         OrderItems []struct {
-            FoodID   int32 `json:"food_id"`
-            Quantity int32 `json:"quantity"`
+            FoodID    int32    `json:"food_id"`
+            Quantity  int32    `json:"quantity"`
+            Modifiers []string `json:"modifiers"`
         } `json:"order_items"`
         DeliveryAddress string `json:"delivery_address"`
+        AddressID       int32  `json:"address_id"`
+        OrderType       string `json:"order_type"`
+        TableNumber     int32  `json:"table_number"`
+        Invoice         invoiceDetails `json:"invoice"`
+        ScheduledFor    *time.Time     `json:"scheduled_for"`
     }
     type CreateOrderResponse struct {
-        Success bool   `json:"success"`
-        Message string `json:"message"`
-        OrderID int32  `json:"order_id"`
+        Success      bool       `json:"success"`
+        Message      string     `json:"message"`
+        OrderID      int32      `json:"order_id"`
+        DeliveryFee  float64    `json:"delivery_fee"`
+        ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+        DelayMinutes int        `json:"delay_minutes,omitempty"`
     }
 
     var orderReq CreateOrderRequest
@@ -89,6 +117,52 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
+    switch orderReq.OrderType {
+    case "":
+        orderReq.OrderType = orderTypeTakeaway
+        if orderReq.IsRanged {
+            orderReq.OrderType = orderTypeDelivery
+        }
+    case orderTypeDelivery:
+        orderReq.IsRanged = true
+    case orderTypeDineIn, orderTypeTakeaway:
+        orderReq.IsRanged = false
+    default:
+        http.Error(writer, "Invalid order_type", http.StatusBadRequest)
+        return
+    }
+    if orderReq.OrderType == orderTypeDineIn && orderReq.TableNumber <= 0 {
+        http.Error(writer, "Dine-in orders need a table_number", http.StatusBadRequest)
+        return
+    }
+    orderReq.DeliveryAddress = strings.TrimSpace(orderReq.DeliveryAddress)
+    if orderReq.OrderType == orderTypeDelivery && orderReq.DeliveryAddress == "" && orderReq.AddressID == 0 {
+        http.Error(writer, "Delivery orders need a delivery_address or address_id", http.StatusBadRequest)
+        return
+    }
+    if orderReq.OrderType != orderTypeDelivery && orderReq.AddressID != 0 {
+        http.Error(writer, "Only delivery orders take an address_id", http.StatusBadRequest)
+        return
+    }
+    if orderReq.OrderType == orderTypeDineIn && orderReq.ScheduledFor != nil {
+        http.Error(writer, "Only takeaway and delivery orders can be scheduled", http.StatusBadRequest)
+        return
+    }
+    if err := orderReq.Invoice.validate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if len(orderReq.OrderItems) == 0 {
+        http.Error(writer, "Orders need at least one item", http.StatusBadRequest)
+        return
+    }
+    for _, item := range orderReq.OrderItems {
+        if item.Quantity <= 0 {
+            http.Error(writer, "Item quantity must be positive", http.StatusBadRequest)
+            return
+        }
+    }
+
     db, err := sql.Open("mysql", dbURL)
     if err != nil {
         http.Error(writer, "Database error", http.StatusInternalServerError)
@@ -98,13 +172,146 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+    // Online ordering can be paused by hand or when the kitchen falls too far
+    // behind; a smaller backlog pushes the promised time back instead
+    kitchen, _, _, err := getKitchenStatus(queries)
+    if err != nil {
+        log.Println("Error getting kitchen status:", err)
+        http.Error(writer, "Failed to get kitchen status", http.StatusInternalServerError)
+        return
+    }
+    if err := kitchen.accepts(orderReq.OrderType, orderReq.ScheduledFor != nil); err != nil {
+        http.Error(writer, err.Error(), http.StatusServiceUnavailable)
+        return
+    }
+    // Orders for later only need the store to be open at their slot
+    calendars, err := loadCalendars(queries)
+    if err != nil {
+        log.Println("Error loading opening hours:", err)
+        http.Error(writer, "Failed to get opening hours", http.StatusInternalServerError)
+        return
+    }
+    calendar := calendars[orderReq.OrderType]
+    if now := time.Now().In(storeLocation); orderReq.ScheduledFor == nil && !calendar.IsOpen(now) {
+        http.Error(writer, storeClosedMessage(calendar, orderReq.OrderType, now), http.StatusServiceUnavailable)
+        return
+    }
+    delayMinutes := 0
+    if orderReq.ScheduledFor == nil {
+        delayMinutes = int(kitchen.decision.Delay / time.Minute)
+    }
+
+    // A saved address is copied onto the order so editing it later doesn't
+    // change where past orders went
+    var saved *database.Address
+    var recipient, phone sql.NullString
+    if orderReq.AddressID != 0 {
+        address, err := queries.GetAddress(context.Background(), database.GetAddressParams{AddressID: orderReq.AddressID, UserID: userID})
+        if err != nil {
+            http.Error(writer, "Invalid address ID", http.StatusBadRequest)
+            return
+        }
+        saved = &address
+        orderReq.DeliveryAddress = formatAddress(address)
+        recipient = sql.NullString{String: address.Recipient, Valid: true}
+        phone = sql.NullString{String: address.Phone, Valid: true}
+    }
+
+    // Snapshot the name and price so later menu edits don't change this order
+    foods := make([]database.Food, len(orderReq.OrderItems))
+    for i, item := range orderReq.OrderItems {
+        foods[i], err = queries.GetFoodById(context.Background(), item.FoodID)
+        if err != nil {
+            http.Error(writer, "Invalid food item in order", http.StatusBadRequest)
+            return
+        }
+        if len(strings.Join(item.Modifiers, ", ")) > 255 {
+            http.Error(writer, "Too many modifiers on one item", http.StatusBadRequest)
+            return
+        }
+    }
+
+    // Delivery orders must reach a zone and meet its minimum order
+    var zoneID sql.NullInt32
+    var deliveryLat, deliveryLng, deliveryDistance sql.NullFloat64
+    var deliveryFee float64
+    if orderReq.IsRanged {
+        subtotal := 0.0
+        for i, item := range orderReq.OrderItems {
+            if !foods[i].LongRange {
+                http.Error(writer, foods[i].FoodName+" is not available for delivery", http.StatusBadRequest)
+                return
+            }
+            subtotal += foods[i].Price * float64(item.Quantity)
+        }
+
+        var point geo.Point
+        var quote geo.Quote
+        if saved != nil {
+            point, err = addressLocation(*saved)
+            if err == nil {
+                quote, err = quoteDeliveryTo(queries, point)
+            }
+        } else {
+            point, quote, err = quoteDelivery(queries, orderReq.DeliveryAddress)
+        }
+        if !writeDeliveryError(writer, err) {
+            return
+        }
+        if payment.RoundAmount(subtotal) < quote.Zone.MinOrder {
+            http.Error(writer, fmt.Sprintf("The minimum order for delivery to %s is %.2f", quote.Zone.Name, quote.Zone.MinOrder), http.StatusBadRequest)
+            return
+        }
+        zoneID = sql.NullInt32{Int32: quote.Zone.ID, Valid: true}
+        deliveryLat = sql.NullFloat64{Float64: point.Lat, Valid: true}
+        deliveryLng = sql.NullFloat64{Float64: point.Lng, Valid: true}
+        deliveryDistance = sql.NullFloat64{Float64: quote.DistanceKm, Valid: true}
+        deliveryFee = quote.Fee
+    }
+
+    // The slot, the order and its items are saved together, so a failure
+    // part way never leaves a slot booked without an order
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
         return
     }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    // Scheduled orders take a place in their slot and wait to be released to
+    // the kitchen. Delivery slots are arrival times, so the food has to be
+    // ready that much earlier.
+    var scheduledFor, releaseAt sql.NullTime
+    if orderReq.ScheduledFor != nil {
+        slot := orderReq.ScheduledFor.In(storeLocation)
+        lead := cookTime(foods)
+        if orderReq.IsRanged {
+            lead += deliveryLead
+        }
+        if err := scheduleRules.Check(slot, time.Now(), lead); err != nil {
+            http.Error(writer, "Invalid scheduled_for: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        if !calendar.IsOpen(slot) {
+            http.Error(writer, "Invalid scheduled_for: the store is closed then", http.StatusBadRequest)
+            return
+        }
+        err = bookSlot(qtx, slot)
+        if errors.Is(err, errSlotFull) {
+            http.Error(writer, err.Error(), http.StatusConflict)
+            return
+        }
+        if err != nil {
+            log.Println("Error booking slot:", err)
+            http.Error(writer, "Failed to book slot", http.StatusInternalServerError)
+            return
+        }
+        scheduledFor = sql.NullTime{Time: slot.UTC(), Valid: true}
+        releaseAt = sql.NullTime{Time: schedule.ReleaseAt(slot, lead, scheduleBuffer).UTC(), Valid: true}
+    }
 
-    err = queries.CreateOrder(context.Background(), database.CreateOrderParams{
+    err = qtx.CreateOrder(context.Background(), database.CreateOrderParams{
         UserID:    userID,
         OrderInfo: orderReq.OrderInfo,
         IsRanged:  orderReq.IsRanged,
@@ -112,23 +319,43 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
             String: orderReq.DeliveryAddress,
             Valid:  orderReq.DeliveryAddress != "",
         },
+        OrderType: orderReq.OrderType,
+        TableNumber: sql.NullInt32{
+            Int32: orderReq.TableNumber,
+            Valid: orderReq.OrderType == orderTypeDineIn,
+        },
+        InvoiceBillingName: orderReq.Invoice.billingName(),
+        InvoiceTaxID:       orderReq.Invoice.taxID(),
+        ZoneID:             zoneID,
+        DeliveryLat:        deliveryLat,
+        DeliveryLng:        deliveryLng,
+        DeliveryDistanceKm: deliveryDistance,
+        DeliveryFee:        deliveryFee,
+        AddressID:          sql.NullInt32{Int32: orderReq.AddressID, Valid: saved != nil},
+        DeliveryRecipient:  recipient,
+        DeliveryPhone:      phone,
+        ScheduledFor:       scheduledFor,
+        ReleaseAt:          releaseAt,
     })
     if err != nil {
         http.Error(writer, "Failed to create order", http.StatusInternalServerError)
         return
     }
 
-    NewOrder, err := queries.GetLastInsertedOrder(context.Background())
+    NewOrder, err := qtx.GetLastInsertedOrder(context.Background())
     if err != nil {
         http.Error(writer, "Failed to retrieve last inserted order", http.StatusInternalServerError)
         return
     }
 
-    for _, item := range orderReq.OrderItems {
-        err = queries.CreateOrderedItem(context.Background(), database.CreateOrderedItemParams{
-            OrderID:  NewOrder.OrderID,
-            FoodID:   item.FoodID,
-            Quantity: item.Quantity,
+    for i, item := range orderReq.OrderItems {
+        err = qtx.CreateOrderedItem(context.Background(), database.CreateOrderedItemParams{
+            OrderID:   NewOrder.OrderID,
+            FoodID:    item.FoodID,
+            Quantity:  item.Quantity,
+            FoodName:  foods[i].FoodName,
+            UnitPrice: foods[i].Price,
+            Modifiers: strings.Join(item.Modifiers, ", "),
         })
         if err != nil {
             http.Error(writer, "Failed to create order item", http.StatusInternalServerError)
@@ -136,20 +363,36 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
         }
     }
 
-    TimeNeeded, err := queries.GetLongestTimeNeededFoodInOrder(context.Background(), NewOrder.OrderID)
-    if err != nil {
-        http.Error(writer, "Failed to get time needed for order", http.StatusInternalServerError)
-        return
+    if scheduledFor.Valid {
+        err = qtx.SetEstimatedTime(context.Background(), database.SetEstimatedTimeParams{
+            EstimatedTime: scheduledFor.Time,
+            OrderID:       NewOrder.OrderID,
+        })
+        if err != nil {
+            http.Error(writer, "Failed to update estimated time", http.StatusInternalServerError)
+            return
+        }
+    } else {
+        TimeNeeded, err := qtx.GetLongestTimeNeededFoodInOrder(context.Background(), NewOrder.OrderID)
+        if err != nil {
+            http.Error(writer, "Failed to get time needed for order", http.StatusInternalServerError)
+            return
+        }
+
+        estimatedTime := TimeNeeded.(int64) + int64(delayMinutes)
+
+        err = qtx.UpdateEstimatedTime(context.Background(), database.UpdateEstimatedTimeParams{
+            DATEADD:       estimatedTime,
+            OrderID:       NewOrder.OrderID,
+        })
+        if err != nil {
+            http.Error(writer, "Failed to update estimated time", http.StatusInternalServerError)
+            return
+        }
     }
 
-    estimatedTime := TimeNeeded.(int64)
-    
-    err = queries.UpdateEstimatedTime(context.Background(), database.UpdateEstimatedTimeParams{
-        DATEADD:       estimatedTime,
-        OrderID:       NewOrder.OrderID,
-    })
-    if err != nil {
-        http.Error(writer, "Failed to update estimated time", http.StatusInternalServerError)
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to create order", http.StatusInternalServerError)
         return
     }
 
@@ -159,7 +402,19 @@ func createOrderHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    resp := CreateOrderResponse{Success: true, Message: "Order created successfully", OrderID: NewOrder.OrderID}
+    // The order is placed either way; failed tickets can be reprinted.
+    // Scheduled orders get their tickets when they are released.
+    if !scheduledFor.Valid {
+        if err := queueKitchenTickets(queries, NewOrder, foods); err != nil {
+            log.Println("Error queueing kitchen tickets:", err)
+        }
+    }
+    linkTableOrder(queries, NewOrder)
+
+    resp := CreateOrderResponse{Success: true, Message: "Order created successfully", OrderID: NewOrder.OrderID, DeliveryFee: deliveryFee, DelayMinutes: delayMinutes}
+    if scheduledFor.Valid {
+        resp.ScheduledFor = &scheduledFor.Time
+    }
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
 	return
@@ -173,17 +428,7 @@ func getOrderStatusHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, userID := currentUser(req)
 
     UserID := userID
 
@@ -204,11 +449,6 @@ func getOrderStatusHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
     orders, err := queries.GetOrder(context.Background(), UserID)
     if err != nil {
         http.Error(writer, "Failed to get orders", http.StatusInternalServerError)
@@ -232,17 +472,7 @@ func deleteOrderHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Delete order request received from user:", username)
 
@@ -269,9 +499,9 @@ func deleteOrderHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
     
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+    order, err := queries.GetOrderById(context.Background(), delReq.OrderID)
+    if err != nil {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
         return
     }
     err = queries.DeleteOrder(context.Background(), delReq.OrderID)
@@ -280,6 +510,11 @@ func deleteOrderHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
+    // A scheduled order the kitchen hasn't started gives its slot back
+    if !order.Deleted && !order.ReleasedAt.Valid {
+        freeSlot(queries, order.ScheduledFor)
+    }
+
     resp := DeleteOrderResponse{Success: true, Message: "Order deleted successfully"}
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
@@ -294,17 +529,7 @@ func getAllOrdersHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get all orders request received from user:", username)
 
@@ -323,12 +548,6 @@ func getAllOrdersHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-    
     orders, err := queries.GetAllOrders(context.Background())
     if err != nil {
         http.Error(writer, "Failed to get orders", http.StatusInternalServerError)
@@ -353,17 +572,7 @@ func rateOrderedItems(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Rate ordered items request received from user:", username)
 
@@ -389,11 +598,6 @@ func rateOrderedItems(writer http.ResponseWriter, req *http.Request) {
     }
     defer db.Close()
     queries := database.New(db)
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
 
     err = queries.RateFood(context.Background(), database.RateFoodParams{
         OrderID: rateReq.OrderID,
@@ -421,17 +625,7 @@ func UpdateFeedback(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Update feedback request received from user:", username)
 
@@ -459,12 +653,6 @@ func UpdateFeedback(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
     
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     err = queries.UpdateFeedback(context.Background(), database.UpdateFeedbackParams{
         OrderID:  feedbackReq.OrderID,
         Feedback: sql.NullString{
@@ -492,17 +680,7 @@ func getOrderedItemsHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get ordered items request received from user:", username)
 
@@ -521,12 +699,6 @@ func getOrderedItemsHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     orderIDStr := req.URL.Query().Get("order_id")
     if orderIDStr == "" {
         http.Error(writer, "Missing order_id query parameter", http.StatusBadRequest)
@@ -562,17 +734,7 @@ func finishOrder(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, userID := currentUser(req)
 
     log.Println("Finish order request received from user:", username)
 
@@ -600,18 +762,25 @@ func finishOrder(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
-    err = queries.UpdateOrderDoneStatus(context.Background(), finishReq.OrderID)
+    // Remember who finished the order for the staff tip pool
+    err = queries.UpdateOrderDoneStatus(context.Background(), database.UpdateOrderDoneStatusParams{
+        FinishedBy: sql.NullInt32{Int32: userID, Valid: true},
+        OrderID:    finishReq.OrderID,
+    })
     if err != nil {
         http.Error(writer, "Failed to finish order", http.StatusInternalServerError)
         return
     }
 
+    // Delivery orders go straight to the nearest idle rider; if nobody is
+    // free the dispatch worker keeps trying
+    order, err := queries.GetOrderById(context.Background(), finishReq.OrderID)
+    if err == nil && order.IsRanged {
+        if _, err := dispatchOrder(db, queries, order.OrderID, 0, sql.NullInt32{}); err != nil {
+            log.Printf("Order %d not dispatched yet: %v", order.OrderID, err)
+        }
+    }
+
     writer.WriteHeader(http.StatusOK)
     resp := FinishOrderResponse{Success: true, Message: "Order finished successfully"}
     writer.Header().Set("Content-Type", "application/json")
@@ -626,17 +795,7 @@ func getAllUndoneOrder(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get all undone orders request received from user:", username)
 
@@ -655,12 +814,6 @@ func getAllUndoneOrder(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     orders, err := queries.GetAllOrdersNotDone(context.Background())
     if err != nil {
         http.Error(writer, "Failed to get undone orders", http.StatusInternalServerError)
@@ -684,17 +837,7 @@ func GetOrderByIdHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get order by ID request received from user:", username)
 
@@ -724,12 +867,6 @@ func GetOrderByIdHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     order, err := queries.GetOrderById(context.Background(), orderID)
     if err != nil {
         http.Error(writer, "Failed to get order by ID", http.StatusInternalServerError)
@@ -753,17 +890,7 @@ func GetOrderTotalPrice(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get order total price request received from user:", username)
 
@@ -793,12 +920,6 @@ func GetOrderTotalPrice(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     totalPrice, err := queries.GetOrderTotalPrice(context.Background(), orderID)
     if err != nil {
         http.Error(writer, "Failed to get order total price", http.StatusInternalServerError)
@@ -823,17 +944,7 @@ func GetAllOrderedItemsHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Get all ordered items request received from user:", username)
 
@@ -852,12 +963,6 @@ func GetAllOrderedItemsHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    if account.ID != userID || err != nil {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     items, err := queries.GetAllOrderedItems(context.Background())
     if err != nil {
         http.Error(writer, "Failed to get ordered items", http.StatusInternalServerError)
@@ -0,0 +1,316 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "net/url"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+)
+
+// PASSWORD_RESET_URL is the page of the frontend that takes the reset token
+var (
+    passwordResetTTL      = envDuration("PASSWORD_RESET_TTL", time.Hour)
+    passwordResetCooldown = envDuration("PASSWORD_RESET_COOLDOWN", 2*time.Minute)
+    passwordResetURL      = envString("PASSWORD_RESET_URL", "http://localhost:5173/reset-password")
+)
+
+// setPassword stores a new password for account and signs it out everywhere,
+// so whoever knew the old password loses access
+func setPassword(queries *database.Queries, account database.Account, password string) error {
+    hashed, err := auth.HashPassword(password)
+    if err != nil {
+        return err
+    }
+    err = queries.UpdateAccountPassword(context.Background(), database.UpdateAccountPasswordParams{
+        Password: hashed,
+        ID:       account.ID,
+    })
+    if err != nil {
+        return err
+    }
+    // Outstanding reset links stop working too
+    err = queries.UsePasswordResets(context.Background(), database.UsePasswordResetsParams{
+        UsedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID: account.ID,
+    })
+    if err != nil {
+        return err
+    }
+    return revokeSessions(queries, account.ID)
+}
+
+func queuePasswordChangedMail(queries *database.Queries, account database.Account) {
+    body := fmt.Sprintf("Hello %s,\n\nThe password of your account was just changed and you have been signed out everywhere.\nIf this was not you, reset your password right away.\n", account.Username)
+    if err := queueMail(queries, account.ID, account.Email, "Your password was changed", body); err != nil {
+        log.Println("Failed to queue password changed mail:", err)
+    }
+}
+
+// sendPasswordReset emails account a single-use reset link. Only the newest
+// link works.
+func sendPasswordReset(queries *database.Queries, account database.Account, ip string, now time.Time) error {
+    token, err := auth.MakeToken()
+    if err != nil {
+        return err
+    }
+    err = queries.UsePasswordResets(context.Background(), database.UsePasswordResetsParams{
+        UsedAt: sql.NullTime{Time: now, Valid: true},
+        UserID: account.ID,
+    })
+    if err != nil {
+        return err
+    }
+    err = queries.CreatePasswordReset(context.Background(), database.CreatePasswordResetParams{
+        UserID:    account.ID,
+        TokenHash: auth.HashToken(token),
+        Ip:        truncate(ip, 64),
+        CreatedAt: now,
+        ExpiresAt: now.Add(passwordResetTTL),
+    })
+    if err != nil {
+        return err
+    }
+    link := passwordResetURL + "?token=" + url.QueryEscape(token)
+    body := fmt.Sprintf("Hello %s,\n\nUse this link to choose a new password:\n%s\n\nThe link works once and expires in %s. If you did not ask for it, ignore this email.\n", account.Username, link, passwordResetTTL)
+    return queueMail(queries, account.ID, account.Email, "Reset your password", body)
+}
+
+// CHANGE PASSWORD
+func changePasswordHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Change password request received from user:", username)
+
+    type ChangePasswordRequest struct {
+        CurrentPassword string `json:"current_password"`
+        NewPassword     string `json:"new_password"`
+    }
+    type ChangePasswordResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var changeReq ChangePasswordRequest
+    if err := json.NewDecoder(req.Body).Decode(&changeReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account := currentAccount(req)
+    if !verifyPassword(writer, req, queries, account, changeReq.CurrentPassword, "Current password is incorrect", time.Now().UTC()) {
+        return
+    }
+    if changeReq.NewPassword == changeReq.CurrentPassword {
+        http.Error(writer, "New password must be different", http.StatusBadRequest)
+        return
+    }
+    if err := auth.CheckPasswordStrength(changeReq.NewPassword, account.Username); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+
+    if err := setPassword(queries.WithTx(tx), account, changeReq.NewPassword); err != nil {
+        http.Error(writer, "Failed to change password", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to change password", http.StatusInternalServerError)
+        return
+    }
+    queuePasswordChangedMail(queries, account)
+
+    resp := ChangePasswordResponse{Success: true, Message: "Password changed, please log in again"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// FORGOT PASSWORD
+func forgotPasswordHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Forgot password request received")
+
+    type ForgotPasswordRequest struct {
+        Email string `json:"email"`
+    }
+    type ForgotPasswordResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var forgotReq ForgotPasswordRequest
+    if err := json.NewDecoder(req.Body).Decode(&forgotReq); err != nil || forgotReq.Email == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // The answer is the same whether or not the address has an account, so
+    // the endpoint cannot be used to find out who is registered
+    resp := ForgotPasswordResponse{Success: true, Message: "If the email belongs to an account, a reset link has been sent"}
+
+    account, err := queries.GetAccountByEmail(context.Background(), forgotReq.Email)
+    if err == sql.ErrNoRows {
+        writer.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(writer).Encode(resp)
+        return
+    }
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+
+    now := time.Now().UTC()
+    recent, err := queries.CountPasswordResetsSince(context.Background(), database.CountPasswordResetsSinceParams{
+        UserID:    account.ID,
+        CreatedAt: now.Add(-passwordResetCooldown),
+    })
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if recent > 0 {
+        log.Println("Password reset requested again too soon for user:", account.Username)
+        writer.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(writer).Encode(resp)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+
+    if err := sendPasswordReset(queries.WithTx(tx), account, clientIP(req), now); err != nil {
+        http.Error(writer, "Failed to create reset link", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to create reset link", http.StatusInternalServerError)
+        return
+    }
+
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+var errInvalidResetToken = errors.New("Invalid or expired reset token")
+
+// RESET PASSWORD
+func resetPasswordHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Reset password request received")
+
+    type ResetPasswordRequest struct {
+        Token       string `json:"token"`
+        NewPassword string `json:"new_password"`
+    }
+    type ResetPasswordResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var resetReq ResetPasswordRequest
+    if err := json.NewDecoder(req.Body).Decode(&resetReq); err != nil || resetReq.Token == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    reset, err := qtx.GetPasswordResetForUpdate(context.Background(), auth.HashToken(resetReq.Token))
+    if err != nil || reset.UsedAt.Valid || !reset.ExpiresAt.After(time.Now().UTC()) {
+        http.Error(writer, errInvalidResetToken.Error(), http.StatusBadRequest)
+        return
+    }
+    account, err := qtx.GetAccountByID(context.Background(), reset.UserID)
+    if err != nil {
+        http.Error(writer, errInvalidResetToken.Error(), http.StatusBadRequest)
+        return
+    }
+    if err := auth.CheckPasswordStrength(resetReq.NewPassword, account.Username); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := setPassword(qtx, account, resetReq.NewPassword); err != nil {
+        http.Error(writer, "Failed to reset password", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to reset password", http.StatusInternalServerError)
+        return
+    }
+    queuePasswordChangedMail(queries, account)
+
+    resp := ResetPasswordResponse{Success: true, Message: "Password reset, please log in"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
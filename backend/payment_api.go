@@ -0,0 +1,1026 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/giftcard"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+    "github.com/Bryanthai/ordersystem/internal/reservation"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+var mockGateway = &payment.MockGateway{
+    Secret:      loadWebhookSecret(),
+    CallbackURL: paymentCallbackURL,
+    Client:      &http.Client{Timeout: 10 * time.Second},
+}
+
+// loadWebhookSecret reads the secret the mock gateway signs its webhooks with
+func loadWebhookSecret() []byte {
+    if secret := os.Getenv("PAYMENT_WEBHOOK_SECRET"); secret != "" {
+        return []byte(secret)
+    }
+    log.Println("PAYMENT_WEBHOOK_SECRET is not set, signing payment webhooks with a temporary secret")
+    secret, err := auth.MakeToken()
+    if err != nil {
+        log.Fatal("Failed to create webhook secret: ", err)
+    }
+    return []byte(secret)
+}
+
+var (
+    errIntentNotPending = errors.New("payment intent is not awaiting confirmation")
+    errOrderAlreadyPaid = errors.New("order is already paid")
+    errOrderPaymentOpen = errors.New("order already has a payment in progress")
+    errOrderSplit       = errors.New("order bill is split into shares")
+    errNotRefundable    = errors.New("only succeeded payments can be refunded")
+    errRechargeSpent    = errors.New("recharged amount has already been spent")
+    errGiftCardRedeemed = errors.New("gift card has already been redeemed")
+)
+
+// accountWallet stores wallet money in accounts.balance
+type accountWallet struct {
+    queries *database.Queries
+}
+
+func (w accountWallet) Debit(ctx context.Context, userID int32, amount float64) error {
+    rows, err := w.queries.DeductAccountBalance(ctx, database.DeductAccountBalanceParams{
+        Amount: amount,
+        ID:     userID,
+    })
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return payment.ErrInsufficientFunds
+    }
+    return nil
+}
+
+func (w accountWallet) Credit(ctx context.Context, userID int32, amount float64) error {
+    return w.queries.AddAccountBalance(ctx, database.AddAccountBalanceParams{
+        Balance: amount,
+        ID:      userID,
+    })
+}
+
+func paymentProviders(queries *database.Queries) map[string]payment.Provider {
+    return map[string]payment.Provider{
+        "wallet":    payment.WalletProvider{Wallet: accountWallet{queries: queries}},
+        "mock_card": mockGateway,
+    }
+}
+
+func intentFromRow(row database.PaymentIntent) payment.Intent {
+    return payment.Intent{
+        ID:          row.IntentID,
+        UserID:      row.UserID,
+        Purpose:     row.Purpose,
+        Amount:      row.Amount,
+        Status:      row.Status,
+        ProviderRef: row.ProviderRef,
+    }
+}
+
+func logPaymentEvent(queries *database.Queries, intentID int32, eventType, status string, amount float64, detail string) {
+    err := queries.CreatePaymentEvent(context.Background(), database.CreatePaymentEventParams{
+        IntentID:  intentID,
+        EventType: eventType,
+        Status:    status,
+        Amount:    amount,
+        Detail:    sql.NullString{String: detail, Valid: detail != ""},
+    })
+    if err != nil {
+        log.Println("Error recording payment event:", err)
+    }
+}
+
+// rechargeFits reports whether amount can be added to the user's wallet
+// without going above maxBalance
+func rechargeFits(queries *database.Queries, userID int32, amount float64) (bool, error) {
+    account, err := queries.GetAccountByID(context.Background(), userID)
+    if err != nil {
+        return false, err
+    }
+    return payment.RoundAmount(account.Balance+amount) <= maxBalance, nil
+}
+
+// startPayment records a new intent and registers it with the provider
+func startPayment(queries *database.Queries, provider payment.Provider, params database.CreatePaymentIntentParams) (database.PaymentIntent, error) {
+    params.Provider = provider.Name()
+    params.Amount = payment.RoundAmount(params.Amount)
+    if params.Amount <= 0 {
+        return database.PaymentIntent{}, payment.ErrInvalidAmount
+    }
+    err := queries.CreatePaymentIntent(context.Background(), params)
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to create payment intent: %w", err)
+    }
+    row, err := queries.GetLastInsertedPaymentIntent(context.Background())
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to retrieve payment intent: %w", err)
+    }
+
+    intent, err := provider.CreateIntent(context.Background(), intentFromRow(row))
+    if err != nil {
+        queries.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+            NewStatus: payment.StatusFailed,
+            IntentID:  row.IntentID,
+            OldStatus: row.Status,
+        })
+        logPaymentEvent(queries, row.IntentID, "intent.rejected", payment.StatusFailed, row.Amount, err.Error())
+        return row, err
+    }
+
+    err = queries.UpdatePaymentIntentProviderRef(context.Background(), database.UpdatePaymentIntentProviderRefParams{
+        ProviderRef: intent.ProviderRef,
+        Status:      intent.Status,
+        IntentID:    row.IntentID,
+    })
+    if err != nil {
+        return row, fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    logPaymentEvent(queries, row.IntentID, "intent.created", intent.Status, row.Amount, provider.Name())
+
+    return queries.GetPaymentIntent(context.Background(), row.IntentID)
+}
+
+// startOrderPayment starts paying for an order. The order is locked while
+// it is checked, so it can't get a second intent while one is open or has
+// already succeeded, or be paid whole once its bill has been split.
+func startOrderPayment(db *sql.DB, queries *database.Queries, provider payment.Provider, userID, orderID int32, amount float64) (database.PaymentIntent, error) {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    order, err := qtx.GetOrderByIdForUpdate(context.Background(), orderID)
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to retrieve order: %w", err)
+    }
+    if order.IsPaid {
+        return database.PaymentIntent{}, errOrderAlreadyPaid
+    }
+    open, err := qtx.CountActiveOrderIntents(context.Background(), sql.NullInt32{Int32: orderID, Valid: true})
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to count payment intents: %w", err)
+    }
+    if open > 0 {
+        return database.PaymentIntent{}, errOrderPaymentOpen
+    }
+    counts, err := qtx.CountBillShares(context.Background(), orderID)
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to count bill shares: %w", err)
+    }
+    if counts.ShareCount > 0 {
+        return database.PaymentIntent{}, errOrderSplit
+    }
+
+    intent, err := startPayment(qtx, provider, database.CreatePaymentIntentParams{
+        UserID:  userID,
+        OrderID: sql.NullInt32{Int32: orderID, Valid: true},
+        Purpose: payment.PurposeOrder,
+        Amount:  amount,
+    })
+    // Intents the provider rejected are kept, marked failed
+    if intent.IntentID != 0 {
+        if commitErr := tx.Commit(); err == nil {
+            err = commitErr
+        }
+    }
+    return intent, err
+}
+
+// confirmPayment hands the intent to its provider. Synchronous results are
+// settled here; asynchronous ones are settled when the webhook arrives.
+func confirmPayment(db *sql.DB, queries *database.Queries, provider payment.Provider, row database.PaymentIntent, method payment.Method) (database.PaymentIntent, error) {
+    if _, ok := provider.(payment.WalletProvider); ok {
+        return confirmWalletPayment(db, queries, row)
+    }
+
+    rows, err := queries.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+        NewStatus: payment.StatusProcessing,
+        IntentID:  row.IntentID,
+        OldStatus: payment.StatusPending,
+    })
+    if err != nil {
+        return row, fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    if rows == 0 {
+        return row, errIntentNotPending
+    }
+    logPaymentEvent(queries, row.IntentID, "intent.confirming", payment.StatusProcessing, row.Amount, "")
+
+    intent := intentFromRow(row)
+    intent.Status = payment.StatusProcessing
+    confirmed, confirmErr := provider.Confirm(context.Background(), intent, method)
+
+    switch confirmed.Status {
+    case payment.StatusSucceeded:
+        err = settleIntent(db, row.IntentID, payment.StatusProcessing, payment.StatusSucceeded, payment.EventPaymentSucceeded, "")
+    case payment.StatusFailed:
+        detail := ""
+        if confirmErr != nil {
+            detail = confirmErr.Error()
+        }
+        err = settleIntent(db, row.IntentID, payment.StatusProcessing, payment.StatusFailed, payment.EventPaymentFailed, detail)
+    default:
+        if errors.Is(confirmErr, payment.ErrInvalidCard) {
+            // Nothing was charged, so the customer may retry with another card
+            queries.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+                NewStatus: payment.StatusPending,
+                IntentID:  row.IntentID,
+                OldStatus: payment.StatusProcessing,
+            })
+            logPaymentEvent(queries, row.IntentID, "intent.confirm_rejected", payment.StatusPending, row.Amount, confirmErr.Error())
+        } else if confirmErr != nil {
+            log.Println("Payment provider error for intent", row.IntentID, ":", confirmErr)
+        }
+    }
+    if err != nil {
+        return row, err
+    }
+
+    updated, err := queries.GetPaymentIntent(context.Background(), row.IntentID)
+    if err != nil {
+        return row, fmt.Errorf("failed to retrieve payment intent: %w", err)
+    }
+    return updated, confirmErr
+}
+
+// confirmWalletPayment debits the wallet in the same transaction that
+// settles the intent, so money never leaves the wallet without the intent
+// being settled
+func confirmWalletPayment(db *sql.DB, queries *database.Queries, row database.PaymentIntent) (database.PaymentIntent, error) {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return row, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    rows, err := qtx.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+        NewStatus: payment.StatusProcessing,
+        IntentID:  row.IntentID,
+        OldStatus: payment.StatusPending,
+    })
+    if err != nil {
+        return row, fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    if rows == 0 {
+        return row, errIntentNotPending
+    }
+    logPaymentEvent(qtx, row.IntentID, "intent.confirming", payment.StatusProcessing, row.Amount, "")
+
+    wallet := payment.WalletProvider{Wallet: accountWallet{queries: qtx}}
+    intent := intentFromRow(row)
+    intent.Status = payment.StatusProcessing
+    confirmed, confirmErr := wallet.Confirm(context.Background(), intent, payment.Method{})
+
+    if confirmed.Status == payment.StatusSucceeded {
+        err = applySettlement(qtx, row.IntentID, payment.StatusProcessing, payment.StatusSucceeded, payment.EventPaymentSucceeded, "")
+    } else {
+        detail := ""
+        if confirmErr != nil {
+            detail = confirmErr.Error()
+        }
+        err = applySettlement(qtx, row.IntentID, payment.StatusProcessing, payment.StatusFailed, payment.EventPaymentFailed, detail)
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        return row, err
+    }
+
+    updated, err := queries.GetPaymentIntent(context.Background(), row.IntentID)
+    if err != nil {
+        return row, fmt.Errorf("failed to retrieve payment intent: %w", err)
+    }
+    return updated, confirmErr
+}
+
+// settleIntent moves an intent between states and applies the effect of the
+// new state in one transaction. Settling an intent that already left
+// fromStatus is a no-op, which makes repeated webhooks harmless.
+func settleIntent(db *sql.DB, intentID int32, fromStatus, toStatus, eventType, detail string) error {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    err = applySettlement(database.New(db).WithTx(tx), intentID, fromStatus, toStatus, eventType, detail)
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// applySettlement is settleIntent inside the caller's transaction
+func applySettlement(queries *database.Queries, intentID int32, fromStatus, toStatus, eventType, detail string) error {
+    intent, err := queries.GetPaymentIntent(context.Background(), intentID)
+    if err != nil {
+        return fmt.Errorf("failed to retrieve payment intent: %w", err)
+    }
+    rows, err := queries.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+        NewStatus: toStatus,
+        IntentID:  intentID,
+        OldStatus: fromStatus,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    if rows == 0 {
+        return nil
+    }
+
+    switch toStatus {
+    case payment.StatusSucceeded:
+        err = fulfillIntent(queries, intent)
+    case payment.StatusFailed:
+        err = abandonIntent(queries, intent)
+    case payment.StatusRefunded:
+        err = reverseIntent(queries, intent)
+    }
+    if err != nil {
+        return err
+    }
+
+    err = queries.CreatePaymentEvent(context.Background(), database.CreatePaymentEventParams{
+        IntentID:  intentID,
+        EventType: eventType,
+        Status:    toStatus,
+        Amount:    intent.Amount,
+        Detail:    sql.NullString{String: detail, Valid: detail != ""},
+    })
+    if err != nil {
+        return fmt.Errorf("failed to record payment event: %w", err)
+    }
+    return nil
+}
+
+// fulfillIntent delivers what the customer paid for
+func fulfillIntent(queries *database.Queries, intent database.PaymentIntent) error {
+    switch intent.Purpose {
+    case payment.PurposeOrder:
+        return markOrderPaid(queries, intent.OrderID.Int32)
+    case payment.PurposeRecharge:
+        rows, err := queries.CreditAccountBalance(context.Background(), database.CreditAccountBalanceParams{
+            Amount:     intent.Amount,
+            ID:         intent.UserID,
+            MaxBalance: maxBalance,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to update user balance: %w", err)
+        }
+        if rows == 0 {
+            return fmt.Errorf("recharge %d would take the balance above %.2f", intent.IntentID, maxBalance)
+        }
+    case payment.PurposeBillShare:
+        rows, err := queries.MarkBillSharePaid(context.Background(), database.MarkBillSharePaidParams{
+            PaidBy:   sql.NullInt32{Int32: intent.UserID, Valid: true},
+            ShareID:  intent.ShareID.Int32,
+            IntentID: sql.NullInt32{Int32: intent.IntentID, Valid: true},
+        })
+        if err != nil {
+            return fmt.Errorf("failed to update bill share: %w", err)
+        }
+        if rows == 0 {
+            return fmt.Errorf("bill share %d is not claimed by intent %d", intent.ShareID.Int32, intent.IntentID)
+        }
+        // The order is only paid once every share is settled
+        counts, err := queries.CountBillShares(context.Background(), intent.OrderID.Int32)
+        if err != nil {
+            return fmt.Errorf("failed to count bill shares: %w", err)
+        }
+        if counts.UnpaidCount == 0 {
+            return markOrderPaid(queries, intent.OrderID.Int32)
+        }
+    case payment.PurposeTip:
+        if err := setTipStatus(queries, intent.IntentID, tipStatusPaid); err != nil {
+            return err
+        }
+    case payment.PurposeGiftCard:
+        return activateGiftCard(queries, intent)
+    case payment.PurposeDeposit:
+        return confirmReservation(queries, intent.ReservationID.Int32)
+    }
+    return nil
+}
+
+// markOrderPaid marks an order paid, failing if something else paid it first
+func markOrderPaid(queries *database.Queries, orderID int32) error {
+    rows, err := queries.UpdateOrderPayment(context.Background(), orderID)
+    if err != nil {
+        return fmt.Errorf("failed to update order status: %w", err)
+    }
+    if rows == 0 {
+        return fmt.Errorf("order %d: %w", orderID, errOrderAlreadyPaid)
+    }
+    return nil
+}
+
+// abandonIntent frees anything held for an intent that failed
+func abandonIntent(queries *database.Queries, intent database.PaymentIntent) error {
+    switch intent.Purpose {
+    case payment.PurposeBillShare:
+        err := queries.ReleaseBillShare(context.Background(), database.ReleaseBillShareParams{
+            ShareID:  intent.ShareID.Int32,
+            IntentID: sql.NullInt32{Int32: intent.IntentID, Valid: true},
+        })
+        if err != nil {
+            return fmt.Errorf("failed to release bill share: %w", err)
+        }
+    case payment.PurposeTip:
+        if err := setTipStatus(queries, intent.IntentID, tipStatusFailed); err != nil {
+            return err
+        }
+    case payment.PurposeGiftCard:
+        return voidGiftCard(queries, intent.GiftCardID.Int32, intent.UserID, sql.NullInt32{Int32: intent.IntentID, Valid: true}, "purchase payment failed")
+    case payment.PurposeDeposit:
+        return releaseReservation(queries, intent.ReservationID.Int32)
+    }
+    return nil
+}
+
+// reverseIntent undoes fulfillIntent after a refund
+func reverseIntent(queries *database.Queries, intent database.PaymentIntent) error {
+    switch intent.Purpose {
+    case payment.PurposeOrder:
+        if err := queries.UpdateOrderUnpaid(context.Background(), intent.OrderID.Int32); err != nil {
+            return fmt.Errorf("failed to update order status: %w", err)
+        }
+    case payment.PurposeRecharge:
+        // The balance was already taken back when the refund was claimed
+    case payment.PurposeBillShare:
+        if err := queries.RefundBillShare(context.Background(), intent.ShareID.Int32); err != nil {
+            return fmt.Errorf("failed to update bill share: %w", err)
+        }
+        if err := queries.UpdateOrderUnpaid(context.Background(), intent.OrderID.Int32); err != nil {
+            return fmt.Errorf("failed to update order status: %w", err)
+        }
+    case payment.PurposeTip:
+        if err := setTipStatus(queries, intent.IntentID, tipStatusRefunded); err != nil {
+            return err
+        }
+    case payment.PurposeGiftCard:
+        return voidGiftCard(queries, intent.GiftCardID.Int32, intent.UserID, sql.NullInt32{Int32: intent.IntentID, Valid: true}, "purchase refunded")
+    case payment.PurposeDeposit:
+        return setDepositStatus(queries, intent.ReservationID.Int32, reservation.DepositRefunded)
+    }
+    return nil
+}
+
+// refundIntent claims a succeeded intent under a row lock by moving it to
+// refunding, so a retried or concurrent refund can't pay out twice. Wallet
+// refunds are credited and settled in the same transaction; other providers
+// are only asked to refund once the claim has been committed.
+func refundIntent(db *sql.DB, queries *database.Queries, intentID int32, detail string) (database.PaymentIntent, error) {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return database.PaymentIntent{}, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    row, err := qtx.GetPaymentIntentForUpdate(context.Background(), intentID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return row, errNotRefundable
+    }
+    if err != nil {
+        return row, fmt.Errorf("failed to retrieve payment intent: %w", err)
+    }
+    if row.Status != payment.StatusSucceeded {
+        return row, errNotRefundable
+    }
+    provider, ok := paymentProviders(queries)[row.Provider]
+    if !ok {
+        return row, fmt.Errorf("unknown payment provider %q", row.Provider)
+    }
+
+    if err := holdRefund(qtx, row); err != nil {
+        return row, err
+    }
+    _, err = qtx.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+        NewStatus: payment.StatusRefunding,
+        IntentID:  row.IntentID,
+        OldStatus: payment.StatusSucceeded,
+    })
+    if err != nil {
+        return row, fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    logPaymentEvent(qtx, row.IntentID, "refund.requested", payment.StatusRefunding, row.Amount, detail)
+
+    if _, ok := provider.(payment.WalletProvider); ok {
+        wallet := payment.WalletProvider{Wallet: accountWallet{queries: qtx}}
+        _, err = wallet.Refund(context.Background(), intentFromRow(row))
+        if err != nil {
+            return row, fmt.Errorf("failed to credit wallet: %w", err)
+        }
+        err = applySettlement(qtx, row.IntentID, payment.StatusRefunding, payment.StatusRefunded, payment.EventRefundSucceeded, "")
+        if err == nil {
+            err = tx.Commit()
+        }
+        if err != nil {
+            return row, err
+        }
+        return queries.GetPaymentIntent(context.Background(), row.IntentID)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return row, err
+    }
+
+    refunded, err := provider.Refund(context.Background(), intentFromRow(row))
+    if err != nil {
+        if releaseErr := releaseRefund(db, queries, row, err.Error()); releaseErr != nil {
+            log.Println("Error releasing refund claim:", releaseErr)
+        }
+        return row, fmt.Errorf("failed to refund payment: %w", err)
+    }
+    if refunded.Status == payment.StatusRefunded {
+        err = settleIntent(db, row.IntentID, payment.StatusRefunding, payment.StatusRefunded, payment.EventRefundSucceeded, "")
+        if err != nil {
+            return row, err
+        }
+    }
+    return queries.GetPaymentIntent(context.Background(), row.IntentID)
+}
+
+// holdRefund takes back whatever the customer could still spend while the
+// refund is in flight: a recharge's balance and an untouched gift card
+func holdRefund(queries *database.Queries, intent database.PaymentIntent) error {
+    switch intent.Purpose {
+    case payment.PurposeRecharge:
+        err := accountWallet{queries: queries}.Debit(context.Background(), intent.UserID, intent.Amount)
+        if errors.Is(err, payment.ErrInsufficientFunds) {
+            return errRechargeSpent
+        }
+        if err != nil {
+            return fmt.Errorf("failed to update user balance: %w", err)
+        }
+    case payment.PurposeGiftCard:
+        card, err := queries.GetGiftCardForUpdate(context.Background(), intent.GiftCardID.Int32)
+        if err != nil {
+            return fmt.Errorf("failed to retrieve gift card: %w", err)
+        }
+        if card.Status != giftcard.StatusActive || billing.ToCents(card.Balance) != billing.ToCents(card.InitialAmount) {
+            return errGiftCardRedeemed
+        }
+        if _, err := queries.HoldGiftCard(context.Background(), card.CardID); err != nil {
+            return fmt.Errorf("failed to hold gift card: %w", err)
+        }
+    }
+    return nil
+}
+
+// releaseRefund puts a claimed intent back to succeeded when the provider
+// turned the refund down, returning anything holdRefund took
+func releaseRefund(db *sql.DB, queries *database.Queries, intent database.PaymentIntent, detail string) error {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    rows, err := qtx.TransitionPaymentIntent(context.Background(), database.TransitionPaymentIntentParams{
+        NewStatus: payment.StatusSucceeded,
+        IntentID:  intent.IntentID,
+        OldStatus: payment.StatusRefunding,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update payment intent: %w", err)
+    }
+    if rows == 0 {
+        // The provider reported the refund after all
+        return nil
+    }
+
+    switch intent.Purpose {
+    case payment.PurposeRecharge:
+        err = qtx.AddAccountBalance(context.Background(), database.AddAccountBalanceParams{
+            Balance: intent.Amount,
+            ID:      intent.UserID,
+        })
+    case payment.PurposeGiftCard:
+        _, err = qtx.ActivateGiftCard(context.Background(), intent.GiftCardID.Int32)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to release refund hold: %w", err)
+    }
+    logPaymentEvent(qtx, intent.IntentID, "refund.failed", payment.StatusSucceeded, intent.Amount, detail)
+    return tx.Commit()
+}
+
+// CREATE PAYMENT INTENT
+func createPaymentIntentHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Create payment intent request received from user:", username)
+
+    type CreateIntentRequest struct {
+        Purpose  string  `json:"purpose"`
+        Provider string  `json:"provider"`
+        OrderID  int32   `json:"order_id"`
+        Amount   float64 `json:"amount"`
+    }
+    type CreateIntentResponse struct {
+        Success bool                   `json:"success"`
+        Intent  database.PaymentIntent `json:"intent"`
+        Message string                 `json:"message"`
+    }
+
+    var intentReq CreateIntentRequest
+    if err := json.NewDecoder(req.Body).Decode(&intentReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    provider, ok := paymentProviders(queries)[intentReq.Provider]
+    if !ok {
+        http.Error(writer, "Unknown payment provider", http.StatusBadRequest)
+        return
+    }
+
+    var orderID sql.NullInt32
+    amount := intentReq.Amount
+    switch intentReq.Purpose {
+    case payment.PurposeOrder:
+        order, err := queries.GetOrderById(context.Background(), intentReq.OrderID)
+        if err != nil || order.UserID != userID || order.IsPaid || order.Deleted {
+            http.Error(writer, "Invalid order ID or already paid", http.StatusBadRequest)
+            return
+        }
+        counts, err := queries.CountBillShares(context.Background(), order.OrderID)
+        if err != nil {
+            http.Error(writer, "Failed to check bill shares", http.StatusInternalServerError)
+            return
+        }
+        if counts.ShareCount > 0 {
+            http.Error(writer, "The bill for this order is split, pay a share instead", http.StatusConflict)
+            return
+        }
+        amount, err = orderAmountDue(queries, order.OrderID)
+        if err != nil {
+            http.Error(writer, "Failed to calculate order total", http.StatusInternalServerError)
+            return
+        }
+        orderID = sql.NullInt32{Int32: order.OrderID, Valid: true}
+    case payment.PurposeRecharge:
+        if amount <= 0 {
+            http.Error(writer, "Invalid recharge amount", http.StatusBadRequest)
+            return
+        }
+        fits, err := rechargeFits(queries, userID, amount)
+        if err != nil {
+            http.Error(writer, "Failed to retrieve account", http.StatusInternalServerError)
+            return
+        }
+        if !fits {
+            http.Error(writer, fmt.Sprintf("Wallet balance cannot exceed %.2f", maxBalance), http.StatusConflict)
+            return
+        }
+    default:
+        http.Error(writer, "Invalid payment purpose", http.StatusBadRequest)
+        return
+    }
+
+    var intent database.PaymentIntent
+    if orderID.Valid {
+        intent, err = startOrderPayment(db, queries, provider, userID, orderID.Int32, amount)
+    } else {
+        intent, err = startPayment(queries, provider, database.CreatePaymentIntentParams{
+            UserID:  userID,
+            Purpose: intentReq.Purpose,
+            Amount:  amount,
+        })
+    }
+    if errors.Is(err, errOrderAlreadyPaid) {
+        http.Error(writer, "Invalid order ID or already paid", http.StatusBadRequest)
+        return
+    }
+    if errors.Is(err, errOrderPaymentOpen) {
+        http.Error(writer, "This order already has a payment in progress", http.StatusConflict)
+        return
+    }
+    if errors.Is(err, errOrderSplit) {
+        http.Error(writer, "The bill for this order is split, pay a share instead", http.StatusConflict)
+        return
+    }
+    if errors.Is(err, payment.ErrUnsupported) {
+        http.Error(writer, "Payment provider cannot be used for this purpose", http.StatusBadRequest)
+        return
+    }
+    if errors.Is(err, payment.ErrInvalidAmount) {
+        http.Error(writer, "Amount must be positive", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Println("Error starting payment:", err)
+        http.Error(writer, "Failed to create payment intent", http.StatusInternalServerError)
+        return
+    }
+
+    resp := CreateIntentResponse{Success: true, Intent: intent, Message: "Payment intent created successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// CONFIRM PAYMENT INTENT
+func confirmPaymentIntentHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Confirm payment intent request received from user:", username)
+
+    type ConfirmIntentRequest struct {
+        IntentID   int32  `json:"intent_id"`
+        CardNumber string `json:"card_number"`
+    }
+    type ConfirmIntentResponse struct {
+        Success bool                   `json:"success"`
+        Intent  database.PaymentIntent `json:"intent"`
+        Message string                 `json:"message"`
+    }
+
+    var confirmReq ConfirmIntentRequest
+    if err := json.NewDecoder(req.Body).Decode(&confirmReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    intent, err := queries.GetPaymentIntent(context.Background(), confirmReq.IntentID)
+    if err != nil || intent.UserID != userID {
+        http.Error(writer, "Invalid payment intent", http.StatusBadRequest)
+        return
+    }
+
+    provider, ok := paymentProviders(queries)[intent.Provider]
+    if !ok {
+        http.Error(writer, "Unknown payment provider", http.StatusInternalServerError)
+        return
+    }
+
+    intent, err = confirmPayment(db, queries, provider, intent, payment.Method{CardNumber: confirmReq.CardNumber})
+    if !writePaymentError(writer, err) {
+        return
+    }
+
+    resp := ConfirmIntentResponse{Success: intent.Status == payment.StatusSucceeded, Intent: intent, Message: "Payment " + intent.Status}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// writePaymentError maps provider errors to responses, returning false if
+// the request has already been answered
+func writePaymentError(writer http.ResponseWriter, err error) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, errIntentNotPending):
+        http.Error(writer, "Payment intent is not awaiting confirmation", http.StatusConflict)
+    case errors.Is(err, errOrderAlreadyPaid):
+        http.Error(writer, "Order is already paid", http.StatusConflict)
+    case errors.Is(err, payment.ErrInsufficientFunds):
+        http.Error(writer, "Insufficient balance", http.StatusPaymentRequired)
+    case errors.Is(err, payment.ErrInvalidCard):
+        http.Error(writer, "Invalid card number", http.StatusBadRequest)
+    case errors.Is(err, payment.ErrInvalidAmount):
+        http.Error(writer, "Amount must be positive", http.StatusBadRequest)
+    default:
+        log.Println("Error confirming payment:", err)
+        http.Error(writer, "Failed to process payment", http.StatusBadGateway)
+    }
+    return false
+}
+
+// GET PAYMENT INTENT
+func getPaymentIntentHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get payment intent request received from user:", username)
+
+    intentIDStr := req.URL.Query().Get("intent_id")
+    if intentIDStr == "" {
+        http.Error(writer, "Missing intent_id query parameter", http.StatusBadRequest)
+        return
+    }
+    var intentID int32
+    if _, err := fmt.Sscanf(intentIDStr, "%d", &intentID); err != nil {
+        http.Error(writer, "Invalid intent_id", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    intent, err := queries.GetPaymentIntent(context.Background(), intentID)
+    if err != nil {
+        http.Error(writer, "Payment intent not found", http.StatusNotFound)
+        return
+    }
+    if intent.UserID != userID && !rbac.Can(currentRoles(req), rbac.PermOrdersView) {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    events, err := queries.GetPaymentEvents(context.Background(), intentID)
+    if err != nil {
+        http.Error(writer, "Failed to get payment events", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool                     `json:"success"`
+        Intent  database.PaymentIntent   `json:"intent"`
+        Events  []database.PaymentEvent  `json:"events"`
+        Message string                   `json:"message"`
+    }{
+        Success: true,
+        Intent:  intent,
+        Events:  events,
+        Message: "Payment intent retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// PAYMENT PROVIDER WEBHOOK
+func paymentWebhookHandler(writer http.ResponseWriter, req *http.Request) {
+    providerName := req.PathValue("provider")
+    log.Println("Payment webhook received from provider:", providerName)
+
+    payload, err := io.ReadAll(io.LimitReader(req.Body, 1<<20))
+    if err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    provider, ok := paymentProviders(queries)[providerName]
+    if !ok {
+        http.Error(writer, "Unknown payment provider", http.StatusNotFound)
+        return
+    }
+
+    event, err := provider.VerifyWebhook(payload, req.Header.Get(payment.SignatureHeader))
+    if err != nil {
+        log.Println("Rejected webhook:", err)
+        http.Error(writer, "Invalid signature", http.StatusBadRequest)
+        return
+    }
+
+    intent, err := queries.GetPaymentIntent(context.Background(), event.IntentID)
+    if err != nil || intent.Provider != providerName || intent.ProviderRef != event.ProviderRef {
+        http.Error(writer, "Unknown payment intent", http.StatusBadRequest)
+        return
+    }
+    if payment.RoundAmount(event.Amount) != intent.Amount {
+        http.Error(writer, "Amount mismatch", http.StatusBadRequest)
+        return
+    }
+
+    switch event.Type {
+    case payment.EventPaymentSucceeded:
+        err = settleIntent(db, intent.IntentID, payment.StatusProcessing, payment.StatusSucceeded, event.Type, "")
+    case payment.EventPaymentFailed:
+        err = settleIntent(db, intent.IntentID, payment.StatusProcessing, payment.StatusFailed, event.Type, event.Reason)
+    case payment.EventRefundSucceeded:
+        err = settleIntent(db, intent.IntentID, payment.StatusRefunding, payment.StatusRefunded, event.Type, "")
+    default:
+        log.Println("Ignoring webhook event type:", event.Type)
+    }
+    if err != nil {
+        log.Println("Error settling payment intent:", err)
+        http.Error(writer, "Failed to process event", http.StatusInternalServerError)
+        return
+    }
+
+    writer.WriteHeader(http.StatusOK)
+}
+
+// ADMIN: REFUND PAYMENT
+func refundPaymentHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Refund request received from admin:", username)
+
+    type RefundRequest struct {
+        IntentID int32 `json:"intent_id"`
+    }
+    type RefundResponse struct {
+        Success bool                   `json:"success"`
+        Intent  database.PaymentIntent `json:"intent"`
+        Message string                 `json:"message"`
+    }
+
+    var refundReq RefundRequest
+    if err := json.NewDecoder(req.Body).Decode(&refundReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    intent, err := refundIntent(db, queries, refundReq.IntentID, "by "+username)
+    switch {
+    case errors.Is(err, errNotRefundable):
+        http.Error(writer, "Only succeeded payments can be refunded", http.StatusBadRequest)
+        return
+    case errors.Is(err, errRechargeSpent):
+        http.Error(writer, "Recharged amount has already been spent", http.StatusConflict)
+        return
+    case errors.Is(err, errGiftCardRedeemed):
+        http.Error(writer, "Gift card has already been redeemed", http.StatusConflict)
+        return
+    case err != nil:
+        log.Println("Error refunding payment:", err)
+        http.Error(writer, "Failed to refund payment", http.StatusBadGateway)
+        return
+    }
+
+    resp := RefundResponse{Success: true, Intent: intent, Message: "Refund processed"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
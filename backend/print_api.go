@@ -0,0 +1,559 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/escpos"
+)
+
+// Kinds of print jobs
+const (
+    printKindKitchenTicket = "kitchen_ticket"
+    printKindReceipt       = "receipt"
+)
+
+const (
+    // printStationReceipt is the station customer receipts are printed at
+    printStationReceipt = "receipt"
+    // printMaxAttempts is how often a job is tried before it is marked failed
+    printMaxAttempts = 8
+    printTimeout     = 5 * time.Second
+)
+
+// enqueuePrintJob adds a rendered job to the print queue
+func enqueuePrintJob(queries *database.Queries, orderID int32, station, kind string, payload []byte, reprintOf int32) (int32, error) {
+    err := queries.CreatePrintJob(context.Background(), database.CreatePrintJobParams{
+        OrderID:       sql.NullInt32{Int32: orderID, Valid: orderID != 0},
+        Station:       station,
+        Kind:          kind,
+        Payload:       payload,
+        NextAttemptAt: time.Now().UTC(),
+        ReprintOf:     sql.NullInt32{Int32: reprintOf, Valid: reprintOf != 0},
+    })
+    if err != nil {
+        return 0, fmt.Errorf("failed to queue print job: %w", err)
+    }
+    return queries.GetLastInsertedPrintJobID(context.Background())
+}
+
+// queueKitchenTickets sends one ticket to each station with items in the order
+func queueKitchenTickets(queries *database.Queries, order database.Order, foods []database.Food) error {
+    items, err := queries.GetOrderedItems(context.Background(), order.OrderID)
+    if err != nil {
+        return fmt.Errorf("failed to get ordered items: %w", err)
+    }
+    stationOf := map[int32]string{}
+    for _, food := range foods {
+        stationOf[food.FoodID] = food.Station
+    }
+
+    var stations []string
+    tickets := map[string]*escpos.Ticket{}
+    for _, item := range items {
+        station := stationOf[item.FoodID]
+        ticket, ok := tickets[station]
+        if !ok {
+            ticket = &escpos.Ticket{
+                OrderID:         order.OrderID,
+                Station:         station,
+                OrderType:       order.OrderType,
+                TableNumber:     order.TableNumber.Int32,
+                DeliveryAddress: order.DeliveryAddress.String,
+                Notes:           order.OrderInfo,
+                PlacedAt:        order.OrderTime.Time,
+            }
+            if order.ScheduledFor.Valid {
+                ticket.DueAt = order.ScheduledFor.Time.In(storeLocation)
+            }
+            tickets[station] = ticket
+            stations = append(stations, station)
+        }
+        var modifiers []string
+        if item.Modifiers != "" {
+            modifiers = strings.Split(item.Modifiers, ", ")
+        }
+        ticket.Items = append(ticket.Items, escpos.TicketItem{
+            Name:      item.FoodName,
+            Quantity:  item.Quantity,
+            Modifiers: modifiers,
+        })
+    }
+
+    for _, station := range stations {
+        _, err := enqueuePrintJob(queries, order.OrderID, station, printKindKitchenTicket, escpos.KitchenTicket(*tickets[station]), 0)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// processPrintQueue sends every due job to its station's printer
+func processPrintQueue() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Print queue: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    now := time.Now().UTC()
+
+    // Jobs left mid-print by a crash go back in the queue
+    err = queries.RequeueStalePrintJobs(context.Background(), sql.NullTime{Time: now.Add(-2 * time.Minute), Valid: true})
+    if err != nil {
+        log.Println("Print queue: failed to requeue stale jobs:", err)
+    }
+
+    jobs, err := queries.GetDuePrintJobs(context.Background(), database.GetDuePrintJobsParams{
+        NextAttemptAt: now,
+        Limit:         20,
+    })
+    if err != nil {
+        log.Println("Print queue: failed to get jobs:", err)
+        return
+    }
+
+    for _, job := range jobs {
+        rows, err := queries.ClaimPrintJob(context.Background(), database.ClaimPrintJobParams{
+            ClaimedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+            JobID:     job.JobID,
+        })
+        if err != nil || rows == 0 {
+            continue
+        }
+        attempt := int(job.Attempts) + 1
+
+        err = printJob(queries, job)
+        if err == nil {
+            err = queries.MarkPrintJobPrinted(context.Background(), database.MarkPrintJobPrintedParams{
+                PrintedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+                JobID:     job.JobID,
+            })
+            if err != nil {
+                log.Println("Print queue: failed to mark job printed:", err)
+            }
+            continue
+        }
+
+        log.Printf("Print queue: job %d attempt %d failed: %v", job.JobID, attempt, err)
+        lastError := err.Error()
+        if len(lastError) > 255 {
+            lastError = lastError[:255]
+        }
+        if attempt >= printMaxAttempts {
+            err = queries.FailPrintJob(context.Background(), database.FailPrintJobParams{
+                LastError: sql.NullString{String: lastError, Valid: true},
+                JobID:     job.JobID,
+            })
+        } else {
+            err = queries.RetryPrintJob(context.Background(), database.RetryPrintJobParams{
+                LastError:     sql.NullString{String: lastError, Valid: true},
+                NextAttemptAt: time.Now().UTC().Add(escpos.RetryDelay(attempt)),
+                JobID:         job.JobID,
+            })
+        }
+        if err != nil {
+            log.Println("Print queue: failed to update job:", err)
+        }
+    }
+}
+
+func printJob(queries *database.Queries, job database.PrintJob) error {
+    printer, err := queries.GetActivePrinterByStation(context.Background(), job.Station)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("no printer for station %s", job.Station)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get printer: %w", err)
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), printTimeout)
+    defer cancel()
+    return escpos.Send(ctx, printer.Address, job.Payload, printTimeout)
+}
+
+// SET PRINTER
+func setPrinterHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Set printer request received from user:", username)
+
+    type SetPrinterRequest struct {
+        Name    string `json:"name"`
+        Station string `json:"station"`
+        Address string `json:"address"`
+    }
+    type SetPrinterResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var printerReq SetPrinterRequest
+    if err := json.NewDecoder(req.Body).Decode(&printerReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    printerReq.Station = strings.ToLower(strings.TrimSpace(printerReq.Station))
+    if printerReq.Name == "" || printerReq.Station == "" || printerReq.Address == "" {
+        http.Error(writer, "name, station and address are required", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // One printer per station; setting it again replaces the old one
+    err = queries.UpsertPrinter(context.Background(), database.UpsertPrinterParams{
+        Name:    printerReq.Name,
+        Station: printerReq.Station,
+        Address: escpos.Address(printerReq.Address),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to save printer", http.StatusInternalServerError)
+        return
+    }
+
+    resp := SetPrinterResponse{Success: true, Message: "Printer saved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET PRINTERS
+func getPrintersHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get printers request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    printers, err := queries.GetPrinters(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get printers", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success  bool               `json:"success"`
+        Printers []database.Printer `json:"printers"`
+        Message  string             `json:"message"`
+    }{
+        Success:  true,
+        Printers: printers,
+        Message:  "Printers retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// DELETE PRINTER
+func deletePrinterHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Delete printer request received from user:", username)
+
+    type DeletePrinterRequest struct {
+        PrinterID int32 `json:"printer_id"`
+    }
+    type DeletePrinterResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var deleteReq DeletePrinterRequest
+    if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.DeletePrinter(context.Background(), deleteReq.PrinterID)
+    if err != nil {
+        http.Error(writer, "Failed to delete printer", http.StatusInternalServerError)
+        return
+    }
+
+    resp := DeletePrinterResponse{Success: true, Message: "Printer deleted successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// SET FOOD STATION
+func setFoodStationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Set food station request received from user:", username)
+
+    type SetStationRequest struct {
+        FoodID  int32  `json:"food_id"`
+        Station string `json:"station"`
+    }
+    type SetStationResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var stationReq SetStationRequest
+    if err := json.NewDecoder(req.Body).Decode(&stationReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    stationReq.Station = strings.ToLower(strings.TrimSpace(stationReq.Station))
+    if stationReq.Station == "" || stationReq.Station == printStationReceipt {
+        http.Error(writer, "Invalid station", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if _, err := queries.GetFoodById(context.Background(), stationReq.FoodID); err != nil {
+        http.Error(writer, "Invalid food ID", http.StatusBadRequest)
+        return
+    }
+
+    err = queries.UpdateFoodStation(context.Background(), database.UpdateFoodStationParams{
+        Station: stationReq.Station,
+        FoodID:  stationReq.FoodID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update food station", http.StatusInternalServerError)
+        return
+    }
+
+    resp := SetStationResponse{Success: true, Message: "Food station updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET PRINT JOBS
+func getPrintJobsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get print jobs request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    jobs, err := queries.GetPrintJobs(context.Background(), database.GetPrintJobsParams{
+        Status: req.URL.Query().Get("status"),
+        Limit:  100,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get print jobs", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool                       `json:"success"`
+        Jobs    []database.GetPrintJobsRow `json:"jobs"`
+        Message string                     `json:"message"`
+    }{
+        Success: true,
+        Jobs:    jobs,
+        Message: "Print jobs retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// PRINT RECEIPT
+func printReceiptHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Print receipt request received from user:", username)
+
+    type PrintReceiptRequest struct {
+        OrderID int32 `json:"order_id"`
+    }
+    type PrintResponse struct {
+        Success bool   `json:"success"`
+        JobID   int32  `json:"job_id"`
+        Message string `json:"message"`
+    }
+
+    var printReq PrintReceiptRequest
+    if err := json.NewDecoder(req.Body).Decode(&printReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), printReq.OrderID)
+    if err != nil {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+    if !order.IsPaid {
+        http.Error(writer, "Order has not been paid yet", http.StatusConflict)
+        return
+    }
+
+    r, err := buildReceipt(queries, order)
+    if err != nil {
+        log.Println("Error building receipt:", err)
+        http.Error(writer, "Failed to build receipt", http.StatusInternalServerError)
+        return
+    }
+    jobID, err := enqueuePrintJob(queries, order.OrderID, printStationReceipt, printKindReceipt, escpos.Receipt(r), 0)
+    if err != nil {
+        log.Println("Error queueing receipt:", err)
+        http.Error(writer, "Failed to queue receipt", http.StatusInternalServerError)
+        return
+    }
+
+    resp := PrintResponse{Success: true, JobID: jobID, Message: "Receipt queued for printing"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// REPRINT
+func reprintHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Reprint request received from user:", username)
+
+    type ReprintRequest struct {
+        JobID int32 `json:"job_id"`
+    }
+    type ReprintResponse struct {
+        Success bool   `json:"success"`
+        JobID   int32  `json:"job_id"`
+        Message string `json:"message"`
+    }
+
+    var reprintReq ReprintRequest
+    if err := json.NewDecoder(req.Body).Decode(&reprintReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    job, err := queries.GetPrintJob(context.Background(), reprintReq.JobID)
+    if err != nil {
+        http.Error(writer, "Invalid job ID", http.StatusBadRequest)
+        return
+    }
+
+    // Reprint the original so banners don't pile up on reprints of reprints
+    original := job
+    if job.ReprintOf.Valid {
+        if first, err := queries.GetPrintJob(context.Background(), job.ReprintOf.Int32); err == nil {
+            original = first
+        }
+    }
+    jobID, err := enqueuePrintJob(queries, job.OrderID.Int32, job.Station, job.Kind, escpos.MarkReprint(original.Payload), original.JobID)
+    if err != nil {
+        log.Println("Error queueing reprint:", err)
+        http.Error(writer, "Failed to queue reprint", http.StatusInternalServerError)
+        return
+    }
+
+    resp := ReprintResponse{Success: true, JobID: jobID, Message: "Reprint queued"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,524 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "fmt"
+    "log"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/export"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+// deletionGracePeriod is how long a customer has to change their mind
+// before a deletion request is carried out
+var deletionGracePeriod = envDuration("DELETION_GRACE_PERIOD", 30*24*time.Hour)
+
+func nullStringPtr(s sql.NullString) *string {
+    if !s.Valid {
+        return nil
+    }
+    return &s.String
+}
+
+func nullFloatPtr(f sql.NullFloat64) *float64 {
+    if !f.Valid {
+        return nil
+    }
+    return &f.Float64
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+    if !n.Valid {
+        return nil
+    }
+    return &n.Int32
+}
+
+type exportAccount struct {
+    ID                 int32      `json:"id"`
+    Username           string     `json:"username"`
+    Email              string     `json:"email"`
+    Address            string     `json:"address"`
+    Phone              int64      `json:"phone"`
+    Balance            float64    `json:"balance"`
+    UserTag            *string    `json:"user_tag"`
+    EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+    PhoneVerifiedAt    *time.Time `json:"phone_verified_at"`
+    TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+    Status             string     `json:"status"`
+    Roles              []string   `json:"roles"`
+}
+
+type exportAddress struct {
+    AddressID int32      `json:"address_id"`
+    Label     string     `json:"label"`
+    Recipient string     `json:"recipient"`
+    Phone     string     `json:"phone"`
+    Line1     string     `json:"line1"`
+    Line2     string     `json:"line2"`
+    City      string     `json:"city"`
+    Postcode  string     `json:"postcode"`
+    Lat       *float64   `json:"lat"`
+    Lng       *float64   `json:"lng"`
+    IsDefault bool       `json:"is_default"`
+    CreatedAt *time.Time `json:"created_at"`
+}
+
+type exportItem struct {
+    ItemID    int32   `json:"item_id"`
+    FoodID    int32   `json:"food_id"`
+    FoodName  string  `json:"food_name"`
+    Quantity  int32   `json:"quantity"`
+    UnitPrice float64 `json:"unit_price"`
+    Modifiers string  `json:"modifiers"`
+    Rating    *int32  `json:"rating"`
+}
+
+type exportOrder struct {
+    OrderID            int32        `json:"order_id"`
+    OrderType          string       `json:"order_type"`
+    OrderInfo          string       `json:"order_info"`
+    OrderTime          *time.Time   `json:"order_time"`
+    ScheduledFor       *time.Time   `json:"scheduled_for"`
+    IsDone             bool         `json:"is_done"`
+    IsPaid             bool         `json:"is_paid"`
+    Deleted            bool         `json:"deleted"`
+    TableNumber        *int32       `json:"table_number"`
+    DeliveryAddress    *string      `json:"delivery_address"`
+    DeliveryRecipient  *string      `json:"delivery_recipient"`
+    DeliveryPhone      *string      `json:"delivery_phone"`
+    DeliveryFee        float64      `json:"delivery_fee"`
+    InvoiceBillingName *string      `json:"invoice_billing_name"`
+    InvoiceTaxID       *string      `json:"invoice_tax_id"`
+    Feedback           *string      `json:"feedback"`
+    Items              []exportItem `json:"items"`
+}
+
+// collectExport gathers everything kept about account
+func collectExport(queries *database.Queries, account database.Account) (exportAccount, []exportAddress, []exportOrder, error) {
+    roles, err := queries.GetAccountRoles(context.Background(), account.ID)
+    if err != nil {
+        return exportAccount{}, nil, nil, err
+    }
+    acc := exportAccount{
+        ID:                 account.ID,
+        Username:           account.Username,
+        Email:              account.Email,
+        Address:            account.Address,
+        Phone:              account.UserPhoneNumber,
+        Balance:            account.Balance,
+        UserTag:            nullStringPtr(account.UserTag),
+        EmailVerifiedAt:    nullTimePtr(account.EmailVerifiedAt),
+        PhoneVerifiedAt:    nullTimePtr(account.PhoneVerifiedAt),
+        TwoFactorEnabledAt: nullTimePtr(account.TwoFactorEnabledAt),
+        Status:             account.Status,
+        Roles:              roles,
+    }
+
+    addressRows, err := queries.GetAddressesByUser(context.Background(), account.ID)
+    if err != nil {
+        return acc, nil, nil, err
+    }
+    addresses := make([]exportAddress, 0, len(addressRows))
+    for _, a := range addressRows {
+        addresses = append(addresses, exportAddress{
+            AddressID: a.AddressID,
+            Label:     a.Label,
+            Recipient: a.Recipient,
+            Phone:     a.Phone,
+            Line1:     a.Line1,
+            Line2:     a.Line2,
+            City:      a.City,
+            Postcode:  a.Postcode,
+            Lat:       nullFloatPtr(a.Lat),
+            Lng:       nullFloatPtr(a.Lng),
+            IsDefault: a.IsDefault,
+            CreatedAt: nullTimePtr(a.CreatedAt),
+        })
+    }
+
+    orderRows, err := queries.GetOrdersByUserForExport(context.Background(), account.ID)
+    if err != nil {
+        return acc, addresses, nil, err
+    }
+    itemRows, err := queries.GetItemsByUser(context.Background(), account.ID)
+    if err != nil {
+        return acc, addresses, nil, err
+    }
+    items := make(map[int32][]exportItem)
+    for _, item := range itemRows {
+        items[item.OrderID] = append(items[item.OrderID], exportItem{
+            ItemID:    item.ItemID,
+            FoodID:    item.FoodID,
+            FoodName:  item.FoodName,
+            Quantity:  item.Quantity,
+            UnitPrice: item.UnitPrice,
+            Modifiers: item.Modifiers,
+            Rating:    nullInt32Ptr(item.Rating),
+        })
+    }
+    orders := make([]exportOrder, 0, len(orderRows))
+    for _, o := range orderRows {
+        orderItems := items[o.OrderID]
+        if orderItems == nil {
+            orderItems = []exportItem{}
+        }
+        orders = append(orders, exportOrder{
+            OrderID:            o.OrderID,
+            OrderType:          o.OrderType,
+            OrderInfo:          o.OrderInfo,
+            OrderTime:          nullTimePtr(o.OrderTime),
+            ScheduledFor:       nullTimePtr(o.ScheduledFor),
+            IsDone:             o.IsDone,
+            IsPaid:             o.IsPaid,
+            Deleted:            o.Deleted,
+            TableNumber:        nullInt32Ptr(o.TableNumber),
+            DeliveryAddress:    nullStringPtr(o.DeliveryAddress),
+            DeliveryRecipient:  nullStringPtr(o.DeliveryRecipient),
+            DeliveryPhone:      nullStringPtr(o.DeliveryPhone),
+            DeliveryFee:        o.DeliveryFee,
+            InvoiceBillingName: nullStringPtr(o.InvoiceBillingName),
+            InvoiceTaxID:       nullStringPtr(o.InvoiceTaxID),
+            Feedback:           nullStringPtr(o.Feedback),
+            Items:              orderItems,
+        })
+    }
+    return acc, addresses, orders, nil
+}
+
+// EXPORT MY DATA
+func exportDataHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Export data request received from user:", username)
+
+    format := req.URL.Query().Get("format")
+    if format == "" {
+        format = "json"
+    }
+    if format != "json" && format != "zip" {
+        http.Error(writer, "Format must be json or zip", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account, addresses, orders, err := collectExport(queries, currentAccount(req))
+    if err != nil {
+        log.Println("Error collecting export:", err)
+        http.Error(writer, "Failed to export data", http.StatusInternalServerError)
+        return
+    }
+
+    now := time.Now().UTC()
+    filename := fmt.Sprintf("export-%s-%s", account.Username, now.Format("20060102"))
+    if format == "zip" {
+        writer.Header().Set("Content-Type", "application/zip")
+        writer.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+        err = export.WriteZIP(writer, []export.File{
+            {Name: "account", Data: account},
+            {Name: "addresses", Data: addresses},
+            {Name: "orders", Data: orders},
+        }, now)
+    } else {
+        writer.Header().Set("Content-Type", "application/json")
+        writer.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+        err = export.WriteJSON(writer, struct {
+            ExportedAt time.Time       `json:"exported_at"`
+            Account    exportAccount   `json:"account"`
+            Addresses  []exportAddress `json:"addresses"`
+            Orders     []exportOrder   `json:"orders"`
+        }{now, account, addresses, orders})
+    }
+    if err != nil {
+        log.Println("Error writing export:", err)
+    }
+}
+
+// GET DELETION REQUEST
+func getDeletionHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get deletion request received from user:", username)
+
+    type DeletionResponse struct {
+        Success      bool       `json:"success"`
+        Pending      bool       `json:"pending"`
+        RequestedAt  *time.Time `json:"requested_at"`
+        ScheduledFor *time.Time `json:"scheduled_for"`
+        Message      string     `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    resp := DeletionResponse{Success: true, Message: "No deletion requested"}
+    request, err := queries.GetPendingDeletionRequest(context.Background(), userID)
+    if err != nil && err != sql.ErrNoRows {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if err == nil {
+        resp.Pending = true
+        resp.RequestedAt = &request.RequestedAt
+        resp.ScheduledFor = &request.ScheduledFor
+        resp.Message = "Account deletion scheduled"
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// REQUEST DELETION
+func requestDeletionHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Request deletion request received from user:", username)
+
+    type DeletionRequest struct {
+        Password string `json:"password"`
+        Code     string `json:"code"`
+    }
+    type DeletionResponse struct {
+        Success      bool      `json:"success"`
+        ScheduledFor time.Time `json:"scheduled_for"`
+        Message      string    `json:"message"`
+    }
+
+    var deletionReq DeletionRequest
+    if err := json.NewDecoder(req.Body).Decode(&deletionReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account := currentAccount(req)
+    if !verifyPassword(writer, req, queries, account, deletionReq.Password, "Password is incorrect", time.Now().UTC()) {
+        return
+    }
+
+    if _, err := queries.GetPendingDeletionRequest(context.Background(), userID); err == nil {
+        http.Error(writer, "Account deletion is already scheduled", http.StatusConflict)
+        return
+    } else if err != sql.ErrNoRows {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    roles, err := queries.GetAccountRoles(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    for _, role := range roles {
+        if role != rbac.RoleOwner {
+            continue
+        }
+        owners, err := queries.CountRole(context.Background(), rbac.RoleOwner)
+        if err != nil {
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        if owners <= 1 {
+            http.Error(writer, "Cannot delete the last owner", http.StatusConflict)
+            return
+        }
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    if account.TwoFactorEnabledAt.Valid && !verifySecondFactor(writer, req, queries, qtx, account, deletionReq.Code, now) {
+        return
+    }
+
+    scheduledFor := now.Add(deletionGracePeriod)
+    err = qtx.CreateDeletionRequest(context.Background(), database.CreateDeletionRequestParams{
+        UserID:       userID,
+        Ip:           truncate(clientIP(req), 64),
+        RequestedAt:  now,
+        ScheduledFor: scheduledFor,
+    })
+    if err == nil {
+        body := fmt.Sprintf("Hello %s,\n\nYour account will be deleted on %s. Your orders are kept for our accounts, but everything that identifies you will be removed.\nIf you change your mind, log in and cancel the deletion before then.\n", account.Username, scheduledFor.Format("2 January 2006 15:04 MST"))
+        err = queueMail(qtx, account.ID, account.Email, "Your account will be deleted", body)
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to schedule deletion", http.StatusInternalServerError)
+        return
+    }
+
+    resp := DeletionResponse{Success: true, ScheduledFor: scheduledFor, Message: "Account deletion scheduled"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// CANCEL DELETION
+func cancelDeletionHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Cancel deletion request received from user:", username)
+
+    type CancelResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.CancelDeletionRequests(context.Background(), database.CancelDeletionRequestsParams{
+        CancelledAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID:      userID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to cancel deletion", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "No deletion is scheduled", http.StatusNotFound)
+        return
+    }
+
+    resp := CancelResponse{Success: true, Message: "Account deletion cancelled"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// processDeletionRequests anonymizes accounts whose grace period is over
+func processDeletionRequests() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Deletions: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    now := time.Now().UTC()
+
+    requests, err := queries.GetDueDeletionRequests(context.Background(), database.GetDueDeletionRequestsParams{
+        ScheduledFor: now,
+        Limit:        20,
+    })
+    if err != nil {
+        log.Println("Deletions: failed to get requests:", err)
+        return
+    }
+
+    for _, request := range requests {
+        if err := completeDeletion(db, queries, request.RequestID, now); err != nil {
+            log.Printf("Deletions: request %d failed: %v", request.RequestID, err)
+        }
+    }
+}
+
+func completeDeletion(db *sql.DB, queries *database.Queries, requestID int32, now time.Time) error {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    // Cancelled or done since it was listed
+    request, err := qtx.GetDeletionRequestForUpdate(context.Background(), requestID)
+    if err != nil || request.CancelledAt.Valid || request.CompletedAt.Valid {
+        return err
+    }
+    account, err := qtx.GetAccountByID(context.Background(), request.UserID)
+    if err != nil {
+        return err
+    }
+
+    // Anonymizing drops the account's mail, so the goodbye is queued after
+    // it, to the old address; the mail worker deletes it once sent
+    if account.Status != statusDeleted {
+        err = anonymizeAccount(qtx, account, "Deleted at the customer's request")
+        if err == nil {
+            body := "Hello,\n\nAs you asked, your account has been deleted. Your orders are kept for our accounts without your personal details.\n"
+            err = queueMail(qtx, account.ID, account.Email, "Your account has been deleted", body)
+        }
+    }
+    if err == nil {
+        err = qtx.CompleteDeletionRequest(context.Background(), database.CompleteDeletionRequestParams{
+            CompletedAt: sql.NullTime{Time: now, Valid: true},
+            RequestID:   requestID,
+        })
+    }
+    if err == nil {
+        err = recordAudit(qtx, 0, account.ID, "user.anonymize", "Deleted at the customer's request", map[string]any{
+            "request_id": requestID,
+        })
+    }
+    if err != nil {
+        return err
+    }
+    log.Println("Deletions: anonymized account", account.ID)
+    return tx.Commit()
+}
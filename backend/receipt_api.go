@@ -0,0 +1,302 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "os"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+    "github.com/Bryanthai/ordersystem/internal/receipt"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+// Prices are tax inclusive; TAX_RATE is the percentage they contain
+var (
+    taxRatePercent = envFloat("TAX_RATE", 10)
+    taxLabel       = envString("TAX_LABEL", "VAT")
+)
+
+func restaurantDetails() receipt.Business {
+    return receipt.Business{
+        Name:    envString("RESTAURANT_NAME", "Food Order System"),
+        Address: os.Getenv("RESTAURANT_ADDRESS"),
+        Phone:   os.Getenv("RESTAURANT_PHONE"),
+        TaxID:   os.Getenv("RESTAURANT_TAX_ID"),
+    }
+}
+
+// invoiceDetails are the company details a customer wants on their receipt
+type invoiceDetails struct {
+    BillingName string `json:"billing_name"`
+    TaxID       string `json:"tax_id"`
+}
+
+func (d *invoiceDetails) validate() error {
+    d.BillingName = strings.TrimSpace(d.BillingName)
+    d.TaxID = strings.TrimSpace(d.TaxID)
+    if d.TaxID != "" && d.BillingName == "" {
+        return errors.New("Invoice details need a billing_name")
+    }
+    if len(d.BillingName) > 255 || len(d.TaxID) > 50 {
+        return errors.New("Invoice details are too long")
+    }
+    return nil
+}
+
+func (d invoiceDetails) billingName() sql.NullString {
+    return sql.NullString{String: d.BillingName, Valid: d.BillingName != ""}
+}
+
+func (d invoiceDetails) taxID() sql.NullString {
+    return sql.NullString{String: d.TaxID, Valid: d.TaxID != ""}
+}
+
+func paymentMethodLabel(provider string) string {
+    switch provider {
+    case "wallet":
+        return "Wallet"
+    case "mock_card":
+        return "Card"
+    }
+    return provider
+}
+
+// buildReceipt collects everything printed on the receipt of a paid order
+func buildReceipt(queries *database.Queries, order database.Order) (receipt.Receipt, error) {
+    items, err := queries.GetOrderedItems(context.Background(), order.OrderID)
+    if err != nil {
+        return receipt.Receipt{}, fmt.Errorf("failed to get ordered items: %w", err)
+    }
+    total, err := orderAmountDue(queries, order.OrderID)
+    if err != nil {
+        return receipt.Receipt{}, fmt.Errorf("failed to calculate order total: %w", err)
+    }
+    intents, err := queries.GetPaymentIntentsByOrder(context.Background(), sql.NullInt32{Int32: order.OrderID, Valid: true})
+    if err != nil {
+        return receipt.Receipt{}, fmt.Errorf("failed to get payments: %w", err)
+    }
+    customer, err := queries.GetAccountByID(context.Background(), order.UserID)
+    if err != nil {
+        return receipt.Receipt{}, fmt.Errorf("failed to get customer: %w", err)
+    }
+
+    r := receipt.Receipt{
+        Business:  restaurantDetails(),
+        OrderID:   order.OrderID,
+        OrderType: order.OrderType,
+        Customer:  customer.Username,
+        Total:     total,
+    }
+    if order.TableNumber.Valid {
+        r.TableNumber = order.TableNumber.Int32
+    }
+    if order.InvoiceBillingName.Valid {
+        r.Invoice = &receipt.Invoice{
+            BillingName: order.InvoiceBillingName.String,
+            TaxID:       order.InvoiceTaxID.String,
+        }
+    }
+
+    var subtotalCents int64
+    for _, item := range items {
+        r.Lines = append(r.Lines, receipt.Line{Name: item.FoodName, Quantity: item.Quantity, UnitPrice: item.UnitPrice})
+        subtotalCents += billing.ToCents(item.UnitPrice * float64(item.Quantity))
+    }
+    r.Subtotal = billing.FromCents(subtotalCents)
+    if order.DeliveryFee > 0 {
+        r.Charges = append(r.Charges, receipt.Charge{Label: "Delivery fee", Amount: order.DeliveryFee})
+    }
+
+    if taxRatePercent > 0 {
+        net, tax := billing.IncludedTax(total, taxRatePercent)
+        r.Taxes = []receipt.Tax{{Label: taxLabel, Rate: taxRatePercent, Net: net, Amount: tax}}
+    }
+
+    var tipCents int64
+    for _, intent := range intents {
+        if intent.Status != payment.StatusSucceeded {
+            continue
+        }
+        switch intent.Purpose {
+        case payment.PurposeOrder, payment.PurposeBillShare:
+            r.Payments = append(r.Payments, receipt.Payment{
+                Method:    paymentMethodLabel(intent.Provider),
+                Reference: intent.ProviderRef,
+                Amount:    intent.Amount,
+            })
+            if intent.UpdatedAt.Valid && intent.UpdatedAt.Time.After(r.IssuedAt) {
+                r.IssuedAt = intent.UpdatedAt.Time
+            }
+        case payment.PurposeTip:
+            tipCents += billing.ToCents(intent.Amount)
+        }
+    }
+    r.Tip = billing.FromCents(tipCents)
+
+    // Orders paid before payment intents existed went straight to the wallet
+    if len(r.Payments) == 0 {
+        r.Payments = []receipt.Payment{{Method: "Wallet", Amount: total}}
+    }
+    if r.IssuedAt.IsZero() {
+        r.IssuedAt = order.OrderTime.Time
+        if !order.OrderTime.Valid {
+            r.IssuedAt = time.Now()
+        }
+    }
+    return r, nil
+}
+
+// GET RECEIPT
+func getReceiptHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get receipt request received from user:", username)
+
+    orderIDStr := req.URL.Query().Get("order_id")
+    if orderIDStr == "" {
+        http.Error(writer, "Missing order_id query parameter", http.StatusBadRequest)
+        return
+    }
+    var orderID int32
+    if _, err := fmt.Sscanf(orderIDStr, "%d", &orderID); err != nil {
+        http.Error(writer, "Invalid order_id", http.StatusBadRequest)
+        return
+    }
+    format := req.URL.Query().Get("format")
+    if format == "" {
+        format = "pdf"
+    }
+    if format != "pdf" && format != "text" && format != "html" {
+        http.Error(writer, "Invalid format, expected pdf, text or html", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), orderID)
+    if err != nil {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+    if order.UserID != userID && !rbac.Can(currentRoles(req), rbac.PermOrdersView) {
+        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+    if !order.IsPaid {
+        http.Error(writer, "Order has not been paid yet", http.StatusConflict)
+        return
+    }
+
+    r, err := buildReceipt(queries, order)
+    if err != nil {
+        log.Println("Error building receipt:", err)
+        http.Error(writer, "Failed to build receipt", http.StatusInternalServerError)
+        return
+    }
+
+    filename := "receipt-" + r.Number()
+    switch format {
+    case "pdf":
+        writer.Header().Set("Content-Type", "application/pdf")
+        writer.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.pdf"`)
+        writer.Write(receipt.PDF(r))
+    case "text":
+        writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+        writer.Header().Set("Content-Disposition", `inline; filename="`+filename+`.txt"`)
+        writer.Write([]byte(receipt.Text(r)))
+    case "html":
+        page, err := receipt.HTML(r)
+        if err != nil {
+            log.Println("Error rendering receipt:", err)
+            http.Error(writer, "Failed to render receipt", http.StatusInternalServerError)
+            return
+        }
+        writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+        writer.Write(page)
+    }
+}
+
+// UPDATE INVOICE DETAILS
+func updateInvoiceDetailsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Update invoice details request received from user:", username)
+
+    type InvoiceRequest struct {
+        OrderID int32 `json:"order_id"`
+        invoiceDetails
+    }
+    type InvoiceResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var invoiceReq InvoiceRequest
+    if err := json.NewDecoder(req.Body).Decode(&invoiceReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if err := invoiceReq.validate(); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), invoiceReq.OrderID)
+    if err != nil || order.UserID != userID || order.Deleted {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+
+    // Empty details take the company details off the receipt again
+    err = queries.UpdateOrderInvoiceDetails(context.Background(), database.UpdateOrderInvoiceDetailsParams{
+        InvoiceBillingName: invoiceReq.billingName(),
+        InvoiceTaxID:       invoiceReq.taxID(),
+        OrderID:            order.OrderID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update invoice details", http.StatusInternalServerError)
+        return
+    }
+
+    resp := InvoiceResponse{Success: true, Message: "Invoice details updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
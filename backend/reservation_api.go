@@ -0,0 +1,1409 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+    "github.com/Bryanthai/ordersystem/internal/reservation"
+    "github.com/Bryanthai/ordersystem/internal/schedule"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+// RESERVATION_DEPOSIT is taken per guest; 0 books without a deposit
+var (
+    reservationLength   = envDuration("RESERVATION_LENGTH", 90*time.Minute)
+    reservationNotice   = envDuration("RESERVATION_REFUND_NOTICE", 24*time.Hour)
+    reservationMaxAhead = envDuration("RESERVATION_MAX_AHEAD", 30*24*time.Hour)
+    tableTurnover       = envDuration("TABLE_TURNOVER", 45*time.Minute)
+    reservationDeposit  = envFloat("RESERVATION_DEPOSIT", 0)
+)
+
+const notificationTableReady = "table_ready"
+
+var errReservationClosed = errors.New("This reservation can no longer be changed")
+
+func diningTables(rows []database.DiningTable) []reservation.Table {
+    tables := make([]reservation.Table, 0, len(rows))
+    for _, row := range rows {
+        if row.IsActive {
+            tables = append(tables, reservation.Table{Number: row.TableNumber, Seats: int(row.Seats)})
+        }
+    }
+    return tables
+}
+
+func tableBookings(rows []database.Reservation) []reservation.Booking {
+    bookings := make([]reservation.Booking, len(rows))
+    for i, row := range rows {
+        bookings[i] = reservation.Booking{Table: row.TableNumber, Start: row.StartsAt, End: row.EndsAt}
+    }
+    return bookings
+}
+
+// checkReservationTime makes sure a table can be booked for start
+func checkReservationTime(queries *database.Queries, start time.Time) error {
+    now := time.Now()
+    switch {
+    case !start.Equal(schedule.SlotStart(start)):
+        return errors.New("Reservations start on the quarter hour")
+    case start.Before(now):
+        return errors.New("Reservations can't start in the past")
+    case start.After(now.Add(reservationMaxAhead)):
+        return errors.New("Reservations can't be made that far ahead")
+    }
+    calendars, err := loadCalendars(queries)
+    if err != nil {
+        return err
+    }
+    if !calendars[orderTypeDineIn].IsOpen(start.In(storeLocation)) {
+        return errors.New("The restaurant is closed at that time")
+    }
+    return nil
+}
+
+// holdTable picks a table for party at start and stores the reservation
+// with save, while every table is locked so two bookings can't take the
+// same one. excludeID is a reservation being moved, whose own time doesn't
+// count against it.
+func holdTable(db *sql.DB, party int, start time.Time, excludeID int32, save func(*database.Queries, reservation.Table) error) error {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := database.New(db).WithTx(tx)
+
+    rows, err := qtx.GetActiveDiningTablesForUpdate(context.Background())
+    if err != nil {
+        return fmt.Errorf("failed to get tables: %w", err)
+    }
+    held, err := qtx.GetHeldReservations(context.Background(), database.GetHeldReservationsParams{
+        PeriodStart: start.UTC(),
+        PeriodEnd:   start.Add(reservationLength).UTC(),
+        ExcludeID:   excludeID,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to get reservations: %w", err)
+    }
+    table, err := reservation.Pick(diningTables(rows), tableBookings(held), party, start, start.Add(reservationLength))
+    if err != nil {
+        return err
+    }
+    if err := save(qtx, table); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func setDepositStatus(queries *database.Queries, reservationID int32, status string) error {
+    err := queries.UpdateReservationDeposit(context.Background(), database.UpdateReservationDepositParams{
+        DepositStatus: status,
+        ReservationID: reservationID,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update deposit: %w", err)
+    }
+    return nil
+}
+
+// confirmReservation books the table once the deposit is paid
+func confirmReservation(queries *database.Queries, reservationID int32) error {
+    _, err := queries.TransitionReservation(context.Background(), database.TransitionReservationParams{
+        NewStatus:     reservation.StatusBooked,
+        ReservationID: reservationID,
+        OldStatus:     reservation.StatusPending,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to book reservation: %w", err)
+    }
+    return setDepositStatus(queries, reservationID, reservation.DepositPaid)
+}
+
+// releaseReservation gives the table back when the deposit wasn't paid
+func releaseReservation(queries *database.Queries, reservationID int32) error {
+    _, err := queries.TransitionReservation(context.Background(), database.TransitionReservationParams{
+        NewStatus:     reservation.StatusCancelled,
+        ReservationID: reservationID,
+        OldStatus:     reservation.StatusPending,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to release reservation: %w", err)
+    }
+    return setDepositStatus(queries, reservationID, reservation.DepositNone)
+}
+
+// settleDeposit returns a paid deposit to the wallet, or keeps it when
+// refund is false. The deposit intent is claimed under a lock before any
+// money moves, so a repeated or concurrent cancellation refunds it once.
+func settleDeposit(db *sql.DB, queries *database.Queries, booking database.Reservation, refund bool) error {
+    if booking.DepositStatus != reservation.DepositPaid {
+        return nil
+    }
+    if !refund {
+        return setDepositStatus(queries, booking.ReservationID, reservation.DepositForfeited)
+    }
+    intent, err := queries.GetReservationDeposit(context.Background(), sql.NullInt32{Int32: booking.ReservationID, Valid: true})
+    if errors.Is(err, sql.ErrNoRows) {
+        // Someone else already claimed the refund
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get deposit: %w", err)
+    }
+    _, err = refundIntent(db, queries, intent.IntentID, "reservation deposit")
+    if errors.Is(err, errNotRefundable) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to refund deposit: %w", err)
+    }
+    return nil
+}
+
+// linkTableOrder ties a new dine-in order to the reservation seated at its
+// table
+func linkTableOrder(queries *database.Queries, order database.Order) {
+    if order.OrderType != orderTypeDineIn || !order.TableNumber.Valid {
+        return
+    }
+    booking, err := queries.GetSeatedReservationForTable(context.Background(), database.GetSeatedReservationForTableParams{
+        TableNumber: order.TableNumber.Int32,
+        SeatedSince: sql.NullTime{Time: time.Now().UTC().Add(-reservationLength), Valid: true},
+    })
+    if err != nil {
+        return
+    }
+    err = queries.LinkReservationOrder(context.Background(), database.LinkReservationOrderParams{
+        OrderID:       sql.NullInt32{Int32: order.OrderID, Valid: true},
+        ReservationID: booking.ReservationID,
+    })
+    if err != nil {
+        log.Println("Error linking reservation to order:", err)
+    }
+}
+
+func writeReservationError(writer http.ResponseWriter, err error) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, reservation.ErrNoTable):
+        http.Error(writer, "No table is free for that party size and time", http.StatusConflict)
+    case errors.Is(err, errReservationClosed):
+        http.Error(writer, err.Error(), http.StatusConflict)
+    default:
+        log.Println("Error reserving table:", err)
+        http.Error(writer, "Failed to reserve table", http.StatusInternalServerError)
+    }
+    return false
+}
+
+// GET AVAILABILITY
+func reservationAvailabilityHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Reservation availability request received")
+
+    var party int
+    if _, err := fmt.Sscanf(req.URL.Query().Get("party_size"), "%d", &party); err != nil || party <= 0 {
+        http.Error(writer, "Invalid party_size", http.StatusBadRequest)
+        return
+    }
+    now := time.Now().In(storeLocation)
+    date := now
+    if value := req.URL.Query().Get("date"); value != "" {
+        var err error
+        date, err = time.ParseInLocation("2006-01-02", value, storeLocation)
+        if err != nil {
+            http.Error(writer, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    y, m, d := date.Date()
+    midnight := time.Date(y, m, d, 0, 0, 0, 0, storeLocation)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetDiningTables(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get tables", http.StatusInternalServerError)
+        return
+    }
+    end := midnight.AddDate(0, 0, 1)
+    held, err := queries.GetHeldReservations(context.Background(), database.GetHeldReservationsParams{
+        PeriodStart: midnight.UTC(),
+        PeriodEnd:   end.Add(reservationLength).UTC(),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get reservations", http.StatusInternalServerError)
+        return
+    }
+    calendars, err := loadCalendars(queries)
+    if err != nil {
+        log.Println("Error loading opening hours:", err)
+        http.Error(writer, "Failed to get opening hours", http.StatusInternalServerError)
+        return
+    }
+
+    var starts []time.Time
+    for start := midnight; start.Before(end); start = start.Add(schedule.SlotLength) {
+        if start.After(now) && !start.After(now.Add(reservationMaxAhead)) && calendars[orderTypeDineIn].IsOpen(start) {
+            starts = append(starts, start)
+        }
+    }
+    options := reservation.Options(diningTables(rows), tableBookings(held), party, starts, reservationLength)
+    if options == nil {
+        options = []reservation.Option{}
+    }
+
+    resp := struct {
+        Success  bool                 `json:"success"`
+        Date     string               `json:"date"`
+        TimeZone string               `json:"time_zone"`
+        Deposit  float64              `json:"deposit"`
+        Times    []reservation.Option `json:"times"`
+        Message  string               `json:"message"`
+    }{
+        Success:  true,
+        Date:     midnight.Format("2006-01-02"),
+        TimeZone: storeLocation.String(),
+        Deposit:  payment.RoundAmount(reservationDeposit * float64(party)),
+        Times:    options,
+        Message:  "Availability retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// CREATE RESERVATION
+func createReservationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Create reservation request received from user:", username)
+
+    type ReservationRequest struct {
+        PartySize int32     `json:"party_size"`
+        StartsAt  time.Time `json:"starts_at"`
+        Note      string    `json:"note"`
+    }
+    type ReservationResponse struct {
+        Success     bool                 `json:"success"`
+        Reservation database.Reservation `json:"reservation"`
+        Message     string               `json:"message"`
+    }
+
+    var reserveReq ReservationRequest
+    if err := json.NewDecoder(req.Body).Decode(&reserveReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    reserveReq.Note = strings.TrimSpace(reserveReq.Note)
+    if reserveReq.PartySize <= 0 {
+        http.Error(writer, "Invalid party_size", http.StatusBadRequest)
+        return
+    }
+    if len(reserveReq.Note) > 255 {
+        http.Error(writer, "Note is too long", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    start := reserveReq.StartsAt.In(storeLocation)
+    if err := checkReservationTime(queries, start); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    // With a deposit the table is held as pending until the wallet is charged
+    deposit := payment.RoundAmount(reservationDeposit * float64(reserveReq.PartySize))
+    status, depositStatus := reservation.StatusBooked, reservation.DepositNone
+    if deposit > 0 {
+        status, depositStatus = reservation.StatusPending, reservation.DepositPending
+    }
+    var booking database.Reservation
+    err = holdTable(db, int(reserveReq.PartySize), start, 0, func(qtx *database.Queries, table reservation.Table) error {
+        err := qtx.CreateReservation(context.Background(), database.CreateReservationParams{
+            UserID:        userID,
+            TableNumber:   table.Number,
+            PartySize:     reserveReq.PartySize,
+            StartsAt:      start.UTC(),
+            EndsAt:        start.Add(reservationLength).UTC(),
+            Status:        status,
+            Note:          reserveReq.Note,
+            Deposit:       deposit,
+            DepositStatus: depositStatus,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to create reservation: %w", err)
+        }
+        booking, err = qtx.GetLastInsertedReservation(context.Background())
+        return err
+    })
+    if !writeReservationError(writer, err) {
+        return
+    }
+
+    if deposit > 0 {
+        wallet := paymentProviders(queries)["wallet"]
+        intent, err := startPayment(queries, wallet, database.CreatePaymentIntentParams{
+            UserID:        userID,
+            ReservationID: sql.NullInt32{Int32: booking.ReservationID, Valid: true},
+            Purpose:       payment.PurposeDeposit,
+            Amount:        deposit,
+        })
+        if err == nil {
+            _, err = confirmPayment(db, queries, wallet, intent, payment.Method{})
+        } else {
+            releaseReservation(queries, booking.ReservationID)
+        }
+        if !writePaymentError(writer, err) {
+            return
+        }
+    }
+
+    booking, err = queries.GetReservation(context.Background(), booking.ReservationID)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve reservation", http.StatusInternalServerError)
+        return
+    }
+
+    resp := ReservationResponse{Success: true, Reservation: booking, Message: "Table reserved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// UPDATE RESERVATION
+func updateReservationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Update reservation request received from user:", username)
+
+    type UpdateReservationRequest struct {
+        ReservationID int32     `json:"reservation_id"`
+        PartySize     int32     `json:"party_size"`
+        StartsAt      time.Time `json:"starts_at"`
+        Note          string    `json:"note"`
+    }
+    type UpdateReservationResponse struct {
+        Success     bool                 `json:"success"`
+        Reservation database.Reservation `json:"reservation"`
+        Message     string               `json:"message"`
+    }
+
+    var updateReq UpdateReservationRequest
+    if err := json.NewDecoder(req.Body).Decode(&updateReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    updateReq.Note = strings.TrimSpace(updateReq.Note)
+    if updateReq.PartySize <= 0 {
+        http.Error(writer, "Invalid party_size", http.StatusBadRequest)
+        return
+    }
+    if len(updateReq.Note) > 255 {
+        http.Error(writer, "Note is too long", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    booking, err := queries.GetReservation(context.Background(), updateReq.ReservationID)
+    if err != nil || booking.UserID != userID {
+        http.Error(writer, "Invalid reservation ID", http.StatusBadRequest)
+        return
+    }
+
+    start := updateReq.StartsAt.In(storeLocation)
+    if err := checkReservationTime(queries, start); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    // The deposit stays as it was paid when the party size changes
+    err = holdTable(db, int(updateReq.PartySize), start, booking.ReservationID, func(qtx *database.Queries, table reservation.Table) error {
+        current, err := qtx.GetReservationForUpdate(context.Background(), booking.ReservationID)
+        if err != nil {
+            return fmt.Errorf("failed to get reservation: %w", err)
+        }
+        if current.Status != reservation.StatusBooked || !time.Now().Before(current.StartsAt) {
+            return errReservationClosed
+        }
+        return qtx.UpdateReservation(context.Background(), database.UpdateReservationParams{
+            TableNumber:   table.Number,
+            PartySize:     updateReq.PartySize,
+            StartsAt:      start.UTC(),
+            EndsAt:        start.Add(reservationLength).UTC(),
+            Note:          updateReq.Note,
+            ReservationID: booking.ReservationID,
+        })
+    })
+    if !writeReservationError(writer, err) {
+        return
+    }
+
+    booking, err = queries.GetReservation(context.Background(), booking.ReservationID)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve reservation", http.StatusInternalServerError)
+        return
+    }
+
+    resp := UpdateReservationResponse{Success: true, Reservation: booking, Message: "Reservation updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// CANCEL RESERVATION
+func cancelReservationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Cancel reservation request received from user:", username)
+
+    type CancelReservationRequest struct {
+        ReservationID int32 `json:"reservation_id"`
+    }
+    type CancelReservationResponse struct {
+        Success         bool   `json:"success"`
+        DepositRefunded bool   `json:"deposit_refunded"`
+        Message         string `json:"message"`
+    }
+
+    var cancelReq CancelReservationRequest
+    if err := json.NewDecoder(req.Body).Decode(&cancelReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // The restaurant can cancel any reservation and always refunds the deposit
+    booking, err := queries.GetReservation(context.Background(), cancelReq.ReservationID)
+    if err != nil {
+        http.Error(writer, "Invalid reservation ID", http.StatusBadRequest)
+        return
+    }
+    byRestaurant := false
+    if booking.UserID != userID {
+        if !rbac.Can(currentRoles(req), rbac.PermReservations) {
+            http.Error(writer, "Invalid reservation ID", http.StatusBadRequest)
+            return
+        }
+        byRestaurant = true
+    }
+
+    now := time.Now().UTC()
+    rows, err := queries.CancelReservation(context.Background(), database.CancelReservationParams{
+        CancelledAt:   sql.NullTime{Time: now, Valid: true},
+        ReservationID: booking.ReservationID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to cancel reservation", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, errReservationClosed.Error(), http.StatusConflict)
+        return
+    }
+
+    refund := byRestaurant || reservation.Refundable(booking.StartsAt, now, reservationNotice)
+    if err := settleDeposit(db, queries, booking, refund); err != nil {
+        log.Println("Error settling deposit:", err)
+        http.Error(writer, "Reservation cancelled but the deposit could not be refunded", http.StatusInternalServerError)
+        return
+    }
+
+    resp := CancelReservationResponse{
+        Success:         true,
+        DepositRefunded: refund && booking.DepositStatus == reservation.DepositPaid,
+        Message:         "Reservation cancelled successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET MY RESERVATIONS
+func getReservationsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get reservations request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    reservations, err := queries.GetReservationsByUser(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Failed to get reservations", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success      bool                   `json:"success"`
+        Reservations []database.Reservation `json:"reservations"`
+        Message      string                 `json:"message"`
+    }{
+        Success:      true,
+        Reservations: reservations,
+        Message:      "Reservations retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// SAVE TABLE
+func saveDiningTableHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Save table request received from user:", username)
+
+    type TableRequest struct {
+        TableNumber int32 `json:"table_number"`
+        Seats       int32 `json:"seats"`
+        IsActive    *bool `json:"is_active"`
+    }
+    type TableResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var tableReq TableRequest
+    if err := json.NewDecoder(req.Body).Decode(&tableReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if tableReq.TableNumber <= 0 || tableReq.Seats <= 0 {
+        http.Error(writer, "Tables need a table_number and seats", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // Tables are taken out of service rather than deleted so past
+    // reservations keep them
+    err = queries.SaveDiningTable(context.Background(), database.SaveDiningTableParams{
+        TableNumber: tableReq.TableNumber,
+        Seats:       tableReq.Seats,
+        IsActive:    tableReq.IsActive == nil || *tableReq.IsActive,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to save table", http.StatusInternalServerError)
+        return
+    }
+
+    resp := TableResponse{Success: true, Message: "Table saved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET TABLES
+func getDiningTablesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get tables request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tables, err := queries.GetDiningTables(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get tables", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool                   `json:"success"`
+        Tables  []database.DiningTable `json:"tables"`
+        Message string                 `json:"message"`
+    }{
+        Success: true,
+        Tables:  tables,
+        Message: "Tables retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// ADMIN: GET RESERVATIONS
+func getAllReservationsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get all reservations request received from user:", username)
+
+    date := time.Now().In(storeLocation)
+    if value := req.URL.Query().Get("date"); value != "" {
+        var err error
+        date, err = time.ParseInLocation("2006-01-02", value, storeLocation)
+        if err != nil {
+            http.Error(writer, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    y, m, d := date.Date()
+    midnight := time.Date(y, m, d, 0, 0, 0, 0, storeLocation)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    reservations, err := queries.GetReservationsBetween(context.Background(), database.GetReservationsBetweenParams{
+        PeriodStart: midnight.UTC(),
+        PeriodEnd:   midnight.AddDate(0, 0, 1).UTC(),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get reservations", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success      bool                   `json:"success"`
+        Date         string                 `json:"date"`
+        Reservations []database.Reservation `json:"reservations"`
+        Message      string                 `json:"message"`
+    }{
+        Success:      true,
+        Date:         midnight.Format("2006-01-02"),
+        Reservations: reservations,
+        Message:      "Reservations retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// SEAT RESERVATION
+func seatReservationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Seat reservation request received from user:", username)
+
+    type SeatRequest struct {
+        ReservationID int32 `json:"reservation_id"`
+        OrderID       int32 `json:"order_id"`
+    }
+    type SeatResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var seatReq SeatRequest
+    if err := json.NewDecoder(req.Body).Decode(&seatReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    booking, err := queries.GetReservation(context.Background(), seatReq.ReservationID)
+    if err != nil {
+        http.Error(writer, "Invalid reservation ID", http.StatusBadRequest)
+        return
+    }
+    if err := reservation.Transition(booking.Status, reservation.StatusSeated); err != nil {
+        http.Error(writer, "Only booked reservations can be seated", http.StatusConflict)
+        return
+    }
+
+    // Without an order_id the party's first dine-in order at the table is
+    // linked when it is placed
+    var orderID sql.NullInt32
+    if seatReq.OrderID != 0 {
+        order, err := queries.GetOrderById(context.Background(), seatReq.OrderID)
+        if err != nil || order.Deleted || order.OrderType != orderTypeDineIn {
+            http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+            return
+        }
+        orderID = sql.NullInt32{Int32: order.OrderID, Valid: true}
+    }
+
+    rows, err := queries.SeatReservation(context.Background(), database.SeatReservationParams{
+        SeatedAt:      sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        OrderID:       orderID,
+        ReservationID: booking.ReservationID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to seat reservation", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Only booked reservations can be seated", http.StatusConflict)
+        return
+    }
+
+    // The deposit only guards against no-shows, so it goes back once the
+    // party arrives
+    if err := settleDeposit(db, queries, booking, true); err != nil {
+        log.Println("Error refunding deposit:", err)
+    }
+
+    resp := SeatResponse{Success: true, Message: "Reservation seated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// MARK NO-SHOW
+func noShowReservationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("No-show request received from user:", username)
+
+    type NoShowRequest struct {
+        ReservationID int32 `json:"reservation_id"`
+    }
+    type NoShowResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var noShowReq NoShowRequest
+    if err := json.NewDecoder(req.Body).Decode(&noShowReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    booking, err := queries.GetReservation(context.Background(), noShowReq.ReservationID)
+    if err != nil {
+        http.Error(writer, "Invalid reservation ID", http.StatusBadRequest)
+        return
+    }
+    if err := reservation.Transition(booking.Status, reservation.StatusNoShow); err != nil {
+        http.Error(writer, "Only booked reservations can be marked as no-shows", http.StatusConflict)
+        return
+    }
+    if time.Now().Before(booking.StartsAt) {
+        http.Error(writer, "The reservation hasn't started yet", http.StatusConflict)
+        return
+    }
+
+    rows, err := queries.TransitionReservation(context.Background(), database.TransitionReservationParams{
+        NewStatus:     reservation.StatusNoShow,
+        ReservationID: booking.ReservationID,
+        OldStatus:     booking.Status,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update reservation", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Only booked reservations can be marked as no-shows", http.StatusConflict)
+        return
+    }
+    if err := settleDeposit(db, queries, booking, false); err != nil {
+        log.Println("Error keeping deposit:", err)
+    }
+
+    resp := NoShowResponse{Success: true, Message: "Reservation marked as a no-show"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// waitlistEntry is a waiting party with their place in the queue
+type waitlistEntry struct {
+    database.Waitlist
+    Position    int  `json:"position"`
+    WaitMinutes *int `json:"wait_minutes"`
+}
+
+// estimateWaits works out each waiting party's place and wait. A table is
+// taken while a dine-in order at it is unpaid, a party was seated at it
+// recently without ordering yet, a waiting party was called to it, or a
+// reservation holds it within the next turnover.
+func estimateWaits(queries *database.Queries, entries []database.Waitlist) ([]waitlistEntry, error) {
+    rows, err := queries.GetDiningTables(context.Background())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get tables: %w", err)
+    }
+    now := time.Now().UTC()
+    occupied, err := queries.GetOccupiedTables(context.Background(), sql.NullTime{Time: now.Add(-reservationLength), Valid: true})
+    if err != nil {
+        return nil, fmt.Errorf("failed to get occupied tables: %w", err)
+    }
+    held, err := queries.GetHeldReservations(context.Background(), database.GetHeldReservationsParams{
+        PeriodStart: now,
+        PeriodEnd:   now.Add(tableTurnover),
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to get reservations: %w", err)
+    }
+
+    taken := make(map[int32]bool)
+    for _, table := range occupied {
+        if table.Valid {
+            taken[table.Int32] = true
+        }
+    }
+    for _, booking := range held {
+        taken[booking.TableNumber] = true
+    }
+    for _, entry := range entries {
+        if entry.Status == reservation.WaitNotified && entry.TableNumber.Valid {
+            taken[entry.TableNumber.Int32] = true
+        }
+    }
+
+    tables := diningTables(rows)
+    result := make([]waitlistEntry, len(entries))
+    var waiting []database.Waitlist
+    for i, entry := range entries {
+        result[i] = waitlistEntry{Waitlist: entry}
+        if entry.Status != reservation.WaitWaiting {
+            zero := 0
+            result[i].WaitMinutes = &zero
+            continue
+        }
+
+        // Parties ahead only hold this one up if they could use its tables
+        largest, suitable, free := 0, 0, 0
+        for _, table := range tables {
+            if table.Seats >= int(entry.PartySize) {
+                suitable++
+                if !taken[table.Number] {
+                    free++
+                }
+                if table.Seats > largest {
+                    largest = table.Seats
+                }
+            }
+        }
+        ahead := 0
+        for _, earlier := range waiting {
+            if int(earlier.PartySize) <= largest {
+                ahead++
+            }
+        }
+        waiting = append(waiting, entry)
+        result[i].Position = len(waiting)
+        if wait, ok := reservation.Wait(ahead, free, suitable, tableTurnover); ok {
+            minutes := int(wait / time.Minute)
+            result[i].WaitMinutes = &minutes
+        }
+    }
+    return result, nil
+}
+
+// JOIN WAITLIST
+func joinWaitlistHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Join waitlist request received from user:", username)
+
+    type JoinWaitlistRequest struct {
+        PartySize int32 `json:"party_size"`
+    }
+    type JoinWaitlistResponse struct {
+        Success bool          `json:"success"`
+        Entry   waitlistEntry `json:"entry"`
+        Message string        `json:"message"`
+    }
+
+    var joinReq JoinWaitlistRequest
+    if err := json.NewDecoder(req.Body).Decode(&joinReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if joinReq.PartySize <= 0 {
+        http.Error(writer, "Invalid party_size", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account := currentAccount(req)
+    if _, err := queries.GetActiveWaitlistEntryByUser(context.Background(), sql.NullInt32{Int32: userID, Valid: true}); err == nil {
+        http.Error(writer, "You are already on the waitlist", http.StatusConflict)
+        return
+    }
+
+    err = queries.CreateWaitlistEntry(context.Background(), database.CreateWaitlistEntryParams{
+        UserID:    sql.NullInt32{Int32: userID, Valid: true},
+        Name:      account.Username,
+        Phone:     strconv.FormatInt(account.UserPhoneNumber, 10),
+        PartySize: joinReq.PartySize,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to join waitlist", http.StatusInternalServerError)
+        return
+    }
+
+    entry, err := findWaitlistEntry(queries, sql.NullInt32{Int32: userID, Valid: true}, 0)
+    if err != nil {
+        log.Println("Error estimating wait:", err)
+        http.Error(writer, "Failed to estimate wait", http.StatusInternalServerError)
+        return
+    }
+
+    resp := JoinWaitlistResponse{Success: true, Entry: entry, Message: "Joined the waitlist successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// findWaitlistEntry looks up one active entry, by user or by ID, with its
+// estimated wait
+func findWaitlistEntry(queries *database.Queries, userID sql.NullInt32, entryID int32) (waitlistEntry, error) {
+    entries, err := queries.GetWaitlist(context.Background())
+    if err != nil {
+        return waitlistEntry{}, fmt.Errorf("failed to get waitlist: %w", err)
+    }
+    waits, err := estimateWaits(queries, entries)
+    if err != nil {
+        return waitlistEntry{}, err
+    }
+    for _, entry := range waits {
+        if (userID.Valid && entry.UserID == userID) || (!userID.Valid && entry.EntryID == entryID) {
+            return entry, nil
+        }
+    }
+    return waitlistEntry{}, sql.ErrNoRows
+}
+
+// GET WAITLIST STATUS
+func getWaitlistStatusHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get waitlist status request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    entry, err := findWaitlistEntry(queries, sql.NullInt32{Int32: userID, Valid: true}, 0)
+    if errors.Is(err, sql.ErrNoRows) {
+        http.Error(writer, "You are not on the waitlist", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Println("Error estimating wait:", err)
+        http.Error(writer, "Failed to estimate wait", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool          `json:"success"`
+        Entry   waitlistEntry `json:"entry"`
+        Message string        `json:"message"`
+    }{
+        Success: true,
+        Entry:   entry,
+        Message: "Waitlist status retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// LEAVE WAITLIST
+func leaveWaitlistHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Leave waitlist request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    entry, err := queries.GetActiveWaitlistEntryByUser(context.Background(), sql.NullInt32{Int32: userID, Valid: true})
+    if err != nil {
+        http.Error(writer, "You are not on the waitlist", http.StatusNotFound)
+        return
+    }
+    if _, err := queries.LeaveWaitlist(context.Background(), entry.EntryID); err != nil {
+        http.Error(writer, "Failed to leave waitlist", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }{
+        Success: true,
+        Message: "Left the waitlist successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// ADMIN: ADD WALK-IN
+func addWalkInHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Add walk-in request received from user:", username)
+
+    type WalkInRequest struct {
+        Name      string `json:"name"`
+        Phone     string `json:"phone"`
+        PartySize int32  `json:"party_size"`
+    }
+    type WalkInResponse struct {
+        Success bool          `json:"success"`
+        Entry   waitlistEntry `json:"entry"`
+        Message string        `json:"message"`
+    }
+
+    var walkInReq WalkInRequest
+    if err := json.NewDecoder(req.Body).Decode(&walkInReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    walkInReq.Name = strings.TrimSpace(walkInReq.Name)
+    walkInReq.Phone = strings.TrimSpace(walkInReq.Phone)
+    if walkInReq.Name == "" || len(walkInReq.Name) > 100 || walkInReq.PartySize <= 0 {
+        http.Error(writer, "Walk-ins need a name and party_size", http.StatusBadRequest)
+        return
+    }
+    if walkInReq.Phone != "" && !validPhone(walkInReq.Phone) {
+        http.Error(writer, "Invalid phone", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.CreateWaitlistEntry(context.Background(), database.CreateWaitlistEntryParams{
+        Name:      walkInReq.Name,
+        Phone:     walkInReq.Phone,
+        PartySize: walkInReq.PartySize,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to add walk-in", http.StatusInternalServerError)
+        return
+    }
+    created, err := queries.GetLastInsertedWaitlistEntry(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to retrieve walk-in", http.StatusInternalServerError)
+        return
+    }
+    entry, err := findWaitlistEntry(queries, sql.NullInt32{}, created.EntryID)
+    if err != nil {
+        log.Println("Error estimating wait:", err)
+        http.Error(writer, "Failed to estimate wait", http.StatusInternalServerError)
+        return
+    }
+
+    resp := WalkInResponse{Success: true, Entry: entry, Message: "Walk-in added successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: GET WAITLIST
+func getWaitlistHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get waitlist request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    entries, err := queries.GetWaitlist(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get waitlist", http.StatusInternalServerError)
+        return
+    }
+    waits, err := estimateWaits(queries, entries)
+    if err != nil {
+        log.Println("Error estimating waits:", err)
+        http.Error(writer, "Failed to estimate waits", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool            `json:"success"`
+        Entries []waitlistEntry `json:"entries"`
+        Message string          `json:"message"`
+    }{
+        Success: true,
+        Entries: waits,
+        Message: "Waitlist retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// ADMIN: UPDATE WAITLIST ENTRY
+func updateWaitlistHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Update waitlist request received from user:", username)
+
+    type UpdateWaitlistRequest struct {
+        EntryID     int32  `json:"entry_id"`
+        Status      string `json:"status"`
+        TableNumber int32  `json:"table_number"`
+    }
+    type UpdateWaitlistResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var updateReq UpdateWaitlistRequest
+    if err := json.NewDecoder(req.Body).Decode(&updateReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if updateReq.Status == reservation.WaitNotified && updateReq.TableNumber <= 0 {
+        http.Error(writer, "Calling a party needs a table_number", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    entry, err := queries.GetWaitlistEntry(context.Background(), updateReq.EntryID)
+    if err != nil {
+        http.Error(writer, "Invalid entry ID", http.StatusBadRequest)
+        return
+    }
+
+    now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+    table := sql.NullInt32{Int32: updateReq.TableNumber, Valid: updateReq.TableNumber > 0}
+    var rows int64
+    switch updateReq.Status {
+    case reservation.WaitNotified:
+        rows, err = queries.NotifyWaitlistEntry(context.Background(), database.NotifyWaitlistEntryParams{
+            TableNumber: table,
+            NotifiedAt:  now,
+            EntryID:     entry.EntryID,
+        })
+    case reservation.WaitSeated:
+        rows, err = queries.SeatWaitlistEntry(context.Background(), database.SeatWaitlistEntryParams{
+            TableNumber: table,
+            SeatedAt:    now,
+            EntryID:     entry.EntryID,
+        })
+    case reservation.WaitLeft:
+        rows, err = queries.LeaveWaitlist(context.Background(), entry.EntryID)
+    default:
+        http.Error(writer, "Invalid status, expected notified, seated or left", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        http.Error(writer, "Failed to update waitlist", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "This party is no longer waiting", http.StatusConflict)
+        return
+    }
+
+    // Walk-ins without an account are called by the host
+    if updateReq.Status == reservation.WaitNotified && entry.UserID.Valid {
+        message := fmt.Sprintf("Your table is ready, please come to the host stand for table %d", updateReq.TableNumber)
+        if err := notifyUser(queries, entry.UserID.Int32, sql.NullInt32{}, notificationTableReady, message); err != nil {
+            log.Println("Error notifying waiting party:", err)
+        }
+    }
+
+    resp := UpdateWaitlistResponse{Success: true, Message: "Waitlist updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,286 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "log"
+    "strconv"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+)
+
+type roleChange struct {
+    UserID int32  `json:"user_id"`
+    Role   string `json:"role"`
+}
+
+// checkRoleChange reports why the caller may not grant or revoke change.Role,
+// along with the status to answer with
+func checkRoleChange(queries *database.Queries, userID int32, change roleChange) (string, int) {
+    if !rbac.Valid(change.Role) {
+        return "Invalid role", http.StatusBadRequest
+    }
+    // Riders need a rider profile as well as the role
+    if change.Role == rbac.RoleRider {
+        return "Riders are managed through /admin/riders", http.StatusBadRequest
+    }
+    if change.Role == rbac.RoleCustomer {
+        return "Every account is a customer", http.StatusBadRequest
+    }
+    if _, err := queries.GetAccountByID(context.Background(), change.UserID); err != nil {
+        return "Invalid user ID", http.StatusBadRequest
+    }
+    roles, err := queries.GetAccountRoles(context.Background(), userID)
+    if err != nil {
+        return "Failed to get roles", http.StatusInternalServerError
+    }
+    if !rbac.CanAssign(roles, change.Role) {
+        return "Forbidden", http.StatusForbidden
+    }
+    return "", 0
+}
+
+// GET STAFF
+func getStaffHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get staff request received from user:", username)
+
+    type StaffMember struct {
+        UserID   int32    `json:"user_id"`
+        Username string   `json:"username"`
+        Roles    []string `json:"roles"`
+    }
+    type GetStaffResponse struct {
+        Success bool          `json:"success"`
+        Staff   []StaffMember `json:"staff"`
+        Message string        `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetStaffAccounts(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get staff", http.StatusInternalServerError)
+        return
+    }
+
+    // Rows come sorted by username, one per role
+    staff := []StaffMember{}
+    for _, row := range rows {
+        if n := len(staff); n > 0 && staff[n-1].UserID == row.UserID {
+            staff[n-1].Roles = append(staff[n-1].Roles, row.Role)
+            continue
+        }
+        staff = append(staff, StaffMember{UserID: row.UserID, Username: row.Username, Roles: []string{row.Role}})
+    }
+
+    resp := GetStaffResponse{Success: true, Staff: staff, Message: "Staff retrieved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET USER ROLES
+func getUserRolesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get user roles request received from user:", username)
+
+    type GetUserRolesResponse struct {
+        Success     bool     `json:"success"`
+        UserID      int32    `json:"user_id"`
+        Roles       []string `json:"roles"`
+        Permissions []string `json:"permissions"`
+        Message     string   `json:"message"`
+    }
+
+    targetID, err := strconv.Atoi(req.URL.Query().Get("user_id"))
+    if err != nil {
+        http.Error(writer, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if _, err := queries.GetAccountByID(context.Background(), int32(targetID)); err != nil {
+        http.Error(writer, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+    roles, err := queries.GetAccountRoles(context.Background(), int32(targetID))
+    if err != nil {
+        http.Error(writer, "Failed to get roles", http.StatusInternalServerError)
+        return
+    }
+    if roles == nil {
+        roles = []string{}
+    }
+    permissions := rbac.Permissions(roles)
+    if permissions == nil {
+        permissions = []string{}
+    }
+
+    resp := GetUserRolesResponse{
+        Success:     true,
+        UserID:      int32(targetID),
+        Roles:       roles,
+        Permissions: permissions,
+        Message:     "Roles retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GRANT ROLE
+func grantRoleHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Grant role request received from user:", username)
+
+    type GrantRoleResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var grantReq roleChange
+    if err := json.NewDecoder(req.Body).Decode(&grantReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if message, status := checkRoleChange(queries, userID, grantReq); status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+
+    err = queries.GrantRole(context.Background(), database.GrantRoleParams{
+        UserID:    grantReq.UserID,
+        Role:      grantReq.Role,
+        GrantedBy: sql.NullInt32{Int32: userID, Valid: true},
+        GrantedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to grant role", http.StatusInternalServerError)
+        return
+    }
+
+    resp := GrantRoleResponse{Success: true, Message: "Role granted successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// REVOKE ROLE
+func revokeRoleHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Revoke role request received from user:", username)
+
+    type RevokeRoleResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var revokeReq roleChange
+    if err := json.NewDecoder(req.Body).Decode(&revokeReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if message, status := checkRoleChange(queries, userID, revokeReq); status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+
+    // Someone must always be left to hand out roles
+    if revokeReq.Role == rbac.RoleOwner {
+        owners, err := queries.CountRole(context.Background(), rbac.RoleOwner)
+        if err != nil {
+            http.Error(writer, "Failed to count owners", http.StatusInternalServerError)
+            return
+        }
+        if owners <= 1 {
+            http.Error(writer, "Cannot remove the last owner", http.StatusConflict)
+            return
+        }
+    }
+
+    rows, err := queries.RevokeRole(context.Background(), database.RevokeRoleParams{
+        UserID: revokeReq.UserID,
+        Role:   revokeReq.Role,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to revoke role", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "User does not have this role", http.StatusBadRequest)
+        return
+    }
+
+    resp := RevokeRoleResponse{Success: true, Message: "Role revoked successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
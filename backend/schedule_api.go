@@ -0,0 +1,405 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/schedule"
+)
+
+// storeLocation is the time zone slots and opening times are written in
+var storeLocation = loadStoreLocation()
+
+var scheduleRules = loadScheduleRules()
+
+var (
+    scheduleBuffer = envDuration("SCHEDULE_BUFFER", 10*time.Minute)
+    reminderBefore = envDuration("REMINDER_BEFORE", 30*time.Minute)
+    deliveryLead   = envDuration("DELIVERY_LEAD", 20*time.Minute)
+)
+
+var errSlotFull = errors.New("This slot is fully booked, please pick another time")
+
+const notificationOrderReminder = "order_reminder"
+
+func loadStoreLocation() *time.Location {
+    name := envString("STORE_TIMEZONE", "Asia/Shanghai")
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        log.Printf("Invalid STORE_TIMEZONE %q, using UTC", name)
+        return time.UTC
+    }
+    return loc
+}
+
+func loadScheduleRules() schedule.Rules {
+    hours := envString("SLOT_HOURS", "10:00-22:00")
+    open, close, err := schedule.ParseHours(hours)
+    if err != nil {
+        log.Printf("Invalid SLOT_HOURS %q, using 10:00-22:00", hours)
+        open, close = 10*time.Hour, 22*time.Hour
+    }
+    return schedule.Rules{
+        MinLead:  envDuration("SCHEDULE_MIN_LEAD", 30*time.Minute),
+        MaxAhead: envDuration("SCHEDULE_MAX_AHEAD", 7*24*time.Hour),
+        Capacity: envInt("SLOT_CAPACITY", 10),
+        Open:     open,
+        Close:    close,
+    }
+}
+
+// cookTime is how long the slowest food in an order takes
+func cookTime(foods []database.Food) time.Duration {
+    longest := int32(0)
+    for _, food := range foods {
+        if food.TimeNeeded > longest {
+            longest = food.TimeNeeded
+        }
+    }
+    return time.Duration(longest) * time.Minute
+}
+
+// bookSlot takes one place in the slot starting at slot
+func bookSlot(queries *database.Queries, slot time.Time) error {
+    err := queries.CreateOrderSlot(context.Background(), slot.UTC())
+    if err != nil {
+        return fmt.Errorf("failed to create slot: %w", err)
+    }
+    rows, err := queries.BookOrderSlot(context.Background(), database.BookOrderSlotParams{
+        SlotStart: slot.UTC(),
+        Capacity:  int32(scheduleRules.Capacity),
+    })
+    if err != nil {
+        return fmt.Errorf("failed to book slot: %w", err)
+    }
+    if rows == 0 {
+        return errSlotFull
+    }
+    return nil
+}
+
+// freeSlot gives back the place a scheduled order took
+func freeSlot(queries *database.Queries, scheduledFor sql.NullTime) {
+    if !scheduledFor.Valid {
+        return
+    }
+    if err := queries.FreeOrderSlot(context.Background(), scheduledFor.Time.UTC()); err != nil {
+        log.Println("Error freeing slot:", err)
+    }
+}
+
+func notifyUser(queries *database.Queries, userID int32, orderID sql.NullInt32, kind, message string) error {
+    err := queries.CreateNotification(context.Background(), database.CreateNotificationParams{
+        UserID:  userID,
+        OrderID: orderID,
+        Kind:    kind,
+        Message: message,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create notification: %w", err)
+    }
+    return nil
+}
+
+// orderFoods looks up the foods on an order for its kitchen tickets
+func orderFoods(queries *database.Queries, orderID int32) ([]database.Food, error) {
+    items, err := queries.GetOrderedItems(context.Background(), orderID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get ordered items: %w", err)
+    }
+    foods := make([]database.Food, 0, len(items))
+    for _, item := range items {
+        food, err := queries.GetFoodById(context.Background(), item.FoodID)
+        if err != nil {
+            // The dish left the menu since; send it to the main kitchen
+            food = database.Food{FoodID: item.FoodID, Station: "kitchen"}
+        }
+        foods = append(foods, food)
+    }
+    return foods, nil
+}
+
+// processScheduledOrders sends scheduled orders to the kitchen once they
+// need to be started, and reminds customers shortly before their slot
+func processScheduledOrders() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Scheduler: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    now := time.Now().UTC()
+
+    due, err := queries.GetOrdersToRelease(context.Background(), sql.NullTime{Time: now, Valid: true})
+    if err != nil {
+        log.Println("Scheduler: failed to get orders to release:", err)
+    }
+    for _, order := range due {
+        rows, err := queries.MarkOrderReleased(context.Background(), database.MarkOrderReleasedParams{
+            ReleasedAt: sql.NullTime{Time: now, Valid: true},
+            OrderID:    order.OrderID,
+        })
+        if err != nil || rows == 0 {
+            continue
+        }
+        foods, err := orderFoods(queries, order.OrderID)
+        if err == nil {
+            err = queueKitchenTickets(queries, order, foods)
+        }
+        if err != nil {
+            log.Printf("Scheduler: order %d released without tickets: %v", order.OrderID, err)
+        }
+    }
+
+    upcoming, err := queries.GetOrdersToRemind(context.Background(), database.GetOrdersToRemindParams{
+        Now:          sql.NullTime{Time: now, Valid: true},
+        RemindBefore: sql.NullTime{Time: now.Add(reminderBefore), Valid: true},
+    })
+    if err != nil {
+        log.Println("Scheduler: failed to get orders to remind:", err)
+    }
+    for _, order := range upcoming {
+        rows, err := queries.MarkOrderReminded(context.Background(), database.MarkOrderRemindedParams{
+            RemindedAt: sql.NullTime{Time: now, Valid: true},
+            OrderID:    order.OrderID,
+        })
+        if err != nil || rows == 0 {
+            continue
+        }
+        due := order.ScheduledFor.Time.In(storeLocation).Format("15:04")
+        message := fmt.Sprintf("Your order #%d will be ready for pickup at %s", order.OrderID, due)
+        if order.IsRanged {
+            message = fmt.Sprintf("Your order #%d will be delivered at around %s", order.OrderID, due)
+        }
+        err = notifyUser(queries, order.UserID, sql.NullInt32{Int32: order.OrderID, Valid: true}, notificationOrderReminder, message)
+        if err != nil {
+            log.Printf("Scheduler: order %d: %v", order.OrderID, err)
+        }
+    }
+}
+
+// GET SLOTS
+func getSlotsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get slots request received from user:", username)
+
+    now := time.Now().In(storeLocation)
+    date := now
+    if value := req.URL.Query().Get("date"); value != "" {
+        var err error
+        date, err = time.ParseInLocation("2006-01-02", value, storeLocation)
+        if err != nil {
+            http.Error(writer, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    y, m, d := date.Date()
+    midnight := time.Date(y, m, d, 0, 0, 0, 0, storeLocation)
+    orderType := req.URL.Query().Get("order_type")
+    if orderType == "" {
+        orderType = orderTypeTakeaway
+    }
+    if orderType != orderTypeTakeaway && orderType != orderTypeDelivery {
+        http.Error(writer, "Invalid order_type, expected takeaway or delivery", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetOrderSlots(context.Background(), database.GetOrderSlotsParams{
+        PeriodStart: midnight.UTC(),
+        PeriodEnd:   midnight.AddDate(0, 0, 1).UTC(),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get slots", http.StatusInternalServerError)
+        return
+    }
+    booked := make(map[int64]int, len(rows))
+    for _, row := range rows {
+        booked[row.SlotStart.Unix()] = int(row.Booked)
+    }
+
+    calendars, err := loadCalendars(queries)
+    if err != nil {
+        log.Println("Error loading opening hours:", err)
+        http.Error(writer, "Failed to get opening hours", http.StatusInternalServerError)
+        return
+    }
+    slots := []schedule.Slot{}
+    for _, slot := range scheduleRules.Day(midnight, now, booked) {
+        if calendars[orderType].IsOpen(slot.Start) {
+            slots = append(slots, slot)
+        }
+    }
+
+    resp := struct {
+        Success  bool            `json:"success"`
+        Date     string          `json:"date"`
+        TimeZone string          `json:"time_zone"`
+        Slots    []schedule.Slot `json:"slots"`
+        Message  string          `json:"message"`
+    }{
+        Success:  true,
+        Date:     midnight.Format("2006-01-02"),
+        TimeZone: storeLocation.String(),
+        Slots:    slots,
+        Message:  "Slots retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET SCHEDULED ORDERS
+func getScheduledOrdersHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get scheduled orders request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    orders, err := queries.GetScheduledOrders(context.Background())
+    if err != nil {
+        http.Error(writer, "Failed to get scheduled orders", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool             `json:"success"`
+        Orders  []database.Order `json:"orders"`
+        Message string           `json:"message"`
+    }{
+        Success: true,
+        Orders:  orders,
+        Message: "Scheduled orders retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// GET NOTIFICATIONS
+func getNotificationsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get notifications request received from user:", username)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    notifications, err := queries.GetNotificationsByUser(context.Background(), database.GetNotificationsByUserParams{
+        UserID: userID,
+        Limit:  50,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get notifications", http.StatusInternalServerError)
+        return
+    }
+    unread := 0
+    for _, notification := range notifications {
+        if !notification.ReadAt.Valid {
+            unread++
+        }
+    }
+
+    resp := struct {
+        Success       bool                    `json:"success"`
+        Unread        int                     `json:"unread"`
+        Notifications []database.Notification `json:"notifications"`
+        Message       string                  `json:"message"`
+    }{
+        Success:       true,
+        Unread:        unread,
+        Notifications: notifications,
+        Message:       "Notifications retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// READ NOTIFICATIONS
+func readNotificationsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Read notifications request received from user:", username)
+
+    type ReadNotificationsResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    err = queries.MarkNotificationsRead(context.Background(), database.MarkNotificationsReadParams{
+        ReadAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID: userID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to update notifications", http.StatusInternalServerError)
+        return
+    }
+
+    resp := ReadNotificationsResponse{Success: true, Message: "Notifications marked as read"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,415 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "log"
+    "net"
+    "os"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+)
+
+// Access tokens are short-lived; refresh tokens keep the session going and
+// are replaced every time they are used
+var (
+    accessTokenTTL  = envDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+    refreshTokenTTL = envDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+)
+
+// tokenKeys sign and verify access tokens. JWT_KEYS lists them as
+// "kid:alg:value" entries and JWT_SIGNING_KID picks the one that signs; the
+// others only verify, which lets a new key be rolled out without logging
+// everyone out.
+var tokenKeys = loadTokenKeys()
+
+func loadTokenKeys() *auth.KeySet {
+    issuer := envString("JWT_ISSUER", "takeaway-dine_in-system")
+    spec := os.Getenv("JWT_KEYS")
+    if spec == "" {
+        log.Println("JWT_KEYS is not set, signing access tokens with a temporary key")
+        key, err := auth.NewRandomHMACKey("temporary")
+        if err != nil {
+            log.Fatal("Failed to create signing key: ", err)
+        }
+        keys, err := auth.NewKeySet(issuer, key)
+        if err != nil {
+            log.Fatal("Failed to create signing key: ", err)
+        }
+        return keys
+    }
+    keys, err := auth.LoadKeySet(issuer, spec, os.Getenv("JWT_SIGNING_KID"))
+    if err != nil {
+        log.Fatal("Invalid JWT_KEYS: ", err)
+    }
+    log.Println("Signing access tokens with key", keys.SigningKeyID())
+    return keys
+}
+
+// clientIP is the address the request came from
+func clientIP(req *http.Request) string {
+    host, _, err := net.SplitHostPort(req.RemoteAddr)
+    if err != nil {
+        return req.RemoteAddr
+    }
+    return host
+}
+
+// truncate cuts s to at most n bytes so it fits its column
+func truncate(s string, n int) string {
+    if len(s) > n {
+        return s[:n]
+    }
+    return s
+}
+
+type sessionTokens struct {
+    Token        string `json:"token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int    `json:"expires_in"`
+    SessionID    int32  `json:"session_id"`
+}
+
+// startSession opens a login session for account and issues its first
+// tokens. expiresIn shortens the access token when it is positive.
+func startSession(queries *database.Queries, req *http.Request, account database.Account, expiresIn time.Duration) (sessionTokens, error) {
+    refresh, err := auth.MakeToken()
+    if err != nil {
+        return sessionTokens{}, err
+    }
+    now := time.Now().UTC()
+    err = queries.CreateSession(context.Background(), database.CreateSessionParams{
+        UserID:      account.ID,
+        RefreshHash: auth.HashToken(refresh),
+        UserAgent:   truncate(req.UserAgent(), 255),
+        Ip:          truncate(clientIP(req), 64),
+        CreatedAt:   now,
+        LastUsedAt:  now,
+        ExpiresAt:   now.Add(refreshTokenTTL),
+    })
+    if err != nil {
+        return sessionTokens{}, err
+    }
+    session, err := queries.GetSessionByRefreshHash(context.Background(), auth.HashToken(refresh))
+    if err != nil {
+        return sessionTokens{}, err
+    }
+    return issueAccessToken(account, session.SessionID, refresh, expiresIn)
+}
+
+func issueAccessToken(account database.Account, sessionID int32, refresh string, expiresIn time.Duration) (sessionTokens, error) {
+    if expiresIn <= 0 || expiresIn > accessTokenTTL {
+        expiresIn = accessTokenTTL
+    }
+    token, err := tokenKeys.MakeJWT(account.ID, account.Username, sessionID, expiresIn)
+    if err != nil {
+        return sessionTokens{}, err
+    }
+    return sessionTokens{
+        Token:        token,
+        RefreshToken: refresh,
+        ExpiresIn:    int(expiresIn / time.Second),
+        SessionID:    sessionID,
+    }, nil
+}
+
+// revokeSessions signs userID out everywhere
+func revokeSessions(queries *database.Queries, userID int32) error {
+    return queries.RevokeUserSessions(context.Background(), database.RevokeUserSessionsParams{
+        RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        UserID:    userID,
+    })
+}
+
+// REFRESH TOKEN
+func refreshTokenHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    type RefreshRequest struct {
+        RefreshToken string `json:"refresh_token"`
+    }
+    type RefreshResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+        sessionTokens
+    }
+
+    var refreshReq RefreshRequest
+    if err := json.NewDecoder(req.Body).Decode(&refreshReq); err != nil || refreshReq.RefreshToken == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+    hash := auth.HashToken(refreshReq.RefreshToken)
+
+    // A refresh token that was already swapped for a new one is being
+    // replayed, so whoever holds the session can no longer be trusted
+    if stolen, err := queries.GetSessionByPreviousHash(context.Background(), sql.NullString{String: hash, Valid: true}); err == nil {
+        log.Printf("Refresh token reused for session %d of user %d, revoking it", stolen.SessionID, stolen.UserID)
+        queries.RevokeSession(context.Background(), database.RevokeSessionParams{
+            RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+            SessionID: stolen.SessionID,
+            UserID:    stolen.UserID,
+        })
+        writeAuthError(writer, http.StatusUnauthorized, "Invalid refresh token")
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    session, err := qtx.GetSessionByRefreshHashForUpdate(context.Background(), hash)
+    if err != nil || session.RevokedAt.Valid || !session.ExpiresAt.After(now) {
+        writeAuthError(writer, http.StatusUnauthorized, "Invalid refresh token")
+        return
+    }
+    account, err := qtx.GetAccountByID(context.Background(), session.UserID)
+    if err != nil {
+        writeAuthError(writer, http.StatusUnauthorized, "Unknown account")
+        return
+    }
+
+    refresh, err := auth.MakeToken()
+    if err != nil {
+        http.Error(writer, "Failed to refresh session", http.StatusInternalServerError)
+        return
+    }
+    err = qtx.RotateSession(context.Background(), database.RotateSessionParams{
+        RefreshHash: auth.HashToken(refresh),
+        LastUsedAt:  now,
+        ExpiresAt:   now.Add(refreshTokenTTL),
+        SessionID:   session.SessionID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to refresh session", http.StatusInternalServerError)
+        return
+    }
+    tokens, err := issueAccessToken(account, session.SessionID, refresh, 0)
+    if err != nil {
+        http.Error(writer, "Failed to refresh session", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to refresh session", http.StatusInternalServerError)
+        return
+    }
+
+    resp := RefreshResponse{Success: true, Message: "Session refreshed", sessionTokens: tokens}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// LOGOUT
+func logoutHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Logout request received from user:", username)
+
+    type LogoutRequest struct {
+        All bool `json:"all"`
+    }
+    type LogoutResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    // The body is optional; without one only this session ends
+    var logoutReq LogoutRequest
+    if req.ContentLength != 0 {
+        if err := json.NewDecoder(req.Body).Decode(&logoutReq); err != nil {
+            http.Error(writer, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if logoutReq.All {
+        err = revokeSessions(queries, userID)
+    } else {
+        _, err = queries.RevokeSession(context.Background(), database.RevokeSessionParams{
+            RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+            SessionID: currentSessionID(req),
+            UserID:    userID,
+        })
+    }
+    if err != nil {
+        http.Error(writer, "Failed to log out", http.StatusInternalServerError)
+        return
+    }
+
+    resp := LogoutResponse{Success: true, Message: "Logged out successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET SESSIONS
+func getSessionsHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get sessions request received from user:", username)
+
+    type Session struct {
+        SessionID  int32     `json:"session_id"`
+        UserAgent  string    `json:"user_agent"`
+        IP         string    `json:"ip"`
+        CreatedAt  time.Time `json:"created_at"`
+        LastUsedAt time.Time `json:"last_used_at"`
+        ExpiresAt  time.Time `json:"expires_at"`
+        Current    bool      `json:"current"`
+    }
+    type GetSessionsResponse struct {
+        Success  bool      `json:"success"`
+        Sessions []Session `json:"sessions"`
+        Message  string    `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetActiveSessionsByUser(context.Background(), database.GetActiveSessionsByUserParams{
+        UserID:    userID,
+        ExpiresAt: time.Now().UTC(),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get sessions", http.StatusInternalServerError)
+        return
+    }
+
+    sessions := make([]Session, 0, len(rows))
+    for _, row := range rows {
+        sessions = append(sessions, Session{
+            SessionID:  row.SessionID,
+            UserAgent:  row.UserAgent,
+            IP:         row.Ip,
+            CreatedAt:  row.CreatedAt,
+            LastUsedAt: row.LastUsedAt,
+            ExpiresAt:  row.ExpiresAt,
+            Current:    row.SessionID == currentSessionID(req),
+        })
+    }
+
+    resp := GetSessionsResponse{Success: true, Sessions: sessions, Message: "Sessions retrieved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// REVOKE SESSION
+func revokeSessionHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Revoke session request received from user:", username)
+
+    type RevokeSessionRequest struct {
+        SessionID int32 `json:"session_id"`
+    }
+    type RevokeSessionResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var revokeReq RevokeSessionRequest
+    if err := json.NewDecoder(req.Body).Decode(&revokeReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.RevokeSession(context.Background(), database.RevokeSessionParams{
+        RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        SessionID: revokeReq.SessionID,
+        UserID:    userID,
+    })
+    if err != nil {
+        http.Error(writer, "Failed to revoke session", http.StatusInternalServerError)
+        return
+    }
+    if rows == 0 {
+        http.Error(writer, "Invalid session ID", http.StatusBadRequest)
+        return
+    }
+
+    resp := RevokeSessionResponse{Success: true, Message: "Session revoked successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// purgeSessions drops sessions that can no longer be refreshed
+func purgeSessions() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Sessions: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    err = database.New(db).DeleteExpiredSessions(context.Background(), time.Now().UTC())
+    if err != nil {
+        log.Println("Sessions: failed to delete expired sessions:", err)
+    }
+}
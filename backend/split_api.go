@@ -0,0 +1,439 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+)
+
+type billShareView struct {
+    Share database.BillShare   `json:"share"`
+    Items []database.ShareItem `json:"items"`
+}
+
+func getBillShareViews(queries *database.Queries, orderID int32) ([]billShareView, error) {
+    shares, err := queries.GetBillSharesByOrder(context.Background(), orderID)
+    if err != nil {
+        return nil, err
+    }
+    items, err := queries.GetShareItemsByOrder(context.Background(), orderID)
+    if err != nil {
+        return nil, err
+    }
+    views := make([]billShareView, 0, len(shares))
+    for _, share := range shares {
+        view := billShareView{Share: share, Items: []database.ShareItem{}}
+        for _, item := range items {
+            if item.ShareID == share.ShareID {
+                view.Items = append(view.Items, item)
+            }
+        }
+        views = append(views, view)
+    }
+    return views, nil
+}
+
+// SPLIT BILL
+func splitBillHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Split bill request received from user:", username)
+
+    type SplitBillRequest struct {
+        OrderID int32                  `json:"order_id"`
+        Mode    string                 `json:"mode"`
+        People  int                    `json:"people"`
+        Amounts []float64              `json:"amounts"`
+        Items   [][]billing.Assignment `json:"items"`
+        Labels  []string               `json:"labels"`
+    }
+    type SplitBillResponse struct {
+        Success bool            `json:"success"`
+        Shares  []billShareView `json:"shares"`
+        Message string          `json:"message"`
+    }
+
+    var splitReq SplitBillRequest
+    if err := json.NewDecoder(req.Body).Decode(&splitReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // The order stays locked until the shares are written, so it can't start
+    // a whole-bill payment in the meantime
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    order, err := qtx.GetOrderByIdForUpdate(context.Background(), splitReq.OrderID)
+    if err != nil || order.UserID != userID {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+    if order.OrderType != orderTypeDineIn || order.IsPaid || order.Deleted {
+        http.Error(writer, "Only unpaid dine-in orders can be split", http.StatusBadRequest)
+        return
+    }
+
+    open, err := qtx.CountActiveOrderIntents(context.Background(), sql.NullInt32{Int32: order.OrderID, Valid: true})
+    if err != nil {
+        http.Error(writer, "Failed to check payments", http.StatusInternalServerError)
+        return
+    }
+    if open > 0 {
+        http.Error(writer, "This order already has a payment in progress", http.StatusConflict)
+        return
+    }
+    counts, err := qtx.CountBillShares(context.Background(), order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to check bill shares", http.StatusInternalServerError)
+        return
+    }
+    if counts.LockedCount > 0 {
+        http.Error(writer, "Some shares are already paid or being paid", http.StatusConflict)
+        return
+    }
+
+    items, err := qtx.GetOrderedItems(context.Background(), order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to get ordered items", http.StatusInternalServerError)
+        return
+    }
+    total, err := orderAmountDue(qtx, order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to calculate order total", http.StatusInternalServerError)
+        return
+    }
+
+    var amounts []float64
+    switch splitReq.Mode {
+    case "even":
+        amounts, err = billing.SplitEven(total, splitReq.People)
+    case "custom":
+        amounts, err = billing.SplitCustom(total, splitReq.Amounts)
+    case "items":
+        lines := make([]billing.Line, len(items))
+        for i, item := range items {
+            lines[i] = billing.Line{ItemID: item.ItemID, Quantity: item.Quantity, UnitPrice: item.UnitPrice}
+        }
+        amounts, err = billing.SplitByItems(lines, splitReq.Items, total)
+    default:
+        http.Error(writer, "Invalid split mode", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    err = qtx.DeleteBillShares(context.Background(), order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to replace existing split", http.StatusInternalServerError)
+        return
+    }
+    for i, amount := range amounts {
+        label := fmt.Sprintf("Share %d", i+1)
+        if i < len(splitReq.Labels) && splitReq.Labels[i] != "" {
+            label = splitReq.Labels[i]
+        }
+        err = qtx.CreateBillShare(context.Background(), database.CreateBillShareParams{
+            OrderID: order.OrderID,
+            Label:   label,
+            Amount:  amount,
+        })
+        if err != nil {
+            http.Error(writer, "Failed to create bill share", http.StatusInternalServerError)
+            return
+        }
+        if splitReq.Mode != "items" {
+            continue
+        }
+        share, err := qtx.GetLastInsertedBillShare(context.Background())
+        if err != nil {
+            http.Error(writer, "Failed to retrieve bill share", http.StatusInternalServerError)
+            return
+        }
+        for _, assignment := range splitReq.Items[i] {
+            err = qtx.CreateShareItem(context.Background(), database.CreateShareItemParams{
+                ShareID:  share.ShareID,
+                ItemID:   assignment.ItemID,
+                Quantity: assignment.Quantity,
+            })
+            if err != nil {
+                http.Error(writer, "Failed to assign items to share", http.StatusInternalServerError)
+                return
+            }
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to save bill split", http.StatusInternalServerError)
+        return
+    }
+
+    shares, err := getBillShareViews(queries, order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to get bill shares", http.StatusInternalServerError)
+        return
+    }
+
+    resp := SplitBillResponse{Success: true, Shares: shares, Message: "Bill split successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// CANCEL BILL SPLIT
+func cancelSplitHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Cancel bill split request received from user:", username)
+
+    type CancelSplitRequest struct {
+        OrderID int32 `json:"order_id"`
+    }
+    type CancelSplitResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var cancelReq CancelSplitRequest
+    if err := json.NewDecoder(req.Body).Decode(&cancelReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), cancelReq.OrderID)
+    if err != nil || order.UserID != userID {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+
+    counts, err := queries.CountBillShares(context.Background(), order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to check bill shares", http.StatusInternalServerError)
+        return
+    }
+    if counts.LockedCount > 0 {
+        http.Error(writer, "Some shares are already paid or being paid", http.StatusConflict)
+        return
+    }
+
+    err = queries.DeleteBillShares(context.Background(), order.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to cancel bill split", http.StatusInternalServerError)
+        return
+    }
+
+    resp := CancelSplitResponse{Success: true, Message: "Bill split cancelled"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// GET BILL SHARES
+func getBillSharesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get bill shares request received from user:", username)
+
+    orderIDStr := req.URL.Query().Get("order_id")
+    if orderIDStr == "" {
+        http.Error(writer, "Missing order_id query parameter", http.StatusBadRequest)
+        return
+    }
+    var orderID int32
+    if _, err := fmt.Sscanf(orderIDStr, "%d", &orderID); err != nil {
+        http.Error(writer, "Invalid order_id", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    // Anyone at the table may look up the shares to pay their part
+    order, err := queries.GetOrderById(context.Background(), orderID)
+    if err != nil || order.OrderType != orderTypeDineIn {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+
+    shares, err := getBillShareViews(queries, orderID)
+    if err != nil {
+        http.Error(writer, "Failed to get bill shares", http.StatusInternalServerError)
+        return
+    }
+
+    resp := struct {
+        Success bool            `json:"success"`
+        Shares  []billShareView `json:"shares"`
+        IsPaid  bool            `json:"is_paid"`
+        Message string          `json:"message"`
+    }{
+        Success: true,
+        Shares:  shares,
+        IsPaid:  order.IsPaid,
+        Message: "Bill shares retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// PAY BILL SHARE
+func payBillShareHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Pay bill share request received from user:", username)
+
+    type PayShareRequest struct {
+        ShareID int32 `json:"share_id"`
+    }
+    type PayShareResponse struct {
+        Success   bool   `json:"success"`
+        OrderPaid bool   `json:"order_paid"`
+        Message   string `json:"message"`
+    }
+
+    var payReq PayShareRequest
+    if err := json.NewDecoder(req.Body).Decode(&payReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    share, err := queries.GetBillShare(context.Background(), payReq.ShareID)
+    if err != nil || share.IsPaid {
+        http.Error(writer, "Invalid share ID or already paid", http.StatusBadRequest)
+        return
+    }
+
+    // Lock the order so the split can't be replaced while the share is claimed
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    order, err := qtx.GetOrderByIdForUpdate(context.Background(), share.OrderID)
+    if err != nil || order.Deleted || order.IsPaid {
+        http.Error(writer, "Invalid share ID or already paid", http.StatusBadRequest)
+        return
+    }
+
+    wallet := paymentProviders(queries)["wallet"]
+    intent, err := startPayment(qtx, wallet, database.CreatePaymentIntentParams{
+        UserID:  userID,
+        OrderID: sql.NullInt32{Int32: share.OrderID, Valid: true},
+        ShareID: sql.NullInt32{Int32: share.ShareID, Valid: true},
+        Purpose: payment.PurposeBillShare,
+        Amount:  share.Amount,
+    })
+    if err != nil {
+        log.Println("Error starting payment:", err)
+        http.Error(writer, "Failed to create payment", http.StatusInternalServerError)
+        return
+    }
+
+    // Claim the share so two people can't pay it at the same time
+    rows, err := qtx.ClaimBillShare(context.Background(), database.ClaimBillShareParams{
+        IntentID: sql.NullInt32{Int32: intent.IntentID, Valid: true},
+        ShareID:  share.ShareID,
+    })
+    if err != nil || rows == 0 {
+        http.Error(writer, "This share is already paid or being paid", http.StatusConflict)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        http.Error(writer, "Failed to create payment", http.StatusInternalServerError)
+        return
+    }
+
+    _, err = confirmPayment(db, queries, wallet, intent, payment.Method{})
+    if errors.Is(err, payment.ErrInsufficientFunds) {
+        http.Error(writer, "Insufficient balance", http.StatusPaymentRequired)
+        return
+    }
+    if !writePaymentError(writer, err) {
+        return
+    }
+
+    order, err = queries.GetOrderById(context.Background(), share.OrderID)
+    if err != nil {
+        http.Error(writer, "Failed to get order", http.StatusInternalServerError)
+        return
+    }
+
+    resp := PayShareResponse{Success: true, OrderPaid: order.IsPaid, Message: "Share paid successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
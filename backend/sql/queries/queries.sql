@@ -62,8 +62,28 @@ DELETE FROM food
 WHERE food_name = ?;
 
 -- name: CreateOrder :exec
-INSERT INTO orders (user_id, order_info, is_ranged, delivery_address)
+INSERT INTO orders (
+    user_id, order_info, is_ranged, delivery_address, order_type, table_number,
+    invoice_billing_name, invoice_tax_id,
+    zone_id, delivery_lat, delivery_lng, delivery_distance_km, delivery_fee,
+    address_id, delivery_recipient, delivery_phone,
+    scheduled_for, release_at
+)
 VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?,
@@ -75,8 +95,11 @@ SELECT * FROM orders
 WHERE order_id = LAST_INSERT_ID();
 
 -- name: CreateOrderedItem :exec
-INSERT INTO items (order_id, food_id, quantity)
+INSERT INTO items (order_id, food_id, quantity, food_name, unit_price, modifiers)
 VALUES (
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?
@@ -116,14 +139,16 @@ SELECT * FROM orders WHERE user_id = ? AND deleted = false;
 SELECT * FROM orders WHERE user_id = ? AND deleted = true;
 
 -- name: GetAllOrdersNotDone :many
-SELECT * FROM orders WHERE is_done = false AND deleted = false ORDER BY order_time DESC;
+SELECT * FROM orders
+WHERE is_done = false AND deleted = false AND (scheduled_for IS NULL OR released_at IS NOT NULL)
+ORDER BY order_time DESC;
 
--- name: UpdateOrderPayment :exec
+-- name: UpdateOrderPayment :execrows
 UPDATE orders
 SET
     is_paid = true
 WHERE
-    order_id = ?;
+    order_id = ? AND is_paid = false;
 
 -- name: UpdateOrderUser :exec
 UPDATE accounts
@@ -142,10 +167,9 @@ JOIN tags ON food.food_name = tags.food_name
 WHERE tags.tag = ?;
 
 -- name: GetMostOrderedFood :many
-SELECT food.food_name, COUNT(items.food_id) AS order_count
-FROM food
-JOIN items ON food.food_id = items.food_id
-GROUP BY food.food_name
+SELECT items.food_name, COUNT(items.food_id) AS order_count
+FROM items
+GROUP BY items.food_name
 ORDER BY order_count DESC;
 
 -- name: GetAverageRating :one
@@ -161,10 +185,9 @@ WHERE food_id = ?;
 -- name: GetAverageSpendingByUser :one
 SELECT AVG(total_price) AS average_spending
 FROM (
-    SELECT SUM(food.price * items.quantity) AS total_price
+    SELECT SUM(items.unit_price * items.quantity) AS total_price
     FROM orders
     JOIN items ON orders.order_id = items.order_id
-    JOIN food ON items.food_id = food.food_id
     WHERE orders.user_id = ?
     GROUP BY orders.order_id
 ) AS spending;
@@ -172,10 +195,9 @@ FROM (
 -- name: GetAverageSpendingByAllUsers :one
 SELECT AVG(total_price) AS average_spending
 FROM (
-    SELECT SUM(food.price * items.quantity) AS total_price
+    SELECT SUM(items.unit_price * items.quantity) AS total_price
     FROM orders
     JOIN items ON orders.order_id = items.order_id
-    JOIN food ON items.food_id = food.food_id
     GROUP BY orders.user_id, orders.order_id
 ) AS spending;
 
@@ -209,13 +231,12 @@ SET
 WHERE
     order_id = ?;
 
--- name: GetAdminAccount :one
-SELECT * FROM accounts WHERE is_admin = true;
-
 -- name: UpdateOrderDoneStatus :exec
 UPDATE orders
 SET
-    is_done = true
+    is_done = true,
+    finished_by = ?,
+    finished_at = CURRENT_TIMESTAMP
 WHERE
     order_id = ?;
 
@@ -251,16 +272,15 @@ ORDER BY count DESC
 LIMIT 3;
 
 -- name: GetOrderTotalPrice :one
-SELECT SUM(food.price * items.quantity) AS total_price
+SELECT SUM(items.unit_price * items.quantity) AS total_price
 FROM orders
 JOIN items ON orders.order_id = items.order_id
-JOIN food ON items.food_id = food.food_id
 WHERE orders.order_id = ?;
 
 -- name: GetMostOrderedTag :one
 SELECT tag, COUNT(*) AS count
 FROM tags
-JOIN items ON tags.food_name = items.food_id
+JOIN items ON tags.food_name = items.food_name
 JOIN orders ON items.order_id = orders.order_id
 WHERE orders.deleted = false
 GROUP BY tag
@@ -273,4 +293,1425 @@ FROM tags
 WHERE food_name = ?;
 
 -- name: GetAllOrderedItems :many
-SELECT * FROM items;
\ No newline at end of file
+SELECT * FROM items;
+
+-- name: AddAccountBalance :exec
+UPDATE accounts
+SET
+    balance = balance + ?
+WHERE
+    id = ?;
+
+-- name: DeductAccountBalance :execrows
+UPDATE accounts
+SET
+    balance = balance - sqlc.arg(amount)
+WHERE
+    id = sqlc.arg(id) AND balance >= sqlc.arg(amount);
+
+-- name: CreditAccountBalance :execrows
+UPDATE accounts
+SET
+    balance = balance + sqlc.arg(amount)
+WHERE
+    id = sqlc.arg(id) AND ROUND(balance + sqlc.arg(amount), 2) <= sqlc.arg(max_balance);
+
+-- name: CreatePaymentIntent :exec
+INSERT INTO payment_intents (user_id, order_id, share_id, gift_card_id, reservation_id, purpose, provider, amount)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+);
+
+-- name: GetLastInsertedPaymentIntent :one
+SELECT * FROM payment_intents
+WHERE intent_id = LAST_INSERT_ID();
+
+-- name: GetPaymentIntent :one
+SELECT * FROM payment_intents WHERE intent_id = ?;
+
+-- name: GetPaymentIntentForUpdate :one
+SELECT * FROM payment_intents WHERE intent_id = ? FOR UPDATE;
+
+-- name: GetPaymentIntentsByOrder :many
+SELECT * FROM payment_intents WHERE order_id = ? ORDER BY created_at;
+
+-- name: CountActiveOrderIntents :one
+SELECT COUNT(*) FROM payment_intents
+WHERE order_id = ? AND purpose = 'order' AND status IN ('pending', 'processing', 'succeeded', 'refunding');
+
+-- name: UpdatePaymentIntentProviderRef :exec
+UPDATE payment_intents
+SET
+    provider_ref = ?,
+    status = ?
+WHERE
+    intent_id = ?;
+
+-- name: TransitionPaymentIntent :execrows
+UPDATE payment_intents
+SET
+    status = sqlc.arg(new_status)
+WHERE
+    intent_id = sqlc.arg(intent_id) AND status = sqlc.arg(old_status);
+
+-- name: CreatePaymentEvent :exec
+INSERT INTO payment_events (intent_id, event_type, status, amount, detail)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+);
+
+-- name: GetPaymentEvents :many
+SELECT * FROM payment_events WHERE intent_id = ? ORDER BY event_id;
+
+-- name: UpdateOrderUnpaid :exec
+UPDATE orders
+SET
+    is_paid = false
+WHERE
+    order_id = ?;
+
+-- name: GetAccountByID :one
+SELECT * FROM accounts WHERE id = ?;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?;
+
+-- name: ReserveIdempotencyKey :execrows
+INSERT IGNORE INTO idempotency_keys (user_id, idem_key, request_hash, expires_at)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?
+);
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = ? AND idem_key = ?;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+    status = 'completed',
+    response_code = ?,
+    content_type = ?,
+    response_body = ?
+WHERE
+    user_id = ? AND idem_key = ?;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ? AND idem_key = ?;
+
+-- name: CreateBillShare :exec
+INSERT INTO bill_shares (order_id, label, amount)
+VALUES (
+    ?,
+    ?,
+    ?
+);
+
+-- name: GetLastInsertedBillShare :one
+SELECT * FROM bill_shares
+WHERE share_id = LAST_INSERT_ID();
+
+-- name: CreateShareItem :exec
+INSERT INTO share_items (share_id, item_id, quantity)
+VALUES (
+    ?,
+    ?,
+    ?
+);
+
+-- name: GetBillShare :one
+SELECT * FROM bill_shares WHERE share_id = ?;
+
+-- name: GetBillSharesByOrder :many
+SELECT * FROM bill_shares WHERE order_id = ? ORDER BY share_id;
+
+-- name: GetShareItemsByOrder :many
+SELECT share_items.*
+FROM share_items
+JOIN bill_shares ON share_items.share_id = bill_shares.share_id
+WHERE bill_shares.order_id = ?;
+
+-- name: CountBillShares :one
+SELECT COUNT(*) AS share_count,
+    CAST(COALESCE(SUM(is_paid = true OR intent_id IS NOT NULL), 0) AS SIGNED) AS locked_count,
+    CAST(COALESCE(SUM(is_paid = false), 0) AS SIGNED) AS unpaid_count
+FROM bill_shares
+WHERE order_id = ?;
+
+-- name: DeleteBillShares :exec
+DELETE FROM bill_shares
+WHERE order_id = ?;
+
+-- name: ClaimBillShare :execrows
+UPDATE bill_shares
+SET
+    intent_id = ?
+WHERE
+    share_id = ? AND is_paid = false AND intent_id IS NULL;
+
+-- name: ReleaseBillShare :exec
+UPDATE bill_shares
+SET
+    intent_id = NULL
+WHERE
+    share_id = ? AND intent_id = ? AND is_paid = false;
+
+-- name: MarkBillSharePaid :execrows
+UPDATE bill_shares
+SET
+    is_paid = true,
+    paid_by = ?,
+    paid_at = CURRENT_TIMESTAMP
+WHERE
+    share_id = ? AND intent_id = ? AND is_paid = false;
+
+-- name: RefundBillShare :exec
+UPDATE bill_shares
+SET
+    is_paid = false,
+    intent_id = NULL,
+    paid_by = NULL,
+    paid_at = NULL
+WHERE
+    share_id = ?;
+
+-- name: CreateTip :exec
+INSERT INTO tips (order_id, user_id, intent_id, kind, rate, amount, pool, recipient_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateTipStatus :exec
+UPDATE tips
+SET
+    status = ?
+WHERE
+    intent_id = ?;
+
+-- name: GetTipsByOrder :many
+SELECT * FROM tips WHERE order_id = ? ORDER BY tip_id;
+
+-- name: CountActiveTips :one
+SELECT COUNT(*) FROM tips WHERE order_id = ? AND status IN ('pending', 'paid');
+
+-- name: GetPaidTipsInPeriod :many
+SELECT * FROM tips
+WHERE status = 'paid' AND created_at >= sqlc.arg(period_start) AND created_at < sqlc.arg(period_end)
+ORDER BY tip_id;
+
+-- name: GetOrdersFinishedByStaff :many
+SELECT accounts.id, accounts.username, COUNT(*) AS orders_finished
+FROM orders
+JOIN accounts ON orders.finished_by = accounts.id
+WHERE orders.finished_at >= sqlc.arg(period_start) AND orders.finished_at < sqlc.arg(period_end)
+GROUP BY accounts.id, accounts.username
+ORDER BY accounts.id;
+
+-- name: CreateGiftCard :exec
+INSERT INTO gift_cards (code, initial_amount, balance, status, message, issued_by, purchased_by, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetLastInsertedGiftCard :one
+SELECT * FROM gift_cards
+WHERE card_id = LAST_INSERT_ID();
+
+-- name: GetGiftCard :one
+SELECT * FROM gift_cards WHERE card_id = ?;
+
+-- name: GetGiftCardForUpdate :one
+SELECT * FROM gift_cards WHERE card_id = ? FOR UPDATE;
+
+-- name: GetGiftCardByCode :one
+SELECT * FROM gift_cards WHERE code = ?;
+
+-- name: GetGiftCardByCodeForUpdate :one
+SELECT * FROM gift_cards WHERE code = ? FOR UPDATE;
+
+-- name: GetGiftCardsByPurchaser :many
+SELECT * FROM gift_cards WHERE purchased_by = ? ORDER BY card_id DESC;
+
+-- name: GetAllGiftCards :many
+SELECT * FROM gift_cards ORDER BY card_id DESC;
+
+-- name: ActivateGiftCard :execrows
+UPDATE gift_cards
+SET
+    status = 'active'
+WHERE
+    card_id = ? AND status = 'pending';
+
+-- name: HoldGiftCard :execrows
+UPDATE gift_cards
+SET
+    status = 'pending'
+WHERE
+    card_id = ? AND status = 'active';
+
+-- name: UpdateGiftCardBalance :exec
+UPDATE gift_cards
+SET
+    balance = ?
+WHERE
+    card_id = ?;
+
+-- name: VoidGiftCard :exec
+UPDATE gift_cards
+SET
+    status = 'void',
+    balance = 0
+WHERE
+    card_id = ?;
+
+-- name: CreateGiftCardTransaction :exec
+INSERT INTO gift_card_transactions (card_id, account_id, kind, amount, balance_after, intent_id, note)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetGiftCardTransactions :many
+SELECT * FROM gift_card_transactions WHERE card_id = ? ORDER BY transaction_id;
+
+-- name: UpdateOrderInvoiceDetails :exec
+UPDATE orders
+SET
+    invoice_billing_name = ?,
+    invoice_tax_id = ?
+WHERE
+    order_id = ?;
+
+-- name: UpdateFoodStation :exec
+UPDATE food
+SET
+    station = ?
+WHERE
+    food_id = ?;
+
+-- name: UpsertPrinter :exec
+INSERT INTO printers (name, station, address)
+VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    name = VALUES(name),
+    address = VALUES(address),
+    is_active = true;
+
+-- name: GetPrinters :many
+SELECT * FROM printers ORDER BY station;
+
+-- name: GetActivePrinterByStation :one
+SELECT * FROM printers WHERE station = ? AND is_active = true;
+
+-- name: DeletePrinter :exec
+DELETE FROM printers WHERE printer_id = ?;
+
+-- name: CreatePrintJob :exec
+INSERT INTO print_jobs (order_id, station, kind, payload, next_attempt_at, reprint_of)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetLastInsertedPrintJobID :one
+SELECT job_id FROM print_jobs
+WHERE job_id = LAST_INSERT_ID();
+
+-- name: GetPrintJob :one
+SELECT * FROM print_jobs WHERE job_id = ?;
+
+-- name: GetPrintJobs :many
+SELECT job_id, order_id, station, kind, status, attempts, last_error, next_attempt_at, printed_at, reprint_of, created_at
+FROM print_jobs
+WHERE sqlc.arg(status) = '' OR status = sqlc.arg(status)
+ORDER BY job_id DESC
+LIMIT ?;
+
+-- name: GetDuePrintJobs :many
+SELECT * FROM print_jobs
+WHERE status = 'queued' AND next_attempt_at <= ?
+ORDER BY job_id
+LIMIT ?;
+
+-- name: ClaimPrintJob :execrows
+UPDATE print_jobs
+SET
+    status = 'printing',
+    attempts = attempts + 1,
+    claimed_at = ?
+WHERE
+    job_id = ? AND status = 'queued';
+
+-- name: MarkPrintJobPrinted :exec
+UPDATE print_jobs
+SET
+    status = 'printed',
+    last_error = NULL,
+    printed_at = ?
+WHERE
+    job_id = ?;
+
+-- name: RetryPrintJob :exec
+UPDATE print_jobs
+SET
+    status = 'queued',
+    last_error = ?,
+    next_attempt_at = ?
+WHERE
+    job_id = ?;
+
+-- name: FailPrintJob :exec
+UPDATE print_jobs
+SET
+    status = 'failed',
+    last_error = ?
+WHERE
+    job_id = ?;
+
+-- name: RequeueStalePrintJobs :exec
+UPDATE print_jobs
+SET
+    status = 'queued'
+WHERE
+    status = 'printing' AND claimed_at < ?;
+
+-- name: CreateDeliveryZone :exec
+INSERT INTO delivery_zones (name, kind, radius_km, polygon, base_fee, fee_per_km, min_order)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateDeliveryZone :exec
+UPDATE delivery_zones
+SET
+    name = ?,
+    kind = ?,
+    radius_km = ?,
+    polygon = ?,
+    base_fee = ?,
+    fee_per_km = ?,
+    min_order = ?,
+    is_active = ?
+WHERE
+    zone_id = ?;
+
+-- name: DeleteDeliveryZone :exec
+DELETE FROM delivery_zones WHERE zone_id = ?;
+
+-- name: GetDeliveryZone :one
+SELECT * FROM delivery_zones WHERE zone_id = ?;
+
+-- name: GetDeliveryZones :many
+SELECT * FROM delivery_zones ORDER BY zone_id;
+
+-- name: GetActiveDeliveryZones :many
+SELECT * FROM delivery_zones WHERE is_active = true ORDER BY zone_id;
+
+-- name: UpsertRider :exec
+INSERT INTO riders (user_id)
+VALUES (?)
+ON DUPLICATE KEY UPDATE is_active = true;
+
+-- name: DeactivateRider :execrows
+UPDATE riders
+SET
+    is_active = false,
+    status = 'offline'
+WHERE
+    user_id = ?;
+
+-- name: GetRider :one
+SELECT * FROM riders WHERE user_id = ? AND is_active = true;
+
+-- name: GetRiderForUpdate :one
+SELECT * FROM riders WHERE user_id = ? AND is_active = true FOR UPDATE;
+
+-- name: GetRiders :many
+SELECT
+    riders.user_id, accounts.username, riders.status, riders.lat, riders.lng, riders.located_at, riders.is_active,
+    (SELECT COUNT(*) FROM deliveries
+     WHERE deliveries.rider_id = riders.user_id AND deliveries.status IN ('assigned', 'picked_up')) AS active_deliveries
+FROM riders
+JOIN accounts ON riders.user_id = accounts.id
+ORDER BY riders.user_id;
+
+-- name: GetIdleRidersForUpdate :many
+SELECT * FROM riders
+WHERE is_active = true AND status = 'available'
+    AND NOT EXISTS (
+        SELECT 1 FROM deliveries
+        WHERE deliveries.rider_id = riders.user_id AND deliveries.status IN ('assigned', 'picked_up')
+    )
+FOR UPDATE;
+
+-- name: UpdateRiderStatus :exec
+UPDATE riders
+SET
+    status = ?,
+    status_at = ?
+WHERE
+    user_id = ?;
+
+-- name: UpdateRiderLocation :exec
+UPDATE riders
+SET
+    lat = ?,
+    lng = ?,
+    located_at = ?
+WHERE
+    user_id = ?;
+
+-- name: CreateRiderLocation :exec
+INSERT INTO rider_locations (rider_id, delivery_id, lat, lng)
+VALUES (?, ?, ?, ?);
+
+-- name: GetOrderByIdForUpdate :one
+SELECT * FROM orders WHERE order_id = ? FOR UPDATE;
+
+-- name: CreateDelivery :exec
+INSERT INTO deliveries (order_id, rider_id, assigned_by, assigned_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetLastInsertedDelivery :one
+SELECT * FROM deliveries
+WHERE delivery_id = LAST_INSERT_ID();
+
+-- name: GetDelivery :one
+SELECT * FROM deliveries WHERE delivery_id = ?;
+
+-- name: GetLatestDeliveryByOrder :one
+SELECT * FROM deliveries WHERE order_id = ? ORDER BY delivery_id DESC LIMIT 1;
+
+-- name: GetActiveDeliveriesByRider :many
+SELECT * FROM deliveries
+WHERE rider_id = ? AND status IN ('assigned', 'picked_up')
+ORDER BY delivery_id;
+
+-- name: GetDeliveries :many
+SELECT * FROM deliveries
+WHERE sqlc.arg(status) = '' OR status = sqlc.arg(status)
+ORDER BY delivery_id DESC
+LIMIT ?;
+
+-- name: MarkDeliveryPickedUp :execrows
+UPDATE deliveries
+SET
+    status = 'picked_up',
+    picked_up_at = ?
+WHERE
+    delivery_id = ? AND status = 'assigned';
+
+-- name: MarkDeliveryDelivered :execrows
+UPDATE deliveries
+SET
+    status = 'delivered',
+    delivered_at = ?,
+    proof_note = ?,
+    proof_photo = ?
+WHERE
+    delivery_id = ? AND status = 'picked_up';
+
+-- name: MarkDeliveryFailed :execrows
+UPDATE deliveries
+SET
+    status = 'failed',
+    failed_at = ?,
+    failure_reason = ?,
+    proof_note = ?,
+    proof_photo = ?
+WHERE
+    delivery_id = ? AND status IN ('assigned', 'picked_up');
+
+-- name: CancelDelivery :execrows
+UPDATE deliveries
+SET
+    status = 'cancelled',
+    failure_reason = ?
+WHERE
+    delivery_id = ? AND status = 'assigned';
+
+-- name: GetUndispatchedDeliveryOrders :many
+SELECT * FROM orders
+WHERE is_ranged = true AND is_done = true AND deleted = false AND finished_at >= ?
+    AND NOT EXISTS (SELECT 1 FROM deliveries WHERE deliveries.order_id = orders.order_id
+        AND deliveries.status IN ('assigned', 'picked_up', 'delivered'))
+ORDER BY finished_at, order_id;
+
+-- name: SetRiderTipRecipient :exec
+UPDATE tips
+SET
+    recipient_id = ?
+WHERE
+    order_id = ? AND pool = 'rider';
+
+-- name: CreateAddress :exec
+INSERT INTO addresses (user_id, label, recipient, phone, line1, line2, city, postcode, lat, lng, is_default)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetLastInsertedAddress :one
+SELECT * FROM addresses
+WHERE address_id = LAST_INSERT_ID();
+
+-- name: GetAddress :one
+SELECT * FROM addresses WHERE address_id = ? AND user_id = ?;
+
+-- name: GetAddressesByUser :many
+SELECT * FROM addresses WHERE user_id = ? ORDER BY is_default DESC, address_id;
+
+-- name: CountAddresses :one
+SELECT COUNT(*) FROM addresses WHERE user_id = ?;
+
+-- name: UpdateAddress :exec
+UPDATE addresses
+SET
+    label = ?,
+    recipient = ?,
+    phone = ?,
+    line1 = ?,
+    line2 = ?,
+    city = ?,
+    postcode = ?,
+    lat = ?,
+    lng = ?
+WHERE
+    address_id = ? AND user_id = ?;
+
+-- name: ClearDefaultAddress :exec
+UPDATE addresses
+SET
+    is_default = false
+WHERE
+    user_id = ?;
+
+-- name: SetDefaultAddress :execrows
+UPDATE addresses
+SET
+    is_default = true
+WHERE
+    address_id = ? AND user_id = ?;
+
+-- name: SetOldestAddressDefault :exec
+UPDATE addresses
+SET
+    is_default = true
+WHERE
+    user_id = ?
+ORDER BY address_id
+LIMIT 1;
+
+-- name: DeleteAddress :execrows
+DELETE FROM addresses WHERE address_id = ? AND user_id = ?;
+
+-- name: SetEstimatedTime :exec
+UPDATE orders
+SET
+    estimated_time = ?
+WHERE
+    order_id = ?;
+
+-- name: CreateOrderSlot :exec
+INSERT INTO order_slots (slot_start)
+VALUES (?)
+ON DUPLICATE KEY UPDATE slot_start = slot_start;
+
+-- name: BookOrderSlot :execrows
+UPDATE order_slots
+SET
+    booked = booked + 1
+WHERE
+    slot_start = sqlc.arg(slot_start) AND booked < sqlc.arg(capacity);
+
+-- name: FreeOrderSlot :exec
+UPDATE order_slots
+SET
+    booked = booked - 1
+WHERE
+    slot_start = ? AND booked > 0;
+
+-- name: GetOrderSlots :many
+SELECT * FROM order_slots
+WHERE slot_start >= sqlc.arg(period_start) AND slot_start < sqlc.arg(period_end)
+ORDER BY slot_start;
+
+-- name: GetScheduledOrders :many
+SELECT * FROM orders
+WHERE scheduled_for IS NOT NULL AND released_at IS NULL AND deleted = false
+ORDER BY scheduled_for, order_id;
+
+-- name: GetOrdersToRelease :many
+SELECT * FROM orders
+WHERE release_at <= ? AND released_at IS NULL AND deleted = false
+ORDER BY release_at, order_id;
+
+-- name: MarkOrderReleased :execrows
+UPDATE orders
+SET
+    released_at = ?
+WHERE
+    order_id = ? AND released_at IS NULL;
+
+-- name: GetOrdersToRemind :many
+SELECT * FROM orders
+WHERE scheduled_for > sqlc.arg(now) AND scheduled_for <= sqlc.arg(remind_before)
+    AND reminded_at IS NULL AND deleted = false
+ORDER BY scheduled_for, order_id;
+
+-- name: MarkOrderReminded :execrows
+UPDATE orders
+SET
+    reminded_at = ?
+WHERE
+    order_id = ? AND reminded_at IS NULL;
+
+-- name: CreateNotification :exec
+INSERT INTO notifications (user_id, order_id, kind, message)
+VALUES (?, ?, ?, ?);
+
+-- name: GetNotificationsByUser :many
+SELECT * FROM notifications
+WHERE user_id = ?
+ORDER BY notification_id DESC
+LIMIT ?;
+
+-- name: MarkNotificationsRead :exec
+UPDATE notifications
+SET
+    read_at = ?
+WHERE
+    user_id = ? AND read_at IS NULL;
+
+-- name: GetKitchenSettings :one
+SELECT * FROM kitchen_settings WHERE settings_id = 1;
+
+-- name: UpdateKitchenCapacity :exec
+UPDATE kitchen_settings
+SET
+    window_minutes = ?,
+    max_items = ?,
+    max_prep_minutes = ?,
+    pushback_after_minutes = ?,
+    pause_after_minutes = ?,
+    updated_by = ?
+WHERE
+    settings_id = 1;
+
+-- name: UpdateKitchenPause :exec
+UPDATE kitchen_settings
+SET
+    takeaway_paused = ?,
+    delivery_paused = ?,
+    resume_at = ?,
+    updated_by = ?
+WHERE
+    settings_id = 1;
+
+-- name: GetKitchenBacklog :one
+SELECT
+    CAST(COALESCE(SUM(items.quantity), 0) AS SIGNED) AS items,
+    CAST(COALESCE(SUM(items.quantity * food.time_needed), 0) AS SIGNED) AS prep_minutes
+FROM orders
+JOIN items ON items.order_id = orders.order_id
+JOIN food ON food.food_id = items.food_id
+WHERE orders.is_done = false AND orders.deleted = false
+    AND (orders.scheduled_for IS NULL OR orders.released_at IS NOT NULL);
+
+-- name: GetOpeningHours :many
+SELECT * FROM opening_hours ORDER BY order_type, weekday, opens_at;
+
+-- name: DeleteOpeningHours :exec
+DELETE FROM opening_hours WHERE order_type = ?;
+
+-- name: CreateOpeningHours :exec
+INSERT INTO opening_hours (order_type, weekday, opens_at, closes_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetStoreHolidays :many
+SELECT * FROM store_holidays
+WHERE holiday_date >= sqlc.arg(from_date)
+ORDER BY holiday_date, order_type;
+
+-- name: SaveStoreHoliday :exec
+INSERT INTO store_holidays (holiday_date, order_type, opens_at, closes_at, note, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    opens_at = VALUES(opens_at),
+    closes_at = VALUES(closes_at),
+    note = VALUES(note),
+    created_by = VALUES(created_by);
+
+-- name: DeleteStoreHoliday :execrows
+DELETE FROM store_holidays WHERE holiday_id = ?;
+
+-- name: SaveDiningTable :exec
+INSERT INTO dining_tables (table_number, seats, is_active)
+VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    seats = VALUES(seats),
+    is_active = VALUES(is_active);
+
+-- name: GetDiningTables :many
+SELECT * FROM dining_tables ORDER BY table_number;
+
+-- name: GetActiveDiningTablesForUpdate :many
+SELECT * FROM dining_tables WHERE is_active = true ORDER BY table_number FOR UPDATE;
+
+-- name: CreateReservation :exec
+INSERT INTO reservations (user_id, table_number, party_size, starts_at, ends_at, status, note, deposit, deposit_status)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetLastInsertedReservation :one
+SELECT * FROM reservations
+WHERE reservation_id = LAST_INSERT_ID();
+
+-- name: GetReservation :one
+SELECT * FROM reservations WHERE reservation_id = ?;
+
+-- name: GetReservationForUpdate :one
+SELECT * FROM reservations WHERE reservation_id = ? FOR UPDATE;
+
+-- name: GetReservationsByUser :many
+SELECT * FROM reservations
+WHERE user_id = ?
+ORDER BY starts_at DESC;
+
+-- name: GetReservationsBetween :many
+SELECT * FROM reservations
+WHERE starts_at >= sqlc.arg(period_start) AND starts_at < sqlc.arg(period_end)
+ORDER BY starts_at, table_number;
+
+-- name: GetHeldReservations :many
+SELECT * FROM reservations
+WHERE status IN ('pending', 'booked')
+    AND starts_at < sqlc.arg(period_end) AND ends_at > sqlc.arg(period_start)
+    AND reservation_id <> sqlc.arg(exclude_id);
+
+-- name: UpdateReservation :exec
+UPDATE reservations
+SET
+    table_number = ?,
+    party_size = ?,
+    starts_at = ?,
+    ends_at = ?,
+    note = ?
+WHERE
+    reservation_id = ?;
+
+-- name: TransitionReservation :execrows
+UPDATE reservations
+SET
+    status = sqlc.arg(new_status)
+WHERE
+    reservation_id = sqlc.arg(reservation_id) AND status = sqlc.arg(old_status);
+
+-- name: CancelReservation :execrows
+UPDATE reservations
+SET
+    status = 'cancelled',
+    cancelled_at = ?
+WHERE
+    reservation_id = ? AND status IN ('pending', 'booked');
+
+-- name: SeatReservation :execrows
+UPDATE reservations
+SET
+    status = 'seated',
+    seated_at = ?,
+    order_id = ?
+WHERE
+    reservation_id = ? AND status = 'booked';
+
+-- name: UpdateReservationDeposit :exec
+UPDATE reservations
+SET
+    deposit_status = ?
+WHERE
+    reservation_id = ?;
+
+-- name: GetSeatedReservationForTable :one
+SELECT * FROM reservations
+WHERE table_number = ? AND status = 'seated' AND order_id IS NULL
+    AND seated_at >= sqlc.arg(seated_since)
+ORDER BY seated_at DESC
+LIMIT 1;
+
+-- name: LinkReservationOrder :exec
+UPDATE reservations
+SET
+    order_id = ?
+WHERE
+    reservation_id = ?;
+
+-- name: GetReservationDeposit :one
+SELECT * FROM payment_intents
+WHERE reservation_id = ? AND purpose = 'reservation_deposit' AND status = 'succeeded'
+ORDER BY intent_id DESC
+LIMIT 1;
+
+-- name: GetOccupiedTables :many
+SELECT table_number FROM orders
+WHERE order_type = 'dine_in' AND deleted = false AND is_paid = false AND table_number IS NOT NULL
+UNION
+SELECT table_number FROM reservations
+WHERE status = 'seated' AND order_id IS NULL AND seated_at >= sqlc.arg(seated_since);
+
+-- name: CreateWaitlistEntry :exec
+INSERT INTO waitlist (user_id, name, phone, party_size)
+VALUES (?, ?, ?, ?);
+
+-- name: GetLastInsertedWaitlistEntry :one
+SELECT * FROM waitlist
+WHERE entry_id = LAST_INSERT_ID();
+
+-- name: GetWaitlistEntry :one
+SELECT * FROM waitlist WHERE entry_id = ?;
+
+-- name: GetActiveWaitlistEntryByUser :one
+SELECT * FROM waitlist
+WHERE user_id = ? AND status IN ('waiting', 'notified')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetWaitlist :many
+SELECT * FROM waitlist
+WHERE status IN ('waiting', 'notified')
+ORDER BY created_at, entry_id;
+
+-- name: NotifyWaitlistEntry :execrows
+UPDATE waitlist
+SET
+    status = 'notified',
+    table_number = ?,
+    notified_at = ?
+WHERE
+    entry_id = ? AND status IN ('waiting', 'notified');
+
+-- name: SeatWaitlistEntry :execrows
+UPDATE waitlist
+SET
+    status = 'seated',
+    table_number = COALESCE(sqlc.narg(table_number), table_number),
+    seated_at = sqlc.arg(seated_at)
+WHERE
+    entry_id = sqlc.arg(entry_id) AND status IN ('waiting', 'notified');
+
+-- name: LeaveWaitlist :execrows
+UPDATE waitlist
+SET
+    status = 'left'
+WHERE
+    entry_id = ? AND status IN ('waiting', 'notified');
+
+-- name: GetAccountRoles :many
+SELECT role FROM account_roles
+WHERE user_id = ?
+ORDER BY role;
+
+-- name: GrantRole :exec
+INSERT IGNORE INTO account_roles (user_id, role, granted_by, granted_at)
+VALUES (?, ?, ?, ?);
+
+-- name: RevokeRole :execrows
+DELETE FROM account_roles
+WHERE user_id = ? AND role = ?;
+
+-- name: CountRole :one
+SELECT COUNT(*) FROM account_roles
+WHERE role = ?;
+
+-- name: GetStaffAccounts :many
+SELECT account_roles.user_id, accounts.username, account_roles.role, account_roles.granted_by, account_roles.granted_at
+FROM account_roles
+JOIN accounts ON accounts.id = account_roles.user_id
+WHERE account_roles.role <> 'customer'
+ORDER BY accounts.username, account_roles.role;
+
+-- name: CreateSession :exec
+INSERT INTO user_sessions (user_id, refresh_hash, user_agent, ip, created_at, last_used_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetSessionByRefreshHash :one
+SELECT * FROM user_sessions
+WHERE refresh_hash = ?;
+
+-- name: GetSessionByRefreshHashForUpdate :one
+SELECT * FROM user_sessions
+WHERE refresh_hash = ?
+FOR UPDATE;
+
+-- name: GetSessionByPreviousHash :one
+SELECT * FROM user_sessions
+WHERE previous_hash = ?;
+
+-- name: GetActiveSession :one
+SELECT * FROM user_sessions
+WHERE session_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?;
+
+-- name: GetActiveSessionsByUser :many
+SELECT * FROM user_sessions
+WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+ORDER BY last_used_at DESC;
+
+-- name: RotateSession :exec
+UPDATE user_sessions
+SET
+    previous_hash = refresh_hash,
+    refresh_hash = sqlc.arg(refresh_hash),
+    last_used_at = sqlc.arg(last_used_at),
+    expires_at = sqlc.arg(expires_at)
+WHERE
+    session_id = sqlc.arg(session_id);
+
+-- name: RevokeSession :execrows
+UPDATE user_sessions
+SET
+    revoked_at = ?
+WHERE
+    session_id = ? AND user_id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE user_sessions
+SET
+    revoked_at = ?
+WHERE
+    user_id = ? AND revoked_at IS NULL;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM user_sessions
+WHERE expires_at < ?;
+
+-- name: CreateMail :exec
+INSERT INTO mail_outbox (user_id, recipient, subject, body, next_attempt_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetDueMail :many
+SELECT * FROM mail_outbox
+WHERE status = 'queued' AND next_attempt_at <= ?
+ORDER BY mail_id
+LIMIT ?;
+
+-- name: ClaimMail :execrows
+UPDATE mail_outbox
+SET
+    status = 'sending',
+    attempts = attempts + 1,
+    claimed_at = ?
+WHERE
+    mail_id = ? AND status = 'queued';
+
+-- name: MarkMailSent :exec
+UPDATE mail_outbox
+SET
+    status = 'sent',
+    body = '',
+    last_error = NULL,
+    sent_at = ?
+WHERE
+    mail_id = ?;
+
+-- name: RetryMail :exec
+UPDATE mail_outbox
+SET
+    status = 'queued',
+    last_error = ?,
+    next_attempt_at = ?
+WHERE
+    mail_id = ?;
+
+-- name: FailMail :exec
+UPDATE mail_outbox
+SET
+    status = 'failed',
+    body = '',
+    last_error = ?
+WHERE
+    mail_id = ?;
+
+-- name: RequeueStaleMail :exec
+UPDATE mail_outbox
+SET
+    status = 'queued'
+WHERE
+    status = 'sending' AND claimed_at < ?;
+
+-- name: PurgeMail :exec
+DELETE FROM mail_outbox
+WHERE status IN ('sent', 'failed') AND created_at < ?;
+
+-- name: PurgeDeletedAccountMail :exec
+DELETE FROM mail_outbox
+WHERE status IN ('sent', 'failed')
+    AND user_id IN (SELECT id FROM accounts WHERE status = 'deleted');
+
+-- name: DeleteMailByUser :exec
+DELETE FROM mail_outbox WHERE user_id = ?;
+
+-- name: GetAccountByEmail :one
+SELECT * FROM accounts WHERE email = ?;
+
+-- name: UpdateAccountPassword :exec
+UPDATE accounts
+SET
+    password = ?
+WHERE
+    id = ?;
+
+-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (user_id, token_hash, ip, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: CountPasswordResetsSince :one
+SELECT COUNT(*) FROM password_resets
+WHERE user_id = ? AND created_at > ?;
+
+-- name: GetPasswordResetForUpdate :one
+SELECT * FROM password_resets
+WHERE token_hash = ?
+FOR UPDATE;
+
+-- name: UsePasswordResets :exec
+UPDATE password_resets
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND used_at IS NULL;
+
+-- name: SetEmailVerified :exec
+UPDATE accounts
+SET
+    email_verified_at = ?
+WHERE
+    id = ?;
+
+-- name: SetPhoneVerified :exec
+UPDATE accounts
+SET
+    phone_verified_at = ?
+WHERE
+    id = ?;
+
+-- name: CreateVerification :exec
+INSERT INTO verifications (user_id, channel, target, code_hash, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetLatestVerification :one
+SELECT * FROM verifications
+WHERE user_id = ? AND channel = ?
+ORDER BY created_at DESC, verification_id DESC
+LIMIT 1;
+
+-- name: GetLatestVerificationForUpdate :one
+SELECT * FROM verifications
+WHERE user_id = ? AND channel = ?
+ORDER BY created_at DESC, verification_id DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetVerificationByHashForUpdate :one
+SELECT * FROM verifications
+WHERE code_hash = ? AND channel = ?
+FOR UPDATE;
+
+-- name: AddVerificationAttempt :exec
+UPDATE verifications
+SET
+    attempts = attempts + 1
+WHERE
+    verification_id = ?;
+
+-- name: UseVerifications :exec
+UPDATE verifications
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND channel = ? AND used_at IS NULL;
+
+-- name: GetLoginThrottle :one
+SELECT * FROM login_throttles
+WHERE scope = ? AND subject = ?;
+
+-- name: AddLoginFailure :exec
+INSERT INTO login_throttles (scope, subject, failures, last_failure_at, blocked_until)
+VALUES (sqlc.arg(scope), sqlc.arg(subject), 1, sqlc.arg(now), sqlc.arg(now))
+ON DUPLICATE KEY UPDATE
+    failures = IF(last_failure_at < sqlc.arg(reset_before), 1, failures + 1),
+    last_failure_at = sqlc.arg(now);
+
+-- name: SetLoginBlockedUntil :exec
+UPDATE login_throttles
+SET
+    blocked_until = ?
+WHERE
+    scope = ? AND subject = ?;
+
+-- name: ClearLoginThrottle :execrows
+DELETE FROM login_throttles
+WHERE scope = ? AND subject = ?;
+
+-- name: DeleteStaleLoginThrottles :exec
+DELETE FROM login_throttles
+WHERE last_failure_at < ? AND blocked_until < ?;
+
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (user_id, username, ip, user_agent, success, reason, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetLoginAttemptsByUser :many
+SELECT * FROM login_attempts
+WHERE user_id = ?
+ORDER BY created_at DESC, attempt_id DESC
+LIMIT ?;
+
+-- name: GetLoginAttemptsByIP :many
+SELECT * FROM login_attempts
+WHERE ip = ?
+ORDER BY created_at DESC, attempt_id DESC
+LIMIT ?;
+
+-- name: SetTwoFactorEnabled :exec
+UPDATE accounts
+SET
+    two_factor_enabled_at = ?
+WHERE
+    id = ?;
+
+-- name: SaveTwoFactorSecret :exec
+INSERT INTO two_factor_secrets (user_id, secret, last_step, created_at)
+VALUES (?, ?, 0, ?)
+ON DUPLICATE KEY UPDATE
+    secret = VALUES(secret),
+    last_step = 0,
+    created_at = VALUES(created_at);
+
+-- name: GetTwoFactorSecretForUpdate :one
+SELECT * FROM two_factor_secrets
+WHERE user_id = ?
+FOR UPDATE;
+
+-- name: SetTwoFactorLastStep :exec
+UPDATE two_factor_secrets
+SET
+    last_step = ?
+WHERE
+    user_id = ?;
+
+-- name: DeleteTwoFactorSecret :exec
+DELETE FROM two_factor_secrets
+WHERE user_id = ?;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash, created_at)
+VALUES (?, ?, ?);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET
+    used_at = ?
+WHERE
+    user_id = ? AND code_hash = ? AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = ? AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = ?;
+
+-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenges (user_id, token_hash, expires_in_seconds, ip, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetLoginChallengeForUpdate :one
+SELECT * FROM login_challenges
+WHERE token_hash = ?
+FOR UPDATE;
+
+-- name: AddLoginChallengeAttempt :exec
+UPDATE login_challenges
+SET
+    attempts = attempts + 1
+WHERE
+    challenge_id = ?;
+
+-- name: UseLoginChallenge :exec
+UPDATE login_challenges
+SET
+    used_at = ?
+WHERE
+    challenge_id = ?;
+
+-- name: DeleteExpiredLoginChallenges :exec
+DELETE FROM login_challenges
+WHERE expires_at < ?;
+
+-- name: SetAccountStatus :exec
+UPDATE accounts
+SET
+    status = ?,
+    status_reason = ?,
+    suspended_until = ?
+WHERE
+    id = ?;
+
+-- name: AnonymizeAccount :exec
+UPDATE accounts
+SET
+    username = ?,
+    password = ?,
+    email = ?,
+    address = '',
+    user_phone_number = 0,
+    user_tag = NULL,
+    email_verified_at = NULL,
+    phone_verified_at = NULL,
+    two_factor_enabled_at = NULL,
+    status = 'deleted',
+    status_reason = ?,
+    suspended_until = NULL
+WHERE
+    id = ?;
+
+-- name: DeleteAccount :execrows
+DELETE FROM accounts WHERE id = ?;
+
+-- name: CountOrdersByUser :one
+SELECT COUNT(*) FROM orders WHERE user_id = ?;
+
+-- name: DeleteAddressesByUser :exec
+DELETE FROM addresses WHERE user_id = ?;
+
+-- name: ClearOrderPersonalData :exec
+UPDATE orders
+SET
+    order_info = '',
+    feedback = NULL,
+    invoice_billing_name = NULL,
+    invoice_tax_id = NULL,
+    delivery_address = NULL,
+    delivery_lat = NULL,
+    delivery_lng = NULL,
+    delivery_recipient = NULL,
+    delivery_phone = NULL
+WHERE
+    user_id = ?;
+
+-- name: GetDeliveryProofPhotosByUser :many
+SELECT proof_photo FROM deliveries
+WHERE proof_photo IS NOT NULL AND order_id IN (SELECT order_id FROM orders WHERE user_id = ?);
+
+-- name: ClearDeliveryProofsByUser :exec
+UPDATE deliveries
+SET
+    proof_note = NULL,
+    proof_photo = NULL
+WHERE
+    order_id IN (SELECT order_id FROM orders WHERE user_id = ?);
+
+-- name: ClearGiftCardMessages :exec
+UPDATE gift_cards
+SET
+    message = NULL
+WHERE
+    purchased_by = ?;
+
+-- name: ClearReservationNotes :exec
+UPDATE reservations
+SET
+    note = ''
+WHERE
+    user_id = ?;
+
+-- name: AnonymizeWaitlist :exec
+UPDATE waitlist
+SET
+    name = 'Deleted',
+    phone = ''
+WHERE
+    user_id = ?;
+
+-- name: DeleteNotificationsByUser :exec
+DELETE FROM notifications WHERE user_id = ?;
+
+-- name: DeleteSessionsByUser :exec
+DELETE FROM user_sessions WHERE user_id = ?;
+
+-- name: AnonymizeLoginAttempts :exec
+UPDATE login_attempts
+SET
+    username = ?,
+    ip = '',
+    user_agent = ''
+WHERE
+    user_id = ?;
+
+-- name: RevokeStaffRoles :exec
+DELETE FROM account_roles
+WHERE user_id = ? AND role <> 'customer';
+
+-- name: DeleteVerifications :exec
+DELETE FROM verifications WHERE user_id = ?;
+
+-- name: DeletePasswordResets :exec
+DELETE FROM password_resets WHERE user_id = ?;
+
+-- name: CreateAuditLog :exec
+INSERT INTO audit_log (actor_id, target_user_id, action, reason, details, created_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetAuditLog :many
+SELECT * FROM audit_log
+WHERE
+    (sqlc.narg(target_user_id) IS NULL OR target_user_id = sqlc.narg(target_user_id)) AND
+    (sqlc.narg(actor_id) IS NULL OR actor_id = sqlc.narg(actor_id))
+ORDER BY created_at DESC, audit_id DESC
+LIMIT ?;
+
+-- name: GetOrdersByUserForExport :many
+SELECT * FROM orders
+WHERE user_id = ?
+ORDER BY order_id;
+
+-- name: GetItemsByUser :many
+SELECT items.* FROM items
+JOIN orders ON items.order_id = orders.order_id
+WHERE orders.user_id = ?
+ORDER BY items.order_id, items.item_id;
+
+-- name: CreateDeletionRequest :exec
+INSERT INTO deletion_requests (user_id, ip, requested_at, scheduled_for)
+VALUES (?, ?, ?, ?);
+
+-- name: GetPendingDeletionRequest :one
+SELECT * FROM deletion_requests
+WHERE user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL
+ORDER BY request_id DESC
+LIMIT 1;
+
+-- name: CancelDeletionRequests :execrows
+UPDATE deletion_requests
+SET
+    cancelled_at = ?
+WHERE
+    user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL;
+
+-- name: GetDueDeletionRequests :many
+SELECT * FROM deletion_requests
+WHERE scheduled_for <= ? AND cancelled_at IS NULL AND completed_at IS NULL
+ORDER BY scheduled_for
+LIMIT ?;
+
+-- name: GetDeletionRequestForUpdate :one
+SELECT * FROM deletion_requests
+WHERE request_id = ?
+FOR UPDATE;
+
+-- name: CompleteDeletionRequest :exec
+UPDATE deletion_requests
+SET
+    completed_at = ?
+WHERE
+    request_id = ?;
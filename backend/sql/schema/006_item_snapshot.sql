@@ -0,0 +1,19 @@
+-- +goose Up
+alter table items
+    add column food_name varchar(255) not null default '',
+    add column unit_price double(5,2) not null default 0.00,
+    drop foreign key items_ibfk_1;
+
+update items
+join food on items.food_id = food.food_id
+set
+    items.food_name = food.food_name,
+    items.unit_price = food.price;
+
+-- +goose Down
+delete from items where food_id not in (select food_id from food);
+
+alter table items
+    drop column food_name,
+    drop column unit_price,
+    add constraint items_ibfk_1 foreign key (food_id) references food(food_id) on delete cascade;
@@ -0,0 +1,30 @@
+-- +goose Up
+create table payment_intents(
+    intent_id int auto_increment primary key,
+    user_id int not null,
+    order_id int default null,
+    purpose varchar(20) not null,
+    provider varchar(20) not null,
+    provider_ref varchar(100) not null default '',
+    amount double(7,2) not null,
+    status varchar(20) not null default 'pending',
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp on update current_timestamp,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    foreign key (order_id) references orders(order_id) on delete set null
+    );
+
+create table payment_events(
+    event_id int auto_increment primary key,
+    intent_id int not null,
+    event_type varchar(40) not null,
+    status varchar(20) not null,
+    amount double(7,2) not null default 0.00,
+    detail varchar(255) default null,
+    created_at timestamp default current_timestamp,
+    foreign key (intent_id) references payment_intents(intent_id) on delete cascade
+    );
+
+-- +goose Down
+DROP TABLE payment_events;
+DROP TABLE payment_intents;
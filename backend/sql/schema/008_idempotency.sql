@@ -0,0 +1,18 @@
+-- +goose Up
+create table idempotency_keys(
+    user_id int not null,
+    idem_key varchar(255) not null,
+    request_hash char(64) not null,
+    status varchar(20) not null default 'in_progress',
+    response_code int not null default 0,
+    content_type varchar(100) not null default '',
+    response_body mediumblob,
+    created_at timestamp default current_timestamp,
+    expires_at timestamp not null,
+    primary key (user_id, idem_key),
+    index (expires_at),
+    foreign key (user_id) references accounts(id) on delete cascade
+    );
+
+-- +goose Down
+DROP TABLE idempotency_keys;
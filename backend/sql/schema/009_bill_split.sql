@@ -0,0 +1,44 @@
+-- +goose Up
+alter table orders
+    add column order_type varchar(20) not null default 'takeaway',
+    add column table_number int default null;
+
+update orders set order_type = 'delivery' where is_ranged = true;
+
+create table bill_shares(
+    share_id int auto_increment primary key,
+    order_id int not null,
+    label varchar(100) not null,
+    amount double(7,2) not null,
+    is_paid bool default false not null,
+    intent_id int default null,
+    paid_by int default null,
+    paid_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    foreign key (order_id) references orders(order_id) on delete cascade,
+    foreign key (intent_id) references payment_intents(intent_id) on delete set null,
+    foreign key (paid_by) references accounts(id) on delete set null
+    );
+
+create table share_items(
+    share_id int not null,
+    item_id int not null,
+    quantity int not null,
+    primary key (share_id, item_id),
+    foreign key (share_id) references bill_shares(share_id) on delete cascade,
+    foreign key (item_id) references items(item_id) on delete cascade
+    );
+
+alter table payment_intents
+    add column share_id int default null,
+    add foreign key (share_id) references bill_shares(share_id) on delete set null;
+
+-- +goose Down
+alter table payment_intents
+    drop foreign key payment_intents_ibfk_3,
+    drop column share_id;
+DROP TABLE share_items;
+DROP TABLE bill_shares;
+alter table orders
+    drop column order_type,
+    drop column table_number;
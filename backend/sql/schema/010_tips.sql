@@ -0,0 +1,31 @@
+-- +goose Up
+alter table orders
+    add column finished_by int default null,
+    add column finished_at timestamp null default null,
+    add foreign key (finished_by) references accounts(id) on delete set null;
+
+create table tips(
+    tip_id int auto_increment primary key,
+    order_id int not null,
+    user_id int not null,
+    intent_id int default null,
+    kind varchar(20) not null,
+    rate double(5,2) default null,
+    amount double(7,2) not null,
+    pool varchar(20) not null,
+    recipient_id int default null,
+    status varchar(20) not null default 'pending',
+    created_at timestamp default current_timestamp,
+    foreign key (order_id) references orders(order_id) on delete cascade,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    foreign key (intent_id) references payment_intents(intent_id) on delete set null,
+    foreign key (recipient_id) references accounts(id) on delete set null,
+    index (created_at)
+    );
+
+-- +goose Down
+DROP TABLE tips;
+alter table orders
+    drop foreign key orders_ibfk_2,
+    drop column finished_by,
+    drop column finished_at;
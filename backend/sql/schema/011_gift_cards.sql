@@ -0,0 +1,41 @@
+-- +goose Up
+create table gift_cards(
+    card_id int auto_increment primary key,
+    code varchar(32) not null unique,
+    initial_amount double(7,2) not null,
+    balance double(7,2) not null,
+    status varchar(20) not null default 'pending',
+    message varchar(255) default null,
+    issued_by int default null,
+    purchased_by int default null,
+    expires_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    foreign key (issued_by) references accounts(id) on delete set null,
+    foreign key (purchased_by) references accounts(id) on delete set null
+    );
+
+create table gift_card_transactions(
+    transaction_id int auto_increment primary key,
+    card_id int not null,
+    account_id int default null,
+    kind varchar(20) not null,
+    amount double(7,2) not null,
+    balance_after double(7,2) not null,
+    intent_id int default null,
+    note varchar(255) default null,
+    created_at timestamp default current_timestamp,
+    foreign key (card_id) references gift_cards(card_id) on delete cascade,
+    foreign key (account_id) references accounts(id) on delete set null,
+    foreign key (intent_id) references payment_intents(intent_id) on delete set null
+    );
+
+alter table payment_intents
+    add column gift_card_id int default null,
+    add foreign key (gift_card_id) references gift_cards(card_id) on delete set null;
+
+-- +goose Down
+alter table payment_intents
+    drop foreign key payment_intents_ibfk_4,
+    drop column gift_card_id;
+DROP TABLE gift_card_transactions;
+DROP TABLE gift_cards;
@@ -0,0 +1,9 @@
+-- +goose Up
+alter table orders
+    add column invoice_billing_name varchar(255) default null,
+    add column invoice_tax_id varchar(50) default null;
+
+-- +goose Down
+alter table orders
+    drop column invoice_billing_name,
+    drop column invoice_tax_id;
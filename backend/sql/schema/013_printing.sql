@@ -0,0 +1,42 @@
+-- +goose Up
+alter table food
+    add column station varchar(50) not null default 'kitchen';
+
+alter table items
+    add column modifiers varchar(255) not null default '';
+
+create table printers(
+    printer_id int auto_increment primary key,
+    name varchar(100) not null,
+    station varchar(50) not null unique,
+    address varchar(255) not null,
+    is_active bool default true not null,
+    created_at timestamp default current_timestamp
+    );
+
+create table print_jobs(
+    job_id int auto_increment primary key,
+    order_id int default null,
+    station varchar(50) not null,
+    kind varchar(20) not null,
+    payload mediumblob not null,
+    status varchar(20) not null default 'queued',
+    attempts int not null default 0,
+    last_error varchar(255) default null,
+    next_attempt_at timestamp not null,
+    claimed_at timestamp null default null,
+    printed_at timestamp null default null,
+    reprint_of int default null,
+    created_at timestamp default current_timestamp,
+    foreign key (order_id) references orders(order_id) on delete cascade,
+    foreign key (reprint_of) references print_jobs(job_id) on delete set null,
+    index (status, next_attempt_at)
+    );
+
+-- +goose Down
+DROP TABLE print_jobs;
+DROP TABLE printers;
+alter table items
+    drop column modifiers;
+alter table food
+    drop column station;
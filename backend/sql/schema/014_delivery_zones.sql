@@ -0,0 +1,31 @@
+-- +goose Up
+create table delivery_zones(
+    zone_id int auto_increment primary key,
+    name varchar(100) not null,
+    kind varchar(20) not null,
+    radius_km double default null,
+    polygon text default null,
+    base_fee double(7,2) not null default 0.00,
+    fee_per_km double(7,2) not null default 0.00,
+    min_order double(7,2) not null default 0.00,
+    is_active bool default true not null,
+    created_at timestamp default current_timestamp
+    );
+
+alter table orders
+    add column zone_id int default null,
+    add column delivery_lat double default null,
+    add column delivery_lng double default null,
+    add column delivery_distance_km double(7,2) default null,
+    add column delivery_fee double(7,2) not null default 0.00,
+    add foreign key (zone_id) references delivery_zones(zone_id) on delete set null;
+
+-- +goose Down
+alter table orders
+    drop foreign key orders_ibfk_3,
+    drop column zone_id,
+    drop column delivery_lat,
+    drop column delivery_lng,
+    drop column delivery_distance_km,
+    drop column delivery_fee;
+DROP TABLE delivery_zones;
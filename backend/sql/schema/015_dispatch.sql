@@ -0,0 +1,49 @@
+-- +goose Up
+create table riders(
+    user_id int primary key,
+    status varchar(20) not null default 'offline',
+    status_at timestamp default current_timestamp,
+    lat double default null,
+    lng double default null,
+    located_at timestamp null default null,
+    is_active bool default true not null,
+    created_at timestamp default current_timestamp,
+    foreign key (user_id) references accounts(id) on delete cascade
+    );
+
+create table deliveries(
+    delivery_id int auto_increment primary key,
+    order_id int not null,
+    rider_id int not null,
+    status varchar(20) not null default 'assigned',
+    assigned_by int default null,
+    assigned_at timestamp not null,
+    picked_up_at timestamp null default null,
+    delivered_at timestamp null default null,
+    failed_at timestamp null default null,
+    failure_reason varchar(255) default null,
+    proof_note varchar(255) default null,
+    proof_photo varchar(255) default null,
+    foreign key (order_id) references orders(order_id) on delete cascade,
+    foreign key (rider_id) references riders(user_id) on delete cascade,
+    foreign key (assigned_by) references accounts(id) on delete set null,
+    index (rider_id, status),
+    index (status)
+    );
+
+create table rider_locations(
+    location_id int auto_increment primary key,
+    rider_id int not null,
+    delivery_id int default null,
+    lat double not null,
+    lng double not null,
+    created_at timestamp default current_timestamp,
+    foreign key (rider_id) references riders(user_id) on delete cascade,
+    foreign key (delivery_id) references deliveries(delivery_id) on delete set null,
+    index (delivery_id, created_at)
+    );
+
+-- +goose Down
+DROP TABLE rider_locations;
+DROP TABLE deliveries;
+DROP TABLE riders;
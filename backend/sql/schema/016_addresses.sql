@@ -0,0 +1,33 @@
+-- +goose Up
+create table addresses(
+    address_id int auto_increment primary key,
+    user_id int not null,
+    label varchar(50) not null,
+    recipient varchar(100) not null,
+    phone varchar(30) not null,
+    line1 varchar(255) not null,
+    line2 varchar(255) not null default '',
+    city varchar(100) not null,
+    postcode varchar(20) not null default '',
+    lat double default null,
+    lng double default null,
+    is_default bool default false not null,
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp on update current_timestamp,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id, is_default)
+    );
+
+alter table orders
+    add column address_id int default null,
+    add column delivery_recipient varchar(100) default null,
+    add column delivery_phone varchar(30) default null,
+    add foreign key (address_id) references addresses(address_id) on delete set null;
+
+-- +goose Down
+alter table orders
+    drop foreign key orders_ibfk_4,
+    drop column address_id,
+    drop column delivery_recipient,
+    drop column delivery_phone;
+DROP TABLE addresses;
@@ -0,0 +1,37 @@
+-- +goose Up
+alter table orders
+    add column scheduled_for timestamp null default null,
+    add column release_at timestamp null default null,
+    add column released_at timestamp null default null,
+    add column reminded_at timestamp null default null,
+    add index (release_at),
+    add index (scheduled_for);
+
+create table order_slots(
+    slot_start timestamp not null primary key,
+    booked int not null default 0
+    );
+
+create table notifications(
+    notification_id int auto_increment primary key,
+    user_id int not null,
+    order_id int default null,
+    kind varchar(30) not null,
+    message varchar(255) not null,
+    read_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    foreign key (order_id) references orders(order_id) on delete set null,
+    index (user_id, created_at)
+    );
+
+-- +goose Down
+DROP TABLE notifications;
+DROP TABLE order_slots;
+alter table orders
+    drop index release_at,
+    drop index scheduled_for,
+    drop column scheduled_for,
+    drop column release_at,
+    drop column released_at,
+    drop column reminded_at;
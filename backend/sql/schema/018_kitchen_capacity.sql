@@ -0,0 +1,20 @@
+-- +goose Up
+create table kitchen_settings(
+    settings_id int primary key,
+    window_minutes int not null default 15,
+    max_items int not null default 0,
+    max_prep_minutes int not null default 0,
+    pushback_after_minutes int not null default 15,
+    pause_after_minutes int not null default 0,
+    takeaway_paused bool default false not null,
+    delivery_paused bool default false not null,
+    resume_at timestamp null default null,
+    updated_by int default null,
+    updated_at timestamp default current_timestamp on update current_timestamp,
+    foreign key (updated_by) references accounts(id) on delete set null
+    );
+
+insert into kitchen_settings (settings_id) values (1);
+
+-- +goose Down
+DROP TABLE kitchen_settings;
@@ -0,0 +1,28 @@
+-- +goose Up
+create table opening_hours(
+    hours_id int not null auto_increment,
+    order_type varchar(20) not null,
+    weekday int not null,
+    opens_at varchar(5) not null,
+    closes_at varchar(5) not null,
+    created_at timestamp default current_timestamp,
+    primary key(hours_id)
+    );
+
+create table store_holidays(
+    holiday_id int not null auto_increment,
+    holiday_date date not null,
+    order_type varchar(20) not null default 'all',
+    opens_at varchar(5) default null,
+    closes_at varchar(5) default null,
+    note varchar(255) not null default '',
+    created_by int default null,
+    created_at timestamp default current_timestamp,
+    primary key(holiday_id),
+    unique key holiday_date_order_type (holiday_date, order_type),
+    foreign key (created_by) references accounts(id) on delete set null
+    );
+
+-- +goose Down
+DROP TABLE store_holidays;
+DROP TABLE opening_hours;
@@ -0,0 +1,56 @@
+-- +goose Up
+create table dining_tables(
+    table_number int primary key,
+    seats int not null,
+    is_active bool default true not null,
+    created_at timestamp default current_timestamp
+    );
+
+create table reservations(
+    reservation_id int auto_increment primary key,
+    user_id int not null,
+    table_number int not null,
+    party_size int not null,
+    starts_at timestamp not null,
+    ends_at timestamp not null,
+    status varchar(20) not null default 'pending',
+    note varchar(255) not null default '',
+    deposit double(7,2) not null default 0,
+    deposit_status varchar(20) not null default 'none',
+    order_id int default null,
+    seated_at timestamp null default null,
+    cancelled_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    foreign key (table_number) references dining_tables(table_number),
+    foreign key (order_id) references orders(order_id) on delete set null,
+    index (table_number, starts_at),
+    index (user_id, starts_at)
+    );
+
+create table waitlist(
+    entry_id int auto_increment primary key,
+    user_id int default null,
+    name varchar(100) not null,
+    phone varchar(30) not null default '',
+    party_size int not null,
+    status varchar(20) not null default 'waiting',
+    table_number int default null,
+    notified_at timestamp null default null,
+    seated_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    foreign key (user_id) references accounts(id) on delete set null,
+    index (status, created_at)
+    );
+
+alter table payment_intents
+    add column reservation_id int default null,
+    add foreign key (reservation_id) references reservations(reservation_id) on delete set null;
+
+-- +goose Down
+alter table payment_intents
+    drop foreign key payment_intents_ibfk_5,
+    drop column reservation_id;
+DROP TABLE waitlist;
+DROP TABLE reservations;
+DROP TABLE dining_tables;
@@ -0,0 +1,18 @@
+-- +goose Up
+create table account_roles(
+    user_id int not null,
+    role varchar(20) not null,
+    granted_by int default null,
+    granted_at timestamp default current_timestamp,
+    primary key (user_id, role),
+    foreign key (user_id) references accounts(id) on delete cascade,
+    foreign key (granted_by) references accounts(id) on delete set null,
+    index (role)
+    );
+
+insert into account_roles (user_id, role) select id, 'customer' from accounts;
+insert into account_roles (user_id, role) select id, 'owner' from accounts where is_admin = true;
+insert into account_roles (user_id, role) select user_id, 'rider' from riders where is_active = true;
+
+-- +goose Down
+DROP TABLE account_roles;
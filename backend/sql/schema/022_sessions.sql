@@ -0,0 +1,19 @@
+-- +goose Up
+create table user_sessions(
+    session_id int auto_increment primary key,
+    user_id int not null,
+    refresh_hash char(64) not null unique,
+    previous_hash char(64) default null,
+    user_agent varchar(255) not null default '',
+    ip varchar(64) not null default '',
+    created_at timestamp not null,
+    last_used_at timestamp not null,
+    expires_at timestamp not null,
+    revoked_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id, revoked_at),
+    index (previous_hash)
+    );
+
+-- +goose Down
+DROP TABLE user_sessions;
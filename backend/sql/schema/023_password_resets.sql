@@ -0,0 +1,31 @@
+-- +goose Up
+create table mail_outbox(
+    mail_id int auto_increment primary key,
+    recipient varchar(255) not null,
+    subject varchar(255) not null,
+    body text not null,
+    status varchar(20) not null default 'queued',
+    attempts int not null default 0,
+    last_error varchar(255) default null,
+    next_attempt_at timestamp not null,
+    claimed_at timestamp null default null,
+    sent_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+    index (status, next_attempt_at)
+    );
+
+create table password_resets(
+    reset_id int auto_increment primary key,
+    user_id int not null,
+    token_hash char(64) not null unique,
+    ip varchar(64) not null default '',
+    created_at timestamp not null,
+    expires_at timestamp not null,
+    used_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id, created_at)
+    );
+
+-- +goose Down
+DROP TABLE password_resets;
+DROP TABLE mail_outbox;
@@ -0,0 +1,27 @@
+-- +goose Up
+alter table accounts
+    add column email_verified_at timestamp null default null,
+    add column phone_verified_at timestamp null default null;
+
+update accounts set email_verified_at = current_timestamp, phone_verified_at = current_timestamp;
+
+create table verifications(
+    verification_id int auto_increment primary key,
+    user_id int not null,
+    channel varchar(10) not null,
+    target varchar(100) not null,
+    code_hash char(64) not null,
+    attempts int not null default 0,
+    created_at timestamp not null,
+    expires_at timestamp not null,
+    used_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id, channel, created_at),
+    index (code_hash)
+    );
+
+-- +goose Down
+DROP TABLE verifications;
+alter table accounts
+    drop column email_verified_at,
+    drop column phone_verified_at;
@@ -0,0 +1,28 @@
+-- +goose Up
+create table login_throttles(
+    scope varchar(10) not null,
+    subject varchar(64) not null,
+    failures int not null default 0,
+    last_failure_at timestamp not null,
+    blocked_until timestamp not null,
+    primary key (scope, subject),
+    index (last_failure_at)
+    );
+
+create table login_attempts(
+    attempt_id int auto_increment primary key,
+    user_id int default null,
+    username varchar(30) not null,
+    ip varchar(64) not null default '',
+    user_agent varchar(255) not null default '',
+    success boolean not null,
+    reason varchar(30) not null default '',
+    created_at timestamp not null,
+    foreign key (user_id) references accounts(id) on delete set null,
+    index (user_id, created_at),
+    index (ip, created_at)
+    );
+
+-- +goose Down
+DROP TABLE login_attempts;
+DROP TABLE login_throttles;
@@ -0,0 +1,42 @@
+-- +goose Up
+alter table accounts
+    add column two_factor_enabled_at timestamp null default null;
+
+create table two_factor_secrets(
+    user_id int primary key,
+    secret varchar(64) not null,
+    last_step bigint not null default 0,
+    created_at timestamp not null,
+    foreign key (user_id) references accounts(id) on delete cascade
+    );
+
+create table recovery_codes(
+    code_id int auto_increment primary key,
+    user_id int not null,
+    code_hash char(64) not null,
+    created_at timestamp not null,
+    used_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id, code_hash)
+    );
+
+create table login_challenges(
+    challenge_id int auto_increment primary key,
+    user_id int not null,
+    token_hash char(64) not null unique,
+    expires_in_seconds int not null default 0,
+    attempts int not null default 0,
+    ip varchar(64) not null default '',
+    created_at timestamp not null,
+    expires_at timestamp not null,
+    used_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (expires_at)
+    );
+
+-- +goose Down
+DROP TABLE login_challenges;
+DROP TABLE recovery_codes;
+DROP TABLE two_factor_secrets;
+alter table accounts
+    drop column two_factor_enabled_at;
@@ -0,0 +1,27 @@
+-- +goose Up
+alter table accounts
+    add column status varchar(20) not null default 'active',
+    add column status_reason varchar(255) not null default '',
+    add column suspended_until timestamp null default null;
+
+create table audit_log(
+    audit_id int auto_increment primary key,
+    actor_id int default null,
+    target_user_id int default null,
+    action varchar(30) not null,
+    reason varchar(255) not null default '',
+    details text,
+    created_at timestamp not null,
+    foreign key (actor_id) references accounts(id) on delete set null,
+    foreign key (target_user_id) references accounts(id) on delete set null,
+    index (target_user_id, created_at),
+    index (actor_id, created_at),
+    index (created_at)
+    );
+
+-- +goose Down
+DROP TABLE audit_log;
+alter table accounts
+    drop column status,
+    drop column status_reason,
+    drop column suspended_until;
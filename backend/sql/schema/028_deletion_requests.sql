@@ -0,0 +1,16 @@
+-- +goose Up
+create table deletion_requests(
+    request_id int auto_increment primary key,
+    user_id int not null,
+    ip varchar(64) not null default '',
+    requested_at timestamp not null,
+    scheduled_for timestamp not null,
+    cancelled_at timestamp null default null,
+    completed_at timestamp null default null,
+    foreign key (user_id) references accounts(id) on delete cascade,
+    index (user_id),
+    index (scheduled_for)
+    );
+
+-- +goose Down
+DROP TABLE deletion_requests;
@@ -0,0 +1,12 @@
+-- +goose Up
+alter table mail_outbox
+    add column user_id int default null after mail_id,
+    add constraint mail_outbox_user_fk foreign key (user_id) references accounts(id) on delete cascade;
+
+update mail_outbox join accounts on accounts.email = mail_outbox.recipient
+set mail_outbox.user_id = accounts.id;
+
+-- +goose Down
+alter table mail_outbox
+    drop foreign key mail_outbox_user_fk,
+    drop column user_id;
@@ -0,0 +1,334 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "errors"
+    "fmt"
+    "log"
+    "sort"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/dispatch"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+)
+
+// Tip statuses follow the intent that pays for them
+const (
+    tipStatusPending  = "pending"
+    tipStatusPaid     = "paid"
+    tipStatusFailed   = "failed"
+    tipStatusRefunded = "refunded"
+)
+
+var errAlreadyTipped = errors.New("order has already been tipped")
+
+func setTipStatus(queries *database.Queries, intentID int32, status string) error {
+    err := queries.UpdateTipStatus(context.Background(), database.UpdateTipStatusParams{
+        Status:   status,
+        IntentID: sql.NullInt32{Int32: intentID, Valid: true},
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update tip: %w", err)
+    }
+    return nil
+}
+
+// chargeTip takes a tip for order from the payer's wallet. Tips are paid
+// through their own intent so they never mix with food revenue. The order is
+// locked while the tip is recorded, so it can only be tipped once.
+func chargeTip(db *sql.DB, queries *database.Queries, userID int32, order database.Order, kind string, value float64) (float64, error) {
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    if _, err := qtx.GetOrderByIdForUpdate(context.Background(), order.OrderID); err != nil {
+        return 0, fmt.Errorf("failed to retrieve order: %w", err)
+    }
+    tips, err := qtx.CountActiveTips(context.Background(), order.OrderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to count tips: %w", err)
+    }
+    if tips > 0 {
+        return 0, errAlreadyTipped
+    }
+
+    base, err := orderAmountDue(qtx, order.OrderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to calculate order total: %w", err)
+    }
+    amount, err := billing.TipAmount(base, kind, value)
+    if err != nil {
+        return 0, err
+    }
+
+    wallet := paymentProviders(queries)["wallet"]
+    intent, err := startPayment(qtx, wallet, database.CreatePaymentIntentParams{
+        UserID:  userID,
+        OrderID: sql.NullInt32{Int32: order.OrderID, Valid: true},
+        Purpose: payment.PurposeTip,
+        Amount:  amount,
+    })
+    if err != nil {
+        return 0, err
+    }
+
+    // Rider tips go to whoever delivered the order, or get their recipient
+    // once it is delivered
+    pool := billing.TipPool(order.OrderType)
+    var recipient sql.NullInt32
+    if pool == billing.PoolRider {
+        delivery, err := qtx.GetLatestDeliveryByOrder(context.Background(), order.OrderID)
+        if err == nil && delivery.Status == dispatch.StatusDelivered {
+            recipient = sql.NullInt32{Int32: delivery.RiderID, Valid: true}
+        }
+    }
+
+    err = qtx.CreateTip(context.Background(), database.CreateTipParams{
+        OrderID:     order.OrderID,
+        UserID:      userID,
+        IntentID:    sql.NullInt32{Int32: intent.IntentID, Valid: true},
+        Kind:        kind,
+        Rate:        sql.NullFloat64{Float64: value, Valid: kind == billing.TipPercentage},
+        Amount:      intent.Amount,
+        Pool:        pool,
+        RecipientID: recipient,
+    })
+    if err != nil {
+        return 0, fmt.Errorf("failed to record tip: %w", err)
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to record tip: %w", err)
+    }
+
+    _, err = confirmPayment(db, queries, wallet, intent, payment.Method{})
+    if err != nil {
+        return 0, err
+    }
+    return intent.Amount, nil
+}
+
+// TIP ORDER
+func tipOrderHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Tip request received from user:", username)
+
+    type TipRequest struct {
+        OrderID int32   `json:"order_id"`
+        Kind    string  `json:"kind"`
+        Value   float64 `json:"value"`
+    }
+    type TipResponse struct {
+        Success bool    `json:"success"`
+        Amount  float64 `json:"amount"`
+        Message string  `json:"message"`
+    }
+
+    var tipReq TipRequest
+    if err := json.NewDecoder(req.Body).Decode(&tipReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    order, err := queries.GetOrderById(context.Background(), tipReq.OrderID)
+    if err != nil || order.UserID != userID || order.Deleted {
+        http.Error(writer, "Invalid order ID", http.StatusBadRequest)
+        return
+    }
+    if !order.IsPaid || !order.IsDone {
+        http.Error(writer, "Orders can be tipped after they are paid and completed", http.StatusBadRequest)
+        return
+    }
+
+    amount, err := chargeTip(db, queries, userID, order, tipReq.Kind, tipReq.Value)
+    if errors.Is(err, errAlreadyTipped) {
+        http.Error(writer, "This order has already been tipped", http.StatusConflict)
+        return
+    }
+    if errors.Is(err, billing.ErrInvalidTip) {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if !writePaymentError(writer, err) {
+        return
+    }
+
+    resp := TipResponse{Success: true, Amount: amount, Message: "Thank you for the tip"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// TIP REPORT
+func tipReportHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Tip report request received from user:", username)
+
+    // Dates are inclusive; the default period is the current month so far
+    now := time.Now().UTC()
+    from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+    to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+    var err error
+    if s := req.URL.Query().Get("from"); s != "" {
+        if from, err = time.Parse("2006-01-02", s); err != nil {
+            http.Error(writer, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    if s := req.URL.Query().Get("to"); s != "" {
+        if to, err = time.Parse("2006-01-02", s); err != nil {
+            http.Error(writer, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    if to.Before(from) {
+        http.Error(writer, "to must not be before from", http.StatusBadRequest)
+        return
+    }
+    end := to.AddDate(0, 0, 1)
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tips, err := queries.GetPaidTipsInPeriod(context.Background(), database.GetPaidTipsInPeriodParams{
+        PeriodStart: sql.NullTime{Time: from, Valid: true},
+        PeriodEnd:   sql.NullTime{Time: end, Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get tips", http.StatusInternalServerError)
+        return
+    }
+    staff, err := queries.GetOrdersFinishedByStaff(context.Background(), database.GetOrdersFinishedByStaffParams{
+        PeriodStart: sql.NullTime{Time: from, Valid: true},
+        PeriodEnd:   sql.NullTime{Time: end, Valid: true},
+    })
+    if err != nil {
+        http.Error(writer, "Failed to get staff activity", http.StatusInternalServerError)
+        return
+    }
+
+    type StaffTips struct {
+        AccountID      int32   `json:"account_id"`
+        Username       string  `json:"username"`
+        OrdersFinished int64   `json:"orders_finished"`
+        DirectTips     float64 `json:"direct_tips"`
+        PoolShare      float64 `json:"pool_share"`
+        Total          float64 `json:"total"`
+    }
+    type TipReportResponse struct {
+        Success             bool        `json:"success"`
+        From                string      `json:"from"`
+        To                  string      `json:"to"`
+        TotalTips           float64     `json:"total_tips"`
+        RiderTips           float64     `json:"rider_tips"`
+        UnassignedRiderTips float64     `json:"unassigned_rider_tips"`
+        StaffPool           float64     `json:"staff_pool"`
+        Staff               []StaffTips `json:"staff"`
+        Message             string      `json:"message"`
+    }
+
+    // Work in cents so the totals add up exactly
+    var totalCents, riderCents, unassignedCents, poolCents int64
+    directCents := map[int32]int64{}
+    for _, tip := range tips {
+        cents := billing.ToCents(tip.Amount)
+        totalCents += cents
+        switch {
+        case tip.RecipientID.Valid:
+            directCents[tip.RecipientID.Int32] += cents
+            if tip.Pool == billing.PoolRider {
+                riderCents += cents
+            }
+        case tip.Pool == billing.PoolRider:
+            riderCents += cents
+            unassignedCents += cents
+        default:
+            poolCents += cents
+        }
+    }
+
+    entries := map[int32]*StaffTips{}
+    for _, member := range staff {
+        entries[member.ID] = &StaffTips{
+            AccountID:      member.ID,
+            Username:       member.Username,
+            OrdersFinished: member.OrdersFinished,
+        }
+    }
+    // The staff pool is shared evenly by everyone who finished an order
+    for i, share := range billing.AllocatePool(billing.FromCents(poolCents), len(staff)) {
+        entries[staff[i].ID].PoolShare = share
+    }
+    for recipientID, cents := range directCents {
+        entry, ok := entries[recipientID]
+        if !ok {
+            account, err := queries.GetAccountByID(context.Background(), recipientID)
+            if err != nil {
+                http.Error(writer, "Failed to get tip recipient", http.StatusInternalServerError)
+                return
+            }
+            entry = &StaffTips{AccountID: account.ID, Username: account.Username}
+            entries[recipientID] = entry
+        }
+        entry.DirectTips = billing.FromCents(cents)
+    }
+
+    report := make([]StaffTips, 0, len(entries))
+    for _, entry := range entries {
+        entry.Total = billing.FromCents(billing.ToCents(entry.DirectTips) + billing.ToCents(entry.PoolShare))
+        report = append(report, *entry)
+    }
+    sort.Slice(report, func(i, j int) bool {
+        return report[i].AccountID < report[j].AccountID
+    })
+
+    resp := TipReportResponse{
+        Success:             true,
+        From:                from.Format("2006-01-02"),
+        To:                  to.Format("2006-01-02"),
+        TotalTips:           billing.FromCents(totalCents),
+        RiderTips:           billing.FromCents(riderCents),
+        UnassignedRiderTips: billing.FromCents(unassignedCents),
+        StaffPool:           billing.FromCents(poolCents),
+        Staff:               report,
+        Message:             "Tip report generated successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
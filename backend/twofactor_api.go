@@ -0,0 +1,613 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "log"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+    "github.com/Bryanthai/ordersystem/internal/totp"
+)
+
+const (
+    recoveryCodeCount = 10
+    // loginChallengeMaxAttempts wrong codes use up a login challenge
+    loginChallengeMaxAttempts = 5
+)
+
+// TWO_FACTOR_ISSUER is the name authenticator apps show next to the code
+var (
+    loginChallengeTTL = envDuration("LOGIN_CHALLENGE_TTL", 5*time.Minute)
+    twoFactorIssuer   = envString("TWO_FACTOR_ISSUER", "Takeaway Dine-in")
+)
+
+// startLoginChallenge records that account got its password right and
+// returns the token that, with a code, finishes the login
+func startLoginChallenge(queries *database.Queries, req *http.Request, account database.Account, expiresInSeconds int) (string, error) {
+    token, err := auth.MakeToken()
+    if err != nil {
+        return "", err
+    }
+    now := time.Now().UTC()
+    err = queries.CreateLoginChallenge(context.Background(), database.CreateLoginChallengeParams{
+        UserID:           account.ID,
+        TokenHash:        auth.HashToken(token),
+        ExpiresInSeconds: int32(expiresInSeconds),
+        Ip:               truncate(clientIP(req), 64),
+        CreatedAt:        now,
+        ExpiresAt:        now.Add(loginChallengeTTL),
+    })
+    if err != nil {
+        return "", err
+    }
+    return token, nil
+}
+
+// checkSecondFactor accepts either a code from the authenticator app or an
+// unused recovery code. queries must run in a transaction, which keeps an
+// app code from being used twice.
+func checkSecondFactor(queries *database.Queries, userID int32, code string, now time.Time) (bool, error) {
+    code = strings.TrimSpace(code)
+    if len(strings.ReplaceAll(code, " ", "")) == totp.Digits {
+        secret, err := queries.GetTwoFactorSecretForUpdate(context.Background(), userID)
+        if err == sql.ErrNoRows {
+            return false, nil
+        }
+        if err != nil {
+            return false, err
+        }
+        step, ok := totp.Validate(secret.Secret, code, now, 1, secret.LastStep)
+        if !ok {
+            return false, nil
+        }
+        err = queries.SetTwoFactorLastStep(context.Background(), database.SetTwoFactorLastStepParams{
+            LastStep: step,
+            UserID:   userID,
+        })
+        return err == nil, err
+    }
+    n, err := queries.UseRecoveryCode(context.Background(), database.UseRecoveryCodeParams{
+        UsedAt:   sql.NullTime{Time: now, Valid: true},
+        UserID:   userID,
+        CodeHash: auth.HashToken(totp.NormalizeRecoveryCode(code)),
+    })
+    return n == 1, err
+}
+
+// verifySecondFactor checks a code sent by a signed-in account. Wrong codes
+// count as failed logins and are throttled the same way, so a stolen access
+// token can't be used to guess codes. It answers the request itself and
+// returns false unless the code was right.
+func verifySecondFactor(writer http.ResponseWriter, req *http.Request, queries, qtx *database.Queries, account database.Account, code string, now time.Time) bool {
+    ip := truncate(clientIP(req), 64)
+    wait, err := loginWait(queries, account.Username, ip, now)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return false
+    }
+    if wait > 0 {
+        writeLoginThrottled(writer, wait)
+        return false
+    }
+
+    ok, err := checkSecondFactor(qtx, account.ID, code, now)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return false
+    }
+    if !ok {
+        log.Println("Wrong two-factor code for user:", account.Username)
+        if err := recordLoginFailure(queries, account.Username, ip, now); err != nil {
+            log.Println("Error recording login failure:", err)
+        }
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "wrong_code")
+        http.Error(writer, "Invalid code", http.StatusBadRequest)
+        return false
+    }
+    return true
+}
+
+// replaceRecoveryCodes drops the account's recovery codes and returns a new
+// set; only their hashes are kept
+func replaceRecoveryCodes(queries *database.Queries, userID int32, now time.Time) ([]string, error) {
+    codes, err := totp.RecoveryCodes(recoveryCodeCount)
+    if err != nil {
+        return nil, err
+    }
+    if err := queries.DeleteRecoveryCodes(context.Background(), userID); err != nil {
+        return nil, err
+    }
+    for _, code := range codes {
+        err := queries.CreateRecoveryCode(context.Background(), database.CreateRecoveryCodeParams{
+            UserID:    userID,
+            CodeHash:  auth.HashToken(code),
+            CreatedAt: now,
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+    return codes, nil
+}
+
+// LOGIN: SECOND STEP
+func loginTwoFactorHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Two-factor login request received")
+
+    type TwoFactorLoginRequest struct {
+        ChallengeToken string `json:"challenge_token"`
+        Code           string `json:"code"`
+    }
+
+    var loginReq TwoFactorLoginRequest
+    if err := json.NewDecoder(req.Body).Decode(&loginReq); err != nil || loginReq.ChallengeToken == "" || loginReq.Code == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    challenge, err := qtx.GetLoginChallengeForUpdate(context.Background(), auth.HashToken(loginReq.ChallengeToken))
+    if err != nil || challenge.UsedAt.Valid || !challenge.ExpiresAt.After(now) || challenge.Attempts >= loginChallengeMaxAttempts {
+        http.Error(writer, "Invalid or expired login, please log in again", http.StatusUnauthorized)
+        return
+    }
+    account, err := qtx.GetAccountByID(context.Background(), challenge.UserID)
+    if err != nil {
+        http.Error(writer, "Invalid or expired login, please log in again", http.StatusUnauthorized)
+        return
+    }
+
+    ip := truncate(clientIP(req), 64)
+    wait, err := loginWait(queries, account.Username, ip, now)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if wait > 0 {
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "throttled")
+        writeLoginThrottled(writer, wait)
+        return
+    }
+
+    if blocked := accountBlocked(account, now); blocked != "" {
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "blocked")
+        http.Error(writer, blocked, http.StatusForbidden)
+        return
+    }
+
+    ok, err := checkSecondFactor(qtx, account.ID, loginReq.Code, now)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if !ok {
+        err = qtx.AddLoginChallengeAttempt(context.Background(), challenge.ChallengeID)
+        if err == nil {
+            err = tx.Commit()
+        }
+        if err != nil {
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        log.Println("Wrong two-factor code for user:", account.Username)
+        if err := recordLoginFailure(queries, account.Username, ip, now); err != nil {
+            log.Println("Error recording login failure:", err)
+        }
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "wrong_code")
+        http.Error(writer, "Invalid code", http.StatusUnauthorized)
+        return
+    }
+
+    err = qtx.UseLoginChallenge(context.Background(), database.UseLoginChallengeParams{
+        UsedAt:      sql.NullTime{Time: now, Valid: true},
+        ChallengeID: challenge.ChallengeID,
+    })
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+
+    finishLogin(writer, req, queries, account, int(challenge.ExpiresInSeconds))
+}
+
+// TWO-FACTOR STATUS
+func getTwoFactorHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Get two-factor request received from user:", username)
+
+    type TwoFactorResponse struct {
+        Success           bool   `json:"success"`
+        Enabled           bool   `json:"enabled"`
+        Required          bool   `json:"required"`
+        RecoveryCodesLeft int64  `json:"recovery_codes_left"`
+        Message           string `json:"message"`
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    left, err := queries.CountRecoveryCodes(context.Background(), userID)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+
+    account := currentAccount(req)
+    resp := TwoFactorResponse{
+        Success:           true,
+        Enabled:           account.TwoFactorEnabledAt.Valid,
+        Required:          twoFactorHeldBack(req) || rbac.RequiresTwoFactor(currentRoles(req)),
+        RecoveryCodesLeft: left,
+        Message:           "Two-factor status retrieved successfully",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// ENROLL TWO-FACTOR
+func enrollTwoFactorHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Enroll two-factor request received from user:", username)
+
+    type EnrollRequest struct {
+        Password string `json:"password"`
+    }
+    type EnrollResponse struct {
+        Success         bool   `json:"success"`
+        Secret          string `json:"secret"`
+        ProvisioningURI string `json:"provisioning_uri"`
+        Message         string `json:"message"`
+    }
+
+    var enrollReq EnrollRequest
+    if err := json.NewDecoder(req.Body).Decode(&enrollReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    account := currentAccount(req)
+    if !verifyPassword(writer, req, queries, account, enrollReq.Password, "Password is incorrect", time.Now().UTC()) {
+        return
+    }
+    if account.TwoFactorEnabledAt.Valid {
+        http.Error(writer, "Two-factor authentication is already on", http.StatusConflict)
+        return
+    }
+
+    secret, err := totp.NewSecret()
+    if err != nil {
+        http.Error(writer, "Failed to create secret", http.StatusInternalServerError)
+        return
+    }
+
+    // Starting over replaces a secret that was never confirmed
+    err = queries.SaveTwoFactorSecret(context.Background(), database.SaveTwoFactorSecretParams{
+        UserID:    userID,
+        Secret:    secret,
+        CreatedAt: time.Now().UTC(),
+    })
+    if err != nil {
+        http.Error(writer, "Failed to save secret", http.StatusInternalServerError)
+        return
+    }
+
+    resp := EnrollResponse{
+        Success:         true,
+        Secret:          secret,
+        ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, account.Username, secret),
+        Message:         "Scan the code with an authenticator app, then confirm with a code from it",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// CONFIRM TWO-FACTOR
+func confirmTwoFactorHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Confirm two-factor request received from user:", username)
+
+    type ConfirmRequest struct {
+        Code string `json:"code"`
+    }
+    type ConfirmResponse struct {
+        Success       bool     `json:"success"`
+        RecoveryCodes []string `json:"recovery_codes"`
+        Message       string   `json:"message"`
+    }
+
+    var confirmReq ConfirmRequest
+    if err := json.NewDecoder(req.Body).Decode(&confirmReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if currentAccount(req).TwoFactorEnabledAt.Valid {
+        http.Error(writer, "Two-factor authentication is already on", http.StatusConflict)
+        return
+    }
+    // Only an app code proves the secret was set up, not a recovery code
+    if len(strings.ReplaceAll(confirmReq.Code, " ", "")) != totp.Digits {
+        http.Error(writer, "Invalid code", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    if !verifySecondFactor(writer, req, queries, qtx, currentAccount(req), confirmReq.Code, now) {
+        return
+    }
+
+    codes, err := replaceRecoveryCodes(qtx, userID, now)
+    if err == nil {
+        err = qtx.SetTwoFactorEnabled(context.Background(), database.SetTwoFactorEnabledParams{
+            TwoFactorEnabledAt: sql.NullTime{Time: now, Valid: true},
+            ID:                 userID,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to turn on two-factor authentication", http.StatusInternalServerError)
+        return
+    }
+
+    resp := ConfirmResponse{
+        Success:       true,
+        RecoveryCodes: codes,
+        Message:       "Two-factor authentication is on. Keep the recovery codes somewhere safe; they are only shown once",
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// DISABLE TWO-FACTOR
+func disableTwoFactorHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Disable two-factor request received from user:", username)
+
+    type DisableRequest struct {
+        Password string `json:"password"`
+        Code     string `json:"code"`
+    }
+    type DisableResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var disableReq DisableRequest
+    if err := json.NewDecoder(req.Body).Decode(&disableReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    account := currentAccount(req)
+    if !account.TwoFactorEnabledAt.Valid {
+        http.Error(writer, "Two-factor authentication is not on", http.StatusConflict)
+        return
+    }
+    if rbac.RequiresTwoFactor(currentRoles(req)) {
+        http.Error(writer, "Two-factor authentication is required for your role", http.StatusForbidden)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    if !verifyPassword(writer, req, queries, account, disableReq.Password, "Password is incorrect", time.Now().UTC()) {
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    if !verifySecondFactor(writer, req, queries, qtx, account, disableReq.Code, time.Now().UTC()) {
+        return
+    }
+
+    err = qtx.DeleteTwoFactorSecret(context.Background(), userID)
+    if err == nil {
+        err = qtx.DeleteRecoveryCodes(context.Background(), userID)
+    }
+    if err == nil {
+        err = qtx.SetTwoFactorEnabled(context.Background(), database.SetTwoFactorEnabledParams{ID: userID})
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to turn off two-factor authentication", http.StatusInternalServerError)
+        return
+    }
+
+    resp := DisableResponse{Success: true, Message: "Two-factor authentication is off"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// NEW RECOVERY CODES
+func regenerateRecoveryCodesHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Recovery codes request received from user:", username)
+
+    type RecoveryCodesRequest struct {
+        Code string `json:"code"`
+    }
+    type RecoveryCodesResponse struct {
+        Success       bool     `json:"success"`
+        RecoveryCodes []string `json:"recovery_codes"`
+        Message       string   `json:"message"`
+    }
+
+    var codesReq RecoveryCodesRequest
+    if err := json.NewDecoder(req.Body).Decode(&codesReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if !currentAccount(req).TwoFactorEnabledAt.Valid {
+        http.Error(writer, "Two-factor authentication is not on", http.StatusConflict)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    if !verifySecondFactor(writer, req, queries, qtx, currentAccount(req), codesReq.Code, now) {
+        return
+    }
+
+    codes, err := replaceRecoveryCodes(qtx, userID, now)
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to create recovery codes", http.StatusInternalServerError)
+        return
+    }
+
+    resp := RecoveryCodesResponse{Success: true, RecoveryCodes: codes, Message: "New recovery codes created; the old ones no longer work"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// purgeLoginChallenges drops challenges that can no longer be answered
+func purgeLoginChallenges() {
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        log.Println("Login challenges: database error:", err)
+        return
+    }
+    defer db.Close()
+
+    err = database.New(db).DeleteExpiredLoginChallenges(context.Background(), time.Now().UTC())
+    if err != nil {
+        log.Println("Login challenges: failed to delete expired challenges:", err)
+    }
+}
@@ -0,0 +1,854 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "fmt"
+    "log"
+    "math"
+    "strings"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/privacy"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+    "github.com/Bryanthai/ordersystem/internal/verify"
+)
+
+// Account statuses. Suspended accounts come back by themselves once
+// suspended_until passes; banned ones stay out until an admin reactivates
+// them, and deleted ones have been anonymized.
+const (
+    statusActive    = "active"
+    statusSuspended = "suspended"
+    statusBanned    = "banned"
+    statusDeleted   = "deleted"
+)
+
+// maxBalance is the largest balance accounts.balance can hold
+const maxBalance = 999.99
+
+// accountBlocked says why account may not log in or order, or "" if it may
+func accountBlocked(account database.Account, now time.Time) string {
+    switch account.Status {
+    case statusSuspended:
+        if account.SuspendedUntil.Valid && !account.SuspendedUntil.Time.After(now) {
+            return ""
+        }
+        return "Account suspended"
+    case statusBanned:
+        return "Account banned"
+    case statusDeleted:
+        return "Account deleted"
+    }
+    return ""
+}
+
+// recordAudit adds an admin action to the audit log. details is stored as
+// JSON.
+func recordAudit(queries *database.Queries, actorID, targetID int32, action, reason string, details any) error {
+    var detailsJSON sql.NullString
+    if details != nil {
+        b, err := json.Marshal(details)
+        if err != nil {
+            return err
+        }
+        detailsJSON = sql.NullString{String: string(b), Valid: true}
+    }
+    return queries.CreateAuditLog(context.Background(), database.CreateAuditLogParams{
+        ActorID:      sql.NullInt32{Int32: actorID, Valid: actorID != 0},
+        TargetUserID: sql.NullInt32{Int32: targetID, Valid: targetID != 0},
+        Action:       action,
+        Reason:       truncate(reason, 255),
+        Details:      detailsJSON,
+        CreatedAt:    time.Now().UTC(),
+    })
+}
+
+// loadManagedUser loads the account an admin wants to act on and checks they
+// may: staff accounts can only be managed by someone who could assign all of
+// their roles. It returns a message and status on failure.
+func loadManagedUser(queries *database.Queries, req *http.Request, userID int32) (database.Account, string, int) {
+    target, err := queries.GetAccountByID(context.Background(), userID)
+    if err == sql.ErrNoRows {
+        return target, "User not found", http.StatusNotFound
+    }
+    if err != nil {
+        return target, "Database error", http.StatusInternalServerError
+    }
+    if target.Status == statusDeleted {
+        return target, "Account has been deleted", http.StatusConflict
+    }
+    roles, err := queries.GetAccountRoles(context.Background(), userID)
+    if err != nil {
+        return target, "Database error", http.StatusInternalServerError
+    }
+    for _, role := range roles {
+        if role != rbac.RoleCustomer && !rbac.CanAssign(currentRoles(req), role) {
+            return target, "You cannot manage this account", http.StatusForbidden
+        }
+    }
+    return target, "", 0
+}
+
+// checkRemovable refuses to lock out the acting admin or the last owner
+func checkRemovable(queries *database.Queries, req *http.Request, target database.Account) (string, int) {
+    if _, userID := currentUser(req); target.ID == userID {
+        return "You cannot do this to your own account", http.StatusBadRequest
+    }
+    roles, err := queries.GetAccountRoles(context.Background(), target.ID)
+    if err != nil {
+        return "Database error", http.StatusInternalServerError
+    }
+    for _, role := range roles {
+        if role != rbac.RoleOwner {
+            continue
+        }
+        owners, err := queries.CountRole(context.Background(), rbac.RoleOwner)
+        if err != nil {
+            return "Database error", http.StatusInternalServerError
+        }
+        if owners <= 1 {
+            return "Cannot remove the last owner", http.StatusConflict
+        }
+    }
+    return "", 0
+}
+
+// anonymizeAccount replaces everything that identifies account and signs it
+// out, keeping the row, its orders and payments so the books still add up.
+func anonymizeAccount(queries *database.Queries, account database.Account, reason string) error {
+    password, err := auth.MakeToken()
+    if err != nil {
+        return err
+    }
+    // Nobody knows this password, so the account cannot be logged into
+    hashed, err := auth.HashPassword(password[:32])
+    if err != nil {
+        return err
+    }
+    anonymous := fmt.Sprintf("deleted-%d", account.ID)
+    err = queries.AnonymizeAccount(context.Background(), database.AnonymizeAccountParams{
+        Username:     anonymous,
+        Password:     hashed,
+        Email:        fmt.Sprintf("deleted-%d@invalid", account.ID),
+        StatusReason: truncate(reason, 255),
+        ID:           account.ID,
+    })
+    if err != nil {
+        return err
+    }
+    return privacy.Erase(context.Background(), accountEraser{queries: queries}, privacy.Account{
+        ID:        account.ID,
+        Username:  account.Username,
+        Anonymous: anonymous,
+    }, proofPhotoDir)
+}
+
+// accountEraser clears an account's personal data from the database
+type accountEraser struct {
+    queries *database.Queries
+}
+
+func (e accountEraser) DeleteAddresses(ctx context.Context, userID int32) error {
+    return e.queries.DeleteAddressesByUser(ctx, userID)
+}
+
+func (e accountEraser) ClearOrders(ctx context.Context, userID int32) error {
+    return e.queries.ClearOrderPersonalData(ctx, userID)
+}
+
+func (e accountEraser) ClearDeliveryProofs(ctx context.Context, userID int32) ([]string, error) {
+    rows, err := e.queries.GetDeliveryProofPhotosByUser(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    photos := make([]string, 0, len(rows))
+    for _, row := range rows {
+        photos = append(photos, row.String)
+    }
+    return photos, e.queries.ClearDeliveryProofsByUser(ctx, userID)
+}
+
+func (e accountEraser) ClearGiftCardMessages(ctx context.Context, userID int32) error {
+    return e.queries.ClearGiftCardMessages(ctx, sql.NullInt32{Int32: userID, Valid: true})
+}
+
+func (e accountEraser) ClearReservationNotes(ctx context.Context, userID int32) error {
+    return e.queries.ClearReservationNotes(ctx, userID)
+}
+
+func (e accountEraser) AnonymizeWaitlist(ctx context.Context, userID int32) error {
+    return e.queries.AnonymizeWaitlist(ctx, sql.NullInt32{Int32: userID, Valid: true})
+}
+
+func (e accountEraser) DeleteNotifications(ctx context.Context, userID int32) error {
+    return e.queries.DeleteNotificationsByUser(ctx, userID)
+}
+
+func (e accountEraser) RevokeStaffRoles(ctx context.Context, userID int32) error {
+    return e.queries.RevokeStaffRoles(ctx, userID)
+}
+
+func (e accountEraser) DeleteTwoFactor(ctx context.Context, userID int32) error {
+    if err := e.queries.DeleteTwoFactorSecret(ctx, userID); err != nil {
+        return err
+    }
+    return e.queries.DeleteRecoveryCodes(ctx, userID)
+}
+
+func (e accountEraser) DeleteVerifications(ctx context.Context, userID int32) error {
+    if err := e.queries.DeleteVerifications(ctx, userID); err != nil {
+        return err
+    }
+    return e.queries.DeletePasswordResets(ctx, userID)
+}
+
+func (e accountEraser) DeleteSessions(ctx context.Context, userID int32) error {
+    return e.queries.DeleteSessionsByUser(ctx, userID)
+}
+
+// DeleteMail drops queued and sent mail, which still carries the address
+// and name
+func (e accountEraser) DeleteMail(ctx context.Context, userID int32) error {
+    return e.queries.DeleteMailByUser(ctx, sql.NullInt32{Int32: userID, Valid: true})
+}
+
+func (e accountEraser) AnonymizeLoginHistory(ctx context.Context, userID int32, anonymous string) error {
+    return e.queries.AnonymizeLoginAttempts(ctx, database.AnonymizeLoginAttemptsParams{
+        Username: anonymous,
+        UserID:   sql.NullInt32{Int32: userID, Valid: true},
+    })
+}
+
+func (e accountEraser) ClearLoginThrottle(ctx context.Context, username string) error {
+    _, err := e.queries.ClearLoginThrottle(ctx, database.ClearLoginThrottleParams{
+        Scope:   throttleAccount,
+        Subject: loginSubject(username),
+    })
+    return err
+}
+
+// ADMIN: SET ACCOUNT STATUS
+func setUserStatusHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Set user status request received from user:", username)
+
+    type StatusRequest struct {
+        UserID int32      `json:"user_id"`
+        Status string     `json:"status"`
+        Reason string     `json:"reason"`
+        Until  *time.Time `json:"until"`
+    }
+    type StatusResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var statusReq StatusRequest
+    if err := json.NewDecoder(req.Body).Decode(&statusReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    statusReq.Reason = strings.TrimSpace(statusReq.Reason)
+    if statusReq.Reason == "" {
+        http.Error(writer, "A reason is required", http.StatusBadRequest)
+        return
+    }
+    now := time.Now().UTC()
+    var until sql.NullTime
+    switch statusReq.Status {
+    case statusActive, statusBanned:
+        if statusReq.Until != nil {
+            http.Error(writer, "Only suspensions can have an end", http.StatusBadRequest)
+            return
+        }
+    case statusSuspended:
+        if statusReq.Until != nil {
+            if !statusReq.Until.After(now) {
+                http.Error(writer, "Suspension end must be in the future", http.StatusBadRequest)
+                return
+            }
+            until = sql.NullTime{Time: statusReq.Until.UTC(), Valid: true}
+        }
+    default:
+        http.Error(writer, "Status must be active, suspended or banned", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    target, message, status := loadManagedUser(queries, req, statusReq.UserID)
+    if status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+    if statusReq.Status != statusActive {
+        if message, status := checkRemovable(queries, req, target); status != 0 {
+            http.Error(writer, message, status)
+            return
+        }
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    err = qtx.SetAccountStatus(context.Background(), database.SetAccountStatusParams{
+        Status:         statusReq.Status,
+        StatusReason:   truncate(statusReq.Reason, 255),
+        SuspendedUntil: until,
+        ID:             target.ID,
+    })
+    // Blocked accounts are signed out at once
+    if err == nil && statusReq.Status != statusActive {
+        err = revokeSessions(qtx, target.ID)
+    }
+    if err == nil {
+        err = recordAudit(qtx, adminID, target.ID, "status."+statusReq.Status, statusReq.Reason, map[string]any{
+            "previous_status": target.Status,
+            "until":           statusReq.Until,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to update account status", http.StatusInternalServerError)
+        return
+    }
+    log.Printf("User %s set account %d to %s", username, target.ID, statusReq.Status)
+
+    resp := StatusResponse{Success: true, Message: "Account status updated"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: ADJUST WALLET
+func adjustWalletHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Adjust wallet request received from user:", username)
+
+    type AdjustRequest struct {
+        UserID int32   `json:"user_id"`
+        Amount float64 `json:"amount"`
+        Reason string  `json:"reason"`
+    }
+    type AdjustResponse struct {
+        Success bool    `json:"success"`
+        Balance float64 `json:"balance"`
+        Message string  `json:"message"`
+    }
+
+    var adjustReq AdjustRequest
+    if err := json.NewDecoder(req.Body).Decode(&adjustReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    adjustReq.Reason = strings.TrimSpace(adjustReq.Reason)
+    if adjustReq.Reason == "" {
+        http.Error(writer, "A reason is required", http.StatusBadRequest)
+        return
+    }
+    amount := math.Round(adjustReq.Amount*100) / 100
+    if amount == 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+        http.Error(writer, "Invalid amount", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    target, message, status := loadManagedUser(queries, req, adjustReq.UserID)
+    if status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    if amount > 0 {
+        if target.Balance+amount > maxBalance {
+            http.Error(writer, fmt.Sprintf("Balance cannot exceed %.2f", maxBalance), http.StatusBadRequest)
+            return
+        }
+        err = qtx.AddAccountBalance(context.Background(), database.AddAccountBalanceParams{
+            Balance: amount,
+            ID:      target.ID,
+        })
+    } else {
+        var rows int64
+        rows, err = qtx.DeductAccountBalance(context.Background(), database.DeductAccountBalanceParams{
+            Amount: -amount,
+            ID:     target.ID,
+        })
+        if err == nil && rows == 0 {
+            http.Error(writer, "Balance cannot go below zero", http.StatusConflict)
+            return
+        }
+    }
+    var account database.Account
+    if err == nil {
+        account, err = qtx.GetAccountByID(context.Background(), target.ID)
+    }
+    if err == nil {
+        err = recordAudit(qtx, adminID, target.ID, "wallet.adjust", adjustReq.Reason, map[string]any{
+            "amount":         amount,
+            "balance_before": target.Balance,
+            "balance_after":  account.Balance,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to adjust balance", http.StatusInternalServerError)
+        return
+    }
+    log.Printf("User %s adjusted the balance of account %d by %.2f", username, target.ID, amount)
+
+    resp := AdjustResponse{Success: true, Balance: account.Balance, Message: "Balance adjusted"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: EDIT USER
+func editUserHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Edit user request received from user:", username)
+
+    type EditRequest struct {
+        UserID  int32  `json:"user_id"`
+        Email   string `json:"email"`
+        Address string `json:"address"`
+        Phone   int64  `json:"phone"`
+        Reason  string `json:"reason"`
+    }
+    type EditResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var editReq EditRequest
+    if err := json.NewDecoder(req.Body).Decode(&editReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    editReq.Reason = strings.TrimSpace(editReq.Reason)
+    if editReq.Reason == "" {
+        http.Error(writer, "A reason is required", http.StatusBadRequest)
+        return
+    }
+    if editReq.Email == "" || editReq.Phone <= 0 {
+        http.Error(writer, "Email and phone are required", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    target, message, status := loadManagedUser(queries, req, editReq.UserID)
+    if status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+    if editReq.Email != target.Email {
+        if _, err := queries.GetAccountByEmail(context.Background(), editReq.Email); err == nil {
+            http.Error(writer, "Email is already in use", http.StatusConflict)
+            return
+        }
+    }
+    emailChanged := editReq.Email != target.Email
+    phoneChanged := editReq.Phone != target.UserPhoneNumber
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    err = qtx.AlterAccount(context.Background(), database.AlterAccountParams{
+        Email:           editReq.Email,
+        Address:         editReq.Address,
+        UserPhoneNumber: editReq.Phone,
+        Username:        target.Username,
+    })
+    // The customer still has to confirm new contact details themselves
+    if err == nil && emailChanged {
+        err = qtx.SetEmailVerified(context.Background(), database.SetEmailVerifiedParams{ID: target.ID})
+    }
+    if err == nil && phoneChanged {
+        err = qtx.SetPhoneVerified(context.Background(), database.SetPhoneVerifiedParams{ID: target.ID})
+    }
+    if err == nil {
+        err = recordAudit(qtx, adminID, target.ID, "user.edit", editReq.Reason, map[string]any{
+            "email_changed":   emailChanged,
+            "phone_changed":   phoneChanged,
+            "address_changed": editReq.Address != target.Address,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to update account", http.StatusInternalServerError)
+        return
+    }
+
+    updated := target
+    updated.Email = editReq.Email
+    updated.UserPhoneNumber = editReq.Phone
+    if emailChanged {
+        if err := sendVerification(queries, updated, verify.ChannelEmail); err != nil {
+            log.Println("Failed to send email verification:", err)
+        }
+    }
+    if phoneChanged {
+        if err := sendVerification(queries, updated, verify.ChannelPhone); err != nil {
+            log.Println("Failed to send phone verification:", err)
+        }
+    }
+
+    resp := EditResponse{Success: true, Message: "Account updated successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: RESET PASSWORD
+func adminResetPasswordHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Admin reset password request received from user:", username)
+
+    type ResetRequest struct {
+        UserID int32  `json:"user_id"`
+        Reason string `json:"reason"`
+    }
+    type ResetResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var resetReq ResetRequest
+    if err := json.NewDecoder(req.Body).Decode(&resetReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    resetReq.Reason = strings.TrimSpace(resetReq.Reason)
+    if resetReq.Reason == "" {
+        http.Error(writer, "A reason is required", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    target, message, status := loadManagedUser(queries, req, resetReq.UserID)
+    if status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    // The old password stops working and the customer picks a new one from
+    // the emailed link; the admin never learns it
+    password, err := auth.MakeToken()
+    if err == nil {
+        err = setPassword(qtx, target, password[:32])
+    }
+    if err == nil {
+        err = sendPasswordReset(qtx, target, clientIP(req), time.Now().UTC())
+    }
+    if err == nil {
+        err = recordAudit(qtx, adminID, target.ID, "user.password_reset", resetReq.Reason, nil)
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to reset password", http.StatusInternalServerError)
+        return
+    }
+
+    resp := ResetResponse{Success: true, Message: "Password reset, a link to choose a new one has been emailed"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: DELETE USER
+func deleteUserHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, adminID := currentUser(req)
+
+    log.Println("Delete user request received from user:", username)
+
+    type DeleteRequest struct {
+        UserID int32  `json:"user_id"`
+        Mode   string `json:"mode"`
+        Reason string `json:"reason"`
+    }
+    type DeleteResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var deleteReq DeleteRequest
+    if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    deleteReq.Reason = strings.TrimSpace(deleteReq.Reason)
+    if deleteReq.Reason == "" {
+        http.Error(writer, "A reason is required", http.StatusBadRequest)
+        return
+    }
+    if deleteReq.Mode == "" {
+        deleteReq.Mode = "anonymize"
+    }
+    if deleteReq.Mode != "anonymize" && deleteReq.Mode != "delete" {
+        http.Error(writer, "Mode must be anonymize or delete", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    target, message, status := loadManagedUser(queries, req, deleteReq.UserID)
+    if status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+    if message, status := checkRemovable(queries, req, target); status != 0 {
+        http.Error(writer, message, status)
+        return
+    }
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    // The audit row points at the account; its username is personal data
+    details := map[string]any{"mode": deleteReq.Mode}
+    if deleteReq.Mode == "delete" {
+        // Deleting the row would take the orders with it, so accounts with
+        // any have to be anonymized instead
+        orders, err := qtx.CountOrdersByUser(context.Background(), target.ID)
+        if err != nil {
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        if orders > 0 {
+            http.Error(writer, "Account has orders, anonymize it instead", http.StatusConflict)
+            return
+        }
+        // Written first, while the target still exists; the reference is
+        // cleared when the row goes
+        err = recordAudit(qtx, adminID, target.ID, "user.delete", deleteReq.Reason, details)
+        if err == nil {
+            _, err = qtx.DeleteAccount(context.Background(), target.ID)
+        }
+    } else {
+        err = anonymizeAccount(qtx, target, deleteReq.Reason)
+        if err == nil {
+            err = recordAudit(qtx, adminID, target.ID, "user.anonymize", deleteReq.Reason, details)
+        }
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        log.Println("Error deleting account:", err)
+        http.Error(writer, "Failed to delete account", http.StatusInternalServerError)
+        return
+    }
+    log.Printf("User %s removed account %d (%s)", username, target.ID, deleteReq.Mode)
+
+    resp := DeleteResponse{Success: true, Message: "Account deleted"}
+    if deleteReq.Mode == "anonymize" {
+        resp.Message = "Account anonymized"
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// ADMIN: GET AUDIT LOG
+func getAuditLogHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get audit log request received from user:", username)
+
+    type AuditEntry struct {
+        AuditID      int32           `json:"audit_id"`
+        ActorID      *int32          `json:"actor_id"`
+        TargetUserID *int32          `json:"target_user_id"`
+        Action       string          `json:"action"`
+        Reason       string          `json:"reason"`
+        Details      json.RawMessage `json:"details"`
+        CreatedAt    time.Time       `json:"created_at"`
+    }
+    type GetAuditLogResponse struct {
+        Success bool         `json:"success"`
+        Entries []AuditEntry `json:"entries"`
+        Message string       `json:"message"`
+    }
+
+    params := database.GetAuditLogParams{Limit: 100}
+    query := req.URL.Query()
+    for name, dest := range map[string]*sql.NullInt32{"user_id": &params.TargetUserID, "actor_id": &params.ActorID} {
+        if s := query.Get(name); s != "" {
+            if _, err := fmt.Sscanf(s, "%d", &dest.Int32); err != nil {
+                http.Error(writer, "Invalid "+name, http.StatusBadRequest)
+                return
+            }
+            dest.Valid = true
+        }
+    }
+    if s := query.Get("limit"); s != "" {
+        if _, err := fmt.Sscanf(s, "%d", &params.Limit); err != nil || params.Limit <= 0 || params.Limit > 500 {
+            http.Error(writer, "Invalid limit", http.StatusBadRequest)
+            return
+        }
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    rows, err := queries.GetAuditLog(context.Background(), params)
+    if err != nil {
+        http.Error(writer, "Failed to get audit log", http.StatusInternalServerError)
+        return
+    }
+
+    entries := make([]AuditEntry, 0, len(rows))
+    for _, row := range rows {
+        entry := AuditEntry{
+            AuditID:   row.AuditID,
+            Action:    row.Action,
+            Reason:    row.Reason,
+            CreatedAt: row.CreatedAt,
+        }
+        if row.ActorID.Valid {
+            id := row.ActorID.Int32
+            entry.ActorID = &id
+        }
+        if row.TargetUserID.Valid {
+            id := row.TargetUserID.Int32
+            entry.TargetUserID = &id
+        }
+        if row.Details.Valid {
+            entry.Details = json.RawMessage(row.Details.String)
+        }
+        entries = append(entries, entry)
+    }
+
+    resp := GetAuditLogResponse{Success: true, Entries: entries, Message: "Audit log retrieved successfully"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
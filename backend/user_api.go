@@ -5,12 +5,18 @@ import(
 	"net/http"
 	"encoding/json"
 	"context"
+    "errors"
     "time"
+    "fmt"
     "log"
     "strings"
 
 	"github.com/Bryanthai/ordersystem/internal/database"
     "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/billing"
+    "github.com/Bryanthai/ordersystem/internal/payment"
+    "github.com/Bryanthai/ordersystem/internal/rbac"
+    "github.com/Bryanthai/ordersystem/internal/verify"
 )
 
 // LOGIN
@@ -26,13 +32,12 @@ func loginHandler(writer http.ResponseWriter, req *http.Request) {
         Password string `json:"password"`
         ExpiresInSeconds int `json:"expires_in_seconds"`
     }
-    type LoginResponse struct {
-        Success  bool   `json:"success"`
-        Message  string `json:"message"`
-        Token    string `json:"token"`
-        ID       int32  `json:"id"`
-        Username string `json:"username"`
-        IsAdmin  bool   `json:"is_admin"`
+    type ChallengeResponse struct {
+        Success           bool   `json:"success"`
+        Message           string `json:"message"`
+        TwoFactorRequired bool   `json:"two_factor_required"`
+        ChallengeToken    string `json:"challenge_token"`
+        ExpiresIn         int    `json:"expires_in"`
     }
 
     log.Println("Login request received")
@@ -53,35 +58,129 @@ func loginHandler(writer http.ResponseWriter, req *http.Request) {
     defer db.Close()
 
     queries := database.New(db)
-    account, err := queries.GetAccount(context.Background(), loginReq.Username)
+    now := time.Now().UTC()
+    ip := truncate(clientIP(req), 64)
+
+    wait, err := loginWait(queries, loginReq.Username, ip, now)
     if err != nil {
+        log.Println("Error checking login throttle:", err)
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if wait > 0 {
+        log.Println("Login throttled for user:", loginReq.Username, "from", ip)
+        recordLoginAttempt(queries, req, 0, loginReq.Username, false, "throttled")
+        writeLoginThrottled(writer, wait)
+        return
+    }
+
+    // Unknown usernames and wrong passwords get the same answer, so the
+    // endpoint cannot be used to find out who has an account
+    account, err := queries.GetAccount(context.Background(), loginReq.Username)
+    if err != nil && err != sql.ErrNoRows {
         log.Println("Error fetching account:", err)
-        http.Error(writer, "Invalid username", http.StatusUnauthorized)
+        http.Error(writer, "Database error", http.StatusInternalServerError)
         return
     }
-    err = auth.CheckPasswordHash(loginReq.Password, account.Password)
-    if err != nil {
-        log.Println("Invalid password for user:", loginReq.Username)
-        http.Error(writer, "Invalid password", http.StatusUnauthorized)
+    reason := ""
+    if err == sql.ErrNoRows {
+        auth.CheckPasswordHash(loginReq.Password, dummyPasswordHash)
+        reason = "unknown_user"
+    } else if auth.CheckPasswordHash(loginReq.Password, account.Password) != nil {
+        reason = "wrong_password"
+    }
+    if reason != "" {
+        log.Println("Failed login for user:", loginReq.Username, reason)
+        if err := recordLoginFailure(queries, loginReq.Username, ip, now); err != nil {
+            log.Println("Error recording login failure:", err)
+        }
+        recordLoginAttempt(queries, req, account.ID, loginReq.Username, false, reason)
+        http.Error(writer, errLoginFailed, http.StatusUnauthorized)
+        return
+    }
+    if blocked := accountBlocked(account, now); blocked != "" {
+        log.Println("Blocked account tried to log in:", account.Username)
+        recordLoginAttempt(queries, req, account.ID, account.Username, false, "blocked")
+        http.Error(writer, blocked, http.StatusForbidden)
+        return
+    }
+
+    // With two-factor authentication on, the password only earns a
+    // challenge; the tokens come from POST /users/login/2fa
+    if account.TwoFactorEnabledAt.Valid {
+        challenge, err := startLoginChallenge(queries, req, account, loginReq.ExpiresInSeconds)
+        if err != nil {
+            log.Println("Error starting login challenge:", err)
+            http.Error(writer, "Failed to start login", http.StatusInternalServerError)
+            return
+        }
+        resp := ChallengeResponse{
+            Success:           true,
+            Message:           "Two-factor code required",
+            TwoFactorRequired: true,
+            ChallengeToken:    challenge,
+            ExpiresIn:         int(loginChallengeTTL.Seconds()),
+        }
+        writer.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(writer).Encode(resp)
         return
     }
 
-    if loginReq.ExpiresInSeconds <= 0 {
-        loginReq.ExpiresInSeconds = 3600
-    } else if loginReq.ExpiresInSeconds > 3600 {
-        loginReq.ExpiresInSeconds = 3600
+    finishLogin(writer, req, queries, account, loginReq.ExpiresInSeconds)
+    return
+}
+
+type loginResponse struct {
+    Success  bool   `json:"success"`
+    Message  string `json:"message"`
+    ID       int32  `json:"id"`
+    Username string `json:"username"`
+    IsAdmin  bool   `json:"is_admin"`
+    Roles       []string `json:"roles"`
+    Permissions []string `json:"permissions"`
+    // TwoFactorSetupRequired is set for owners and managers who have not
+    // turned on two-factor authentication; their staff permissions are held
+    // back until they do
+    TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
+    sessionTokens
+}
+
+// finishLogin starts a session for an account that has proven who it is and
+// answers with its tokens and roles
+func finishLogin(writer http.ResponseWriter, req *http.Request, queries *database.Queries, account database.Account, expiresInSeconds int) {
+    roles, err := queries.GetAccountRoles(context.Background(), account.ID)
+    if err != nil {
+        log.Println("Error fetching roles:", err)
+        http.Error(writer, "Failed to load roles", http.StatusInternalServerError)
+        return
     }
-    token, err := auth.MakeJWT(account.ID, account.Username, time.Duration(loginReq.ExpiresInSeconds) * time.Second)
+
+    tokens, err := startSession(queries, req, account, time.Duration(expiresInSeconds) * time.Second)
     if err != nil {
-        log.Println("Error creating JWT:", err)
+        log.Println("Error starting session:", err)
         http.Error(writer, "Error creating JWT", http.StatusInternalServerError)
         return
     }
 
-    resp := LoginResponse{Success: true, Message: "Login successful", Token: token, ID: account.ID, Username: account.Username, IsAdmin: account.IsAdmin}
+    if err := clearLoginFailures(queries, account.Username); err != nil {
+        log.Println("Error clearing login throttle:", err)
+    }
+    recordLoginAttempt(queries, req, account.ID, account.Username, true, "")
+
+    setupRequired := rbac.RequiresTwoFactor(roles) && !account.TwoFactorEnabledAt.Valid
+    resp := loginResponse{
+        Success:                true,
+        Message:                "Login successful",
+        ID:                     account.ID,
+        Username:               account.Username,
+        IsAdmin:                rbac.Staff(roles),
+        Roles:                  roles,
+        Permissions:            rbac.Permissions(effectiveRoles(roles, account)),
+        TwoFactorSetupRequired: setupRequired,
+        sessionTokens:          tokens,
+    }
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
-    return
 }
 
 // REGISTER
@@ -113,6 +212,11 @@ func registerHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
+    if err := auth.CheckPasswordStrength(regReq.Password, regReq.Username); err != nil {
+        http.Error(writer, err.Error(), http.StatusBadRequest)
+        return
+    }
+
     hashedPassword, err := auth.HashPassword(regReq.Password)
     if err != nil {
         log.Println("Error hashing password:", err)
@@ -153,7 +257,28 @@ func registerHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    resp := RegisterResponse{Success: true, Message: "Registration successful"}
+    account, err := queries.GetAccount(context.Background(), regReq.Username)
+    if err == nil {
+        err = queries.GrantRole(context.Background(), database.GrantRoleParams{
+            UserID:    account.ID,
+            Role:      rbac.RoleCustomer,
+            GrantedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+        })
+    }
+    if err != nil {
+        log.Println("Error granting customer role:", err)
+        http.Error(writer, "Failed to create account", http.StatusInternalServerError)
+        return
+    }
+
+    // The account can sign in right away but cannot order until both are confirmed
+    for _, channel := range []string{verify.ChannelEmail, verify.ChannelPhone} {
+        if err := sendVerification(queries, account, channel); err != nil {
+            log.Println("Failed to send", channel, "verification:", err)
+        }
+    }
+
+    resp := RegisterResponse{Success: true, Message: "Registration successful, check your email and phone to verify your account"}
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
 
@@ -168,17 +293,7 @@ func alterAccountHandler(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     log.Println("Alter account request received for user:", username)
 
@@ -208,24 +323,58 @@ func alterAccountHandler(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
+    // Only the signed in account can be changed; the username in the body is ignored
+    account := currentAccount(req)
+    emailChanged := alterReq.Email != account.Email
+    phoneChanged := alterReq.Phone != account.UserPhoneNumber
 
-    if account.ID != userID || account.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
         return
     }
-    err = queries.AlterAccount(context.Background(), database.AlterAccountParams{
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    err = qtx.AlterAccount(context.Background(), database.AlterAccountParams{
         Email:          alterReq.Email,
         Address:        alterReq.Address,
         UserPhoneNumber: alterReq.Phone,
-        Username:       alterReq.Username,
+        Username:       account.Username,
     })
+    // A new email or phone number has to be confirmed again before ordering
+    if err == nil && emailChanged {
+        err = qtx.SetEmailVerified(context.Background(), database.SetEmailVerifiedParams{ID: account.ID})
+    }
+    if err == nil && phoneChanged {
+        err = qtx.SetPhoneVerified(context.Background(), database.SetPhoneVerifiedParams{ID: account.ID})
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
     if err != nil {
         http.Error(writer, "Failed to update account", http.StatusInternalServerError)
         return
     }
 
-    resp := AlterAccountResponse{Success: true, Message: "Account updated successfully"}
+    account.Email = alterReq.Email
+    account.UserPhoneNumber = alterReq.Phone
+    message := "Account updated successfully"
+    if emailChanged || phoneChanged {
+        message = "Account updated, please verify your new contact details"
+    }
+    if emailChanged {
+        if err := sendVerification(queries, account, verify.ChannelEmail); err != nil {
+            log.Println("Failed to send email verification:", err)
+        }
+    }
+    if phoneChanged {
+        if err := sendVerification(queries, account, verify.ChannelPhone); err != nil {
+            log.Println("Failed to send phone verification:", err)
+        }
+    }
+
+    resp := AlterAccountResponse{Success: true, Message: message}
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
 	return
@@ -270,17 +419,7 @@ func getFoodByUserTag(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, _, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     tags := getUserTag(username)
     if tags == nil {
@@ -341,26 +480,20 @@ func MakePayment(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, userID := currentUser(req)
 
     log.Println("Payment request received from user:", username)
 
     type PaymentRequest struct {
-        OrderID int32 `json:"order_id"`
+        OrderID  int32   `json:"order_id"`
+        TipKind  string  `json:"tip_kind"`
+        TipValue float64 `json:"tip_value"`
     }
     type PaymentResponse struct {
-        Success bool   `json:"success"`
-        Message string `json:"message"`
+        Success    bool    `json:"success"`
+        TipAmount  float64 `json:"tip_amount"`
+        ReceiptURL string  `json:"receipt_url"`
+        Message    string  `json:"message"`
     }
 
     var paymentReq PaymentRequest
@@ -384,53 +517,72 @@ func MakePayment(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    // Check if user has enough balance
-    account, err := queries.GetAccount(context.Background(), username)
+    counts, err := queries.CountBillShares(context.Background(), order.OrderID)
     if err != nil {
-        http.Error(writer, "Failed to retrieve account", http.StatusInternalServerError)
+        http.Error(writer, "Failed to check bill shares", http.StatusInternalServerError)
+        return
+    }
+    if counts.ShareCount > 0 {
+        http.Error(writer, "The bill for this order is split, pay a share instead", http.StatusConflict)
         return
     }
-    // Get the total price of the order
-    items, err := queries.GetOrderedItems(context.Background(), paymentReq.OrderID)
+
+    amount, err := orderAmountDue(queries, paymentReq.OrderID)
     if err != nil {
         http.Error(writer, "Failed to retrieve order items", http.StatusInternalServerError)
         return
     }
 
-    totalPrice := 0.0
-    for _, item := range items {
-        food, err := queries.GetFoodById(context.Background(), item.FoodID)
-        if err != nil {
-            http.Error(writer, "Failed to retrieve food item", http.StatusInternalServerError)
+    // Reject a bad tip before anything is charged
+    if paymentReq.TipKind != "" {
+        if _, err := billing.TipAmount(amount, paymentReq.TipKind, paymentReq.TipValue); err != nil {
+            http.Error(writer, err.Error(), http.StatusBadRequest)
             return
         }
-        totalPrice += food.Price * float64(item.Quantity)
     }
-    if account.Balance < totalPrice {
-        http.Error(writer, "Insufficient balance", http.StatusPaymentRequired)
+
+    // Pay through a wallet intent so the deduction is recorded
+    wallet := paymentProviders(queries)["wallet"]
+    intent, err := startOrderPayment(db, queries, wallet, userID, order.OrderID, amount)
+    if errors.Is(err, errOrderAlreadyPaid) {
+        http.Error(writer, "Invalid order ID or already paid", http.StatusBadRequest)
         return
     }
-
-    // Deduct the total price from user's balance
-    newBalance := account.Balance - totalPrice
-    err = queries.UpdateOrderUser(context.Background(), database.UpdateOrderUserParams{
-        Balance: newBalance,
-        ID:      userID,
-    })
-    if err != nil {
-        http.Error(writer, "Failed to update user balance", http.StatusInternalServerError)
+    if errors.Is(err, errOrderPaymentOpen) {
+        http.Error(writer, "This order already has a payment in progress", http.StatusConflict)
+        return
+    }
+    if errors.Is(err, errOrderSplit) {
+        http.Error(writer, "The bill for this order is split, pay a share instead", http.StatusConflict)
+        return
+    }
+    if errors.Is(err, payment.ErrInvalidAmount) {
+        http.Error(writer, "Amount must be positive", http.StatusBadRequest)
         return
     }
-
-    // Update order status to paid
-    err = queries.UpdateOrderPayment(context.Background(), paymentReq.OrderID)
-    
     if err != nil {
-        http.Error(writer, "Failed to update order status", http.StatusInternalServerError)
+        log.Println("Error starting payment:", err)
+        http.Error(writer, "Failed to create payment", http.StatusInternalServerError)
+        return
+    }
+    _, err = confirmPayment(db, queries, wallet, intent, payment.Method{})
+    if !writePaymentError(writer, err) {
         return
     }
 
-    resp := PaymentResponse{Success: true, Message: "Payment successful"}
+    resp := PaymentResponse{
+        Success:    true,
+        ReceiptURL: fmt.Sprintf("/orders/receipt?order_id=%d", order.OrderID),
+        Message:    "Payment successful",
+    }
+    if paymentReq.TipKind != "" {
+        // The order stays paid even if the tip can't be taken
+        resp.TipAmount, err = chargeTip(db, queries, userID, order, paymentReq.TipKind, paymentReq.TipValue)
+        if err != nil {
+            log.Println("Error charging tip:", err)
+            resp.Message = "Payment successful, but the tip could not be charged"
+        }
+    }
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(resp)
     return
@@ -444,22 +596,13 @@ func RechargeAccount(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, userID := currentUser(req)
 
     log.Println("Recharge request received from user:", username)
 
     type RechargeRequest struct {
-        Amount float64 `json:"amount"`
+        Amount     float64 `json:"amount"`
+        CardNumber string  `json:"card_number"`
     }
     type RechargeResponse struct {
         Success bool   `json:"success"`
@@ -476,6 +619,10 @@ func RechargeAccount(writer http.ResponseWriter, req *http.Request) {
         http.Error(writer, "Invalid recharge amount", http.StatusBadRequest)
         return
     }
+    if rechargeReq.CardNumber == "" {
+        http.Error(writer, "Missing card number", http.StatusBadRequest)
+        return
+    }
 
     db, err := sql.Open("mysql", dbURL)
     if err != nil {
@@ -486,21 +633,37 @@ func RechargeAccount(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    account, err := queries.GetAccount(context.Background(), username)
-    
-    if account.ID != userID || account.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+    fits, err := rechargeFits(queries, userID, rechargeReq.Amount)
+    if err != nil {
+        http.Error(writer, "Failed to retrieve account", http.StatusInternalServerError)
         return
     }
-    
-    // Update user's balance
-    newBalance := account.Balance + rechargeReq.Amount
-    err = queries.UpdateOrderUser(context.Background(), database.UpdateOrderUserParams{
-        Balance: newBalance,
-        ID:      userID,
+    if !fits {
+        http.Error(writer, fmt.Sprintf("Wallet balance cannot exceed %.2f", maxBalance), http.StatusConflict)
+        return
+    }
+
+    // The balance is only credited once the card gateway confirms the charge
+    card := paymentProviders(queries)["mock_card"]
+    intent, err := startPayment(queries, card, database.CreatePaymentIntentParams{
+        UserID:  userID,
+        Purpose: payment.PurposeRecharge,
+        Amount:  rechargeReq.Amount,
     })
     if err != nil {
-        http.Error(writer, "Failed to update user balance", http.StatusInternalServerError)
+        log.Println("Error starting payment:", err)
+        http.Error(writer, "Failed to create payment", http.StatusInternalServerError)
+        return
+    }
+    intent, err = confirmPayment(db, queries, card, intent, payment.Method{CardNumber: rechargeReq.CardNumber})
+    if !writePaymentError(writer, err) {
+        return
+    }
+    if intent.Status != payment.StatusSucceeded {
+        resp := RechargeResponse{Success: false, Message: "Recharge " + intent.Status}
+        writer.Header().Set("Content-Type", "application/json")
+        writer.WriteHeader(http.StatusPaymentRequired)
+        json.NewEncoder(writer).Encode(resp)
         return
     }
 
@@ -518,17 +681,7 @@ func GetAllUsers(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     db, err := sql.Open("mysql", dbURL)
     if err != nil {
@@ -541,12 +694,6 @@ func GetAllUsers(writer http.ResponseWriter, req *http.Request) {
 
     log.Println("Get all users request received from admin:", username)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
-
     users, err := queries.GetAllAccounts(context.Background())
     if err != nil {
         http.Error(writer, "Failed to retrieve users", http.StatusInternalServerError)
@@ -575,17 +722,7 @@ func GetAverageSpendingAll(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     db, err := sql.Open("mysql", dbURL)
     if err != nil {
@@ -596,11 +733,7 @@ func GetAverageSpendingAll(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
+    log.Println("Average spending request received from admin:", username)
 
     avgSpending, err := queries.GetAverageSpendingByAllUsers(context.Background())
     if avgSpending == nil {
@@ -633,17 +766,7 @@ func GetAverageSpendingByUser(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
+    username, _ := currentUser(req)
 
     db, err := sql.Open("mysql", dbURL)
     if err != nil {
@@ -654,11 +777,7 @@ func GetAverageSpendingByUser(writer http.ResponseWriter, req *http.Request) {
 
     queries := database.New(db)
 
-    admin, err := queries.GetAdminAccount(context.Background())
-    if admin.ID != userID || admin.Username != username {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
+    log.Println("Average spending by user request received from admin:", username)
 
     accounts, err := queries.GetAllAccounts(context.Background())
     if err != nil {
@@ -714,32 +833,7 @@ func getCurrentAccount(writer http.ResponseWriter, req *http.Request) {
         return
     }
 
-    token, err := auth.GetBearerToken(req.Header)
-    if err != nil {
-        http.Error(writer, "Invalid or missing token", http.StatusUnauthorized)
-        return
-    }
-
-    username, userID, err := auth.ValidateJWT(token, authKey)
-    if err != nil {
-        http.Error(writer, "Invalid token", http.StatusUnauthorized)
-        return
-    }
-
-    db, err := sql.Open("mysql", dbURL)
-    if err != nil {
-        http.Error(writer, "Database error", http.StatusInternalServerError)
-        return
-    }
-    defer db.Close()
-
-    queries := database.New(db)
-
-    account, err := queries.GetAccount(context.Background(), username)
-    if err != nil || account.ID != userID {
-        http.Error(writer, "Unauthorized", http.StatusUnauthorized)
-        return
-    }
+    account := currentAccount(req)
 
     writer.Header().Set("Content-Type", "application/json")
     json.NewEncoder(writer).Encode(account)
@@ -0,0 +1,395 @@
+package main
+
+import(
+	"database/sql"
+	"net/http"
+	"encoding/json"
+	"context"
+    "crypto/subtle"
+    "fmt"
+    "log"
+    "math"
+    "net/url"
+    "os"
+    "strconv"
+    "time"
+
+	"github.com/Bryanthai/ordersystem/internal/database"
+    "github.com/Bryanthai/ordersystem/internal/auth"
+    "github.com/Bryanthai/ordersystem/internal/verify"
+)
+
+// EMAIL_VERIFY_URL is the page of the frontend that takes the email token
+var (
+    emailVerifyTTL    = envDuration("EMAIL_VERIFY_TTL", 24*time.Hour)
+    phoneVerifyTTL    = envDuration("PHONE_VERIFY_TTL", 15*time.Minute)
+    verifyCooldown    = envDuration("VERIFY_RESEND_COOLDOWN", time.Minute)
+    verifyMaxAttempts = int32(envInt("VERIFY_MAX_ATTEMPTS", 5))
+    emailVerifyURL    = envString("EMAIL_VERIFY_URL", "http://localhost:5173/verify-email")
+)
+
+// smsSender delivers phone codes. SMS_TRANSPORT picks "webhook"
+// (SMS_WEBHOOK_URL, SMS_WEBHOOK_TOKEN) or "stdout", the default.
+var smsSender = loadSMSSender()
+
+func loadSMSSender() verify.SMSSender {
+    switch transport := envString("SMS_TRANSPORT", "stdout"); transport {
+    case "webhook":
+        return verify.WebhookSMS{
+            URL:    envString("SMS_WEBHOOK_URL", "http://localhost:9000/sms"),
+            Token:  os.Getenv("SMS_WEBHOOK_TOKEN"),
+            Client: &http.Client{Timeout: 10 * time.Second},
+        }
+    case "stdout":
+        return &verify.WriterSMS{W: os.Stdout}
+    default:
+        log.Printf("Invalid SMS_TRANSPORT %q, using stdout", transport)
+        return &verify.WriterSMS{W: os.Stdout}
+    }
+}
+
+func isVerified(account database.Account) bool {
+    return account.EmailVerifiedAt.Valid && account.PhoneVerifiedAt.Valid
+}
+
+// sendVerification replaces any outstanding code for channel with a new one
+// and sends it: a link by email, or a short code by SMS
+func sendVerification(queries *database.Queries, account database.Account, channel string) error {
+    now := time.Now().UTC()
+    err := queries.UseVerifications(context.Background(), database.UseVerificationsParams{
+        UsedAt:  sql.NullTime{Time: now, Valid: true},
+        UserID:  account.ID,
+        Channel: channel,
+    })
+    if err != nil {
+        return err
+    }
+
+    params := database.CreateVerificationParams{
+        UserID:    account.ID,
+        Channel:   channel,
+        CreatedAt: now,
+    }
+    var code string
+    switch channel {
+    case verify.ChannelEmail:
+        code, err = auth.MakeToken()
+        params.Target = account.Email
+        params.ExpiresAt = now.Add(emailVerifyTTL)
+    case verify.ChannelPhone:
+        code, err = verify.NewCode()
+        params.Target = verify.PhoneNumber(account.UserPhoneNumber)
+        params.ExpiresAt = now.Add(phoneVerifyTTL)
+    default:
+        return fmt.Errorf("unknown verification channel %q", channel)
+    }
+    if err != nil {
+        return err
+    }
+    params.CodeHash = auth.HashToken(code)
+    if err := queries.CreateVerification(context.Background(), params); err != nil {
+        return err
+    }
+
+    if channel == verify.ChannelEmail {
+        link := emailVerifyURL + "?token=" + url.QueryEscape(code)
+        body := fmt.Sprintf("Hello %s,\n\nConfirm your email address with this link:\n%s\n\nThe link expires in %s.\n", account.Username, link, emailVerifyTTL)
+        return queueMail(queries, account.ID, account.Email, "Confirm your email address", body)
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+    defer cancel()
+    text := fmt.Sprintf("Your verification code is %s. It expires in %s.", code, phoneVerifyTTL)
+    return smsSender.Send(ctx, params.Target, text)
+}
+
+// VERIFICATION STATUS
+func getVerificationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, _ := currentUser(req)
+
+    log.Println("Get verification request received from user:", username)
+
+    type VerificationResponse struct {
+        EmailVerified bool `json:"email_verified"`
+        PhoneVerified bool `json:"phone_verified"`
+        CanOrder      bool `json:"can_order"`
+    }
+
+    account := currentAccount(req)
+    resp := VerificationResponse{
+        EmailVerified: account.EmailVerifiedAt.Valid,
+        PhoneVerified: account.PhoneVerifiedAt.Valid,
+        CanOrder:      isVerified(account),
+    }
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+}
+
+// RESEND VERIFICATION
+func resendVerificationHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Resend verification request received from user:", username)
+
+    type ResendRequest struct {
+        Channel string `json:"channel"`
+    }
+    type ResendResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var resendReq ResendRequest
+    if err := json.NewDecoder(req.Body).Decode(&resendReq); err != nil {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    account := currentAccount(req)
+    switch resendReq.Channel {
+    case verify.ChannelEmail:
+        if account.EmailVerifiedAt.Valid {
+            http.Error(writer, "Email is already verified", http.StatusConflict)
+            return
+        }
+    case verify.ChannelPhone:
+        if account.PhoneVerifiedAt.Valid {
+            http.Error(writer, "Phone number is already verified", http.StatusConflict)
+            return
+        }
+    default:
+        http.Error(writer, "Channel must be email or phone", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    last, err := queries.GetLatestVerification(context.Background(), database.GetLatestVerificationParams{
+        UserID:  userID,
+        Channel: resendReq.Channel,
+    })
+    if err != nil && err != sql.ErrNoRows {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    if err == nil {
+        if wait := verify.ResendWait(last.CreatedAt, time.Now().UTC(), verifyCooldown); wait > 0 {
+            writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+            http.Error(writer, "Please wait before asking for another code", http.StatusTooManyRequests)
+            return
+        }
+    }
+
+    if err := sendVerification(queries, account, resendReq.Channel); err != nil {
+        log.Println("Failed to send verification:", err)
+        http.Error(writer, "Failed to send verification", http.StatusBadGateway)
+        return
+    }
+
+    resp := ResendResponse{Success: true, Message: "Verification sent"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// VERIFY EMAIL
+func verifyEmailHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    log.Println("Verify email request received")
+
+    type VerifyEmailRequest struct {
+        Token string `json:"token"`
+    }
+    type VerifyEmailResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var verifyReq VerifyEmailRequest
+    if err := json.NewDecoder(req.Body).Decode(&verifyReq); err != nil || verifyReq.Token == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    verification, err := qtx.GetVerificationByHashForUpdate(context.Background(), database.GetVerificationByHashForUpdateParams{
+        CodeHash: auth.HashToken(verifyReq.Token),
+        Channel:  verify.ChannelEmail,
+    })
+    if err != nil || verification.UsedAt.Valid || !verification.ExpiresAt.After(now) {
+        http.Error(writer, "Invalid or expired verification link", http.StatusBadRequest)
+        return
+    }
+    account, err := qtx.GetAccountByID(context.Background(), verification.UserID)
+    // A link sent to an address the customer has since changed is no good
+    if err != nil || account.Email != verification.Target {
+        http.Error(writer, "Invalid or expired verification link", http.StatusBadRequest)
+        return
+    }
+
+    err = qtx.UseVerifications(context.Background(), database.UseVerificationsParams{
+        UsedAt:  sql.NullTime{Time: now, Valid: true},
+        UserID:  account.ID,
+        Channel: verify.ChannelEmail,
+    })
+    if err == nil {
+        err = qtx.SetEmailVerified(context.Background(), database.SetEmailVerifiedParams{
+            EmailVerifiedAt: sql.NullTime{Time: now, Valid: true},
+            ID:              account.ID,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to verify email", http.StatusInternalServerError)
+        return
+    }
+
+    resp := VerifyEmailResponse{Success: true, Message: "Email verified"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
+
+// VERIFY PHONE
+func verifyPhoneHandler(writer http.ResponseWriter, req *http.Request) {
+    enableCORS(writer)
+    if req.Method == "OPTIONS" {
+        writer.WriteHeader(http.StatusOK)
+        return
+    }
+
+    username, userID := currentUser(req)
+
+    log.Println("Verify phone request received from user:", username)
+
+    type VerifyPhoneRequest struct {
+        Code string `json:"code"`
+    }
+    type VerifyPhoneResponse struct {
+        Success bool   `json:"success"`
+        Message string `json:"message"`
+    }
+
+    var verifyReq VerifyPhoneRequest
+    if err := json.NewDecoder(req.Body).Decode(&verifyReq); err != nil || verifyReq.Code == "" {
+        http.Error(writer, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    account := currentAccount(req)
+    if account.PhoneVerifiedAt.Valid {
+        http.Error(writer, "Phone number is already verified", http.StatusConflict)
+        return
+    }
+
+    db, err := sql.Open("mysql", dbURL)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer db.Close()
+
+    queries := database.New(db)
+
+    tx, err := db.BeginTx(context.Background(), nil)
+    if err != nil {
+        http.Error(writer, "Database error", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback()
+    qtx := queries.WithTx(tx)
+
+    now := time.Now().UTC()
+    verification, err := qtx.GetLatestVerificationForUpdate(context.Background(), database.GetLatestVerificationForUpdateParams{
+        UserID:  userID,
+        Channel: verify.ChannelPhone,
+    })
+    if err != nil || verification.UsedAt.Valid || !verification.ExpiresAt.After(now) ||
+        verification.Target != verify.PhoneNumber(account.UserPhoneNumber) {
+        http.Error(writer, "Invalid or expired code, request a new one", http.StatusBadRequest)
+        return
+    }
+    if verification.Attempts >= verifyMaxAttempts {
+        http.Error(writer, "Too many wrong codes, request a new one", http.StatusTooManyRequests)
+        return
+    }
+
+    if subtle.ConstantTimeCompare([]byte(auth.HashToken(verifyReq.Code)), []byte(verification.CodeHash)) != 1 {
+        err = qtx.AddVerificationAttempt(context.Background(), verification.VerificationID)
+        if err == nil {
+            err = tx.Commit()
+        }
+        if err != nil {
+            http.Error(writer, "Database error", http.StatusInternalServerError)
+            return
+        }
+        http.Error(writer, "Wrong code", http.StatusBadRequest)
+        return
+    }
+
+    err = qtx.UseVerifications(context.Background(), database.UseVerificationsParams{
+        UsedAt:  sql.NullTime{Time: now, Valid: true},
+        UserID:  userID,
+        Channel: verify.ChannelPhone,
+    })
+    if err == nil {
+        err = qtx.SetPhoneVerified(context.Background(), database.SetPhoneVerifiedParams{
+            PhoneVerifiedAt: sql.NullTime{Time: now, Valid: true},
+            ID:              userID,
+        })
+    }
+    if err == nil {
+        err = tx.Commit()
+    }
+    if err != nil {
+        http.Error(writer, "Failed to verify phone number", http.StatusInternalServerError)
+        return
+    }
+
+    resp := VerifyPhoneResponse{Success: true, Message: "Phone number verified"}
+    writer.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(writer).Encode(resp)
+    return
+}
@@ -0,0 +1,25 @@
+package main
+
+import(
+    "log"
+    "time"
+)
+
+// runEvery calls task every interval for the life of the server. A panic in
+// one run is logged and doesn't stop the next.
+func runEvery(name string, interval time.Duration, task func()) {
+    run := func() {
+        defer func() {
+            if r := recover(); r != nil {
+                log.Printf("Background task %s panicked: %v", name, r)
+            }
+        }()
+        task()
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for range ticker.C {
+        run()
+    }
+}